@@ -0,0 +1,106 @@
+package activity
+
+import (
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/models"
+)
+
+// DefaultLocale is the language of the messages when the reader
+// prefers none of the supported ones, see i18n.Locale
+const DefaultLocale = "en"
+
+// catalogs are the message templates of every supported language.
+// The messages of todos in a list have a key ending in ".list", the
+// placeholders are {actor}, {title}, {list}, {previous} and {fields}.
+var catalogs = map[string]map[string]string{
+	"en": {
+		"someone":             "Someone",
+		"todo.created":        "{actor} added '{title}'",
+		"todo.created.list":   "{actor} added '{title}' to {list}",
+		"todo.updated":        "{actor} changed the {fields} of '{title}'",
+		"todo.updated.list":   "{actor} changed the {fields} of '{title}' in {list}",
+		"todo.completed":      "{actor} completed '{title}'",
+		"todo.completed.list": "{actor} completed '{title}' in {list}",
+		"todo.reopened":       "{actor} reopened '{title}'",
+		"todo.reopened.list":  "{actor} reopened '{title}' in {list}",
+		"todo.moved":          "{actor} took '{title}' out of its list",
+		"todo.moved.list":     "{actor} moved '{title}' to {list}",
+		"todo.deleted":        "{actor} deleted '{title}'",
+		"todo.deleted.list":   "{actor} deleted '{title}' from {list}",
+		"todo.restored":       "{actor} restored '{title}'",
+		"todo.restored.list":  "{actor} restored '{title}' in {list}",
+		"list.created":        "{actor} created the list {list}",
+		"list.renamed":        "{actor} renamed the list {previous} to {list}",
+		"list.deleted":        "{actor} deleted the list {list}",
+		"list.restored":       "{actor} restored the list {list}",
+		"field.title":         "title",
+		"field.description":   "description",
+		"field.due_at":        "due date",
+		"field.timezone":      "timezone",
+		"field.recurrence":    "recurrence",
+	},
+	"es": {
+		"someone":             "Alguien",
+		"todo.created":        "{actor} añadió '{title}'",
+		"todo.created.list":   "{actor} añadió '{title}' a {list}",
+		"todo.updated":        "{actor} cambió {fields} de '{title}'",
+		"todo.updated.list":   "{actor} cambió {fields} de '{title}' en {list}",
+		"todo.completed":      "{actor} completó '{title}'",
+		"todo.completed.list": "{actor} completó '{title}' en {list}",
+		"todo.reopened":       "{actor} reabrió '{title}'",
+		"todo.reopened.list":  "{actor} reabrió '{title}' en {list}",
+		"todo.moved":          "{actor} sacó '{title}' de su lista",
+		"todo.moved.list":     "{actor} movió '{title}' a {list}",
+		"todo.deleted":        "{actor} eliminó '{title}'",
+		"todo.deleted.list":   "{actor} eliminó '{title}' de {list}",
+		"todo.restored":       "{actor} restauró '{title}'",
+		"todo.restored.list":  "{actor} restauró '{title}' en {list}",
+		"list.created":        "{actor} creó la lista {list}",
+		"list.renamed":        "{actor} renombró la lista {previous} a {list}",
+		"list.deleted":        "{actor} eliminó la lista {list}",
+		"list.restored":       "{actor} restauró la lista {list}",
+		"field.title":         "el título",
+		"field.description":   "la descripción",
+		"field.due_at":        "la fecha de vencimiento",
+		"field.timezone":      "la zona horaria",
+		"field.recurrence":    "la repetición",
+	},
+}
+
+// Message renders the activity in the language of the locale
+func Message(locale string, a *models.Activity) string {
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = catalogs[DefaultLocale]
+	}
+
+	key := a.Subject + "." + a.Verb
+	if a.Subject == models.ActivityTodo && a.ListName != "" {
+		key += ".list"
+	}
+	template, ok := catalog[key]
+	if !ok {
+		return ""
+	}
+
+	actor := a.Actor.Name
+	if actor == "" {
+		actor = catalog["someone"]
+	}
+
+	fields := make([]string, 0, len(a.FieldNames()))
+	for _, f := range a.FieldNames() {
+		if name, ok := catalog["field."+f]; ok {
+			fields = append(fields, name)
+		}
+	}
+
+	return strings.NewReplacer(
+		"{actor}", actor,
+		"{title}", a.Title,
+		"{list}", a.ListName,
+		"{previous}", a.Detail,
+		"{fields}", strings.Join(fields, ", "),
+	).Replace(template)
+}
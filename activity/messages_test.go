@@ -0,0 +1,44 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryMessageIsInEveryLocale(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key := range catalogs[DefaultLocale] {
+			assert.Contains(t, catalog, key, "%s is missing from %s", key, locale)
+		}
+		for key := range catalog {
+			assert.Contains(t, catalogs[DefaultLocale], key, "%s of %s is unknown", key, locale)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	a := &models.Activity{
+		Actor:    models.User{Name: "Bob"},
+		Verb:     models.ActivityCompleted,
+		Subject:  models.ActivityTodo,
+		Title:    "Ship release",
+		ListName: "Project X",
+	}
+	assert.Equal(t, "Bob completed 'Ship release' in Project X", Message("en", a))
+	assert.Equal(t, "Bob completó 'Ship release' en Project X", Message("es", a))
+
+	a.Verb = models.ActivityUpdated
+	a.ListName = ""
+	a.Fields = "title,due_at"
+	assert.Equal(t, "Bob changed the title, due date of 'Ship release'", Message("en", a))
+
+	renamed := &models.Activity{
+		Verb:     models.ActivityRenamed,
+		Subject:  models.ActivityList,
+		ListName: "Project Y",
+		Detail:   "Project X",
+	}
+	assert.Equal(t, "Someone renamed the list Project X to Project Y", Message("fr", renamed))
+}
@@ -0,0 +1,154 @@
+// Package activity keeps the human-readable feeds of what changed,
+// e.g. "Bob completed 'Ship release' in Project X". The Recorder
+// turns the published events into activities, and Message renders
+// them in the reader's language.
+package activity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+)
+
+// Recorder adds an activity to the feeds of the users of every
+// published change to a todo or a list. It is meant to be combined
+// with the event bus through events.Multi.
+type Recorder struct {
+	ar     repositories.ActivityRepository
+	lr     repositories.ListRepository
+	window time.Duration
+}
+
+var _ events.Publisher = &Recorder{}
+
+// NewRecorder creates Recorder instance. Updates to the same
+// subject made within the window of each other are grouped.
+func NewRecorder(ar repositories.ActivityRepository, lr repositories.ListRepository, window time.Duration) *Recorder {
+	return &Recorder{ar, lr, window}
+}
+
+// Publish records the activity of the event in the feeds of its users
+func (r *Recorder) Publish(e events.Event) error {
+	a := r.activity(e)
+	if a == nil || len(e.UserIDs) == 0 {
+		return nil
+	}
+
+	for _, userID := range e.UserIDs {
+		activity := *a
+		activity.UserID = userID
+		// events carry no actor yet, only the owners can change their
+		// todos and lists so the first of the users made the change
+		activity.ActorID = e.UserIDs[0]
+		if err := r.ar.Record(&activity, r.window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activity is a private function creating the activity of the event,
+// nil when it isn't worth showing in the feeds
+func (r *Recorder) activity(e events.Event) *models.Activity {
+	data, _ := e.Data.(map[string]interface{})
+
+	switch e.Resource {
+	case "todo":
+		verb, fields := todoVerb(e)
+		if verb == "" {
+			return nil
+		}
+		a := &models.Activity{
+			Verb:      verb,
+			Subject:   models.ActivityTodo,
+			SubjectID: e.ResourceID,
+		}
+		a.Title, _ = data["title"].(string)
+		a.AddFields(fields...)
+		if listID, _ := data["list_id"].(*uint); listID != nil {
+			a.ListID = listID
+			if list := r.lr.ByID(*listID); list != nil {
+				a.ListName = list.Name
+			}
+		}
+		return a
+
+	case "list":
+		verb := listVerb(e)
+		if verb == "" {
+			return nil
+		}
+		listID := e.ResourceID
+		a := &models.Activity{
+			Verb:      verb,
+			Subject:   models.ActivityList,
+			SubjectID: e.ResourceID,
+			ListID:    &listID,
+		}
+		a.Title, _ = data["name"].(string)
+		a.ListName = a.Title
+		if verb == models.ActivityRenamed {
+			a.Detail, _ = e.Previous["name"].(string)
+		}
+		return a
+	}
+
+	return nil
+}
+
+// todoVerb is the verb of the todo event, along with the fields it
+// changed when it's a plain update
+func todoVerb(e events.Event) (string, []string) {
+	switch e.Type {
+	case events.TodoCreated:
+		return models.ActivityCreated, nil
+	case events.TodoDeleted:
+		return models.ActivityDeleted, nil
+	case events.TodoRestored:
+		return models.ActivityRestored, nil
+	case events.TodoUpdated:
+	default:
+		return "", nil
+	}
+
+	if _, ok := e.Previous["completed"]; ok {
+		data, _ := e.Data.(map[string]interface{})
+		if completed, _ := data["completed"].(bool); completed {
+			return models.ActivityCompleted, nil
+		}
+		return models.ActivityReopened, nil
+	}
+	if _, ok := e.Previous["list_id"]; ok {
+		return models.ActivityMoved, nil
+	}
+
+	fields := make([]string, 0, len(e.Previous))
+	for field := range e.Previous {
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+	sort.Strings(fields)
+	return models.ActivityUpdated, fields
+}
+
+// listVerb is the verb of the list event
+func listVerb(e events.Event) string {
+	switch e.Type {
+	case events.ListCreated:
+		return models.ActivityCreated
+	case events.ListDeleted:
+		return models.ActivityDeleted
+	case events.ListRestored:
+		return models.ActivityRestored
+	case events.ListUpdated:
+		if _, ok := e.Previous["name"]; ok {
+			return models.ActivityRenamed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// todoUpdated creates the event of an update to a todo of the first user's list
+func todoUpdated(data map[string]interface{}, previous map[string]interface{}) events.Event {
+	listID := uint(3)
+	data["list_id"] = &listID
+	return events.Event{
+		Type:       events.TodoUpdated,
+		Resource:   "todo",
+		ResourceID: 2,
+		UserIDs:    []uint{1},
+		Data:       data,
+		Previous:   previous,
+	}
+}
+
+// newRecorder creates a Recorder whose activities are recorded by the returned mock
+func newRecorder() (*Recorder, *mocks.ActivityRepository) {
+	ar := &mocks.ActivityRepository{}
+	ar.On("Record", mock.AnythingOfType("*models.Activity"), 5*time.Minute).Return(nil)
+	lr := &mocks.ListRepository{}
+	lr.On("ByID", uint(3)).Return(&models.List{Model: gorm.Model{ID: 3}, UserID: 1, Name: "Project X"})
+
+	return NewRecorder(ar, lr, 5*time.Minute), ar
+}
+
+func TestRecorderRecordsCompletedTodos(t *testing.T) {
+	recorder, ar := newRecorder()
+
+	e := todoUpdated(
+		map[string]interface{}{"title": "Ship release", "completed": true},
+		map[string]interface{}{"completed": false},
+	)
+	assert.NoError(t, recorder.Publish(e))
+
+	ar.AssertNumberOfCalls(t, "Record", 1)
+	a := ar.Calls[0].Arguments.Get(0).(*models.Activity)
+	assert.Equal(t, models.ActivityCompleted, a.Verb)
+	assert.Equal(t, uint(1), a.UserID)
+	assert.Equal(t, uint(1), a.ActorID)
+	assert.Equal(t, "Ship release", a.Title)
+	assert.Equal(t, uint(3), *a.ListID)
+	assert.Equal(t, "Project X", a.ListName)
+}
+
+func TestRecorderRecordsTheFieldsOfUpdates(t *testing.T) {
+	recorder, ar := newRecorder()
+
+	e := todoUpdated(
+		map[string]interface{}{"title": "Ship the release"},
+		map[string]interface{}{"title": "Ship release", "description": ""},
+	)
+	assert.NoError(t, recorder.Publish(e))
+
+	a := ar.Calls[0].Arguments.Get(0).(*models.Activity)
+	assert.Equal(t, models.ActivityUpdated, a.Verb)
+	assert.Equal(t, []string{"description", "title"}, a.FieldNames())
+}
+
+func TestRecorderRecordsRenamedLists(t *testing.T) {
+	recorder, ar := newRecorder()
+
+	e := events.Event{
+		Type:       events.ListUpdated,
+		Resource:   "list",
+		ResourceID: 3,
+		UserIDs:    []uint{1},
+		Data:       map[string]interface{}{"id": uint(3), "name": "Project Y"},
+		Previous:   map[string]interface{}{"name": "Project X"},
+	}
+	assert.NoError(t, recorder.Publish(e))
+
+	a := ar.Calls[0].Arguments.Get(0).(*models.Activity)
+	assert.Equal(t, models.ActivityRenamed, a.Verb)
+	assert.Equal(t, "Project Y", a.ListName)
+	assert.Equal(t, "Project X", a.Detail)
+}
+
+func TestRecorderSkipsWhatIsNotWorthShowing(t *testing.T) {
+	recorder, ar := newRecorder()
+
+	assert.NoError(t, recorder.Publish(todoUpdated(map[string]interface{}{"title": "Ship release"}, nil)))
+	assert.NoError(t, recorder.Publish(events.Event{Type: events.ReminderCreated, Resource: "reminder", UserIDs: []uint{1}}))
+
+	ar.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,168 @@
+// Package apperrors defines the errors handlers return and how they
+// are responded with. Every error is responded with as an RFC 7807
+// problem, application/problem+json, carrying a stable code clients
+// can rely on. The internal cause of an error is only logged, along
+// with the ID of the request the problem names.
+package apperrors
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+)
+
+// MIMEProblem is the media type of the problem responses
+const MIMEProblem = "application/problem+json"
+
+// Codes of the errors which are not more specific
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationFailed     = "validation_failed"
+	CodeFailedDependency     = "failed_dependency"
+	CodePreconditionRequired = "precondition_required"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInternal             = "internal_error"
+	CodeUnavailable          = "unavailable"
+)
+
+// Error is an error of the application. Message is safe to show to
+// the clients, unlike Cause, and Fields are the messages of the
+// invalid fields of a request. Key is the message of the catalogs
+// translating Message, with Args. Extensions are added to the problem
+// as members of their own, e.g. the current version of a record.
+type Error struct {
+	Status     int
+	Code       string
+	Message    string
+	Key        string
+	Args       i18n.Args
+	Fields     map[string][]string
+	Extensions map[string]interface{}
+	Cause      error
+
+	invalid fieldErrors
+}
+
+// fieldErrors is implemented by the errors of invalid fields, such as
+// requests.ValidationErrors
+type fieldErrors interface {
+	FieldErrors(locale string) map[string][]string
+}
+
+// New creates an Error of the status with a code, its message is the
+// one of the code in the catalogs, e.g. "error.todo_not_found"
+func New(status int, code string) *Error {
+	key := "error." + code
+	return &Error{Status: status, Code: code, Message: i18n.T(i18n.DefaultLocale, key, nil), Key: key}
+}
+
+// Internal creates the Error of something that went wrong, the cause
+// is only logged
+func Internal(cause error) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal)
+	e.Cause = cause
+	return e
+}
+
+// From creates the Error of responding to a request with the error
+// and the status. Errors of the application keep their own status,
+// invalid fields are a validation problem, and the messages of the
+// errors of the server are kept from the clients.
+func From(status int, err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case fieldErrors:
+		v := New(status, CodeValidationFailed)
+		v.Fields, v.invalid = e.FieldErrors(i18n.DefaultLocale), e
+		return v
+	}
+
+	if status >= http.StatusInternalServerError {
+		e := New(status, codeOf(status))
+		e.Cause = err
+		return e
+	}
+	return &Error{Status: status, Code: codeOf(status), Message: err.Error()}
+}
+
+// Error returns the safe message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// With returns a copy of the error with the extension member
+func (e *Error) With(name string, value interface{}) *Error {
+	c := *e
+	c.Extensions = make(map[string]interface{}, len(e.Extensions)+1)
+	for n, v := range e.Extensions {
+		c.Extensions[n] = v
+	}
+	c.Extensions[name] = value
+	return &c
+}
+
+// Localize returns the message and the invalid fields of the error in
+// the language of the locale
+func (e *Error) Localize(locale string) (string, map[string][]string) {
+	message, fields := e.Message, e.Fields
+	if e.Key != "" {
+		message = i18n.T(locale, e.Key, e.Args)
+	}
+	if e.invalid != nil {
+		fields = e.invalid.FieldErrors(locale)
+	}
+	return message, fields
+}
+
+// codeOf is the code of the errors of the status which are not more
+// specific
+func codeOf(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusFailedDependency:
+		return CodeFailedDependency
+	case http.StatusPreconditionRequired:
+		return CodePreconditionRequired
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,114 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// invalid are the errors of the invalid fields of a request
+type invalid map[string][]string
+
+func (i invalid) Error() string {
+	return "invalid"
+}
+
+func (i invalid) FieldErrors(locale string) map[string][]string {
+	return i
+}
+
+// respond responds to a request to the path with the problem of the
+// error and decodes it
+func respond(method string, err error) (*httptest.ResponseRecorder, map[string]interface{}) {
+	return respondIn("", method, err)
+}
+
+// respondIn responds like respond to a request accepting the languages
+func respondIn(acceptLanguage string, method string, err error) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, "/todos/2", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	req.Header.Set("Accept-Language", acceptLanguage)
+	response := httptest.NewRecorder()
+	Handler(err, echo.New().NewContext(req, response))
+
+	var problem map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &problem)
+	return response, problem
+}
+
+func TestFrom(t *testing.T) {
+	notFound := New(http.StatusNotFound, "todo_not_found")
+	assert.Equal(t, notFound, From(http.StatusUnprocessableEntity, notFound))
+
+	e := From(http.StatusConflict, errors.New("Nothing to undo"))
+	assert.Equal(t, http.StatusConflict, e.Status)
+	assert.Equal(t, CodeConflict, e.Code)
+	assert.Equal(t, "Nothing to undo", e.Message)
+
+	e = From(http.StatusUnprocessableEntity, invalid{"title": {"The title field is required"}})
+	assert.Equal(t, CodeValidationFailed, e.Code)
+	assert.Equal(t, map[string][]string{"title": {"The title field is required"}}, e.Fields)
+
+	cause := errors.New("database is locked")
+	e = From(http.StatusServiceUnavailable, cause)
+	assert.Equal(t, CodeUnavailable, e.Code)
+	assert.NotContains(t, e.Message, "locked")
+	assert.True(t, errors.Is(e, cause))
+}
+
+func TestHandlerRespondsWithTheProblem(t *testing.T) {
+	response, problem := respond(echo.PUT, From(http.StatusUnprocessableEntity, invalid{"title": {"The title field is required"}}))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, MIMEProblem, response.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "about:blank", problem["type"])
+	assert.Equal(t, "Unprocessable Entity", problem["title"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), problem["status"])
+	assert.Equal(t, "/todos/2", problem["instance"])
+	assert.Equal(t, CodeValidationFailed, problem["code"])
+	assert.Equal(t, "req-1", problem["request_id"])
+	assert.Equal(t, map[string]interface{}{"title": []interface{}{"The title field is required"}}, problem["errors"])
+}
+
+func TestHandlerTranslatesTheMessage(t *testing.T) {
+	response, problem := respondIn("es-MX,es;q=0.9", echo.GET, New(http.StatusNotFound, "todo_not_found"))
+	assert.Equal(t, "es", response.Header().Get("Content-Language"))
+	assert.Equal(t, "Tarea no encontrada", problem["detail"])
+	assert.Equal(t, "todo_not_found", problem["code"])
+
+	_, problem = respondIn("fr", echo.GET, New(http.StatusNotFound, "todo_not_found"))
+	assert.Equal(t, "Todo not found", problem["detail"])
+}
+
+func TestHandlerAddsTheExtensions(t *testing.T) {
+	mismatch := New(http.StatusPreconditionFailed, "version_mismatch")
+	_, problem := respond(echo.PUT, mismatch.With("data", map[string]string{"title": "Ship release"}))
+
+	assert.Equal(t, "version_mismatch", problem["code"])
+	assert.Equal(t, map[string]interface{}{"title": "Ship release"}, problem["data"])
+	assert.Empty(t, mismatch.Extensions)
+}
+
+func TestHandlerHidesTheCause(t *testing.T) {
+	response, problem := respond(echo.POST, errors.New("UNIQUE constraint failed: users.email"))
+
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Equal(t, CodeInternal, problem["code"])
+	assert.NotContains(t, response.Body.String(), "UNIQUE")
+}
+
+func TestHandlerKeepsTheStatusOfEcho(t *testing.T) {
+	response, problem := respond(echo.GET, echo.ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, CodeNotFound, problem["code"])
+	assert.Equal(t, "Not found", problem["detail"])
+
+	response, _ = respond(echo.HEAD, echo.ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Empty(t, response.Body.String())
+}
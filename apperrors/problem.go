@@ -0,0 +1,119 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+// Problem is the RFC 7807 problem details of an error. The type of
+// every problem is about:blank, telling it is no more than its status,
+// while its code tells the errors apart. Errors lists the messages of
+// the invalid fields, Extensions are the members of its own.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Errors     map[string][]string    `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem creates the problem of the error responding to the
+// request, in the language of the request
+func NewProblem(ctx echo.Context, e *Error) *Problem {
+	detail, fields := e.Localize(i18n.Locale(ctx))
+	return &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(e.Status),
+		Status:     e.Status,
+		Detail:     detail,
+		Instance:   ctx.Request().URL.Path,
+		Code:       e.Code,
+		RequestID:  requestID(ctx),
+		Errors:     fields,
+		Extensions: e.Extensions,
+	}
+}
+
+// MarshalJSON encodes the extension members alongside the others
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	b, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	var standard map[string]json.RawMessage
+	if err := json.Unmarshal(b, &standard); err != nil {
+		return nil, err
+	}
+	for name, value := range standard {
+		members[name] = value
+	}
+	return json.Marshal(members)
+}
+
+// Handler is the echo.HTTPErrorHandler of the server, it responds to
+// the request with the problem of the error the handler returned
+func Handler(err error, ctx echo.Context) {
+	if err := Respond(ctx, err); err != nil {
+		ctx.Logger().Errorf("request %s: responding with the problem: %v", requestID(ctx), err)
+	}
+}
+
+// Respond responds with the problem of the error, unless a response
+// was sent already. The cause of the error is logged along with the
+// ID of the request.
+func Respond(ctx echo.Context, err error) error {
+	e := fromHandler(err)
+	if e.Cause != nil {
+		ctx.Logger().Errorf("request %s: %s %s: %v", requestID(ctx), ctx.Request().Method, ctx.Request().URL.Path, e.Cause)
+	}
+	if ctx.Response().Committed {
+		return nil
+	}
+	if ctx.Request().Method == http.MethodHead {
+		return ctx.NoContent(e.Status)
+	}
+
+	b, err := json.Marshal(NewProblem(ctx, e))
+	if err != nil {
+		return err
+	}
+	ctx.Response().Header().Set("Content-Language", i18n.Locale(ctx))
+	return ctx.Blob(e.Status, MIMEProblem, b)
+}
+
+// fromHandler creates the Error of the error a handler returned, the
+// errors of echo, e.g. for unknown routes, keep their status
+func fromHandler(err error) *Error {
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		return From(http.StatusInternalServerError, err)
+	}
+
+	e := New(he.Code, codeOf(he.Code))
+	e.Cause = he.Internal
+	if message, ok := he.Message.(string); ok && message != http.StatusText(he.Code) && he.Code < http.StatusInternalServerError {
+		e.Message, e.Key = message, ""
+	}
+	return e
+}
+
+// requestID is the ID the RequestID middleware gave the request
+func requestID(ctx echo.Context) string {
+	if id := ctx.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return ctx.Request().Header.Get(echo.HeaderXRequestID)
+}
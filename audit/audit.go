@@ -0,0 +1,142 @@
+// Package audit records who did what. Handlers describe what they
+// did with Log, and the Middleware appends it to the audit log
+// along with where the request came from.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Actions of the auth endpoints, the changes to the resources are
+// named like the events published for them, e.g. "todo.updated"
+const (
+	LoginSucceeded  = "auth.login"
+	LoginFailed     = "auth.login_failed"
+	Registered      = "auth.register"
+	PasswordChanged = "auth.password_changed"
+	ProfileUpdated  = "auth.profile_updated"
+)
+
+// Actions on the resources which publish no event of their own
+const (
+	TodoDestroyed       = "todo.destroyed"
+	TodosImported       = "todo.imported"
+	ListDestroyed       = "list.destroyed"
+	TrashEmptied        = "trash.emptied"
+	OperationUndone     = "operation.undone"
+	OperationRedone     = "operation.redone"
+	CalendarFeedCreated = "calendar_feed.created"
+	CalendarFeedRevoked = "calendar_feed.revoked"
+	WebhookCreated      = "webhook.created"
+	WebhookUpdated      = "webhook.updated"
+	WebhookDeleted      = "webhook.deleted"
+	WebhookRedelivered  = "webhook.redelivered"
+	NotificationsRead   = "notification.read"
+	PreferencesUpdated  = "notification.preferences_updated"
+	CommentCreated      = "comment.created"
+	CommentUpdated      = "comment.updated"
+	CommentDeleted      = "comment.deleted"
+	AttachmentCreated   = "attachment.created"
+	AttachmentDeleted   = "attachment.deleted"
+	ListMemberAdded     = "list_member.added"
+	ListMemberRemoved   = "list_member.removed"
+	ColumnCreated       = "column.created"
+	ColumnUpdated       = "column.updated"
+	ColumnMoved         = "column.moved"
+	ColumnDeleted       = "column.deleted"
+	WorkspaceCreated    = "workspace.created"
+	WorkspaceUpdated    = "workspace.updated"
+	MemberRoleChanged   = "workspace_member.role_changed"
+	MemberRemoved       = "workspace_member.removed"
+	InviteCreated       = "invite.created"
+	InviteRevoked       = "invite.revoked"
+	InviteAccepted      = "invite.accepted"
+	TemplateCreated     = "template.created"
+	TemplateUpdated     = "template.updated"
+	TemplateDeleted     = "template.deleted"
+	TemplateApplied     = "template.applied"
+)
+
+// contextKey is where the entries of the request are kept
+const contextKey = "audit"
+
+// Change is a field of the target which changed
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Log records that the authenticated user did the action to the
+// target, before and after being its state around the action
+// (nil when it did not exist). Only the fields which changed
+// are kept, so they must not hold secrets.
+func Log(ctx echo.Context, action string, targetType string, targetID uint, before, after interface{}) {
+	LogActor(ctx, auth.User(ctx), action, targetType, targetID, before, after)
+}
+
+// LogActor records that the actor did the action. It's for the
+// requests which aren't authenticated, the actor is nil when
+// the request didn't get to who it is.
+func LogActor(ctx echo.Context, actor *models.User, action string, targetType string, targetID uint, before, after interface{}) {
+	e := &models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+	}
+	if actor != nil {
+		e.ActorID = &actor.ID
+	}
+	if targetID != 0 {
+		e.TargetID = &targetID
+	}
+	if diff := Diff(before, after); len(diff) > 0 {
+		b, _ := json.Marshal(diff)
+		e.Diff = string(b)
+	}
+
+	entries, _ := ctx.Get(contextKey).([]*models.AuditEvent)
+	ctx.Set(contextKey, append(entries, e))
+}
+
+// Entries returns what was recorded during the request
+func Entries(ctx echo.Context) []*models.AuditEvent {
+	entries, _ := ctx.Get(contextKey).([]*models.AuditEvent)
+	return entries
+}
+
+// Diff compares the JSON encoding of the states field by field
+// and returns the fields which changed
+func Diff(before, after interface{}) map[string]Change {
+	from, to := fields(before), fields(after)
+
+	diff := make(map[string]Change)
+	for name, value := range from {
+		if other, ok := to[name]; !ok || !reflect.DeepEqual(value, other) {
+			diff[name] = Change{From: value, To: other}
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			diff[name] = Change{To: value}
+		}
+	}
+	return diff
+}
+
+// fields decodes the state as JSON fields, none when it's nil
+func fields(state interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if state == nil || reflect.ValueOf(state).Kind() == reflect.Ptr && reflect.ValueOf(state).IsNil() {
+		return fields
+	}
+
+	b, err := json.Marshal(state)
+	if err == nil {
+		json.Unmarshal(b, &fields)
+	}
+	return fields
+}
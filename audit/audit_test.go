@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestDiffKeepsWhatChanged(t *testing.T) {
+	before := &models.ListSnapshot{Name: "Work"}
+	after := map[string]interface{}{"name": "Office", "color": "red"}
+
+	diff := Diff(before, after)
+
+	assert.Equal(t, map[string]Change{
+		"name":  {From: "Work", To: "Office"},
+		"color": {To: "red"},
+	}, diff)
+	assert.Empty(t, Diff(before, &models.ListSnapshot{Name: "Work"}))
+	assert.Len(t, Diff((*models.ListSnapshot)(nil), before), 1)
+}
+
+func TestMiddlewareAppendsTheEntriesOfTheRequest(t *testing.T) {
+	repo := &mocks.AuditRepository{}
+	repo.On("Append", mock.AnythingOfType("*models.AuditEvent")).Return(nil)
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	req := httptest.NewRequest(echo.DELETE, "/lists/3", nil)
+	req.RemoteAddr = "203.0.113.7:52100"
+	req.Header.Set("User-Agent", "curl/8.0")
+	// the clients can't make up the address they are recorded with
+	req.Header.Set(echo.HeaderXRealIP, "198.51.100.1")
+	response := httptest.NewRecorder()
+	ctx := e.NewContext(req, response)
+	ctx.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+	auth.SetUser(ctx, &models.User{Model: gorm.Model{ID: 9}})
+
+	handler := Middleware(repo)(func(ctx echo.Context) error {
+		Log(ctx, "list.deleted", "list", 3, &models.ListSnapshot{Name: "Work"}, nil)
+		return ctx.NoContent(http.StatusNoContent)
+	})
+	assert.NoError(t, handler(ctx))
+
+	repo.AssertNumberOfCalls(t, "Append", 1)
+	entry := repo.Calls[0].Arguments.Get(0).(*models.AuditEvent)
+	assert.Equal(t, uint(9), *entry.ActorID)
+	assert.Equal(t, uint(3), *entry.TargetID)
+	assert.Equal(t, "203.0.113.7", entry.IP)
+	assert.Equal(t, "curl/8.0", entry.UserAgent)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.JSONEq(t, `{"name": {"from": "Work", "to": null}}`, entry.Diff)
+}
+
+func TestMiddlewareDoesNotFailTheRequest(t *testing.T) {
+	repo := &mocks.AuditRepository{}
+	repo.On("Append", mock.Anything).Return(errors.New("disk full"))
+
+	e := echo.New()
+	ctx := e.NewContext(httptest.NewRequest(echo.POST, "/auth/login", nil), httptest.NewRecorder())
+
+	handler := Middleware(repo)(func(ctx echo.Context) error {
+		LogActor(ctx, nil, LoginFailed, "user", 0, nil, map[string]string{"username": "alice"})
+		return ctx.NoContent(http.StatusForbidden)
+	})
+
+	assert.NoError(t, handler(ctx))
+}
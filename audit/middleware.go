@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/text"
+	"github.com/labstack/echo/v4"
+)
+
+// Lengths of the request details kept, the sizes of their columns
+const (
+	maxUserAgent = 255
+	maxRequestID = 64
+)
+
+// Middleware appends what the handler recorded with Log to the audit
+// log, once it's done. Failing to do so doesn't fail the request,
+// the change was already made, so it's logged instead.
+func Middleware(ar repositories.AuditRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			err := next(ctx)
+
+			for _, e := range Entries(ctx) {
+				e.IP = ctx.RealIP()
+				e.UserAgent = text.Truncate(ctx.Request().UserAgent(), maxUserAgent)
+				e.RequestID = text.Truncate(requestID(ctx), maxRequestID)
+
+				if err := ar.Append(e); err != nil {
+					ctx.Logger().Errorf("audit: appending %s: %v", e.Action, err)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// requestID is the ID the RequestID middleware gave to the request,
+// falling back to the one the client sent
+func requestID(ctx echo.Context) string {
+	if id := ctx.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return ctx.Request().Header.Get(echo.HeaderXRequestID)
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/labstack/echo/v4"
+)
+
+// ErrForbidden is returned when the user is not allowed to the route
+var ErrForbidden = apperrors.New(http.StatusForbidden, apperrors.CodeForbidden)
+
+// Admin only lets the admins through, identified by their IDs since
+// the users can change their usernames. It must come after the
+// middleware authenticating the user.
+func Admin(ids []uint) echo.MiddlewareFunc {
+	admins := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		admins[id] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			user := User(ctx)
+			if user == nil || !admins[user.ID] {
+				return apperrors.From(http.StatusForbidden, ErrForbidden)
+			}
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKey is where the authenticated user is stored in echo.Context
+const contextKey = "user"
+
+// ErrUnauthenticated is returned when the request has no valid token
+var ErrUnauthenticated = apperrors.New(http.StatusUnauthorized, apperrors.CodeUnauthenticated)
+
+// JWT issues and verifies the tokens returned on login
+type JWT struct {
+	config configs.AuthConfig
+}
+
+// NewJWT creates JWT instance
+func NewJWT(config configs.AuthConfig) *JWT {
+	return &JWT{config}
+}
+
+// Issue creates a signed token for the user
+func (j *JWT) Issue(u *models.User) (string, error) {
+	now := time.Now()
+	claims := jwt.StandardClaims{
+		Subject:   strconv.FormatUint(uint64(u.ID), 10),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(j.config.TTL).Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(j.config.Secret))
+}
+
+// Parse verifies the token and returns the ID of the user it was issued to
+func (j *JWT) Parse(token string) (uint, error) {
+	claims := new(jwt.StandardClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrUnauthenticated
+		}
+		return []byte(j.config.Secret), nil
+	})
+	if err != nil {
+		return 0, ErrUnauthenticated
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrUnauthenticated
+	}
+	return uint(id), nil
+}
+
+// Middleware authenticates the request using its bearer token and
+// makes the user available to the handlers through auth.User. The
+// language the user prefers becomes the one of the request.
+func (j *JWT) Middleware(ur repositories.UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			token := bearerToken(ctx)
+			if token == "" {
+				return apperrors.From(http.StatusUnauthorized, ErrUnauthenticated)
+			}
+
+			id, err := j.Parse(token)
+			if err != nil {
+				return apperrors.From(http.StatusUnauthorized, err)
+			}
+
+			user := ur.ByID(id)
+			if user == nil {
+				return apperrors.From(http.StatusUnauthorized, ErrUnauthenticated)
+			}
+
+			SetUser(ctx, user)
+			i18n.SetLocale(ctx, user.Locale)
+			return next(ctx)
+		}
+	}
+}
+
+// QueryToken lets the requests of the routes it is used on give their
+// bearer token by the access_token query param, since browsers can't
+// set headers on EventSource and WebSocket connections. The param is
+// taken off the URI so the token is never logged. It must come before
+// the middleware authenticating the user.
+func QueryToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		query := req.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			if req.Header.Get(echo.HeaderAuthorization) == "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			}
+			query.Del("access_token")
+			req.URL.RawQuery = query.Encode()
+			req.RequestURI = req.URL.RequestURI()
+		}
+		return next(ctx)
+	}
+}
+
+// bearerToken reads the token from the Authorization header
+func bearerToken(ctx echo.Context) string {
+	header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+	if token := strings.TrimPrefix(header, "Bearer "); token != header {
+		return token
+	}
+	return ""
+}
+
+// User returns the authenticated user of the request
+func User(ctx echo.Context) *models.User {
+	u, _ := ctx.Get(contextKey).(*models.User)
+	return u
+}
+
+// SetUser stores the authenticated user on the request
+func SetUser(ctx echo.Context, u *models.User) {
+	ctx.Set(contextKey, u)
+}
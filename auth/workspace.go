@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/echo/v4"
+)
+
+// membershipKey is where the membership of the user in the active
+// workspace is stored in echo.Context
+const membershipKey = "membership"
+
+// WorkspaceHeader is the header naming the workspace of a request
+const WorkspaceHeader = "X-Workspace-ID"
+
+// ErrWorkspaceNotFound is returned when the workspace does not exist or
+// the user is not one of its members, so their existence won't leak.
+var ErrWorkspaceNotFound = apperrors.New(http.StatusNotFound, "workspace_not_found")
+
+// Workspace resolves the workspace the request is made in from the
+// :workspace param of its path, else its X-Workspace-ID header, else
+// it is the user's personal workspace. The membership of the user in
+// there is available to the handlers through auth.Membership. It must
+// come after the middleware authenticating the user.
+func Workspace(wr repositories.WorkspaceRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			user := User(ctx)
+			id := ctx.Param("workspace")
+			if id == "" {
+				id = ctx.Request().Header.Get(WorkspaceHeader)
+			}
+
+			var membership *models.Membership
+			if id == "" {
+				w, err := wr.Personal(user)
+				if err != nil {
+					return apperrors.From(http.StatusInternalServerError, err)
+				}
+				membership = wr.Membership(w.ID, user.ID)
+			} else if workspaceID, err := strconv.ParseUint(id, 10, 64); err == nil {
+				membership = wr.Membership(uint(workspaceID), user.ID)
+			}
+			if membership == nil {
+				return apperrors.From(http.StatusNotFound, ErrWorkspaceNotFound)
+			}
+
+			SetMembership(ctx, membership)
+			return next(ctx)
+		}
+	}
+}
+
+// Membership returns the membership of the user in the workspace of the request
+func Membership(ctx echo.Context) *models.Membership {
+	m, _ := ctx.Get(membershipKey).(*models.Membership)
+	return m
+}
+
+// SetMembership stores the membership of the user in the workspace of the request
+func SetMembership(ctx echo.Context, m *models.Membership) {
+	ctx.Set(membershipKey, m)
+}
+
+// WorkspaceID returns the ID of the workspace of the request, 0 when
+// it has none
+func WorkspaceID(ctx echo.Context) uint {
+	if m := Membership(ctx); m != nil {
+		return m.WorkspaceID
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+// Package changelog keeps the change logs offline clients sync from.
+// The Recorder logs every published change to a todo or a list for
+// each of the users who can see it, deletes as tombstones.
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/gommon/log"
+)
+
+// Recorder appends the published changes to the todos and the lists
+// to the change logs of their users. It is meant to be combined with
+// the event bus through events.Multi.
+type Recorder struct {
+	cr repositories.ChangeRepository
+}
+
+var _ events.Publisher = &Recorder{}
+
+// NewRecorder creates Recorder instance
+func NewRecorder(cr repositories.ChangeRepository) *Recorder {
+	return &Recorder{cr}
+}
+
+// Publish logs the change of the event for each of its users. A change
+// which fails to be appended for one of them is still appended for the
+// others, the first failure is returned once they all were tried.
+func (r *Recorder) Publish(e events.Event) error {
+	change := newChange(e)
+	if change == nil {
+		return nil
+	}
+
+	var first error
+	for _, userID := range e.UserIDs {
+		c := *change
+		c.UserID = userID
+		if err := r.cr.Append(&c); err != nil {
+			err = fmt.Errorf("changelog: appending %s %d for user %d: %w", c.Resource, c.ResourceID, userID, err)
+			log.Error(err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// newChange is a private function creating the change of the event,
+// nil when it changed nothing clients sync
+func newChange(e events.Event) *models.Change {
+	if e.Resource != models.ChangeTodo && e.Resource != models.ChangeList {
+		return nil
+	}
+	data, _ := e.Data.(map[string]interface{})
+	at := e.CreatedAt
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	var fields []string
+	switch e.Type {
+	case events.TodoDeleted, events.ListDeleted:
+		fields = []string{models.ChangeDeleted}
+	case events.TodoUpdated, events.ListUpdated:
+		for field := range e.Previous {
+			fields = append(fields, field)
+		}
+	default:
+		for field := range data {
+			if field != "id" && field != "workspace_id" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	times := make(map[string]time.Time, len(fields))
+	for _, field := range fields {
+		times[field] = at
+	}
+	c := &models.Change{
+		Resource:   e.Resource,
+		ResourceID: e.ResourceID,
+		Deleted:    e.Type == events.TodoDeleted || e.Type == events.ListDeleted,
+	}
+	if workspaceID, ok := data["workspace_id"].(uint); ok {
+		c.WorkspaceID = workspaceID
+	}
+	if !c.Deleted {
+		b, _ := json.Marshal(data)
+		c.Data = string(b)
+	}
+	c.SetTimes(times)
+	return c
+}
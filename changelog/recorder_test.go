@@ -0,0 +1,157 @@
+package changelog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newRecorder creates a Recorder whose changes are appended by the returned mock
+func newRecorder() (*Recorder, *mocks.ChangeRepository) {
+	cr := &mocks.ChangeRepository{}
+	cr.On("Append", mock.AnythingOfType("*models.Change")).Return(nil)
+
+	return NewRecorder(cr), cr
+}
+
+// appended returns the changes appended to the mock
+func appended(cr *mocks.ChangeRepository) []*models.Change {
+	var changes []*models.Change
+	for _, call := range cr.Calls {
+		changes = append(changes, call.Arguments.Get(0).(*models.Change))
+	}
+	return changes
+}
+
+func TestRecorderLogsTheChangeForEveryUser(t *testing.T) {
+	recorder, cr := newRecorder()
+
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	e := events.Event{
+		Type:       events.TodoUpdated,
+		Resource:   "todo",
+		ResourceID: 2,
+		UserIDs:    []uint{1, 4},
+		Data:       map[string]interface{}{"id": uint(2), "workspace_id": uint(5), "title": "Ship it", "completed": true},
+		Previous:   map[string]interface{}{"title": "Ship release"},
+		CreatedAt:  at,
+	}
+	assert.NoError(t, recorder.Publish(e))
+
+	changes := appended(cr)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, uint(1), changes[0].UserID)
+		assert.Equal(t, uint(4), changes[1].UserID)
+		for _, c := range changes {
+			assert.Equal(t, models.ChangeTodo, c.Resource)
+			assert.Equal(t, uint(2), c.ResourceID)
+			assert.Equal(t, uint(5), c.WorkspaceID)
+			assert.False(t, c.Deleted)
+			assert.JSONEq(t, `{"id": 2, "workspace_id": 5, "title": "Ship it", "completed": true}`, c.Data)
+			// only the fields the update changed are stamped
+			assert.Equal(t, map[string]time.Time{"title": at}, c.Times())
+		}
+	}
+}
+
+func TestRecorderLogsTombstones(t *testing.T) {
+	recorder, cr := newRecorder()
+
+	e := events.Event{
+		Type:       events.ListDeleted,
+		Resource:   "list",
+		ResourceID: 3,
+		UserIDs:    []uint{1},
+		Data:       map[string]interface{}{"id": uint(3), "workspace_id": uint(0), "name": "Project X"},
+	}
+	assert.NoError(t, recorder.Publish(e))
+
+	changes := appended(cr)
+	if assert.Len(t, changes, 1) {
+		assert.True(t, changes[0].Deleted)
+		assert.Empty(t, changes[0].Data)
+		assert.Contains(t, changes[0].Times(), models.ChangeDeleted)
+	}
+}
+
+func TestRecorderSkipsWhatIsNotSynced(t *testing.T) {
+	recorder, cr := newRecorder()
+
+	// reminders are not synced, and neither are updates changing nothing
+	assert.NoError(t, recorder.Publish(events.Event{Type: events.ReminderCreated, Resource: "reminder", ResourceID: 1, UserIDs: []uint{1}}))
+	assert.NoError(t, recorder.Publish(events.Event{
+		Type:       events.TodoUpdated,
+		Resource:   "todo",
+		ResourceID: 2,
+		UserIDs:    []uint{1},
+		Data:       map[string]interface{}{"id": uint(2), "title": "Ship it"},
+	}))
+
+	cr.AssertNotCalled(t, "Append", mock.Anything)
+}
+
+func TestRecorderReturnsTheFailedAppends(t *testing.T) {
+	cr := &mocks.ChangeRepository{}
+	failure := errors.New("connection reset")
+	cr.On("Append", mock.MatchedBy(func(c *models.Change) bool { return c.UserID == 1 })).Return(failure)
+	cr.On("Append", mock.AnythingOfType("*models.Change")).Return(nil)
+
+	e := events.Event{
+		Type:       events.TodoCreated,
+		Resource:   "todo",
+		ResourceID: 2,
+		UserIDs:    []uint{1, 4},
+		Data:       map[string]interface{}{"id": uint(2), "title": "Ship it"},
+	}
+	err := NewRecorder(cr).Publish(e)
+
+	assert.True(t, errors.Is(err, failure))
+	// the change is still logged for the other users
+	if changes := appended(cr); assert.Len(t, changes, 2) {
+		assert.Equal(t, uint(4), changes[1].UserID)
+	}
+}
+
+func TestRestoringAListSyncsItsTodos(t *testing.T) {
+	db, _ := test.InitTestDB()
+	db.Where("1 = 1").Delete(&models.Change{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Reminder{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+
+	changes := repositories.NewChangeRepository(db)
+	recorder := NewRecorder(changes)
+	lists := repositories.NewListRepository(db, recorder)
+	todos := repositories.NewTodoRepository(db, recorder)
+
+	list := &models.List{UserID: 1, Name: "Groceries"}
+	assert.NoError(t, lists.Create(list))
+	milk := &models.Todo{UserID: 1, ListID: &list.ID, Title: "Milk"}
+	assert.NoError(t, todos.Create(milk))
+	synced := changes.Since(1, 0, 0, 100)
+	seq := synced[len(synced)-1].Seq
+
+	// the tombstone of the list stands for its todos on the clients
+	assert.NoError(t, lists.Delete(list.ID))
+	assert.NoError(t, lists.Restore(lists.TrashedByID(list.ID)))
+
+	pulled := changes.Since(1, 0, seq, 100)
+	if assert.Len(t, pulled, 3) {
+		assert.Equal(t, models.ChangeList, pulled[0].Resource)
+		assert.True(t, pulled[0].Deleted)
+		assert.Equal(t, models.ChangeList, pulled[1].Resource)
+		assert.False(t, pulled[1].Deleted)
+		assert.Equal(t, models.ChangeTodo, pulled[2].Resource)
+		assert.Equal(t, milk.ID, pulled[2].ResourceID)
+		assert.False(t, pulled[2].Deleted)
+		assert.Contains(t, pulled[2].Data, `"title":"Milk"`)
+	}
+}
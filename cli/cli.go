@@ -0,0 +1,210 @@
+// Package cli holds the subcommands of the application, which
+// share the services the HTTP API is built on.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/transfer"
+)
+
+// usage describes the subcommands
+const usage = `Usage:
+  todo-echo export -user <username|email> [-workspace id] [-format json] [-o file]
+  todo-echo import -user <username|email> [-workspace id] [-format csv] [-timezone UTC] [-dry-run] [-allow-duplicates] <file>`
+
+// CLI runs the subcommands
+type CLI struct {
+	ur       repositories.UserRepository
+	wr       repositories.WorkspaceRepository
+	importer *transfer.Importer
+	exporter *transfer.Exporter
+	out      io.Writer
+}
+
+// New creates a CLI writing its output to out
+func New(ur repositories.UserRepository, wr repositories.WorkspaceRepository, importer *transfer.Importer, exporter *transfer.Exporter, out io.Writer) *CLI {
+	return &CLI{ur, wr, importer, exporter, out}
+}
+
+// Run runs the subcommand named by the first argument
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "export":
+		return c.export(args[1:])
+	case "import":
+		return c.importFile(args[1:])
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+// export writes the todos of a user to a file or the output
+func (c *CLI) export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	username := fs.String("user", "", "username or email of the user")
+	workspaceID := fs.Uint("workspace", 0, "ID of the workspace (default: the user's personal one)")
+	name := fs.String("format", "", "csv, json, todotxt, markdown or ics (default: from -o, else json)")
+	output := fs.String("o", "", "file to write to (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := c.user(*username)
+	if err != nil {
+		return err
+	}
+	workspace, err := c.workspace(user, *workspaceID)
+	if err != nil {
+		return err
+	}
+	exporter := c.exporter.Workspace(workspace)
+
+	format := transfer.FormatJSON
+	switch {
+	case *name != "":
+		format, err = transfer.ParseFormat(*name)
+	case *output != "":
+		format, err = transfer.FormatFromFilename(*output)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return exporter.Export(user.ID, format, c.out)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(user.ID, format, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// importFile creates the todos of a user from a file
+func (c *CLI) importFile(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	username := fs.String("user", "", "username or email of the user")
+	workspaceID := fs.Uint("workspace", 0, "ID of the workspace (default: the user's personal one)")
+	name := fs.String("format", "", "csv, json, todotxt, markdown or ics (default: from the file name)")
+	timezone := fs.String("timezone", "", "timezone of the todos which have none")
+	dryRun := fs.Bool("dry-run", false, "only report what would be created")
+	allowDuplicates := fs.Bool("allow-duplicates", false, "create todos which already exist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+
+	user, err := c.user(*username)
+	if err != nil {
+		return err
+	}
+	workspace, err := c.workspace(user, *workspaceID)
+	if err != nil {
+		return err
+	}
+
+	opts := transfer.Options{DryRun: *dryRun, AllowDuplicates: *allowDuplicates, Timezone: *timezone}
+	if *name != "" {
+		opts.Format, err = transfer.ParseFormat(*name)
+	} else {
+		opts.Format, err = transfer.FormatFromFilename(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := c.importer.Workspace(workspace).Import(user.ID, f, opts)
+	if err != nil {
+		return err
+	}
+
+	c.printReport(report)
+	return nil
+}
+
+// printReport writes a summary of the import and the rows which were skipped
+func (c *CLI) printReport(report *transfer.Report) {
+	for _, row := range report.Rows {
+		switch row.Status {
+		case transfer.RowDuplicate:
+			fmt.Fprintf(c.out, "row %d: skipped duplicate %q\n", row.Row, row.Title)
+		case transfer.RowInvalid:
+			fields := make([]string, 0, len(row.Errors))
+			for field := range row.Errors {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				fmt.Fprintf(c.out, "row %d: %s: %s\n", row.Row, field, strings.Join(row.Errors[field], ", "))
+			}
+		}
+	}
+
+	verb := "created"
+	if report.DryRun {
+		verb = "would be created"
+	}
+	fmt.Fprintf(c.out, "%d of %d todos %s, %d duplicates, %d invalid\n",
+		report.Created, report.Total, verb, report.Duplicates, report.Invalid)
+}
+
+// user looks up a user by username, or email when it has an @
+func (c *CLI) user(name string) (*models.User, error) {
+	if name == "" {
+		return nil, errors.New("the -user flag is required")
+	}
+
+	var user *models.User
+	if strings.Contains(name, "@") {
+		user = c.ur.ByEmail(name)
+	} else {
+		user = c.ur.ByUsername(name)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %q not found", name)
+	}
+	return user, nil
+}
+
+// workspace returns the ID of the workspace of the user, their
+// personal one when none is given
+func (c *CLI) workspace(user *models.User, id uint) (uint, error) {
+	if id == 0 {
+		w, err := c.wr.Personal(user)
+		if err != nil {
+			return 0, err
+		}
+		return w.ID, nil
+	}
+	if c.wr.Membership(id, user.ID) == nil {
+		return 0, fmt.Errorf("user %q is not a member of workspace %d", user.Username, id)
+	}
+	return id, nil
+}
@@ -0,0 +1,15 @@
+package configs
+
+import "time"
+
+// ActivityConfig definition
+type ActivityConfig struct {
+	GroupWindow time.Duration `json:"group_window"`
+}
+
+// NewActivityConfig creates ActivityConfig
+func NewActivityConfig() ActivityConfig {
+	return ActivityConfig{
+		GroupWindow: time.Duration(GetEnvInt("ACTIVITY_GROUP_MINUTES", 5)) * time.Minute,
+	}
+}
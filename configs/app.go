@@ -3,13 +3,30 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // AppConfig definition
+//
+// TrustProxy reads the address of the clients from X-Forwarded-For,
+// which only a proxy in front of the app can be trusted to set.
 type AppConfig struct {
-	Port     int            `json:"port"`
-	Env      string         `json:"env"`
-	Database DatabaseConfig `json:"database"`
+	Port         int                `json:"port"`
+	Env          string             `json:"env"`
+	TrustProxy   bool               `json:"trust_proxy"`
+	Database     DatabaseConfig     `json:"database"`
+	Auth         AuthConfig         `json:"auth"`
+	Mail         MailConfig         `json:"mail"`
+	Scheduler    SchedulerConfig    `json:"scheduler"`
+	Notification NotificationConfig `json:"notification"`
+	Events       EventsConfig       `json:"events"`
+	Webhook      WebhookConfig      `json:"webhook"`
+	Import       ImportConfig       `json:"import"`
+	Trash        TrashConfig        `json:"trash"`
+	Journal      JournalConfig      `json:"journal"`
+	Activity     ActivityConfig     `json:"activity"`
+	Attachment   AttachmentConfig   `json:"attachment"`
+	Idempotency  IdempotencyConfig  `json:"idempotency"`
 }
 
 // IsProd determines if current app env is in production
@@ -19,11 +36,31 @@ func (c AppConfig) IsProd() bool {
 
 // New creates new AppConfig
 func New() AppConfig {
-	return AppConfig{
-		Port:     GetEnvInt("APP_PORT", 5050),
-		Env:      GetEnv("APP_ENV", "development"),
-		Database: NewDatabaseConfig(),
+	c := AppConfig{
+		Port:         GetEnvInt("APP_PORT", 5050),
+		Env:          GetEnv("APP_ENV", "development"),
+		TrustProxy:   GetEnvBool("APP_TRUST_PROXY", false),
+		Database:     NewDatabaseConfig(),
+		Auth:         NewAuthConfig(),
+		Mail:         NewMailConfig(),
+		Scheduler:    NewSchedulerConfig(),
+		Notification: NewNotificationConfig(),
+		Events:       NewEventsConfig(),
+		Webhook:      NewWebhookConfig(),
+		Import:       NewImportConfig(),
+		Trash:        NewTrashConfig(),
+		Journal:      NewJournalConfig(),
+		Activity:     NewActivityConfig(),
+		Attachment:   NewAttachmentConfig(),
+		Idempotency:  NewIdempotencyConfig(),
 	}
+
+	// the tokens signed with the default key could be forged by anyone
+	if c.IsProd() && GetEnv("APP_KEY", "") == "" {
+		panic("configs: APP_KEY must be set in production")
+	}
+
+	return c
 }
 
 // GetEnv with a fallback
@@ -63,3 +100,37 @@ func GetEnvBool(key string, fallback bool) bool {
 
 	return v
 }
+
+// GetEnvList gets env and splits it by commas, leaving out blanks
+func GetEnvList(key string, fallback []string) []string {
+	env, ok := os.LookupEnv(key)
+	if ok == false {
+		return fallback
+	}
+
+	list := []string{}
+	for _, v := range strings.Split(env, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// GetEnvUintList gets env and splits it by commas into unsigned
+// integers, leaving out blanks and the ones which are not numbers
+func GetEnvUintList(key string, fallback []uint) []uint {
+	if _, ok := os.LookupEnv(key); ok == false {
+		return fallback
+	}
+
+	list := []uint{}
+	for _, v := range GetEnvList(key, nil) {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			list = append(list, uint(n))
+		}
+	}
+
+	return list
+}
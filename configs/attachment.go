@@ -0,0 +1,45 @@
+package configs
+
+import "time"
+
+// AttachmentConfig definition. Driver is either "local", keeping
+// the files in Dir, or "s3" keeping them in the S3 bucket.
+type AttachmentConfig struct {
+	Driver          string        `json:"driver"`
+	Dir             string        `json:"dir"`
+	S3              S3Config      `json:"s3"`
+	MaxBytes        int64         `json:"max_bytes"`
+	QuotaBytes      int64         `json:"quota_bytes"`
+	URLTTL          time.Duration `json:"url_ttl"`
+	CleanupInterval time.Duration `json:"cleanup_interval"`
+}
+
+// S3Config definition
+type S3Config struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"-"`
+	SecretKey string `json:"-"`
+	PathStyle bool   `json:"path_style"`
+}
+
+// NewAttachmentConfig creates AttachmentConfig
+func NewAttachmentConfig() AttachmentConfig {
+	return AttachmentConfig{
+		Driver: GetEnv("ATTACHMENT_DRIVER", "local"),
+		Dir:    GetEnv("ATTACHMENT_DIR", "data/attachments"),
+		S3: S3Config{
+			Endpoint:  GetEnv("ATTACHMENT_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    GetEnv("ATTACHMENT_S3_REGION", "us-east-1"),
+			Bucket:    GetEnv("ATTACHMENT_S3_BUCKET", ""),
+			AccessKey: GetEnv("ATTACHMENT_S3_ACCESS_KEY", ""),
+			SecretKey: GetEnv("ATTACHMENT_S3_SECRET_KEY", ""),
+			PathStyle: GetEnvBool("ATTACHMENT_S3_PATH_STYLE", false),
+		},
+		MaxBytes:        int64(GetEnvInt("ATTACHMENT_MAX_BYTES", 10<<20)),
+		QuotaBytes:      int64(GetEnvInt("ATTACHMENT_QUOTA_BYTES", 100<<20)),
+		URLTTL:          time.Duration(GetEnvInt("ATTACHMENT_URL_TTL_MINUTES", 15)) * time.Minute,
+		CleanupInterval: time.Duration(GetEnvInt("ATTACHMENT_CLEANUP_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+}
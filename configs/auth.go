@@ -0,0 +1,22 @@
+package configs
+
+import "time"
+
+// AuthConfig definition
+//
+// AdminIDs are the IDs of the admins, which unlike usernames can't be
+// changed or taken by another user.
+type AuthConfig struct {
+	Secret   string        `json:"secret"`
+	TTL      time.Duration `json:"ttl"`
+	AdminIDs []uint        `json:"admin_ids"`
+}
+
+// NewAuthConfig creates AuthConfig
+func NewAuthConfig() AuthConfig {
+	return AuthConfig{
+		Secret:   GetEnv("APP_KEY", "secret"),
+		TTL:      time.Duration(GetEnvInt("AUTH_TTL_MINUTES", 60*24)) * time.Minute,
+		AdminIDs: GetEnvUintList("AUTH_ADMIN_IDS", []uint{}),
+	}
+}
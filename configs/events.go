@@ -0,0 +1,19 @@
+package configs
+
+import "time"
+
+// EventsConfig definition
+type EventsConfig struct {
+	History   int           `json:"history"`
+	Buffer    int           `json:"buffer"`
+	Heartbeat time.Duration `json:"heartbeat"`
+}
+
+// NewEventsConfig creates EventsConfig
+func NewEventsConfig() EventsConfig {
+	return EventsConfig{
+		History:   GetEnvInt("EVENTS_HISTORY", 1000),
+		Buffer:    GetEnvInt("EVENTS_BUFFER", 64),
+		Heartbeat: time.Duration(GetEnvInt("EVENTS_HEARTBEAT_SECONDS", 25)) * time.Second,
+	}
+}
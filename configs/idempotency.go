@@ -0,0 +1,18 @@
+package configs
+
+import "time"
+
+// IdempotencyConfig definition. TTL is how long the response to a
+// request with an Idempotency-Key is replayed for.
+type IdempotencyConfig struct {
+	TTL           time.Duration `json:"ttl"`
+	PruneInterval time.Duration `json:"prune_interval"`
+}
+
+// NewIdempotencyConfig creates IdempotencyConfig
+func NewIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:           time.Duration(GetEnvInt("IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
+		PruneInterval: time.Duration(GetEnvInt("IDEMPOTENCY_PRUNE_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+}
@@ -0,0 +1,15 @@
+package configs
+
+// ImportConfig definition
+type ImportConfig struct {
+	MaxBytes int64 `json:"max_bytes"`
+	MaxRows  int   `json:"max_rows"`
+}
+
+// NewImportConfig creates ImportConfig
+func NewImportConfig() ImportConfig {
+	return ImportConfig{
+		MaxBytes: int64(GetEnvInt("IMPORT_MAX_BYTES", 5<<20)),
+		MaxRows:  GetEnvInt("IMPORT_MAX_ROWS", 5000),
+	}
+}
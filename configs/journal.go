@@ -0,0 +1,13 @@
+package configs
+
+// JournalConfig definition
+type JournalConfig struct {
+	History int `json:"history"`
+}
+
+// NewJournalConfig creates JournalConfig
+func NewJournalConfig() JournalConfig {
+	return JournalConfig{
+		History: GetEnvInt("JOURNAL_HISTORY", 50),
+	}
+}
@@ -0,0 +1,23 @@
+package configs
+
+import "os"
+
+// MailConfig definition
+type MailConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// NewMailConfig creates MailConfig
+func NewMailConfig() MailConfig {
+	return MailConfig{
+		Host:     os.Getenv("MAIL_HOST"),
+		Port:     GetEnvInt("MAIL_PORT", 587),
+		Username: os.Getenv("MAIL_USERNAME"),
+		Password: os.Getenv("MAIL_PASSWORD"),
+		From:     GetEnv("MAIL_FROM", "todo@localhost"),
+	}
+}
@@ -0,0 +1,17 @@
+package configs
+
+import "time"
+
+// NotificationConfig definition
+type NotificationConfig struct {
+	Retention     time.Duration `json:"retention"`
+	PruneInterval time.Duration `json:"prune_interval"`
+}
+
+// NewNotificationConfig creates NotificationConfig
+func NewNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		Retention:     time.Duration(GetEnvInt("NOTIFICATION_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		PruneInterval: time.Duration(GetEnvInt("NOTIFICATION_PRUNE_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+}
@@ -0,0 +1,23 @@
+package configs
+
+import "time"
+
+// SchedulerConfig definition
+type SchedulerConfig struct {
+	Enabled     bool          `json:"enabled"`
+	Interval    time.Duration `json:"interval"`
+	Lease       time.Duration `json:"lease"`
+	BatchSize   int           `json:"batch_size"`
+	MaxAttempts int           `json:"max_attempts"`
+}
+
+// NewSchedulerConfig creates SchedulerConfig
+func NewSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		Enabled:     GetEnvBool("SCHEDULER_ENABLED", true),
+		Interval:    time.Duration(GetEnvInt("SCHEDULER_INTERVAL_SECONDS", 30)) * time.Second,
+		Lease:       time.Duration(GetEnvInt("SCHEDULER_LEASE_SECONDS", 120)) * time.Second,
+		BatchSize:   GetEnvInt("SCHEDULER_BATCH_SIZE", 50),
+		MaxAttempts: GetEnvInt("SCHEDULER_MAX_ATTEMPTS", 5),
+	}
+}
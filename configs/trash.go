@@ -0,0 +1,17 @@
+package configs
+
+import "time"
+
+// TrashConfig definition
+type TrashConfig struct {
+	Retention     time.Duration `json:"retention"`
+	PurgeInterval time.Duration `json:"purge_interval"`
+}
+
+// NewTrashConfig creates TrashConfig
+func NewTrashConfig() TrashConfig {
+	return TrashConfig{
+		Retention:     time.Duration(GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		PurgeInterval: time.Duration(GetEnvInt("TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+}
@@ -0,0 +1,27 @@
+package configs
+
+import "time"
+
+// WebhookConfig definition
+type WebhookConfig struct {
+	Interval     time.Duration `json:"interval"`
+	Lease        time.Duration `json:"lease"`
+	BatchSize    int           `json:"batch_size"`
+	Timeout      time.Duration `json:"timeout"`
+	RetryBackoff time.Duration `json:"retry_backoff"`
+	MaxAttempts  int           `json:"max_attempts"`
+	DisableAfter int           `json:"disable_after"`
+}
+
+// NewWebhookConfig creates WebhookConfig
+func NewWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Interval:     time.Duration(GetEnvInt("WEBHOOK_INTERVAL_SECONDS", 10)) * time.Second,
+		Lease:        time.Duration(GetEnvInt("WEBHOOK_LEASE_SECONDS", 60)) * time.Second,
+		BatchSize:    GetEnvInt("WEBHOOK_BATCH_SIZE", 50),
+		Timeout:      time.Duration(GetEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+		RetryBackoff: time.Duration(GetEnvInt("WEBHOOK_RETRY_BACKOFF_SECONDS", 30)) * time.Second,
+		MaxAttempts:  GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		DisableAfter: GetEnvInt("WEBHOOK_DISABLE_AFTER", 20),
+	}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/activity"
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// ActivityController handles the activity feeds of the user and their lists
+type ActivityController struct {
+	ar repositories.ActivityRepository
+	lr repositories.ListRepository
+}
+
+// activityResponse is a private struct for activity response,
+// the message is rendered in the language the user accepts
+type activityResponse struct {
+	ID        uint                   `json:"id"`
+	Verb      string                 `json:"verb"`
+	Subject   string                 `json:"subject"`
+	SubjectID uint                   `json:"subject_id"`
+	ListID    *uint                  `json:"list_id"`
+	Actor     *activityActorResponse `json:"actor"`
+	Message   string                 `json:"message"`
+	Fields    []string               `json:"fields"`
+	Count     int                    `json:"count"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// activityActorResponse is a private struct for the user who made a change
+type activityActorResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewActivity creates ActivityController instance
+func NewActivity(ar repositories.ActivityRepository, lr repositories.ListRepository) *ActivityController {
+	return &ActivityController{ar, lr}
+}
+
+// Index lists the activities of the user's feed, the latest first
+// GET /activity
+func (ac *ActivityController) Index(ctx echo.Context) error {
+	page := requests.NewPagination(ctx)
+	activities, total := ac.ar.ByUser(auth.User(ctx).ID, page.Offset(), page.PerPage)
+
+	return ac.respond(ctx, activities, &paginationMeta{Pagination: page, Total: total})
+}
+
+// List lists the activities of a list the user owns, the latest first
+// GET /lists/:id/activity
+func (ac *ActivityController) List(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	list := listsOf(ctx, ac.lr).ByID(uint(id))
+	if list == nil || list.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+
+	page := requests.NewPagination(ctx)
+	activities, total := ac.ar.ByList(list.ID, page.Offset(), page.PerPage)
+
+	return ac.respond(ctx, activities, &paginationMeta{Pagination: page, Total: total})
+}
+
+// respond renders the page of activities in the language of the request
+func (ac *ActivityController) respond(ctx echo.Context, activities []models.Activity, meta *paginationMeta) error {
+	locale := i18n.Locale(ctx)
+
+	res := make([]*activityResponse, 0, len(activities))
+	for i := range activities {
+		res = append(res, newActivityResponse(locale, &activities[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, meta))
+}
+
+// newActivityResponse is a private function for creating *activityResponse
+func newActivityResponse(locale string, a *models.Activity) *activityResponse {
+	return &activityResponse{
+		ID:        a.ID,
+		Verb:      a.Verb,
+		Subject:   a.Subject,
+		SubjectID: a.SubjectID,
+		ListID:    a.ListID,
+		Actor:     &activityActorResponse{ID: a.ActorID, Name: a.Actor.Name},
+		Message:   activity.Message(locale, a),
+		Fields:    a.FieldNames(),
+		Count:     a.Count,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
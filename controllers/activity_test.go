@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ActivityControllerTestSuite struct {
+	suite.Suite
+	repo     *mocks.ActivityRepository
+	lists    *mocks.ListRepository
+	activity *ActivityController
+	server   *echo.Echo
+}
+
+func (suite *ActivityControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.ActivityRepository{}
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.activity = NewActivity(suite.repo, suite.lists)
+	suite.server = echo.New()
+}
+
+// newContext creates a context getting the target in the language
+// authenticated as the first user
+func (suite *ActivityControllerTestSuite) newContext(target string, language string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(echo.GET, target, nil)
+	req.Header.Set("Accept-Language", language)
+
+	return newUserContext(suite.server, req)
+}
+
+// activityPage is the body of a page of activities
+type activityPage struct {
+	Data []activityResponse `json:"data"`
+	Meta struct {
+		Total int `json:"total"`
+	} `json:"meta"`
+}
+
+// decode asserts the response succeeded and decodes its page of activities
+func (suite *ActivityControllerTestSuite) decode(response *httptest.ResponseRecorder) activityPage {
+	var body activityPage
+	suite.Equal(http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &body)
+
+	return body
+}
+
+// completed is the activity of the first user completing a todo of their list
+func completed() models.Activity {
+	listID := uint(3)
+	return models.Activity{
+		Model:     gorm.Model{ID: 7},
+		UserID:    1,
+		ActorID:   1,
+		Actor:     models.User{Model: gorm.Model{ID: 1}, Name: "Bob"},
+		ListID:    &listID,
+		Verb:      models.ActivityCompleted,
+		Subject:   models.ActivityTodo,
+		SubjectID: 2,
+		Title:     "Ship release",
+		ListName:  "Project X",
+		Count:     1,
+	}
+}
+
+func (suite *ActivityControllerTestSuite) TestIndex() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("ByUser", uint(1), 0, 10).Return([]models.Activity{completed()}, int64(1))
+
+	context, response := suite.newContext("/activity?per_page=10", "es")
+	assert.NoError(test.Serve(context, suite.activity.Index))
+
+	body := suite.decode(response)
+	assert.Equal(1, body.Meta.Total)
+	if assert.Len(body.Data, 1) {
+		assert.Equal("Bob completó 'Ship release' en Project X", body.Data[0].Message)
+		assert.Equal("Bob", body.Data[0].Actor.Name)
+	}
+}
+
+func (suite *ActivityControllerTestSuite) TestList() {
+	assert := assert.New(suite.T())
+
+	suite.lists.On("ByID", uint(3)).Return(&models.List{Model: gorm.Model{ID: 3}, UserID: 1})
+	suite.repo.On("ByList", uint(3), 0, 10).Return([]models.Activity{completed()}, int64(1))
+
+	context, response := suite.newContext("/lists/3/activity?per_page=10", "")
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	assert.NoError(test.Serve(context, suite.activity.List))
+
+	body := suite.decode(response)
+	if assert.Len(body.Data, 1) {
+		assert.Equal("Bob completed 'Ship release' in Project X", body.Data[0].Message)
+	}
+}
+
+func (suite *ActivityControllerTestSuite) TestListOfAnotherUser() {
+	assert := assert.New(suite.T())
+
+	suite.lists.On("ByID", uint(3)).Return(&models.List{Model: gorm.Model{ID: 3}, UserID: 2})
+
+	context, response := suite.newContext("/lists/3/activity", "")
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	assert.NoError(test.Serve(context, suite.activity.List))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.repo.AssertNotCalled(suite.T(), "ByList")
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestActivityControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ActivityControllerTestSuite))
+}
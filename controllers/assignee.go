@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// errInvalidAssignee is returned when assigning a todo to a user
+// who is not a member of its list
+var errInvalidAssignee = requests.NewFieldError("user_ids", "validation.invalid_assignee", nil)
+
+// AssigneeController handles who the todos are assigned to
+type AssigneeController struct {
+	tr    repositories.TodoRepository
+	lr    repositories.ListRepository
+	mr    repositories.ListMemberRepository
+	ur    repositories.UserRepository
+	cr    repositories.CommentRepository
+	inbox *notifiers.Inbox
+}
+
+// assigneeResponse is a private struct for a user a todo is assigned to
+type assigneeResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// NewAssignee creates AssigneeController instance which notifies the
+// users who are assigned and unassigned through the inbox
+func NewAssignee(tr repositories.TodoRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, ur repositories.UserRepository, cr repositories.CommentRepository, inbox *notifiers.Inbox) *AssigneeController {
+	return &AssigneeController{tr, lr, mr, ur, cr, inbox}
+}
+
+// Index lists the todos assigned to the user across all the lists,
+// the ones due first at the top
+// GET /todos/assigned
+func (ac *AssigneeController) Index(ctx echo.Context) error {
+	todos := todosOf(ctx, ac.tr).AssignedTo(auth.User(ctx).ID)
+
+	res := make([]*todoResponse, 0, len(todos))
+	for i := range todos {
+		res = append(res, newTodoResponse(&todos[i]))
+	}
+	withCommentCounts(ac.cr, res)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Update replaces the users a todo is assigned to, they have to be
+// members of its list. Only the owner of the todo can, the members
+// can see it. A todo without a list can only be assigned to its owner.
+// PUT /todos/:id/assignees
+func (ac *AssigneeController) Update(ctx echo.Context) error {
+	todo := findVisibleTodo(ctx, ac.tr, ac.lr, ac.mr)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+	if todo.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	ar := new(requests.AssigneeRequest)
+	if code, err := ar.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if !ac.canBeAssigned(todo, ar.UserIDs) {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidAssignee)
+	}
+
+	before := todo.AssigneeIDs()
+	added, removed, err := todosOf(ctx, ac.tr).Assign(todo, ar.UserIDs)
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		audit.Log(ctx, events.TodoUpdated, "todo", todo.ID,
+			map[string][]uint{"assignees": before}, map[string][]uint{"assignees": todo.AssigneeIDs()})
+		ac.notify(ctx, todo, added, removed)
+	}
+
+	res := newTodoResponse(todo)
+	withCommentCounts(ac.cr, []*todoResponse{res})
+	withVersion(ctx, todo.Version)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// canBeAssigned determines if the todo can be assigned to all the users
+func (ac *AssigneeController) canBeAssigned(todo *models.Todo, userIDs []uint) bool {
+	var list *models.List
+	if todo.ListID != nil {
+		if list = ac.lr.ByID(*todo.ListID); list == nil {
+			return false
+		}
+	}
+
+	for _, id := range userIDs {
+		if id == todo.UserID {
+			continue
+		}
+		if list == nil || !isListMember(ac.mr, list, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// notify notifies the users who were assigned and unassigned the todo
+func (ac *AssigneeController) notify(ctx echo.Context, todo *models.Todo, added []uint, removed []uint) {
+	by := auth.User(ctx)
+	assigned := make(map[uint]bool, len(added))
+	for _, id := range added {
+		assigned[id] = true
+	}
+
+	for i := range todo.Assignees {
+		if a := &todo.Assignees[i]; assigned[a.UserID] {
+			if err := ac.inbox.Assigned(&a.User, by, todo); err != nil {
+				ctx.Logger().Errorf("assignees: notifying user %d of an assignment: %v", a.UserID, err)
+			}
+		}
+	}
+	for _, id := range removed {
+		user := ac.ur.ByID(id)
+		if user == nil {
+			continue
+		}
+		if err := ac.inbox.Unassigned(user, by, todo); err != nil {
+			ctx.Logger().Errorf("assignees: notifying user %d of an unassignment: %v", id, err)
+		}
+	}
+}
+
+// newAssigneeResponses is a private function for creating the
+// []*assigneeResponse of a todo
+func newAssigneeResponses(assignees []models.TodoAssignee) []*assigneeResponse {
+	res := make([]*assigneeResponse, 0, len(assignees))
+	for _, a := range assignees {
+		res = append(res, &assigneeResponse{ID: a.UserID, Username: a.User.Username, Name: a.User.Name})
+	}
+	return res
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AssigneeControllerTestSuite struct {
+	suite.Suite
+	todos         *mocks.TodoRepository
+	lists         *mocks.ListRepository
+	members       *mocks.ListMemberRepository
+	users         *mocks.UserRepository
+	comments      *mocks.CommentRepository
+	notifications *mocks.NotificationRepository
+	assignee      *AssigneeController
+	server        *echo.Echo
+	alice         *models.User
+	bob           *models.User
+	carol         *models.User
+	todo          *models.Todo
+}
+
+func (suite *AssigneeControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.members = &mocks.ListMemberRepository{}
+	suite.users = &mocks.UserRepository{}
+	suite.comments = &mocks.CommentRepository{}
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+	suite.notifications = &mocks.NotificationRepository{}
+	suite.notifications.On("IsEnabled", mock.Anything, mock.Anything).Return(true)
+	suite.notifications.On("Create", mock.AnythingOfType("*models.Notification")).Return(nil)
+	suite.assignee = NewAssignee(suite.todos, suite.lists, suite.members, suite.users, suite.comments,
+		notifiers.NewInbox(suite.notifications, time.Hour))
+	suite.server = echo.New()
+
+	suite.alice = &models.User{Model: gorm.Model{ID: 1}, Username: "alice", Name: "Alice"}
+	suite.bob = &models.User{Model: gorm.Model{ID: 2}, Username: "bob", Name: "Bob"}
+	suite.carol = &models.User{Model: gorm.Model{ID: 3}, Username: "carol", Name: "Carol"}
+
+	listID := uint(7)
+	suite.todo = &models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, ListID: &listID, Title: "Ship release",
+		Assignees: []models.TodoAssignee{{UserID: 3, User: *suite.carol}}}
+	suite.todos.On("ByID", uint(3)).Return(suite.todo)
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Team"})
+	suite.members.On("IsMember", uint(7), uint(2)).Return(true)
+	suite.members.On("IsMember", uint(7), mock.Anything).Return(false)
+	suite.users.On("ByID", uint(3)).Return(suite.carol)
+}
+
+// newContext creates a context on the assignees of the todo authenticated as the user
+func (suite *AssigneeControllerTestSuite) newContext(user *models.User, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(echo.PUT, "/todos/3/assignees", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	auth.SetUser(context, user)
+
+	return context, response
+}
+
+// notified are the notifications pushed to the inbox
+func (suite *AssigneeControllerTestSuite) notified() []*models.Notification {
+	var notifications []*models.Notification
+	for _, call := range suite.notifications.Calls {
+		if call.Method == "Create" {
+			notifications = append(notifications, call.Arguments.Get(0).(*models.Notification))
+		}
+	}
+	return notifications
+}
+
+func (suite *AssigneeControllerTestSuite) TestUpdateNotifiesReassignedUsers() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Assign", suite.todo, []uint{1, 2}).Return([]uint{1, 2}, []uint{3}, nil).Run(func(args mock.Arguments) {
+		suite.todo.Assignees = []models.TodoAssignee{{UserID: 1, User: *suite.alice}, {UserID: 2, User: *suite.bob}}
+	})
+
+	context, response := suite.newContext(suite.alice, `{"user_ids":[1,2]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	assignees := test.GetResponseData(response)["assignees"].([]interface{})
+	if assert.Len(assignees, 2) {
+		assert.Equal("bob", assignees[1].(map[string]interface{})["username"])
+	}
+
+	// alice assigned herself, so she isn't notified
+	notifications := suite.notified()
+	if assert.Len(notifications, 2) {
+		assert.Equal(uint(2), notifications[0].UserID)
+		assert.Equal(`Alice assigned "Ship release" to you`, notifications[0].Title)
+		assert.Equal(uint(3), notifications[1].UserID)
+		assert.Equal(`Alice unassigned you from "Ship release"`, notifications[1].Title)
+	}
+}
+
+func (suite *AssigneeControllerTestSuite) TestUpdateChangesTheETagOfTheTodo() {
+	assert := assert.New(suite.T())
+
+	suite.todo.Version = 3
+	// the repository moves the todo to its next version
+	suite.todos.On("Assign", suite.todo, []uint{2}).Return([]uint{2}, []uint{3}, nil).Run(func(args mock.Arguments) {
+		suite.todo.Assignees = []models.TodoAssignee{{UserID: 2, User: *suite.bob}}
+		suite.todo.Version++
+	})
+
+	context, response := suite.newContext(suite.alice, `{"user_ids":[2]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+	assert.Equal(`"4"`, response.Header().Get("ETag"))
+
+	// revalidating with the ETag from before gets the new assignees
+	journal := &mocks.JournalRepository{}
+	journal.On("Workspace", mock.Anything).Return(journal)
+	todos := NewTodo(suite.todos, &mocks.ReminderRepository{}, suite.lists, suite.members, journal, suite.comments)
+
+	request := httptest.NewRequest(echo.GET, "/todos/3", nil)
+	request.Header.Set("If-None-Match", `"3"`)
+	response = httptest.NewRecorder()
+	context = suite.server.NewContext(request, response)
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	auth.SetUser(context, suite.alice)
+	assert.NoError(test.Serve(context, todos.Show))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal(`"4"`, response.Header().Get("ETag"))
+		assert.Len(test.GetResponseData(response)["assignees"], 1)
+	}
+}
+
+func (suite *AssigneeControllerTestSuite) TestUpdateToNonMember() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(suite.alice, `{"user_ids":[2,4]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "user_ids")
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Assign", mock.Anything, mock.Anything)
+}
+
+func (suite *AssigneeControllerTestSuite) TestUpdateTodoWithoutList() {
+	assert := assert.New(suite.T())
+
+	suite.todo.ListID = nil
+
+	context, response := suite.newContext(suite.alice, `{"user_ids":[2]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Assign", mock.Anything, mock.Anything)
+}
+
+func (suite *AssigneeControllerTestSuite) TestUpdateOthersTodo() {
+	assert := assert.New(suite.T())
+
+	// members can see the todos of the list, only its owner assigns them
+	context, response := suite.newContext(suite.bob, `{"user_ids":[2]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+	assert.Equal(http.StatusForbidden, response.Code)
+
+	// the others can't even see them
+	dave := &models.User{Model: gorm.Model{ID: 4}, Username: "dave"}
+	context, response = suite.newContext(dave, `{"user_ids":[2]}`)
+	assert.NoError(test.Serve(context, suite.assignee.Update))
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Assign", mock.Anything, mock.Anything)
+}
+
+func (suite *AssigneeControllerTestSuite) TestIndex() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("AssignedTo", uint(3)).Return([]models.Todo{*suite.todo})
+
+	context, response := suite.newContext(suite.carol, "")
+	assert.NoError(test.Serve(context, suite.assignee.Index))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data []todoResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if assert.Len(body.Data, 1) {
+		assert.Equal(uint(3), body.Data[0].ID)
+		assert.Equal(uint(3), body.Data[0].Assignees[0].ID)
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAssigneeControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AssigneeControllerTestSuite))
+}
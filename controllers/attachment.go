@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/storage"
+	"github.com/ksungcaya/todo-echo/text"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errAttachmentNotFound is returned when the attachment does not
+	// exist or is on another todo
+	errAttachmentNotFound = apperrors.New(http.StatusNotFound, "attachment_not_found")
+
+	// errInvalidDownloadURL is returned when the signature of a download
+	// URL is wrong or expired
+	errInvalidDownloadURL = apperrors.New(http.StatusForbidden, "invalid_download_url")
+
+	// errChecksumMismatch is returned when the uploaded file is not the
+	// one the client computed the checksum of
+	errChecksumMismatch = requests.NewFieldError("checksum", "validation.checksum_mismatch", nil)
+)
+
+// maxFilenameLength is the longest name of an attached file, the size
+// of its column
+const maxFilenameLength = 255
+
+// AttachmentController handles the files attached to the todos the user
+// can see, their owners' and the ones on the lists they are a member of
+type AttachmentController struct {
+	ar       repositories.AttachmentRepository
+	tr       repositories.TodoRepository
+	lr       repositories.ListRepository
+	mr       repositories.ListMemberRepository
+	store    storage.Storage
+	signer   *storage.URLSigner
+	maxBytes int64
+	quota    int64
+}
+
+// attachmentResponse is a private struct for attachment response,
+// the download URL works without authentication until it expires
+type attachmentResponse struct {
+	ID          uint      `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewAttachment creates AttachmentController instance keeping the files
+// in the store. Files are at most maxBytes and the files of every user
+// at most quota bytes altogether.
+func NewAttachment(ar repositories.AttachmentRepository, tr repositories.TodoRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, store storage.Storage, signer *storage.URLSigner, maxBytes int64, quota int64) *AttachmentController {
+	return &AttachmentController{ar, tr, lr, mr, store, signer, maxBytes, quota}
+}
+
+// Index lists the files attached to a todo
+// GET /todos/:id/attachments
+func (ac *AttachmentController) Index(ctx echo.Context) error {
+	todo := ac.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	attachments := ac.ar.ByTodo(todo.ID)
+	res := make([]*attachmentResponse, 0, len(attachments))
+	for i := range attachments {
+		res = append(res, ac.newResponse(ctx, &attachments[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store attaches the file field of a multipart form to a todo. Its
+// content type is sniffed from the content, and when the checksum
+// field has the hex encoded SHA-256 of the file it is verified.
+// POST /todos/:id/attachments
+func (ac *AttachmentController) Store(ctx echo.Context) error {
+	todo := ac.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return apperrors.From(http.StatusUnprocessableEntity, errFileRequired)
+	}
+	if fh.Size > ac.maxBytes {
+		err := requests.NewFieldError("file", "validation.file_too_large", i18n.Args{"max": ac.maxBytes})
+		return apperrors.From(http.StatusRequestEntityTooLarge, err)
+	}
+	user := auth.User(ctx)
+	if ac.ar.Usage(user.ID)+fh.Size > ac.quota {
+		err := requests.NewFieldError("file", "validation.quota_exceeded", i18n.Args{"quota": ac.quota})
+		return apperrors.From(http.StatusUnprocessableEntity, err)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	head = head[:n]
+
+	attachment := &models.Attachment{
+		TodoID:      todo.ID,
+		UserID:      user.ID,
+		Filename:    attachmentFilename(fh.Filename),
+		ContentType: http.DetectContentType(head),
+		Size:        fh.Size,
+		StorageKey:  fmt.Sprintf("todos/%d/%s", todo.ID, auth.NewToken("")),
+	}
+
+	hasher := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), f), hasher)
+	if err := ac.store.Put(ctx.Request().Context(), attachment.StorageKey, body, fh.Size, attachment.ContentType); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	attachment.Checksum = hex.EncodeToString(hasher.Sum(nil))
+
+	if want := strings.ToLower(strings.TrimSpace(ctx.FormValue("checksum"))); want != "" && want != attachment.Checksum {
+		ac.deleteBlob(ctx, attachment)
+		return apperrors.From(http.StatusUnprocessableEntity, errChecksumMismatch)
+	}
+	if err := ac.ar.Create(attachment); err != nil {
+		ac.deleteBlob(ctx, attachment)
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	res := ac.newResponse(ctx, attachment)
+	audit.Log(ctx, audit.AttachmentCreated, "attachment", attachment.ID, nil, res)
+	return ctx.JSON(http.StatusCreated, NewResponseData(res))
+}
+
+// Destroy deletes a file attached to a todo, the user who attached it
+// and the owner of the todo can
+// DELETE /todos/:id/attachments/:attachment
+func (ac *AttachmentController) Destroy(ctx echo.Context) error {
+	todo := ac.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("attachment"), 10, 64)
+	attachment := ac.ar.ByID(uint(id))
+	if attachment == nil || attachment.TodoID != todo.ID {
+		return apperrors.From(http.StatusNotFound, errAttachmentNotFound)
+	}
+	if user := auth.User(ctx); attachment.UserID != user.ID && todo.UserID != user.ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	if err := ac.store.Delete(ctx.Request().Context(), attachment.StorageKey); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	if err := ac.ar.Delete(attachment.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.AttachmentDeleted, "attachment", attachment.ID, ac.newResponse(ctx, attachment), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Download sends an attached file. It needs no authentication,
+// the signature of the URL authorizes it until it expires.
+// GET /attachments/:id?expires=&signature=
+func (ac *AttachmentController) Download(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	expires, _ := strconv.ParseInt(ctx.QueryParam("expires"), 10, 64)
+	if !ac.signer.Verify(attachmentResource(uint(id)), expires, ctx.QueryParam("signature"), time.Now()) {
+		return apperrors.From(http.StatusForbidden, errInvalidDownloadURL)
+	}
+
+	attachment := ac.ar.ByID(uint(id))
+	if attachment == nil {
+		return apperrors.From(http.StatusNotFound, errAttachmentNotFound)
+	}
+	blob, err := ac.store.Get(ctx.Request().Context(), attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return apperrors.From(http.StatusNotFound, errAttachmentNotFound)
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	defer blob.Close()
+
+	sum, _ := hex.DecodeString(attachment.Checksum)
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, attachment.ContentType)
+	res.Header().Set(echo.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	res.Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	res.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+	res.Header().Set("Cache-Control", "private, no-store")
+	res.WriteHeader(http.StatusOK)
+
+	// the status was sent already, so a corrupted file can only be logged
+	hasher := sha256.New()
+	if _, err := io.Copy(res, io.TeeReader(blob, hasher)); err != nil {
+		return err
+	}
+	verifyChecksum(ctx, attachment, hasher)
+	return nil
+}
+
+// findTodo looks up the todo from the :id param which the user can
+// see, its owner and the members of its list can
+func (ac *AttachmentController) findTodo(ctx echo.Context) *models.Todo {
+	return findVisibleTodo(ctx, ac.tr, ac.lr, ac.mr)
+}
+
+// deleteBlob deletes the file of an attachment which couldn't be
+// kept, a failure leaves it for the cleanup of orphaned files
+func (ac *AttachmentController) deleteBlob(ctx echo.Context, a *models.Attachment) {
+	if err := ac.store.Delete(ctx.Request().Context(), a.StorageKey); err != nil {
+		ctx.Logger().Errorf("attachments: deleting %s: %v", a.StorageKey, err)
+	}
+}
+
+// newResponse is a private function for creating *attachmentResponse
+// with a freshly signed download URL
+func (ac *AttachmentController) newResponse(ctx echo.Context, a *models.Attachment) *attachmentResponse {
+	expires, signature := ac.signer.Sign(attachmentResource(a.ID), time.Now())
+	return &attachmentResponse{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Checksum:    a.Checksum,
+		DownloadURL: fmt.Sprintf("%s://%s/attachments/%d?expires=%d&signature=%s",
+			ctx.Scheme(), ctx.Request().Host, a.ID, expires.Unix(), signature),
+		ExpiresAt: expires,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// verifyChecksum logs when the file sent doesn't match its checksum
+func verifyChecksum(ctx echo.Context, a *models.Attachment, hasher hash.Hash) {
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != a.Checksum {
+		ctx.Logger().Errorf("attachments: %s is corrupted, its checksum is %s instead of %s", a.StorageKey, sum, a.Checksum)
+	}
+}
+
+// attachmentResource is what the download URLs of the attachment sign
+func attachmentResource(id uint) string {
+	return "attachment:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// attachmentFilename is the name of an uploaded file without any
+// directories, cut to what the attachments table stores. A long name
+// is cut before its extension, so the file still opens with the right
+// application when it is downloaded.
+func attachmentFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.ToValidUTF8(name, ""), "\\", "/"))
+	if name == "." || name == ".." || name == "/" {
+		name = "file"
+	}
+	if utf8.RuneCountInString(name) <= maxFilenameLength {
+		return name
+	}
+
+	// a name which is mostly extension is not worth keeping it for
+	ext := filepath.Ext(name)
+	n := utf8.RuneCountInString(ext)
+	if n >= maxFilenameLength/2 {
+		return text.Truncate(name, maxFilenameLength)
+	}
+	return text.Truncate(strings.TrimSuffix(name, ext), maxFilenameLength-n) + ext
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/storage"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AttachmentControllerTestSuite struct {
+	suite.Suite
+	attachments *mocks.AttachmentRepository
+	todos       *mocks.TodoRepository
+	lists       *mocks.ListRepository
+	members     *mocks.ListMemberRepository
+	dir         string
+	store       storage.Storage
+	attachment  *AttachmentController
+	server      *echo.Echo
+}
+
+func (suite *AttachmentControllerTestSuite) SetupTest() {
+	suite.dir, _ = ioutil.TempDir("", "attachments")
+	suite.store = storage.NewLocal(suite.dir)
+	suite.attachments = &mocks.AttachmentRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.members = &mocks.ListMemberRepository{}
+	suite.attachment = NewAttachment(suite.attachments, suite.todos, suite.lists, suite.members, suite.store,
+		storage.NewURLSigner("secret", 15*time.Minute), 1024, 4096)
+	suite.server = echo.New()
+
+	// the user 1 owns the list of the todo, 2 is a member of it and 4 isn't
+	listID := uint(5)
+	suite.todos.On("ByID", uint(3)).Return(&models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, ListID: &listID, Title: "Ship release"})
+	suite.lists.On("ByID", listID).Return(&models.List{Model: gorm.Model{ID: listID}, UserID: 1})
+	suite.members.On("IsMember", listID, uint(2)).Return(true)
+	suite.members.On("IsMember", listID, mock.Anything).Return(false)
+}
+
+func (suite *AttachmentControllerTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+// upload creates a context uploading the content as the file field,
+// along with the checksum field when there is one
+func (suite *AttachmentControllerTestSuite) upload(filename string, content []byte, checksum string) (echo.Context, *httptest.ResponseRecorder) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, _ := w.CreateFormFile("file", filename)
+	part.Write(content)
+	if checksum != "" {
+		w.WriteField("checksum", checksum)
+	}
+	w.Close()
+
+	request := httptest.NewRequest(echo.POST, "/todos/3/attachments", body)
+	request.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}})
+
+	return context, response
+}
+
+// request creates a context of the user for the attachments of the todo
+func (suite *AttachmentControllerTestSuite) request(method string, userID uint, attachment string) (echo.Context, *httptest.ResponseRecorder) {
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(httptest.NewRequest(method, "/todos/3/attachments", nil), response)
+	context.SetParamNames("id", "attachment")
+	context.SetParamValues("3", attachment)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: userID}})
+
+	return context, response
+}
+
+// download creates a context following the download URL
+func (suite *AttachmentControllerTestSuite) download(downloadURL string) (echo.Context, *httptest.ResponseRecorder) {
+	u, _ := url.Parse(downloadURL)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(httptest.NewRequest(echo.GET, u.RequestURI(), nil), response)
+	context.SetParamNames("id")
+	context.SetParamValues("7")
+
+	return context, response
+}
+
+func (suite *AttachmentControllerTestSuite) TestStoreAndDownload() {
+	assert := assert.New(suite.T())
+
+	content := []byte("%PDF-1.4 release notes")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var stored *models.Attachment
+	suite.attachments.On("Usage", uint(1)).Return(int64(0))
+	suite.attachments.On("Create", mock.AnythingOfType("*models.Attachment")).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.Attachment)
+		stored.ID = 7
+	})
+
+	context, response := suite.upload("../../notes.txt", content, checksum)
+	assert.NoError(test.Serve(context, suite.attachment.Store))
+
+	if !assert.Equal(http.StatusCreated, response.Code) {
+		return
+	}
+	data := test.GetResponseData(response)
+	assert.Equal("notes.txt", data["filename"])
+	assert.Equal("application/pdf", data["content_type"])
+	assert.Equal(checksum, data["checksum"])
+
+	suite.attachments.On("ByID", uint(7)).Return(stored)
+	context, response = suite.download(data["download_url"].(string))
+	assert.NoError(test.Serve(context, suite.attachment.Download))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal(content, response.Body.Bytes())
+		assert.Equal("application/pdf", response.Header().Get(echo.HeaderContentType))
+		assert.Equal(`attachment; filename=notes.txt`, response.Header().Get(echo.HeaderContentDisposition))
+		assert.Equal("nosniff", response.Header().Get(echo.HeaderXContentTypeOptions))
+	}
+}
+
+func (suite *AttachmentControllerTestSuite) TestStoreWithWrongChecksum() {
+	assert := assert.New(suite.T())
+
+	suite.attachments.On("Usage", uint(1)).Return(int64(0))
+
+	context, response := suite.upload("notes.txt", []byte("hello"), "deadbeef")
+	assert.NoError(test.Serve(context, suite.attachment.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "checksum")
+	}
+	suite.attachments.AssertNotCalled(suite.T(), "Create", mock.Anything)
+
+	// the uploaded blob was deleted
+	files, _ := ioutil.ReadDir(suite.dir + "/todos/3")
+	assert.Empty(files)
+}
+
+func (suite *AttachmentControllerTestSuite) TestStoreTooLarge() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.upload("big.bin", make([]byte, 1025), "")
+	assert.NoError(test.Serve(context, suite.attachment.Store))
+
+	assert.Equal(http.StatusRequestEntityTooLarge, response.Code)
+}
+
+func (suite *AttachmentControllerTestSuite) TestStoreOverQuota() {
+	assert := assert.New(suite.T())
+
+	suite.attachments.On("Usage", uint(1)).Return(int64(4000))
+
+	context, response := suite.upload("notes.txt", make([]byte, 100), "")
+	assert.NoError(test.Serve(context, suite.attachment.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response)["file"].([]interface{})[0], "quota")
+	}
+}
+
+func (suite *AttachmentControllerTestSuite) TestDownloadWithInvalidSignature() {
+	assert := assert.New(suite.T())
+
+	expired, signature := storage.NewURLSigner("secret", -time.Minute).Sign("attachment:7", time.Now())
+	_, other := storage.NewURLSigner("secret", time.Minute).Sign("attachment:8", time.Now())
+
+	for _, query := range []string{
+		fmt.Sprintf("expires=%d&signature=%s", expired.Unix(), signature),
+		fmt.Sprintf("expires=%d&signature=%s", time.Now().Add(time.Minute).Unix(), other),
+		"",
+	} {
+		context, response := suite.download("http://example.com/attachments/7?" + query)
+		assert.NoError(test.Serve(context, suite.attachment.Download))
+		assert.Equal(http.StatusForbidden, response.Code, query)
+	}
+	suite.attachments.AssertNotCalled(suite.T(), "ByID", mock.Anything)
+}
+
+func (suite *AttachmentControllerTestSuite) TestMembersOfTheList() {
+	assert := assert.New(suite.T())
+
+	suite.attachments.On("ByTodo", uint(3)).Return([]models.Attachment{{Model: gorm.Model{ID: 7}, TodoID: 3, UserID: 1, Filename: "notes.txt"}})
+	suite.attachments.On("ByID", uint(7)).Return(&models.Attachment{Model: gorm.Model{ID: 7}, TodoID: 3, UserID: 1, Filename: "notes.txt"})
+
+	// members see the files attached to the todo
+	context, response := suite.request(echo.GET, 2, "")
+	assert.NoError(test.Serve(context, suite.attachment.Index))
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Contains(response.Body.String(), "notes.txt")
+	}
+
+	// but can't delete the ones others attached
+	context, response = suite.request(echo.DELETE, 2, "7")
+	assert.NoError(test.Serve(context, suite.attachment.Destroy))
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.attachments.AssertNotCalled(suite.T(), "Delete", mock.Anything)
+
+	// and the others don't see the todo at all
+	context, response = suite.request(echo.GET, 4, "")
+	assert.NoError(test.Serve(context, suite.attachment.Index))
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestAttachmentFilename(t *testing.T) {
+	long := strings.Repeat("é", 300)
+	for name, want := range map[string]string{
+		`C:\Users\jane\notes.txt`: "notes.txt",
+		"../":                     "file",
+		long + ".pdf":             strings.Repeat("é", 251) + ".pdf",
+		"notes." + long:           "notes." + strings.Repeat("é", 249),
+	} {
+		got := attachmentFilename(name)
+		assert.Equal(t, want, got)
+		assert.LessOrEqual(t, utf8.RuneCountInString(got), maxFilenameLength)
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAttachmentControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AttachmentControllerTestSuite))
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// auditColumns are the columns of the audit log exported as CSV
+var auditColumns = []string{
+	"id", "created_at", "actor_id", "action", "target_type", "target_id",
+	"ip", "user_agent", "request_id", "diff", "prev_hash", "hash",
+}
+
+// AuditController lets the admins look into the audit log
+type AuditController struct {
+	ar repositories.AuditRepository
+}
+
+// auditEventResponse is a private struct for audit event response
+type auditEventResponse struct {
+	ID         uint            `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   *uint           `json:"target_id"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	Diff       json.RawMessage `json:"diff"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// auditVerifyResponse is a private struct for the audit log verification
+type auditVerifyResponse struct {
+	Intact   bool                `json:"intact"`
+	BrokenAt *auditEventResponse `json:"broken_at"`
+}
+
+// NewAudit creates AuditController instance
+func NewAudit(ar repositories.AuditRepository) *AuditController {
+	return &AuditController{ar}
+}
+
+// Index lists the audit events matching the filters in the query
+// string, the latest first. With format=csv all of them are
+// exported instead of a page.
+// GET /admin/audit
+func (ac *AuditController) Index(ctx echo.Context) error {
+	aq := new(requests.AuditQueryRequest)
+	if code, err := aq.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	filter := newAuditFilter(aq)
+
+	if aq.Format == "csv" {
+		return ac.export(ctx, filter)
+	}
+
+	page := requests.NewPagination(ctx)
+	audits, total := ac.ar.Query(filter, page.Offset(), page.PerPage)
+
+	res := make([]*auditEventResponse, 0, len(audits))
+	for i := range audits {
+		res = append(res, newAuditEventResponse(&audits[i]))
+	}
+	meta := &paginationMeta{Pagination: page, Total: total}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, meta))
+}
+
+// Verify checks the hash chain of the audit log, pointing to the
+// first event which was tampered with when it's broken
+// GET /admin/audit/verify
+func (ac *AuditController) Verify(ctx echo.Context) error {
+	broken, err := ac.ar.Verify()
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	res := &auditVerifyResponse{Intact: broken == nil}
+	if broken != nil {
+		res.BrokenAt = newAuditEventResponse(broken)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// export streams the audit events matching the filter as CSV,
+// the oldest first
+func (ac *AuditController) export(ctx echo.Context, filter repositories.AuditFilter) error {
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="audit.csv"`)
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	w.Write(auditColumns)
+	err := ac.ar.Each(filter, func(e *models.AuditEvent) error {
+		return w.Write([]string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			e.CreatedAt.UTC().Format(time.RFC3339Nano),
+			optionalIDString(e.ActorID),
+			e.Action,
+			e.TargetType,
+			optionalIDString(e.TargetID),
+			e.IP,
+			e.UserAgent,
+			e.RequestID,
+			e.Diff,
+			e.PrevHash,
+			e.Hash,
+		})
+	})
+	w.Flush()
+
+	// the status was sent already, so a failure can only cut the file short
+	if err == nil {
+		err = w.Error()
+	}
+	return err
+}
+
+// newAuditFilter is a private function for creating the filter of a
+// validated audit query
+func newAuditFilter(aq *requests.AuditQueryRequest) repositories.AuditFilter {
+	filter := repositories.AuditFilter{
+		Action:     aq.Action,
+		TargetType: aq.TargetType,
+	}
+	if id, err := strconv.ParseUint(aq.ActorID, 10, 64); err == nil {
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+	if id, err := strconv.ParseUint(aq.TargetID, 10, 64); err == nil {
+		targetID := uint(id)
+		filter.TargetID = &targetID
+	}
+	if from, err := requests.ParseDateTime(aq.From, "UTC"); err == nil {
+		filter.From = &from
+	}
+	if to, err := requests.ParseDateTime(aq.To, "UTC"); err == nil {
+		filter.To = &to
+	}
+	return filter
+}
+
+// optionalIDString formats an optional ID, empty when there's none
+func optionalIDString(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// newAuditEventResponse is a private function for creating *auditEventResponse
+func newAuditEventResponse(e *models.AuditEvent) *auditEventResponse {
+	r := &auditEventResponse{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		RequestID:  e.RequestID,
+		Diff:       json.RawMessage("null"),
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+	if e.Diff != "" {
+		r.Diff = json.RawMessage(e.Diff)
+	}
+	return r
+}
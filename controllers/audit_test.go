@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditControllerTestSuite struct {
+	suite.Suite
+	repo   *mocks.AuditRepository
+	audit  *AuditController
+	server *echo.Echo
+}
+
+func (suite *AuditControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.AuditRepository{}
+	suite.audit = NewAudit(suite.repo)
+	suite.server = echo.New()
+}
+
+func (suite *AuditControllerTestSuite) TestIndexFilters() {
+	assert := assert.New(suite.T())
+
+	actorID := uint(4)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter := repositories.AuditFilter{ActorID: &actorID, Action: "todo.deleted", From: &from}
+	suite.repo.On("Query", filter, 0, 20).Return([]models.AuditEvent{
+		{ID: 7, ActorID: &actorID, Action: "todo.deleted", Diff: `{"title":{"from":"Pay rent","to":null}}`},
+	}, int64(1))
+
+	req := httptest.NewRequest(echo.GET, "/admin/audit?actor_id=4&action=todo.deleted&from=2026-05-01", nil)
+	response := httptest.NewRecorder()
+	assert.NoError(test.Serve(suite.server.NewContext(req, response), suite.audit.Index))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Contains(response.Body.String(), `"diff":{"title":{"from":"Pay rent","to":null}}`)
+		assert.Equal(float64(1), test.GetResponse(response, "meta")["total"])
+	}
+}
+
+func (suite *AuditControllerTestSuite) TestIndexValidation() {
+	assert := assert.New(suite.T())
+
+	req := httptest.NewRequest(echo.GET, "/admin/audit?actor_id=alice&format=xml", nil)
+	response := httptest.NewRecorder()
+	assert.NoError(test.Serve(suite.server.NewContext(req, response), suite.audit.Index))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.NotEmpty(err["actor_id"])
+		assert.NotEmpty(err["format"])
+	}
+}
+
+func (suite *AuditControllerTestSuite) TestExportCSV() {
+	assert := assert.New(suite.T())
+
+	created := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	suite.repo.On("Each", repositories.AuditFilter{}, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*models.AuditEvent) error)
+		fn(&models.AuditEvent{ID: 1, CreatedAt: created, Action: "auth.login_failed", Diff: `{"username":{"from":null,"to":"alice"}}`})
+	}).Return(nil)
+
+	req := httptest.NewRequest(echo.GET, "/admin/audit?format=csv", nil)
+	response := httptest.NewRecorder()
+	assert.NoError(test.Serve(suite.server.NewContext(req, response), suite.audit.Index))
+
+	assert.Equal("text/csv; charset=UTF-8", response.Header().Get(echo.HeaderContentType))
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	if assert.Len(lines, 2) {
+		assert.Equal(strings.Join(auditColumns, ","), lines[0])
+		assert.Equal(`1,2026-05-01T09:30:00Z,,auth.login_failed,,,,,,"{""username"":{""from"":null,""to"":""alice""}}",,`, lines[1])
+	}
+}
+
+func (suite *AuditControllerTestSuite) TestVerify() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("Verify").Return(&models.AuditEvent{ID: 12}, nil)
+
+	req := httptest.NewRequest(echo.GET, "/admin/audit/verify", nil)
+	response := httptest.NewRecorder()
+	assert.NoError(suite.audit.Verify(suite.server.NewContext(req, response)))
+
+	data := test.GetResponseData(response)
+	assert.Equal(false, data["intact"])
+	assert.Equal(float64(12), data["broken_at"].(map[string]interface{})["id"])
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAuditControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditControllerTestSuite))
+}
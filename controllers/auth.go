@@ -1,9 +1,11 @@
 package controllers
 
 import (
-	"errors"
 	"net/http"
 
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
 	"github.com/ksungcaya/todo-echo/models"
 	"github.com/ksungcaya/todo-echo/repositories"
 	"github.com/ksungcaya/todo-echo/requests"
@@ -12,7 +14,8 @@ import (
 
 // AuthController todo
 type AuthController struct {
-	ur repositories.UserRepository
+	ur  repositories.UserRepository
+	jwt *auth.JWT
 }
 
 // userResponse is a private struct for user response
@@ -20,16 +23,23 @@ type userResponse struct {
 	Username string `json:"username,omitempty"`
 	Name     string `json:"name,omitempty"`
 	Email    string `json:"email,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Version  uint   `json:"version,omitempty"`
 }
 
+// errInvalidCredentials is returned when no user has the username
+// and the password of a login
+var errInvalidCredentials = apperrors.New(http.StatusForbidden, "invalid_credentials")
+
 // tokenResponse is a private struct for token response
 type tokenResponse struct {
 	Token string `json:"token,omitempty"`
 }
 
 // NewAuth creates AuthController instance
-func NewAuth(ur repositories.UserRepository) *AuthController {
-	return &AuthController{ur}
+func NewAuth(ur repositories.UserRepository, jwt *auth.JWT) *AuthController {
+	return &AuthController{ur, jwt}
 }
 
 // Login handles login route
@@ -37,12 +47,19 @@ func NewAuth(ur repositories.UserRepository) *AuthController {
 func (ac *AuthController) Login(ctx echo.Context) error {
 	lr := new(requests.LoginRequest)
 	if code, err := lr.Validate(ctx); err != nil {
-		return ctx.JSON(code, requests.NewResponseError(err))
+		return apperrors.From(code, err)
+	}
+	user, err := ac.authUser(lr)
+	if err != nil {
+		audit.LogActor(ctx, nil, audit.LoginFailed, "user", 0, nil, map[string]string{"username": lr.Username})
+		return apperrors.From(http.StatusForbidden, err)
 	}
-	if err := ac.authUser(lr); err != nil {
-		return ctx.JSON(http.StatusForbidden, requests.NewResponseError(err))
+	token, err := ac.jwt.Issue(user)
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
 	}
-	return ctx.JSON(http.StatusOK, NewResponseData(&tokenResponse{Token: "token"}))
+	audit.LogActor(ctx, user, audit.LoginSucceeded, "user", user.ID, nil, nil)
+	return ctx.JSON(http.StatusOK, NewResponseData(&tokenResponse{Token: token}))
 }
 
 // Register handles register route
@@ -50,30 +67,66 @@ func (ac *AuthController) Login(ctx echo.Context) error {
 func (ac *AuthController) Register(ctx echo.Context) error {
 	rr := new(requests.RegisterRequest)
 	if code, err := rr.Validate(ctx); err != nil {
-		return ctx.JSON(code, requests.NewResponseError(err))
-	}
-	if user := ac.ur.ByEmail(rr.Email); user != nil {
-		return ctx.JSON(
-			http.StatusUnprocessableEntity,
-			requests.NewValidationError("email", "The email already exist"),
-		)
+		return apperrors.From(code, err)
 	}
 	user := rr.UserModel()
 	if err := ac.ur.Create(user); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, requests.NewResponseError(err))
+		return apperrors.From(http.StatusInternalServerError, err)
 	}
+	audit.LogActor(ctx, user, audit.Registered, "user", user.ID, nil, newAuditUser(user))
 
 	return ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
+// Password changes the password of the authenticated user
+// PUT /auth/password
+func (ac *AuthController) Password(ctx echo.Context) error {
+	user := auth.User(ctx)
+
+	pr := new(requests.PasswordRequest)
+	if code, err := pr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if !user.CheckPassword(pr.CurrentPassword) {
+		return apperrors.From(http.StatusUnprocessableEntity, requests.NewFieldError("current_password", "validation.current_password", nil))
+	}
+
+	hashed, err := models.HashPassword(pr.Password)
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	user.Password = hashed
+	if err := ac.ur.Update(user); err != nil {
+		code := http.StatusInternalServerError
+		if err == repositories.ErrVersionConflict {
+			code = http.StatusPreconditionFailed
+		}
+		return apperrors.From(code, err)
+	}
+
+	audit.Log(ctx, audit.PasswordChanged, "user", user.ID, nil, nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 // attempt to authenticate user, else, return an error
-func (ac *AuthController) authUser(lr *requests.LoginRequest) error {
-	loginErr := errors.New("Invalid username or password")
+func (ac *AuthController) authUser(lr *requests.LoginRequest) (*models.User, error) {
 	user := ac.ur.ByUsername(lr.Username)
 	if user == nil || user.CheckPassword(lr.Password) != true {
-		return loginErr
+		return nil, errInvalidCredentials
+	}
+	return user, nil
+}
+
+// newAuditUser is the state of the user kept in the audit log, leaving
+// out the password
+func newAuditUser(u *models.User) map[string]string {
+	return map[string]string{
+		"username": u.Username,
+		"email":    u.Email,
+		"name":     u.Name,
+		"locale":   u.Locale,
+		"timezone": u.Timezone,
 	}
-	return nil
 }
 
 // newUserResponse is a private function for creating *userResponse
@@ -82,6 +135,9 @@ func newUserResponse(u *models.User) ResponseData {
 	r.Username = u.Username
 	r.Email = u.Email
 	r.Name = u.Name
+	r.Locale = u.Locale
+	r.Timezone = u.Timezone
+	r.Version = u.Version
 
 	return NewResponseData(r)
 }
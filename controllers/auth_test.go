@@ -3,18 +3,25 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/configs"
 	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
 	"github.com/ksungcaya/todo-echo/models"
 	"github.com/ksungcaya/todo-echo/requests"
 	"github.com/ksungcaya/todo-echo/test"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type AuthControllerTestSuite struct {
@@ -29,7 +36,7 @@ var (
 		Username: "alice",
 		Name:     "Alice Wonder",
 		Email:    "alice@realworld.io",
-		Password: "secret",
+		Password: "secret-42",
 	}
 
 	loginRequest = requests.LoginRequest{
@@ -48,7 +55,8 @@ var (
 // Setup auth
 func (suite *AuthControllerTestSuite) SetupTest() {
 	suite.repo = &mocks.UserRepository{}
-	suite.auth = NewAuth(suite.repo)
+	requests.RegisterUnique("users", suite.repo)
+	suite.auth = NewAuth(suite.repo, auth.NewJWT(configs.AuthConfig{Secret: "secret", TTL: time.Hour}))
 	suite.server = echo.New()
 }
 
@@ -60,11 +68,11 @@ func (suite *AuthControllerTestSuite) TestLoignInvalidPayload() {
 	response := httptest.NewRecorder()
 	context := suite.server.NewContext(request, response)
 
-	assert.NoError(suite.auth.Login(context))
+	assert.NoError(test.Serve(context, suite.auth.Login))
 
 	if assert.Equal(http.StatusBadRequest, response.Code) {
-		err := test.GetResponseErrors(response)
-		assert.Equal(err["message"], "Invalid request payload")
+		problem := test.GetResponseProblem(response)
+		assert.Equal("Invalid request payload", problem["detail"])
 	}
 }
 
@@ -80,7 +88,7 @@ func (suite *AuthControllerTestSuite) TestLoginValidation() {
 	response := httptest.NewRecorder()
 	context := suite.server.NewContext(request, response)
 
-	assert.NoError(suite.auth.Register(context))
+	assert.NoError(test.Serve(context, suite.auth.Register))
 
 	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
 		err := test.GetResponseErrors(response)
@@ -100,12 +108,12 @@ func (suite *AuthControllerTestSuite) TestLoginWithUnregisteredUser() {
 
 	suite.repo.On("ByUsername", loginRequest.Username).Return(nil)
 
-	assert.NoError(suite.auth.Login(context))
+	assert.NoError(test.Serve(context, suite.auth.Login))
 
 	suite.repo.AssertCalled(suite.T(), "ByUsername", loginRequest.Username)
 	if assert.Equal(http.StatusForbidden, response.Code) {
-		err := test.GetResponseErrors(response)
-		assert.Equal(err["message"], "Invalid username or password")
+		problem := test.GetResponseProblem(response)
+		assert.Equal("Invalid username or password", problem["detail"])
 	}
 }
 
@@ -122,12 +130,20 @@ func (suite *AuthControllerTestSuite) TestLoginWithInvalidPassword() {
 
 	suite.repo.On("ByUsername", loginRequest.Username).Return(existingUser)
 
-	assert.NoError(suite.auth.Login(context))
+	assert.NoError(test.Serve(context, suite.auth.Login))
 
 	suite.repo.AssertCalled(suite.T(), "ByUsername", loginRequest.Username)
 	if assert.Equal(http.StatusForbidden, response.Code) {
-		err := test.GetResponseErrors(response)
-		assert.Equal(err["message"], "Invalid username or password")
+		problem := test.GetResponseProblem(response)
+		assert.Equal("Invalid username or password", problem["detail"])
+	}
+
+	entries := audit.Entries(context)
+	if assert.Len(entries, 1) {
+		assert.Equal(audit.LoginFailed, entries[0].Action)
+		assert.Nil(entries[0].ActorID)
+		assert.Contains(entries[0].Diff, `"to":"alice"`)
+		assert.NotContains(entries[0].Diff, "invalid-pass")
 	}
 }
 
@@ -142,7 +158,7 @@ func (suite *AuthControllerTestSuite) TestLoginSuccess() {
 
 	suite.repo.On("ByUsername", loginRequest.Username).Return(existingUser)
 
-	assert.NoError(suite.auth.Login(context))
+	assert.NoError(test.Serve(context, suite.auth.Login))
 
 	suite.repo.AssertCalled(suite.T(), "ByUsername", loginRequest.Username)
 	if assert.Equal(http.StatusOK, response.Code) {
@@ -150,6 +166,53 @@ func (suite *AuthControllerTestSuite) TestLoginSuccess() {
 		suite.T().Logf("\n%v", data)
 		assert.NotEmpty(data["token"])
 	}
+
+	entries := audit.Entries(context)
+	if assert.Len(entries, 1) {
+		assert.Equal(audit.LoginSucceeded, entries[0].Action)
+		assert.Equal(&existingUser.ID, entries[0].ActorID)
+	}
+}
+
+func (suite *AuthControllerTestSuite) TestPasswordRequiresTheCurrentOne() {
+	assert := assert.New(suite.T())
+
+	request := httptest.NewRequest(echo.PUT, "/auth/password", strings.NewReader(`{"current_password": "guess", "password": "new-secret-42"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}, Password: "$2a$10$vFN7/BdlTDFcp1ndGQELtu4eRY6MtEccXJ3tUwfP4qAzMMfDaypBe"})
+
+	assert.NoError(test.Serve(context, suite.auth.Password))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.NotEmpty(test.GetResponseErrors(response)["current_password"])
+	}
+	suite.repo.AssertNotCalled(suite.T(), "Update", mock.Anything)
+	assert.Empty(audit.Entries(context))
+}
+
+func (suite *AuthControllerTestSuite) TestPasswordSuccess() {
+	assert := assert.New(suite.T())
+
+	request := httptest.NewRequest(echo.PUT, "/auth/password", strings.NewReader(`{"current_password": "secret", "password": "new-secret-42"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	user := &models.User{Model: gorm.Model{ID: 1}, Password: "$2a$10$vFN7/BdlTDFcp1ndGQELtu4eRY6MtEccXJ3tUwfP4qAzMMfDaypBe"}
+	auth.SetUser(context, user)
+	suite.repo.On("Update", user).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.auth.Password))
+
+	assert.Equal(http.StatusNoContent, response.Code)
+	assert.True(user.CheckPassword("new-secret-42"))
+
+	entries := audit.Entries(context)
+	if assert.Len(entries, 1) {
+		assert.Equal(audit.PasswordChanged, entries[0].Action)
+		assert.Empty(entries[0].Diff)
+	}
 }
 
 func (suite *AuthControllerTestSuite) TestRegistrationInvalidPayload() {
@@ -160,11 +223,63 @@ func (suite *AuthControllerTestSuite) TestRegistrationInvalidPayload() {
 	response := httptest.NewRecorder()
 	context := suite.server.NewContext(request, response)
 
-	assert.NoError(suite.auth.Register(context))
+	assert.NoError(test.Serve(context, suite.auth.Register))
 
 	if assert.Equal(http.StatusBadRequest, response.Code) {
+		problem := test.GetResponseProblem(response)
+		assert.Equal("Invalid request payload", problem["detail"])
+	}
+}
+
+func (suite *AuthControllerTestSuite) TestRegistrationValidationIsTranslated() {
+	assert := assert.New(suite.T())
+
+	request := httptest.NewRequest(echo.POST, "/auth/register", strings.NewReader(`{"username": "al", "name": "Alice Wonder", "email": "alice", "password": "secret"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set("Accept-Language", "es-ES,es;q=0.9,en;q=0.5")
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+
+	assert.NoError(test.Serve(context, suite.auth.Register))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		problem := test.GetResponseProblem(response)
+		assert.Equal("La solicitud tiene campos no válidos", problem["detail"])
 		err := test.GetResponseErrors(response)
-		assert.Equal(err["message"], "Invalid request payload")
+		assert.Equal([]interface{}{"El campo nombre de usuario debe estar entre 3 y 30"}, err["username"])
+		assert.Equal([]interface{}{"El campo correo electrónico debe ser un correo electrónico válido"}, err["email"])
+	}
+
+	// the user who registered already has the email
+	request = httptest.NewRequest(echo.POST, "/auth/register", strings.NewReader(`{"username": "alice", "name": "Alice Wonder", "email": "alice@real.io", "password": "secret-42"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set("Accept-Language", "es")
+	response = httptest.NewRecorder()
+	suite.repo.On("Taken", "username", "alice", uint(0)).Return(false)
+	suite.repo.On("Taken", "email", "alice@real.io", uint(0)).Return(true)
+
+	assert.NoError(test.Serve(suite.server.NewContext(request, response), suite.auth.Register))
+	assert.Equal([]interface{}{"El correo electrónico ya existe"}, test.GetResponseErrors(response)["email"])
+}
+
+func (suite *AuthControllerTestSuite) TestRegistrationHidesTheDatabaseError() {
+	assert := assert.New(suite.T())
+
+	payload, _ := json.Marshal(registerRequest)
+	request := httptest.NewRequest(echo.POST, "/auth/register", bytes.NewReader(payload))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+
+	suite.repo.On("Taken", mock.Anything, mock.Anything, uint(0)).Return(false)
+	suite.repo.On("Create", mock.Anything).Return(errors.New("UNIQUE constraint failed: users.username"))
+
+	assert.NoError(test.Serve(context, suite.auth.Register))
+
+	if assert.Equal(http.StatusInternalServerError, response.Code) {
+		problem := test.GetResponseProblem(response)
+		assert.Equal("internal_error", problem["code"])
+		assert.NotContains(response.Body.String(), "UNIQUE")
 	}
 }
 
@@ -183,7 +298,7 @@ func (suite *AuthControllerTestSuite) TestRegistrationValidation() {
 	response := httptest.NewRecorder()
 	context := suite.server.NewContext(request, response)
 
-	assert.NoError(suite.auth.Register(context))
+	assert.NoError(test.Serve(context, suite.auth.Register))
 
 	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
 		err := test.GetResponseErrors(response)
@@ -206,15 +321,17 @@ func (suite *AuthControllerTestSuite) TestRegistrationWithExistingEmail() {
 	response := httptest.NewRecorder()
 	context := suite.server.NewContext(request, response)
 
-	suite.repo.On("ByEmail", registerRequest.Email).Return(existingUser)
+	suite.repo.On("Taken", "username", registerRequest.Username, uint(0)).Return(false)
+	suite.repo.On("Taken", "email", registerRequest.Email, uint(0)).Return(true)
 
-	assert.NoError(suite.auth.Register(context))
+	assert.NoError(test.Serve(context, suite.auth.Register))
 
-	suite.repo.AssertCalled(suite.T(), "ByEmail", registerRequest.Email)
+	suite.repo.AssertCalled(suite.T(), "Taken", "email", registerRequest.Email, uint(0))
+	suite.repo.AssertNotCalled(suite.T(), "Create", mock.Anything)
 	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
 		err := test.GetResponseErrors(response)
-		assert.NotEmpty(err["email"])
-		assert.Contains(err["email"].([]interface{})[0], "exist")
+		assert.NotContains(err, "username")
+		assert.Equal([]interface{}{"The email has already been taken"}, err["email"])
 	}
 }
 
@@ -228,12 +345,12 @@ func (suite *AuthControllerTestSuite) TestRegistrationSuccess() {
 	context := suite.server.NewContext(request, response)
 
 	user := existingUser
-	user.Password = "secret"
+	user.Password = registerRequest.Password
 
-	suite.repo.On("ByEmail", registerRequest.Email).Return(nil)
+	suite.repo.On("Taken", mock.Anything, mock.Anything, uint(0)).Return(false)
 	suite.repo.On("Create", user).Return(nil)
 
-	assert.NoError(suite.auth.Register(context))
+	assert.NoError(test.Serve(context, suite.auth.Register))
 
 	suite.repo.AssertCalled(suite.T(), "Create", user)
 	if assert.Equal(http.StatusOK, response.Code) {
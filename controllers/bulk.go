@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// errRolledBack is the error of the operations of an atomic bulk
+// request which were undone, or not even tried, as another one failed
+var errRolledBack = apperrors.New(http.StatusFailedDependency, "rolled_back")
+
+// BulkController handles the batches of operations on the todos
+type BulkController struct {
+	tr repositories.TodoRepository
+	lr repositories.ListRepository
+	jr repositories.JournalRepository
+}
+
+// bulkResult is a private struct for the result of an operation of a
+// bulk request, its status is the one it would have had on its own
+type bulkResult struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	ID     uint          `json:"id"`
+	Status int           `json:"status"`
+	Todo   *todoResponse `json:"todo,omitempty"`
+	Errors interface{}   `json:"errors,omitempty"`
+}
+
+// bulkMeta is a private struct for the outcome of a bulk request
+type bulkMeta struct {
+	Mode      string `json:"mode"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// bulkChange is a private struct for a change made by a bulk
+// operation, it is journaled and audited once it is committed. The
+// tags are not journaled, so a change of them has no op.
+type bulkChange struct {
+	op     *models.Operation
+	action string
+	before interface{}
+	after  interface{}
+}
+
+// NewBulk creates BulkController instance which records the changes
+// to the todos in the user's journal
+func NewBulk(tr repositories.TodoRepository, lr repositories.ListRepository, jr repositories.JournalRepository) *BulkController {
+	return &BulkController{tr, lr, jr}
+}
+
+// Store applies a batch of operations to the todos of the user in one
+// transaction. In the atomic mode, the default, they all fail when one
+// of them does, while in the partial mode every operation succeeds or
+// fails on its own. The result of every operation is in the response.
+// POST /todos/bulk
+func (bc *BulkController) Store(ctx echo.Context) error {
+	br := new(requests.BulkRequest)
+	if code, err := br.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	results := make([]*bulkResult, len(br.Operations))
+	changes := make([]*bulkChange, len(br.Operations))
+	apply := func(tx repositories.TodoRepository, i int) error {
+		op := &br.Operations[i]
+		todo, change, code, err := bc.apply(ctx, tx, op)
+		results[i] = &bulkResult{Index: i, Op: op.Op, ID: op.ID, Status: code}
+		if err != nil {
+			results[i].Errors = itemErrors(ctx, code, err)
+			return err
+		}
+		if todo != nil {
+			results[i].Todo = newTodoResponse(todo)
+		}
+		changes[i] = change
+		return nil
+	}
+
+	failed := -1
+	err := todosOf(ctx, bc.tr).Transaction(func(tx repositories.TodoRepository) error {
+		for i := range br.Operations {
+			if br.Mode == requests.BulkAtomic {
+				if err := apply(tx, i); err != nil {
+					failed = i
+					return err
+				}
+				continue
+			}
+			if err := tx.Transaction(func(item repositories.TodoRepository) error { return apply(item, i) }); err != nil {
+				changes[i] = nil
+			}
+		}
+		return nil
+	})
+	if err != nil && failed < 0 {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	meta := &bulkMeta{Mode: br.Mode}
+	if failed >= 0 {
+		for i, op := range br.Operations {
+			if i != failed {
+				results[i] = &bulkResult{Index: i, Op: op.Op, ID: op.ID, Status: http.StatusFailedDependency,
+					Errors: itemErrors(ctx, http.StatusFailedDependency, errRolledBack)}
+			}
+		}
+		meta.Failed = len(br.Operations)
+		code := http.StatusUnprocessableEntity
+		if results[failed].Status >= http.StatusInternalServerError {
+			code = results[failed].Status
+		}
+		return ctx.JSON(code, NewResponseDataWithMeta(results, meta))
+	}
+
+	for i, change := range changes {
+		if results[i].Errors != nil {
+			meta.Failed++
+			continue
+		}
+		meta.Succeeded++
+		if change != nil {
+			if change.op != nil {
+				record(ctx, bc.jr, change.op)
+			}
+			audit.Log(ctx, change.action, "todo", br.Operations[i].ID, change.before, change.after)
+		}
+	}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(results, meta))
+}
+
+// apply applies the operation to the todo it names, which the user
+// must own, and returns the todo as it is after along with the change
+// and the status code of the operation. The change is nil when the
+// todo did not change.
+func (bc *BulkController) apply(ctx echo.Context, tx repositories.TodoRepository, op *requests.BulkOperation) (*models.Todo, *bulkChange, int, error) {
+	todo := tx.ByID(op.ID)
+	if todo == nil || todo.UserID != auth.User(ctx).ID {
+		return nil, nil, http.StatusNotFound, errTodoNotFound
+	}
+
+	before := models.NewTodoSnapshot(todo)
+	switch op.Op {
+	case requests.BulkDelete:
+		if err := tx.Delete(todo.ID); err != nil {
+			return nil, nil, http.StatusInternalServerError, err
+		}
+		return nil, &bulkChange{
+			op:     models.NewTodoOperation(models.OperationDelete, todo, before, nil),
+			action: events.TodoDeleted,
+			before: before,
+		}, http.StatusNoContent, nil
+	case requests.BulkComplete:
+		if !todo.Completed && len(todo.Blockers) > 0 && !op.IgnoreBlockers {
+			if blockers := tx.OpenBlockers(todo.ID); len(blockers) > 0 {
+				err := requests.NewFieldError("completed", "validation.blocked", i18n.Args{"count": len(blockers)})
+				return nil, nil, http.StatusUnprocessableEntity, err
+			}
+		}
+		todo.Completed = true
+	case requests.BulkReopen:
+		todo.Completed = false
+	case requests.BulkMove:
+		if op.ListID != nil {
+			list := listsOf(ctx, bc.lr).ByID(*op.ListID)
+			if list == nil || list.UserID != todo.UserID {
+				return nil, nil, http.StatusUnprocessableEntity, errInvalidList
+			}
+		}
+		todo.ListID = op.ListID
+	case requests.BulkTag:
+		return bc.tag(tx, todo, op.Tags)
+	case requests.BulkUpdate:
+		op.Fields.Fill(todo)
+	}
+
+	if err := tx.Update(todo); err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	if updated := tx.ByID(todo.ID); updated != nil {
+		todo = updated
+	}
+	after := models.NewTodoSnapshot(todo)
+	if after.Equal(before) {
+		return todo, nil, http.StatusOK, nil
+	}
+	return todo, &bulkChange{
+		op:     models.NewTodoOperation(todoChange(before, after), todo, before, after),
+		action: events.TodoUpdated,
+		before: before,
+		after:  after,
+	}, http.StatusOK, nil
+}
+
+// tag adds the tags to the ones the todo has, as long as it ends up
+// with no more than a todo can have
+func (bc *BulkController) tag(tx repositories.TodoRepository, todo *models.Todo, tags []string) (*models.Todo, *bulkChange, int, error) {
+	before := todo.TagNames()
+	names := requests.NormalizeTags(append(todo.TagNames(), tags...))
+	if len(names) > requests.MaxTags {
+		err := requests.NewFieldError("tags", "validation.max_size", i18n.Args{"max": requests.MaxTags})
+		return nil, nil, http.StatusUnprocessableEntity, err
+	}
+
+	version := todo.Version
+	if err := tx.Tag(todo, names); err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	if todo.Version == version {
+		return todo, nil, http.StatusOK, nil
+	}
+	return todo, &bulkChange{
+		action: events.TodoUpdated,
+		before: map[string][]string{"tags": before},
+		after:  map[string][]string{"tags": todo.TagNames()},
+	}, http.StatusOK, nil
+}
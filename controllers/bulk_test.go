@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type BulkControllerTestSuite struct {
+	suite.Suite
+	todos   *mocks.TodoRepository
+	lists   *mocks.ListRepository
+	journal *mocks.JournalRepository
+	bulk    *BulkController
+	server  *echo.Echo
+}
+
+// bulkBody is the body of a bulk response
+type bulkBody struct {
+	Data []bulkResult `json:"data"`
+	Meta bulkMeta     `json:"meta"`
+}
+
+func (suite *BulkControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	// the transactions run straight on the mock, whatever fn returns
+	suite.todos.On("Transaction", mock.Anything).Return(func(fn func(repositories.TodoRepository) error) error {
+		return fn(suite.todos)
+	})
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.journal = &mocks.JournalRepository{}
+	suite.journal.On("Workspace", mock.Anything).Return(suite.journal)
+	suite.journal.On("Record", mock.AnythingOfType("*models.Operation")).Return(nil)
+	suite.bulk = NewBulk(suite.todos, suite.lists, suite.journal)
+	suite.server = echo.New()
+
+	suite.todos.On("ByID", uint(1)).Return(&models.Todo{Model: gorm.Model{ID: 1}, UserID: 1, Title: "Ship release"})
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 2, Title: "Not mine"})
+	suite.todos.On("ByID", mock.Anything).Return(nil)
+}
+
+// newContext creates a context with the body authenticated as the
+// first user
+func (suite *BulkControllerTestSuite) newContext(body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(echo.POST, "/todos/bulk", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}})
+
+	return context, response
+}
+
+// decode reads the results of the bulk response
+func (suite *BulkControllerTestSuite) decode(response *httptest.ResponseRecorder) bulkBody {
+	var body bulkBody
+	json.Unmarshal(response.Body.Bytes(), &body)
+
+	return body
+}
+
+func (suite *BulkControllerTestSuite) TestStoreValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(`{"mode": "sometimes", "operations": [
+		{"op": "archive", "id": 1},
+		{"op": "update", "id": 1, "fields": {"title": "", "priority": "soon"}},
+		{"op": "complete"}
+	]}`)
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "mode")
+	}
+
+	context, response = suite.newContext(`{"operations": [
+		{"op": "archive", "id": 1},
+		{"op": "update", "id": 1, "fields": {"title": "", "priority": "soon"}},
+		{"op": "complete"},
+		{"op": "tag", "id": 1},
+		{"op": "tag", "id": 1, "tags": ["urgent", " "]}
+	]}`)
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errs := test.GetResponseErrors(response)
+		assert.Contains(errs, "operations.0.op")
+		assert.Contains(errs, "operations.1.fields.title")
+		assert.Contains(errs, "operations.1.fields.priority")
+		assert.Contains(errs, "operations.2.id")
+		assert.Contains(errs, "operations.3.tags")
+		assert.Contains(errs, "operations.4.tags.1")
+		assert.NotContains(errs, "operations.4.tags.0")
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Transaction", mock.Anything)
+}
+
+func (suite *BulkControllerTestSuite) TestStoreAtomicFailure() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+
+	context, response := suite.newContext(`{"operations": [
+		{"op": "complete", "id": 1},
+		{"op": "complete", "id": 2},
+		{"op": "complete", "id": 1}
+	]}`)
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		body := suite.decode(response)
+		if assert.Len(body.Data, 3) {
+			assert.Equal(http.StatusFailedDependency, body.Data[0].Status)
+			assert.Equal(http.StatusNotFound, body.Data[1].Status)
+			assert.Equal(http.StatusFailedDependency, body.Data[2].Status)
+		}
+		assert.Equal(3, body.Meta.Failed)
+	}
+	// the third operation is not even tried and nothing is journaled
+	suite.todos.AssertNumberOfCalls(suite.T(), "Update", 1)
+	suite.journal.AssertNotCalled(suite.T(), "Record", mock.Anything)
+}
+
+func (suite *BulkControllerTestSuite) TestStorePartial() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+	suite.todos.On("Delete", uint(1)).Return(errors.New("boom"))
+
+	context, response := suite.newContext(`{"mode": "partial", "operations": [
+		{"op": "update", "id": 1, "fields": {"title": "Ship it", "priority": "high"}},
+		{"op": "complete", "id": 2},
+		{"op": "delete", "id": 1}
+	]}`)
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		body := suite.decode(response)
+		if assert.Len(body.Data, 3) {
+			assert.Equal(http.StatusOK, body.Data[0].Status)
+			assert.Equal(http.StatusNotFound, body.Data[1].Status)
+			assert.Equal(http.StatusInternalServerError, body.Data[2].Status)
+		}
+		assert.Equal(bulkMeta{Mode: "partial", Succeeded: 1, Failed: 2}, body.Meta)
+	}
+
+	var updated *models.Todo
+	for _, call := range suite.todos.Calls {
+		if call.Method == "Update" {
+			updated = call.Arguments.Get(0).(*models.Todo)
+		}
+	}
+	if assert.NotNil(updated) {
+		assert.Equal("Ship it", updated.Title)
+		assert.Equal(models.PriorityHigh, updated.Priority)
+	}
+	// only the change which succeeded is journaled
+	suite.journal.AssertNumberOfCalls(suite.T(), "Record", 1)
+}
+
+func (suite *BulkControllerTestSuite) TestStoreTag() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Tag", mock.AnythingOfType("*models.Todo"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		todo := args.Get(0).(*models.Todo)
+		todo.Tags = nil
+		for _, name := range args.Get(1).([]string) {
+			todo.Tags = append(todo.Tags, models.TodoTag{Name: name})
+		}
+		todo.Version++
+	})
+
+	context, response := suite.newContext(`{"mode": "partial", "operations": [
+		{"op": "tag", "id": 1, "tags": ["Urgent", "work", "urgent"]},
+		{"op": "tag", "id": 2, "tags": ["urgent"]}
+	]}`)
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		body := suite.decode(response)
+		if assert.Len(body.Data, 2) {
+			assert.Equal(http.StatusOK, body.Data[0].Status)
+			assert.Equal(http.StatusNotFound, body.Data[1].Status)
+		}
+	}
+	suite.todos.AssertCalled(suite.T(), "Tag", mock.Anything, []string{"urgent", "work"})
+	suite.journal.AssertNotCalled(suite.T(), "Record", mock.Anything)
+
+	// the tags are added to the ones the todo has, up to the most it can have
+	tags := make([]string, requests.MaxTags-1)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d", i)
+	}
+	ops, _ := json.Marshal(map[string]interface{}{"operations": []interface{}{
+		map[string]interface{}{"op": "tag", "id": 1, "tags": tags},
+	}})
+	context, response = suite.newContext(string(ops))
+	assert.NoError(test.Serve(context, suite.bulk.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Equal(http.StatusUnprocessableEntity, suite.decode(response).Data[0].Status)
+	}
+	suite.todos.AssertNumberOfCalls(suite.T(), "Tag", 1)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestBulkControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkControllerTestSuite))
+}
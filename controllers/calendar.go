@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// feedTokenPrefix is the prefix of calendar feed tokens
+const feedTokenPrefix = "cal_"
+
+// errCalendarFeedNotFound is returned when the calendar feed does
+// not exist, was revoked or belongs to another user
+var errCalendarFeedNotFound = apperrors.New(http.StatusNotFound, "calendar_feed_not_found")
+
+// CalendarController handles the calendar feeds of the user
+type CalendarController struct {
+	cr       repositories.CalendarFeedRepository
+	wr       repositories.WorkspaceRepository
+	exporter *transfer.Exporter
+}
+
+// calendarFeedResponse is a private struct for calendar feed response.
+// The URL is only shown once, right after creating the feed.
+type calendarFeedResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Component  string     `json:"component"`
+	URL        string     `json:"url,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewCalendar creates CalendarController instance
+func NewCalendar(cr repositories.CalendarFeedRepository, wr repositories.WorkspaceRepository, exporter *transfer.Exporter) *CalendarController {
+	return &CalendarController{cr, wr, exporter}
+}
+
+// Index lists the calendar feeds of the user in the workspace
+// GET /calendar/feeds
+func (cc *CalendarController) Index(ctx echo.Context) error {
+	feeds := cc.cr.ByUser(auth.User(ctx).ID)
+
+	res := make([]*calendarFeedResponse, 0, len(feeds))
+	for i := range feeds {
+		if feeds[i].WorkspaceID == auth.WorkspaceID(ctx) {
+			res = append(res, newCalendarFeedResponse(&feeds[i]))
+		}
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a calendar feed of the todos in the workspace with a
+// new secret URL
+// POST /calendar/feeds
+func (cc *CalendarController) Store(ctx echo.Context) error {
+	cr := new(requests.CalendarFeedRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	token := auth.NewToken(feedTokenPrefix)
+	feed := cr.CalendarFeedModel(auth.User(ctx).ID, auth.HashToken(token))
+	feed.WorkspaceID = auth.WorkspaceID(ctx)
+	if err := cc.cr.Create(feed); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.CalendarFeedCreated, "calendar_feed", feed.ID, nil, newCalendarFeedResponse(feed))
+
+	res := newCalendarFeedResponse(feed)
+	res.URL = ctx.Scheme() + "://" + ctx.Request().Host + "/calendar/" + token + ".ics"
+	return ctx.JSON(http.StatusCreated, NewResponseData(res))
+}
+
+// Destroy revokes a calendar feed, its URL stops working at once
+// DELETE /calendar/feeds/:id
+func (cc *CalendarController) Destroy(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	feed := cc.cr.ByID(uint(id))
+	if feed == nil || feed.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusNotFound, errCalendarFeedNotFound)
+	}
+	if err := cc.cr.Delete(feed.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.CalendarFeedRevoked, "calendar_feed", feed.ID, newCalendarFeedResponse(feed), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Feed serves the todos of the feed's owner in its workspace as an
+// iCalendar file, the secret token in the URL is what grants access
+// to it while the owner is a member of the workspace. Calendar apps
+// poll feeds, so an unchanged feed is answered with 304.
+// GET /calendar/:token
+func (cc *CalendarController) Feed(ctx echo.Context) error {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+	if !strings.HasPrefix(token, feedTokenPrefix) {
+		return apperrors.From(http.StatusNotFound, errCalendarFeedNotFound)
+	}
+
+	feed := cc.cr.ByTokenHash(auth.HashToken(token))
+	if feed == nil || cc.wr.Membership(feed.WorkspaceID, feed.UserID) == nil {
+		return apperrors.From(http.StatusNotFound, errCalendarFeedNotFound)
+	}
+
+	var buf bytes.Buffer
+	if err := cc.exporter.Workspace(feed.WorkspaceID).Encode(feed.UserID, transfer.NewICalendarEncoder(&buf, feed.Component)); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	cc.cr.Touch(feed.ID, time.Now())
+
+	etag := etagOf(buf.Bytes())
+	ctx.Response().Header().Set("Cache-Control", "private, no-cache")
+	ctx.Response().Header().Set("ETag", etag)
+	if etagMatches(ctx.Request().Header.Get("If-None-Match"), etag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.Blob(http.StatusOK, transfer.FormatICalendar.ContentType(), buf.Bytes())
+}
+
+// newCalendarFeedResponse is a private function for creating *calendarFeedResponse
+func newCalendarFeedResponse(f *models.CalendarFeed) *calendarFeedResponse {
+	component := "todo"
+	if f.Component == models.CalendarEvents {
+		component = "event"
+	}
+
+	return &calendarFeedResponse{
+		ID:         f.ID,
+		Name:       f.Name,
+		Component:  component,
+		LastUsedAt: f.LastUsedAt,
+		CreatedAt:  f.CreatedAt,
+	}
+}
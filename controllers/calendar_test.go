@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/ksungcaya/todo-echo/transfer"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+const feedToken = "cal_0123456789abcdef"
+
+type CalendarControllerTestSuite struct {
+	suite.Suite
+	feeds    *mocks.CalendarFeedRepository
+	todos    *mocks.TodoRepository
+	spaces   *mocks.WorkspaceRepository
+	calendar *CalendarController
+	server   *echo.Echo
+}
+
+func (suite *CalendarControllerTestSuite) SetupTest() {
+	due := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	suite.feeds = &mocks.CalendarFeedRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.todos.On("ByUser", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 1, UpdatedAt: due}, UserID: 1, Title: "Pay rent", DueAt: &due},
+	})
+	suite.spaces = &mocks.WorkspaceRepository{}
+	suite.spaces.On("Membership", uint(0), uint(1)).Return(&models.Membership{UserID: 1, Role: models.WorkspaceOwner})
+	suite.spaces.On("Membership", mock.Anything, mock.Anything).Return(nil)
+	suite.calendar = NewCalendar(suite.feeds, suite.spaces, transfer.NewExporter(suite.todos))
+	suite.server = echo.New()
+}
+
+// feedContext creates a context fetching the feed of the token
+func (suite *CalendarControllerTestSuite) feedContext(token string, etag string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(echo.GET, "/calendar/"+token+".ics", nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(req, response)
+	context.SetParamNames("token")
+	context.SetParamValues(token + ".ics")
+
+	return context, response
+}
+
+func (suite *CalendarControllerTestSuite) TestStoreShowsTheURLOnce() {
+	assert := assert.New(suite.T())
+
+	req := httptest.NewRequest(echo.POST, "/calendar/feeds", strings.NewReader(`{"name": "Phone", "component": "event"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	context, response := newUserContext(suite.server, req)
+	suite.feeds.On("Create", mock.AnythingOfType("*models.CalendarFeed")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.calendar.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		feed := suite.feeds.Calls[0].Arguments.Get(0).(*models.CalendarFeed)
+		assert.Equal(models.CalendarEvents, feed.Component)
+
+		url := test.GetResponseData(response)["url"].(string)
+		token := strings.TrimSuffix(strings.TrimPrefix(url, "http://example.com/calendar/"), ".ics")
+		assert.Equal(auth.HashToken(token), feed.TokenHash)
+		assert.NotContains(feed.TokenHash, token)
+	}
+}
+
+func (suite *CalendarControllerTestSuite) TestStoreValidation() {
+	assert := assert.New(suite.T())
+
+	req := httptest.NewRequest(echo.POST, "/calendar/feeds", strings.NewReader(`{"component": "journal"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	context, response := newUserContext(suite.server, req)
+
+	assert.NoError(test.Serve(context, suite.calendar.Store))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+	assert.NotEmpty(test.GetResponseErrors(response)["component"])
+}
+
+func (suite *CalendarControllerTestSuite) TestFeed() {
+	assert := assert.New(suite.T())
+
+	suite.feeds.On("ByTokenHash", auth.HashToken(feedToken)).Return(&models.CalendarFeed{
+		Model: gorm.Model{ID: 5}, UserID: 1, Component: models.CalendarTodos,
+	})
+	suite.feeds.On("Touch", uint(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	context, response := suite.feedContext(feedToken, "")
+	assert.NoError(test.Serve(context, suite.calendar.Feed))
+
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("text/calendar; charset=UTF-8", response.Header().Get(echo.HeaderContentType))
+	assert.Contains(response.Body.String(), "BEGIN:VTODO\r\nUID:todo-1@todo-echo\r\n")
+	etag := response.Header().Get("ETag")
+	assert.NotEmpty(etag)
+
+	// unchanged feeds are not sent again
+	context, response = suite.feedContext(feedToken, `"stale", W/`+etag)
+	assert.NoError(test.Serve(context, suite.calendar.Feed))
+
+	assert.Equal(http.StatusNotModified, response.Code)
+	assert.Empty(response.Body.String())
+	assert.Equal(etag, response.Header().Get("ETag"))
+}
+
+func (suite *CalendarControllerTestSuite) TestRevokedFeed() {
+	assert := assert.New(suite.T())
+
+	suite.feeds.On("ByTokenHash", auth.HashToken(feedToken)).Return(nil)
+
+	context, response := suite.feedContext(feedToken, "")
+	assert.NoError(test.Serve(context, suite.calendar.Feed))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "ByUser", mock.Anything)
+}
+
+func (suite *CalendarControllerTestSuite) TestDestroyOthersFeed() {
+	assert := assert.New(suite.T())
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.DELETE, "/calendar/feeds/5", nil))
+	context.SetParamNames("id")
+	context.SetParamValues("5")
+	suite.feeds.On("ByID", uint(5)).Return(&models.CalendarFeed{Model: gorm.Model{ID: 5}, UserID: 2})
+
+	assert.NoError(test.Serve(context, suite.calendar.Destroy))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.feeds.AssertNotCalled(suite.T(), "Delete", mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestCalendarControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(CalendarControllerTestSuite))
+}
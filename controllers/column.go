@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errColumnNotFound is returned when the column is not one of the list's
+	errColumnNotFound = apperrors.New(http.StatusNotFound, "column_not_found")
+
+	// errInvalidColumn is returned when a todo is moved to a column
+	// which is not on the board of its list
+	errInvalidColumn = requests.NewFieldError("column_id", "validation.invalid_selection", nil)
+)
+
+// ColumnController handles the boards of the lists, their columns
+// and the todos moving through them
+type ColumnController struct {
+	cr  repositories.ColumnRepository
+	lr  repositories.ListRepository
+	mr  repositories.ListMemberRepository
+	tr  repositories.TodoRepository
+	cmr repositories.CommentRepository
+}
+
+// boardResponse is a private struct for board response, the todos of
+// the list which aren't in a column are its backlog
+type boardResponse struct {
+	ID      uint              `json:"id"`
+	Name    string            `json:"name"`
+	Columns []*columnResponse `json:"columns"`
+	Backlog []*todoResponse   `json:"backlog"`
+}
+
+// columnResponse is a private struct for column response,
+// the todos are only listed on the board
+type columnResponse struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Position  int             `json:"position"`
+	WIPLimit  *int            `json:"wip_limit"`
+	Done      bool            `json:"done"`
+	Todos     []*todoResponse `json:"todos,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// NewColumn creates ColumnController instance
+func NewColumn(cr repositories.ColumnRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, tr repositories.TodoRepository, cmr repositories.CommentRepository) *ColumnController {
+	return &ColumnController{cr, lr, mr, tr, cmr}
+}
+
+// Board displays the columns of a list with their todos in order,
+// its members can see it too
+// GET /lists/:id/board
+func (cc *ColumnController) Board(ctx echo.Context) error {
+	list := findVisibleList(ctx, cc.lr, cc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+
+	columns := cc.cr.ByList(list.ID)
+	res := &boardResponse{
+		ID:      list.ID,
+		Name:    list.Name,
+		Columns: make([]*columnResponse, 0, len(columns)),
+		Backlog: []*todoResponse{},
+	}
+	byID := make(map[uint]*columnResponse, len(columns))
+	for i := range columns {
+		column := newColumnResponse(&columns[i])
+		column.Todos = []*todoResponse{}
+		byID[column.ID] = column
+		res.Columns = append(res.Columns, column)
+	}
+
+	todos := todosOf(ctx, cc.tr).ByList(list.ID)
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Position < todos[j].Position
+	})
+	all := make([]*todoResponse, 0, len(todos))
+	for i := range todos {
+		todo := newTodoResponse(&todos[i])
+		all = append(all, todo)
+		if todo.ColumnID == nil || byID[*todo.ColumnID] == nil {
+			res.Backlog = append(res.Backlog, todo)
+			continue
+		}
+		column := byID[*todo.ColumnID]
+		column.Todos = append(column.Todos, todo)
+	}
+	withCommentCounts(cc.cmr, all)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store adds a column to the end of a list's board, only its owner can
+// POST /lists/:id/columns
+func (cc *ColumnController) Store(ctx echo.Context) error {
+	list, code, err := cc.findOwnList(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+
+	cr := new(requests.ColumnRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	column := &models.Column{ListID: list.ID}
+	cr.Fill(column)
+	if err := cc.cr.Create(column); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ColumnCreated, "column", column.ID, nil, newColumnResponse(column))
+	return ctx.JSON(http.StatusCreated, NewResponseData(newColumnResponse(column)))
+}
+
+// Update changes the name, WIP limit and Done flag of a column
+// PUT /lists/:id/columns/:column
+func (cc *ColumnController) Update(ctx echo.Context) error {
+	column, code, err := cc.findColumn(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+
+	cr := new(requests.ColumnRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := newColumnResponse(column)
+	cr.Fill(column)
+	if err := cc.cr.Update(column); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ColumnUpdated, "column", column.ID, before, newColumnResponse(column))
+	return ctx.JSON(http.StatusOK, NewResponseData(newColumnResponse(column)))
+}
+
+// Move puts a column at another position of its board
+// POST /lists/:id/columns/:column/move
+func (cc *ColumnController) Move(ctx echo.Context) error {
+	column, code, err := cc.findColumn(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+
+	mr := new(requests.MoveRequest)
+	if code, err := mr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := column.Position
+	if err := cc.cr.Move(column, mr.At()); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ColumnMoved, "column", column.ID,
+		map[string]int{"position": before}, map[string]int{"position": column.Position})
+	return ctx.JSON(http.StatusOK, NewResponseData(newColumnResponse(column)))
+}
+
+// Destroy removes a column from its board, the todos in it go back
+// to the backlog
+// DELETE /lists/:id/columns/:column
+func (cc *ColumnController) Destroy(ctx echo.Context) error {
+	column, code, err := cc.findColumn(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+	if err := cc.cr.Delete(column.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ColumnDeleted, "column", column.ID, newColumnResponse(column), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// MoveTodo puts a todo at a position of a column on its list's board,
+// unless the column is full. Moving it to the Done column completes
+// it and moving it out of there reopens it.
+// POST /todos/:id/move
+func (cc *ColumnController) MoveTodo(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := todosOf(ctx, cc.tr).ByID(uint(id))
+	if todo == nil || todo.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	mr := new(requests.MoveRequest)
+	if code, err := mr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	column := cc.cr.ByID(mr.ColumnID)
+	if column == nil || todo.ListID == nil || column.ListID != *todo.ListID {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidColumn)
+	}
+
+	before := boardPlace(todo)
+	err := todosOf(ctx, cc.tr).Move(todo, column, mr.At())
+	if err == repositories.ErrWIPLimit {
+		err := requests.NewFieldError("column_id", "validation.wip_limit", nil)
+		return apperrors.From(http.StatusUnprocessableEntity, err)
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.TodoUpdated, "todo", todo.ID, before, boardPlace(todo))
+
+	res := newTodoResponse(todo)
+	withCommentCounts(cc.cmr, []*todoResponse{res})
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// findOwnList looks up the list from the :id param which the user
+// owns, its members are forbidden to change it
+func (cc *ColumnController) findOwnList(ctx echo.Context) (*models.List, int, error) {
+	list := findVisibleList(ctx, cc.lr, cc.mr)
+	if list == nil {
+		return nil, http.StatusNotFound, errListNotFound
+	}
+	if list.UserID != auth.User(ctx).ID {
+		return nil, http.StatusForbidden, auth.ErrForbidden
+	}
+	return list, http.StatusOK, nil
+}
+
+// findColumn looks up the column from the :column param on the board
+// of the list the user owns
+func (cc *ColumnController) findColumn(ctx echo.Context) (*models.Column, int, error) {
+	list, code, err := cc.findOwnList(ctx)
+	if err != nil {
+		return nil, code, err
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("column"), 10, 64)
+	column := cc.cr.ByID(uint(id))
+	if column == nil || column.ListID != list.ID {
+		return nil, http.StatusNotFound, errColumnNotFound
+	}
+	return column, http.StatusOK, nil
+}
+
+// boardPlace is where the todo is on the board, as it is audited
+func boardPlace(t *models.Todo) map[string]interface{} {
+	return map[string]interface{}{
+		"column_id": t.ColumnID,
+		"position":  t.Position,
+		"completed": t.Completed,
+	}
+}
+
+// newColumnResponse is a private function for creating *columnResponse
+func newColumnResponse(c *models.Column) *columnResponse {
+	return &columnResponse{
+		ID:        c.ID,
+		Name:      c.Name,
+		Position:  c.Position,
+		WIPLimit:  c.WIPLimit,
+		Done:      c.Done,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ColumnControllerTestSuite struct {
+	suite.Suite
+	columns  *mocks.ColumnRepository
+	lists    *mocks.ListRepository
+	members  *mocks.ListMemberRepository
+	todos    *mocks.TodoRepository
+	comments *mocks.CommentRepository
+	column   *ColumnController
+	server   *echo.Echo
+	doing    *models.Column
+}
+
+func (suite *ColumnControllerTestSuite) SetupTest() {
+	suite.columns = &mocks.ColumnRepository{}
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.members = &mocks.ListMemberRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.comments = &mocks.CommentRepository{}
+	suite.column = NewColumn(suite.columns, suite.lists, suite.members, suite.todos, suite.comments)
+	suite.server = echo.New()
+
+	listID, otherID := uint(7), uint(8)
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Release"})
+	suite.members.On("IsMember", uint(7), uint(2)).Return(true)
+	suite.members.On("IsMember", mock.Anything, mock.Anything).Return(false)
+	suite.doing = &models.Column{Model: gorm.Model{ID: 3}, ListID: 7, Name: "Doing"}
+	suite.columns.On("ByID", uint(3)).Return(suite.doing)
+	suite.columns.On("ByID", uint(4)).Return(&models.Column{Model: gorm.Model{ID: 4}, ListID: 8, Name: "Elsewhere"})
+	suite.columns.On("ByID", mock.Anything).Return(nil)
+	suite.todos.On("ByID", uint(5)).Return(&models.Todo{Model: gorm.Model{ID: 5}, UserID: 1, ListID: &listID, Title: "Ship release"})
+	suite.todos.On("ByID", uint(6)).Return(&models.Todo{Model: gorm.Model{ID: 6}, UserID: 1, ListID: &otherID, Title: "Pay rent"})
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+}
+
+// newContext creates a context with the params authenticated as the user
+func (suite *ColumnControllerTestSuite) newContext(userID uint, method string, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	var names, values []string
+	for i := 0; i < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	context.SetParamNames(names...)
+	context.SetParamValues(values...)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: userID}})
+
+	return context, response
+}
+
+func (suite *ColumnControllerTestSuite) TestBoard() {
+	assert := assert.New(suite.T())
+
+	doneID, doingID, goneID := uint(2), uint(3), uint(99)
+	suite.columns.On("ByList", uint(7)).Return([]models.Column{
+		{Model: gorm.Model{ID: 3}, ListID: 7, Name: "Doing"},
+		{Model: gorm.Model{ID: 2}, ListID: 7, Name: "Done", Position: 1, Done: true},
+	})
+	suite.todos.On("ByList", uint(7)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 10}, Title: "Test", ColumnID: &doingID, Position: 1},
+		{Model: gorm.Model{ID: 11}, Title: "Design", ColumnID: &doneID, Completed: true},
+		{Model: gorm.Model{ID: 12}, Title: "Build", ColumnID: &doingID},
+		{Model: gorm.Model{ID: 13}, Title: "Idea"},
+		{Model: gorm.Model{ID: 14}, Title: "Stale", ColumnID: &goneID},
+	})
+
+	// the members of the list can see its board
+	context, response := suite.newContext(2, echo.GET, "", "id", "7")
+	assert.NoError(test.Serve(context, suite.column.Board))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data struct {
+			Columns []struct {
+				Name  string         `json:"name"`
+				Todos []todoResponse `json:"todos"`
+			} `json:"columns"`
+			Backlog []todoResponse `json:"backlog"`
+		} `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	titles := func(todos []todoResponse) []string {
+		var titles []string
+		for _, t := range todos {
+			titles = append(titles, t.Title)
+		}
+		return titles
+	}
+	if assert.Len(body.Data.Columns, 2) {
+		assert.Equal([]string{"Build", "Test"}, titles(body.Data.Columns[0].Todos))
+		assert.Equal([]string{"Design"}, titles(body.Data.Columns[1].Todos))
+	}
+	assert.Equal([]string{"Idea", "Stale"}, titles(body.Data.Backlog))
+
+	context, response = suite.newContext(3, echo.GET, "", "id", "7")
+	assert.NoError(test.Serve(context, suite.column.Board))
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func (suite *ColumnControllerTestSuite) TestStore() {
+	assert := assert.New(suite.T())
+
+	suite.columns.On("Create", mock.AnythingOfType("*models.Column")).Return(nil)
+
+	context, response := suite.newContext(1, echo.POST, `{"name": "Review", "wip_limit": 3}`, "id", "7")
+	assert.NoError(test.Serve(context, suite.column.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		column := suite.columns.Calls[0].Arguments.Get(0).(*models.Column)
+		assert.Equal(uint(7), column.ListID)
+		assert.Equal(3, *column.WIPLimit)
+	}
+
+	context, response = suite.newContext(1, echo.POST, `{"name": "Review", "wip_limit": 0}`, "id", "7")
+	assert.NoError(test.Serve(context, suite.column.Store))
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "wip_limit")
+	}
+
+	// members can see the board but not change it
+	context, response = suite.newContext(2, echo.POST, `{"name": "Review"}`, "id", "7")
+	assert.NoError(test.Serve(context, suite.column.Store))
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.columns.AssertNumberOfCalls(suite.T(), "Create", 1)
+}
+
+func (suite *ColumnControllerTestSuite) TestDestroyColumnOfAnotherList() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(1, echo.DELETE, "", "id", "7", "column", "4")
+	assert.NoError(test.Serve(context, suite.column.Destroy))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.columns.AssertNotCalled(suite.T(), "Delete", mock.Anything)
+}
+
+func (suite *ColumnControllerTestSuite) TestMoveTodo() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Move", mock.AnythingOfType("*models.Todo"), suite.doing, 0).Return(nil).Run(func(args mock.Arguments) {
+		todo := args.Get(0).(*models.Todo)
+		todo.ColumnID = &suite.doing.ID
+	})
+
+	context, response := suite.newContext(1, echo.POST, `{"column_id": 3, "position": 0}`, "id", "5")
+	assert.NoError(test.Serve(context, suite.column.MoveTodo))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal(float64(3), test.GetResponseData(response)["column_id"])
+	}
+}
+
+func (suite *ColumnControllerTestSuite) TestMoveTodoToInvalidColumn() {
+	assert := assert.New(suite.T())
+
+	for _, c := range []struct{ todo, body string }{
+		{"5", `{"column_id": 4}`},
+		{"5", `{"column_id": 42}`},
+		{"6", `{"column_id": 3}`},
+	} {
+		context, response := suite.newContext(1, echo.POST, c.body, "id", c.todo)
+		assert.NoError(test.Serve(context, suite.column.MoveTodo))
+
+		if assert.Equal(http.StatusUnprocessableEntity, response.Code, c.body) {
+			assert.Contains(test.GetResponseErrors(response), "column_id")
+		}
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Move", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ColumnControllerTestSuite) TestMoveTodoToFullColumn() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Move", mock.AnythingOfType("*models.Todo"), suite.doing, -1).Return(repositories.ErrWIPLimit)
+
+	context, response := suite.newContext(1, echo.POST, `{"column_id": 3}`, "id", "5")
+	assert.NoError(test.Serve(context, suite.column.MoveTodo))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Equal([]interface{}{"The column has reached its WIP limit"}, test.GetResponseErrors(response)["column_id"])
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestColumnControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ColumnControllerTestSuite))
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/markdown"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// errCommentNotFound is returned when the comment does not exist
+// or is on another todo
+var errCommentNotFound = apperrors.New(http.StatusNotFound, "comment_not_found")
+
+// errInvalidParent is returned when replying to a comment which
+// does not exist or is on another todo
+var errInvalidParent = requests.NewFieldError("parent_id", "validation.invalid_selection", nil)
+
+// CommentController handles the comments on the todos the user can see
+type CommentController struct {
+	cr    repositories.CommentRepository
+	tr    repositories.TodoRepository
+	lr    repositories.ListRepository
+	mr    repositories.ListMemberRepository
+	ur    repositories.UserRepository
+	inbox *notifiers.Inbox
+}
+
+// commentResponse is a private struct for comment response. The body
+// is the Markdown as written, html is what it renders to. Deleted
+// comments are only shown for their replies, without their content.
+type commentResponse struct {
+	ID        uint               `json:"id"`
+	ParentID  *uint              `json:"parent_id"`
+	Author    *commentAuthor     `json:"author"`
+	Body      string             `json:"body"`
+	HTML      string             `json:"html"`
+	Edited    bool               `json:"edited"`
+	EditedAt  *time.Time         `json:"edited_at"`
+	Version   uint               `json:"version"`
+	Deleted   bool               `json:"deleted"`
+	Replies   []*commentResponse `json:"replies"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// commentAuthor is a private struct for the user who wrote a comment
+type commentAuthor struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// NewComment creates CommentController instance which notifies the
+// users mentioned in the comments through the inbox
+func NewComment(cr repositories.CommentRepository, tr repositories.TodoRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, ur repositories.UserRepository, inbox *notifiers.Inbox) *CommentController {
+	return &CommentController{cr, tr, lr, mr, ur, inbox}
+}
+
+// Index lists the comments on a todo as threads, the oldest first
+// GET /todos/:id/comments
+func (cc *CommentController) Index(ctx echo.Context) error {
+	todo := cc.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newCommentThreads(cc.cr.ByTodo(todo.ID))))
+}
+
+// Store posts a comment on a todo, or a reply to one of its comments
+// POST /todos/:id/comments
+func (cc *CommentController) Store(ctx echo.Context) error {
+	todo := cc.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	cr := new(requests.CommentRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	var parent *models.Comment
+	if cr.ParentID != nil {
+		if parent = cc.cr.ByID(*cr.ParentID); parent == nil || parent.TodoID != todo.ID {
+			return apperrors.From(http.StatusUnprocessableEntity, errInvalidParent)
+		}
+	}
+
+	user := auth.User(ctx)
+	comment := cr.CommentModel(todo.ID, user.ID)
+	if err := cc.cr.Create(comment); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	comment.User = *user
+	audit.Log(ctx, audit.CommentCreated, "comment", comment.ID, nil, map[string]string{"body": comment.Body})
+
+	mentioned := cc.notifyMentioned(ctx, todo, comment.Body, "")
+	cc.notifyCommented(ctx, todo, parent, mentioned)
+
+	return ctx.JSON(http.StatusCreated, NewResponseData(newCommentResponse(comment)))
+}
+
+// Update edits a comment, only its author can. The version it is made
+// to must be given by If-Match or the version field.
+// PUT /todos/:id/comments/:comment
+func (cc *CommentController) Update(ctx echo.Context) error {
+	todo, comment, err := cc.findComment(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if comment.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	cr := new(requests.CommentRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if code, err := checkVersion(ctx, comment.Version, cr.Version); err != nil {
+		return versionError(ctx, code, err, comment.Version, newCommentResponse(comment))
+	}
+
+	before := comment.Body
+	if cr.Body != before {
+		now := time.Now()
+		comment.Body = cr.Body
+		comment.EditedAt = &now
+		err = cc.cr.Update(comment)
+		if err == repositories.ErrVersionConflict {
+			// it was changed after it was read above
+			if current := cc.cr.ByID(comment.ID); current != nil {
+				return versionError(ctx, http.StatusPreconditionFailed, errVersionMismatch, current.Version, newCommentResponse(current))
+			}
+		}
+		if err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+		audit.Log(ctx, audit.CommentUpdated, "comment", comment.ID, map[string]string{"body": before}, map[string]string{"body": comment.Body})
+		cc.notifyMentioned(ctx, todo, comment.Body, before)
+	}
+
+	withVersion(ctx, comment.Version)
+	return ctx.JSON(http.StatusOK, NewResponseData(newCommentResponse(comment)))
+}
+
+// Destroy deletes a comment, its author and the owner of the todo can.
+// The replies to it stay in the thread. The version is required as for
+// editing it.
+// DELETE /todos/:id/comments/:comment
+func (cc *CommentController) Destroy(ctx echo.Context) error {
+	todo, comment, err := cc.findComment(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if user := auth.User(ctx); comment.UserID != user.ID && todo.UserID != user.ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	if code, err := checkVersion(ctx, comment.Version, bodyVersion(ctx)); err != nil {
+		return versionError(ctx, code, err, comment.Version, newCommentResponse(comment))
+	}
+
+	if err := cc.cr.Delete(comment.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.CommentDeleted, "comment", comment.ID, map[string]string{"body": comment.Body}, nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// findTodo looks up the todo from the :id param which the user can
+// see, its owner and the members of its list can
+func (cc *CommentController) findTodo(ctx echo.Context) *models.Todo {
+	return findVisibleTodo(ctx, cc.tr, cc.lr, cc.mr)
+}
+
+// findComment looks up the comment from the :comment param on the
+// todo from the :id param which the user can see
+func (cc *CommentController) findComment(ctx echo.Context) (*models.Todo, *models.Comment, error) {
+	todo := cc.findTodo(ctx)
+	if todo == nil {
+		return nil, nil, errTodoNotFound
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("comment"), 10, 64)
+	comment := cc.cr.ByID(uint(id))
+	if comment == nil || comment.TodoID != todo.ID {
+		return nil, nil, errCommentNotFound
+	}
+	return todo, comment, nil
+}
+
+// notifyMentioned notifies the users mentioned in the body of a
+// comment, leaving out the ones the previous body mentioned already
+// and the ones who can't see the todo. It returns the IDs of the
+// users it notified.
+func (cc *CommentController) notifyMentioned(ctx echo.Context, todo *models.Todo, body string, previous string) map[uint]bool {
+	already := make(map[string]bool)
+	for _, username := range markdown.Mentions(previous) {
+		already[username] = true
+	}
+
+	by := auth.User(ctx)
+	notified := make(map[uint]bool)
+	for _, username := range markdown.Mentions(body) {
+		if already[username] {
+			continue
+		}
+		user := cc.ur.ByUsername(username)
+		if user == nil || !canSeeTodo(cc.lr, cc.mr, todo, user.ID) {
+			continue
+		}
+		notified[user.ID] = true
+		if err := cc.inbox.Mentioned(user, by, todo); err != nil {
+			ctx.Logger().Errorf("comments: notifying %s of a mention: %v", username, err)
+		}
+	}
+	return notified
+}
+
+// notifyCommented notifies the owner of the todo and the author of
+// the comment replied to, unless they were mentioned in the comment
+func (cc *CommentController) notifyCommented(ctx echo.Context, todo *models.Todo, parent *models.Comment, mentioned map[uint]bool) {
+	by := auth.User(ctx)
+	ids := []uint{todo.UserID}
+	if parent != nil && parent.UserID != todo.UserID {
+		ids = append(ids, parent.UserID)
+	}
+
+	for _, id := range ids {
+		if id == by.ID || mentioned[id] {
+			continue
+		}
+		user := cc.ur.ByID(id)
+		if user == nil {
+			continue
+		}
+		if err := cc.inbox.Commented(user, by, todo); err != nil {
+			ctx.Logger().Errorf("comments: notifying user %d of a comment: %v", id, err)
+		}
+	}
+}
+
+// withCommentCounts sets how many comments each of the todos has
+func withCommentCounts(cr repositories.CommentRepository, todos []*todoResponse) {
+	ids := make([]uint, 0, len(todos))
+	for _, t := range todos {
+		ids = append(ids, t.ID)
+	}
+
+	counts := cr.Counts(ids)
+	for _, t := range todos {
+		t.Comments = counts[t.ID]
+	}
+}
+
+// newCommentThreads is a private function for arranging the comments
+// into threads of *commentResponse. Deleted comments without replies
+// are left out.
+func newCommentThreads(comments []models.Comment) []*commentResponse {
+	byID := make(map[uint]*commentResponse, len(comments))
+	for i := range comments {
+		byID[comments[i].ID] = newCommentResponse(&comments[i])
+	}
+
+	threads := make([]*commentResponse, 0, len(comments))
+	for i := range comments {
+		res := byID[comments[i].ID]
+		if res.ParentID != nil {
+			if parent, ok := byID[*res.ParentID]; ok {
+				parent.Replies = append(parent.Replies, res)
+				continue
+			}
+		}
+		threads = append(threads, res)
+	}
+
+	return withoutDeletedLeaves(threads)
+}
+
+// withoutDeletedLeaves drops the deleted comments nobody replied to
+func withoutDeletedLeaves(comments []*commentResponse) []*commentResponse {
+	kept := comments[:0]
+	for _, c := range comments {
+		c.Replies = withoutDeletedLeaves(c.Replies)
+		if !c.Deleted || len(c.Replies) > 0 {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// newCommentResponse is a private function for creating *commentResponse
+func newCommentResponse(c *models.Comment) *commentResponse {
+	r := &commentResponse{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Deleted:   c.IsDeleted(),
+		Replies:   []*commentResponse{},
+		CreatedAt: c.CreatedAt,
+	}
+	if r.Deleted {
+		return r
+	}
+
+	r.Author = &commentAuthor{ID: c.UserID, Username: c.User.Username, Name: c.User.Name}
+	r.Body = c.Body
+	r.HTML = markdown.Render(c.Body)
+	r.Edited = c.IsEdited()
+	r.EditedAt = c.EditedAt
+	r.Version = c.Version
+	return r
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type CommentControllerTestSuite struct {
+	suite.Suite
+	comments      *mocks.CommentRepository
+	todos         *mocks.TodoRepository
+	lists         *mocks.ListRepository
+	members       *mocks.ListMemberRepository
+	users         *mocks.UserRepository
+	notifications *mocks.NotificationRepository
+	comment       *CommentController
+	server        *echo.Echo
+	alice         *models.User
+	bob           *models.User
+	carol         *models.User
+}
+
+func (suite *CommentControllerTestSuite) SetupTest() {
+	suite.comments = &mocks.CommentRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.members = &mocks.ListMemberRepository{}
+	suite.users = &mocks.UserRepository{}
+	suite.notifications = &mocks.NotificationRepository{}
+	suite.notifications.On("IsEnabled", mock.Anything, mock.Anything).Return(true)
+	suite.notifications.On("Create", mock.AnythingOfType("*models.Notification")).Return(nil)
+	suite.comment = NewComment(suite.comments, suite.todos, suite.lists, suite.members, suite.users, notifiers.NewInbox(suite.notifications, time.Hour))
+	suite.server = echo.New()
+
+	// alice owns the list of the todo, bob is a member of it and carol isn't
+	suite.alice = &models.User{Model: gorm.Model{ID: 1}, Username: "alice", Name: "Alice"}
+	suite.bob = &models.User{Model: gorm.Model{ID: 2}, Username: "bob", Name: "Bob"}
+	suite.carol = &models.User{Model: gorm.Model{ID: 4}, Username: "carol", Name: "Carol"}
+	suite.users.On("ByUsername", "bob").Return(suite.bob)
+	suite.users.On("ByUsername", "carol").Return(suite.carol)
+	suite.users.On("ByUsername", mock.Anything).Return(nil)
+	listID := uint(7)
+	suite.todos.On("ByID", uint(3)).Return(&models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, ListID: &listID, Title: "Ship release"})
+	suite.lists.On("ByID", listID).Return(&models.List{Model: gorm.Model{ID: listID}, UserID: 1})
+	suite.members.On("IsMember", listID, uint(2)).Return(true)
+	suite.members.On("IsMember", listID, mock.Anything).Return(false)
+}
+
+// newContext creates a context on the comments of the todo authenticated as the user
+func (suite *CommentControllerTestSuite) newContext(user *models.User, method string, body string, comment string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/todos/3/comments", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id", "comment")
+	context.SetParamValues("3", comment)
+	auth.SetUser(context, user)
+
+	return context, response
+}
+
+// notified are the notifications pushed to the inbox
+func (suite *CommentControllerTestSuite) notified() []*models.Notification {
+	var notifications []*models.Notification
+	for _, call := range suite.notifications.Calls {
+		if call.Method == "Create" {
+			notifications = append(notifications, call.Arguments.Get(0).(*models.Notification))
+		}
+	}
+	return notifications
+}
+
+func (suite *CommentControllerTestSuite) TestStoreNotifiesTheMentionedUsers() {
+	assert := assert.New(suite.T())
+
+	suite.comments.On("Create", mock.AnythingOfType("*models.Comment")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Comment).ID = 9
+	})
+
+	context, response := suite.newContext(suite.alice, echo.POST, `{"body": "**Ready** for review @bob, @carol, @nobody"}`, "")
+	assert.NoError(test.Serve(context, suite.comment.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal("<p><strong>Ready</strong> for review <span class=\"mention\">@bob</span>, <span class=\"mention\">@carol</span>, <span class=\"mention\">@nobody</span></p>", data["html"])
+		assert.Equal("alice", data["author"].(map[string]interface{})["username"])
+		assert.Equal(false, data["edited"])
+	}
+
+	// alice owns the todo and carol can't see it, so only bob is notified
+	if notifications := suite.notified(); assert.Len(notifications, 1) {
+		assert.Equal(uint(2), notifications[0].UserID)
+		assert.Equal(models.NotificationMentioned, notifications[0].Type)
+		assert.Equal(`Alice mentioned you on "Ship release"`, notifications[0].Title)
+	}
+}
+
+func (suite *CommentControllerTestSuite) TestStoreReplyToAnotherTodo() {
+	assert := assert.New(suite.T())
+
+	suite.comments.On("ByID", uint(5)).Return(&models.Comment{Model: gorm.Model{ID: 5}, TodoID: 4})
+
+	context, response := suite.newContext(suite.alice, echo.POST, `{"body": "Done", "parent_id": 5}`, "")
+	assert.NoError(test.Serve(context, suite.comment.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "parent_id")
+	}
+	suite.comments.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *CommentControllerTestSuite) TestUpdateMarksTheCommentEdited() {
+	assert := assert.New(suite.T())
+
+	suite.comments.On("ByID", uint(5)).Return(&models.Comment{Model: gorm.Model{ID: 5}, TodoID: 3, UserID: 1, User: *suite.alice, Body: "cc @bob", Version: 1})
+	suite.comments.On("Update", mock.AnythingOfType("*models.Comment")).Return(nil)
+
+	context, response := suite.newContext(suite.alice, echo.PUT, `{"body": "cc @bob, done", "version": 1}`, "5")
+	assert.NoError(test.Serve(context, suite.comment.Update))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal(true, data["edited"])
+		assert.NotNil(data["edited_at"])
+	}
+	// bob was mentioned before the edit already
+	assert.Empty(suite.notified())
+}
+
+func (suite *CommentControllerTestSuite) TestUpdateCommentOfAnotherUser() {
+	assert := assert.New(suite.T())
+
+	suite.comments.On("ByID", uint(5)).Return(&models.Comment{Model: gorm.Model{ID: 5}, TodoID: 3, UserID: 2})
+
+	context, response := suite.newContext(suite.alice, echo.PUT, `{"body": "Hijacked"}`, "5")
+	assert.NoError(test.Serve(context, suite.comment.Update))
+
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.comments.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *CommentControllerTestSuite) TestStoreAsListMember() {
+	assert := assert.New(suite.T())
+
+	suite.comments.On("Create", mock.AnythingOfType("*models.Comment")).Return(nil)
+	suite.users.On("ByID", uint(1)).Return(suite.alice)
+
+	context, response := suite.newContext(suite.bob, echo.POST, `{"body": "On it"}`, "")
+	assert.NoError(test.Serve(context, suite.comment.Store))
+
+	assert.Equal(http.StatusCreated, response.Code)
+	// the owner of the todo is notified of the comment of bob
+	if notifications := suite.notified(); assert.Len(notifications, 1) {
+		assert.Equal(uint(1), notifications[0].UserID)
+		assert.Equal(models.NotificationCommented, notifications[0].Type)
+	}
+}
+
+func (suite *CommentControllerTestSuite) TestIndexOfTodoOfAnotherUser() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(suite.carol, echo.GET, "", "")
+	assert.NoError(test.Serve(context, suite.comment.Index))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func (suite *CommentControllerTestSuite) TestNewCommentThreads() {
+	assert := assert.New(suite.T())
+
+	root, reply, deleted, orphan := uint(1), uint(2), uint(3), uint(4)
+	trashed := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	threads := newCommentThreads([]models.Comment{
+		{Model: gorm.Model{ID: root}, Body: "Root"},
+		{Model: gorm.Model{ID: reply}, ParentID: &root, Body: "Reply"},
+		{Model: gorm.Model{ID: deleted, DeletedAt: trashed}, ParentID: &root, Body: "Gone"},
+		{Model: gorm.Model{ID: orphan, DeletedAt: trashed}, Body: "Gone with its replies"},
+		{Model: gorm.Model{ID: 5}, ParentID: &orphan, Body: "Still here"},
+	})
+
+	if assert.Len(threads, 2) {
+		if assert.Len(threads[0].Replies, 1) {
+			assert.Equal("Reply", threads[0].Replies[0].Body)
+		}
+		assert.True(threads[1].Deleted)
+		assert.Empty(threads[1].Body)
+		assert.Len(threads[1].Replies, 1)
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestCommentControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentControllerTestSuite))
+}
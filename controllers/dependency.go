@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errBlockerNotFound is returned when the todo is not blocked by the other one
+	errBlockerNotFound = apperrors.New(http.StatusNotFound, "blocker_not_found")
+
+	// errInvalidBlocker is returned when a todo is blocked by a todo
+	// which does not exist or belongs to another user
+	errInvalidBlocker = requests.NewFieldError("blocker_id", "validation.invalid_selection", nil)
+)
+
+// DependencyController handles which todos block the others
+type DependencyController struct {
+	tr repositories.TodoRepository
+	cr repositories.CommentRepository
+}
+
+// NewDependency creates DependencyController instance
+func NewDependency(tr repositories.TodoRepository, cr repositories.CommentRepository) *DependencyController {
+	return &DependencyController{tr, cr}
+}
+
+// Ready lists the open todos of the user which no open todo blocks,
+// the most urgent first
+// GET /todos/ready
+func (dc *DependencyController) Ready(ctx echo.Context) error {
+	todos := todosOf(ctx, dc.tr).Ready(auth.User(ctx).ID)
+
+	res := make([]*todoResponse, 0, len(todos))
+	for i := range todos {
+		res = append(res, newTodoResponse(&todos[i]))
+	}
+	withCommentCounts(dc.cr, res)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store makes a todo blocked by another todo of the user, unless
+// the other todo is already blocked by it
+// POST /todos/:id/blockers
+func (dc *DependencyController) Store(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := dc.findTodo(ctx, uint(id))
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	br := new(requests.BlockerRequest)
+	if code, err := br.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	blocker := dc.findTodo(ctx, br.BlockerID)
+	if blocker == nil {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidBlocker)
+	}
+
+	before := todo.BlockerIDs()
+	err := todosOf(ctx, dc.tr).Block(todo, blocker.ID)
+	if err == repositories.ErrDependencyCycle {
+		err := requests.NewFieldError("blocker_id", "validation.dependency_cycle", nil)
+		return apperrors.From(http.StatusUnprocessableEntity, err)
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.TodoUpdated, "todo", todo.ID,
+		map[string][]uint{"blocked_by": before}, map[string][]uint{"blocked_by": todo.BlockerIDs()})
+
+	withVersion(ctx, todo.Version)
+	return ctx.JSON(http.StatusCreated, NewResponseData(newTodoResponse(todo)))
+}
+
+// Destroy removes the dependency of a todo on another todo
+// DELETE /todos/:id/blockers/:blocker
+func (dc *DependencyController) Destroy(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := dc.findTodo(ctx, uint(id))
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	blockerID, _ := strconv.ParseUint(ctx.Param("blocker"), 10, 64)
+	before := todo.BlockerIDs()
+	if !containsID(before, uint(blockerID)) {
+		return apperrors.From(http.StatusNotFound, errBlockerNotFound)
+	}
+	if err := todosOf(ctx, dc.tr).Unblock(todo, uint(blockerID)); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.TodoUpdated, "todo", todo.ID,
+		map[string][]uint{"blocked_by": before}, map[string][]uint{"blocked_by": todo.BlockerIDs()})
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// findTodo looks up the todo by ID which the user owns
+func (dc *DependencyController) findTodo(ctx echo.Context, id uint) *models.Todo {
+	todo := todosOf(ctx, dc.tr).ByID(id)
+	if todo == nil || todo.UserID != auth.User(ctx).ID {
+		return nil
+	}
+	return todo
+}
+
+// containsID determines if the ID is one of the IDs
+func containsID(ids []uint, id uint) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type DependencyControllerTestSuite struct {
+	suite.Suite
+	todos      *mocks.TodoRepository
+	comments   *mocks.CommentRepository
+	dependency *DependencyController
+	server     *echo.Echo
+	todo       *models.Todo
+}
+
+func (suite *DependencyControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.comments = &mocks.CommentRepository{}
+	suite.dependency = NewDependency(suite.todos, suite.comments)
+	suite.server = echo.New()
+
+	suite.todo = &models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Ship release",
+		Blockers: []models.TodoDependency{{TodoID: 3, BlockerID: 4}}}
+	suite.todos.On("ByID", uint(3)).Return(suite.todo)
+	suite.todos.On("ByID", uint(4)).Return(&models.Todo{Model: gorm.Model{ID: 4}, UserID: 1, Title: "Build"})
+	suite.todos.On("ByID", uint(5)).Return(&models.Todo{Model: gorm.Model{ID: 5}, UserID: 2, Title: "Theirs"})
+	suite.todos.On("ByID", mock.Anything).Return(nil)
+}
+
+// newContext creates a context on the blockers of the todo authenticated as the first user
+func (suite *DependencyControllerTestSuite) newContext(method string, body string, blocker string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/todos/3/blockers", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id", "blocker")
+	context.SetParamValues("3", blocker)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}})
+
+	return context, response
+}
+
+func (suite *DependencyControllerTestSuite) TestStore() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Block", suite.todo, uint(4)).Return(nil)
+
+	context, response := suite.newContext(echo.POST, `{"blocker_id": 4}`, "")
+	assert.NoError(test.Serve(context, suite.dependency.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		assert.Equal([]interface{}{float64(4)}, test.GetResponseData(response)["blocked_by"])
+	}
+}
+
+func (suite *DependencyControllerTestSuite) TestStoreCycle() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Block", suite.todo, uint(4)).Return(repositories.ErrDependencyCycle)
+
+	context, response := suite.newContext(echo.POST, `{"blocker_id": 4}`, "")
+	assert.NoError(test.Serve(context, suite.dependency.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "blocker_id")
+	}
+}
+
+func (suite *DependencyControllerTestSuite) TestStoreInvalidBlocker() {
+	assert := assert.New(suite.T())
+
+	for _, body := range []string{`{"blocker_id": 5}`, `{"blocker_id": 6}`, `{}`} {
+		context, response := suite.newContext(echo.POST, body, "")
+		assert.NoError(test.Serve(context, suite.dependency.Store))
+
+		if assert.Equal(http.StatusUnprocessableEntity, response.Code, body) {
+			assert.Contains(test.GetResponseErrors(response), "blocker_id")
+		}
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Block", mock.Anything, mock.Anything)
+}
+
+func (suite *DependencyControllerTestSuite) TestDestroy() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Unblock", suite.todo, uint(4)).Return(nil)
+
+	context, response := suite.newContext(echo.DELETE, "", "4")
+	assert.NoError(test.Serve(context, suite.dependency.Destroy))
+	assert.Equal(http.StatusNoContent, response.Code)
+
+	context, response = suite.newContext(echo.DELETE, "", "5")
+	assert.NoError(test.Serve(context, suite.dependency.Destroy))
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.todos.AssertNumberOfCalls(suite.T(), "Unblock", 1)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestDependencyControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(DependencyControllerTestSuite))
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errVersionRequired is returned when changing a record without
+	// telling which version of it the change was made to
+	errVersionRequired = apperrors.New(http.StatusPreconditionRequired, "version_required")
+
+	// errVersionMismatch is returned when changing a record which was
+	// changed since the version the change was made to
+	errVersionMismatch = apperrors.New(http.StatusPreconditionFailed, "version_mismatch")
+)
+
+// versionBody is a private struct for the version of a record given
+// in the body or the query of a request without any other fields
+type versionBody struct {
+	Version *uint `json:"version" form:"version" query:"version"`
+}
+
+// etagOf creates a strong entity tag of the response body
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// versionTag creates the strong entity tag of a version of a record
+func versionTag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// etagMatches determines if an If-None-Match header lists the
+// entity tag. Weak tags match as well, as the comparison for
+// If-None-Match is the weak one.
+func etagMatches(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatchesStrongly determines if an If-Match header lists the
+// entity tag. Weak tags never match, as the comparison for If-Match
+// is the strong one.
+func etagMatchesStrongly(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag == etag && !strings.HasPrefix(tag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// withVersion sets the entity tag of the version of the record on the
+// response
+func withVersion(ctx echo.Context, version uint) {
+	ctx.Response().Header().Set("ETag", versionTag(version))
+}
+
+// notModified sets the entity tag of the version of the record on the
+// response, and determines if the client has that version already
+func notModified(ctx echo.Context, version uint) bool {
+	withVersion(ctx, version)
+	return etagMatches(ctx.Request().Header.Get("If-None-Match"), versionTag(version))
+}
+
+// checkVersion makes sure a change is made to the current version of
+// a record, which is given by the If-Match header or else the version
+// field. It returns the status code to respond with when it is not.
+func checkVersion(ctx echo.Context, current uint, version *uint) (int, error) {
+	if header := ctx.Request().Header.Get("If-Match"); header != "" {
+		if !etagMatchesStrongly(header, versionTag(current)) {
+			return http.StatusPreconditionFailed, errVersionMismatch
+		}
+		return http.StatusOK, nil
+	}
+
+	if version == nil {
+		return http.StatusPreconditionRequired, errVersionRequired
+	}
+	if *version != current {
+		return http.StatusPreconditionFailed, errVersionMismatch
+	}
+	return http.StatusOK, nil
+}
+
+// versionError responds with the error of checkVersion, a change made
+// to an outdated version gets the current one of the record
+func versionError(ctx echo.Context, code int, err error, version uint, current interface{}) error {
+	if code != http.StatusPreconditionFailed {
+		return apperrors.From(code, err)
+	}
+
+	withVersion(ctx, version)
+	return apperrors.From(code, err).With("data", current)
+}
+
+// bodyVersion reads the version of the record from the body or the
+// query of a request which has no other fields, e.g. DELETE
+func bodyVersion(ctx echo.Context) *uint {
+	vb := new(versionBody)
+	if err := ctx.Bind(vb); err != nil {
+		return nil
+	}
+	return vb.Version
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// EventController streams the changes of the user's data in real-time
+type EventController struct {
+	bus       events.Bus
+	heartbeat time.Duration
+}
+
+// NewEvents creates EventController instance
+func NewEvents(bus events.Bus, heartbeat time.Duration) *EventController {
+	return &EventController{bus, heartbeat}
+}
+
+// Stream sends the events as Server-Sent Events. Clients resume
+// from where they left off through the Last-Event-ID header.
+// GET /events
+func (ec *EventController) Stream(ctx echo.Context) error {
+	sub := ec.bus.Subscribe(auth.User(ctx).ID, lastEventID(ctx))
+	defer sub.Close()
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(res, "retry: %d\n\n", 3000)
+	if sub.Missed {
+		fmt.Fprint(res, "event: reset\ndata: {}\n\n")
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(ec.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(res, ": heartbeat\n\n")
+		case e, ok := <-sub.C:
+			if !ok {
+				// fell behind, the client reconnects with its Last-Event-ID
+				return nil
+			}
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		}
+		res.Flush()
+	}
+}
+
+// Socket sends the events as JSON messages over a WebSocket.
+// Clients resume by passing their last_event_id query param.
+// GET /events/ws
+func (ec *EventController) Socket(ctx echo.Context) error {
+	sub := ec.bus.Subscribe(auth.User(ctx).ID, lastEventID(ctx))
+	defer sub.Close()
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// drain the client's messages so closing the socket is noticed
+		closed := make(chan struct{})
+		go func() {
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+			close(closed)
+		}()
+
+		send := func(v interface{}) bool {
+			ws.SetWriteDeadline(time.Now().Add(ec.heartbeat))
+			return websocket.JSON.Send(ws, v) == nil
+		}
+
+		if sub.Missed && !send(map[string]string{"type": "reset"}) {
+			return
+		}
+
+		heartbeat := time.NewTicker(ec.heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			var ok bool
+			select {
+			case <-closed:
+				return
+			case <-heartbeat.C:
+				ok = send(map[string]string{"type": "heartbeat"})
+			case e, open := <-sub.C:
+				ok = open && send(e)
+			}
+			if !ok {
+				return
+			}
+		}
+	}}
+
+	server.ServeHTTP(ctx.Response(), ctx.Request())
+	return nil
+}
+
+// lastEventID reads the ID of the last event the client has seen
+func lastEventID(ctx echo.Context) uint64 {
+	id := ctx.Request().Header.Get("Last-Event-ID")
+	if id == "" {
+		id = ctx.QueryParam("last_event_id")
+	}
+
+	n, _ := strconv.ParseUint(id, 10, 64)
+	return n
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+type EventControllerTestSuite struct {
+	suite.Suite
+	bus    *events.MemoryBus
+	server *httptest.Server
+}
+
+func (suite *EventControllerTestSuite) SetupTest() {
+	suite.bus = events.NewMemoryBus(10, 10)
+	ec := NewEvents(suite.bus, time.Hour)
+
+	e := echo.New()
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			auth.SetUser(ctx, &models.User{Model: gorm.Model{ID: 1}})
+			return next(ctx)
+		}
+	}
+	e.GET("/events", ec.Stream, authenticate)
+	e.GET("/events/ws", ec.Socket, authenticate)
+	suite.server = httptest.NewServer(e)
+}
+
+func (suite *EventControllerTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *EventControllerTestSuite) TestStreamResumesFromLastEventID() {
+	assert := assert.New(suite.T())
+
+	suite.bus.Publish(events.Event{Type: events.TodoCreated, ResourceID: 1, UserIDs: []uint{1}})
+	suite.bus.Publish(events.Event{Type: events.TodoCreated, ResourceID: 2, UserIDs: []uint{2}})
+	suite.bus.Publish(events.Event{Type: events.TodoUpdated, ResourceID: 3, UserIDs: []uint{1}})
+
+	request, _ := http.NewRequest(echo.GET, suite.server.URL+"/events", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if !assert.NoError(err) {
+		return
+	}
+	defer response.Body.Close()
+
+	assert.Equal("text/event-stream", response.Header.Get(echo.HeaderContentType))
+
+	var lines []string
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() && len(lines) < 4 {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal("retry: 3000", lines[0])
+	assert.Equal("id: 3", lines[1])
+	assert.Equal("event: todo.updated", lines[2])
+	assert.True(strings.HasPrefix(lines[3], `data: {"id":3,"type":"todo.updated"`))
+}
+
+func (suite *EventControllerTestSuite) TestSocketStreamsUserEvents() {
+	assert := assert.New(suite.T())
+
+	url := strings.Replace(suite.server.URL, "http", "ws", 1) + "/events/ws"
+	ws, err := websocket.Dial(url, "", suite.server.URL)
+	if !assert.NoError(err) {
+		return
+	}
+	defer ws.Close()
+
+	// the subscription is made before the handshake completes
+	suite.bus.Publish(events.Event{Type: events.TodoDeleted, ResourceID: 7, UserIDs: []uint{2}})
+	suite.bus.Publish(events.Event{Type: events.TodoDeleted, ResourceID: 8, UserIDs: []uint{1}})
+
+	var e events.Event
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	if assert.NoError(websocket.JSON.Receive(ws, &e)) {
+		assert.Equal(events.TodoDeleted, e.Type)
+		assert.Equal(uint(8), e.ResourceID)
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestEventControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(EventControllerTestSuite))
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// newUserContext creates a context of the request on the server which
+// is authenticated as the first user. It can't be in the test package
+// along with test.Serve, the tests of the repositories import it and
+// auth imports the repositories.
+func newUserContext(server *echo.Echo, req *http.Request) (echo.Context, *httptest.ResponseRecorder) {
+	response := httptest.NewRecorder()
+	context := server.NewContext(req, response)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}})
+
+	return context, response
+}
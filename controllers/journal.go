@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/echo/v4"
+)
+
+// JournalController handles undoing and redoing the user's changes
+type JournalController struct {
+	jr repositories.JournalRepository
+}
+
+// operationResponse is a private struct for operation response
+type operationResponse struct {
+	ID        uint            `json:"id"`
+	Kind      string          `json:"kind"`
+	Subject   string          `json:"subject"`
+	SubjectID uint            `json:"subject_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Undone    bool            `json:"undone"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewJournal creates JournalController instance
+func NewJournal(jr repositories.JournalRepository) *JournalController {
+	return &JournalController{jr}
+}
+
+// Index lists the operations the user can undo or redo, the latest first
+// GET /journal
+func (jc *JournalController) Index(ctx echo.Context) error {
+	ops := journalOf(ctx, jc.jr).History(auth.User(ctx).ID)
+
+	res := make([]*operationResponse, 0, len(ops))
+	for i := range ops {
+		res = append(res, newOperationResponse(&ops[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Undo reverts the user's latest change
+// POST /undo
+func (jc *JournalController) Undo(ctx echo.Context) error {
+	op, err := journalOf(ctx, jc.jr).Undo(auth.User(ctx).ID)
+	if err != nil {
+		return apperrors.From(journalErrorCode(err), err)
+	}
+	audit.Log(ctx, audit.OperationUndone, op.Subject, op.SubjectID, snapshotJSON(op.After), snapshotJSON(op.Before))
+	return ctx.JSON(http.StatusOK, NewResponseData(newOperationResponse(op)))
+}
+
+// Redo applies again the change the user undid last
+// POST /redo
+func (jc *JournalController) Redo(ctx echo.Context) error {
+	op, err := journalOf(ctx, jc.jr).Redo(auth.User(ctx).ID)
+	if err != nil {
+		return apperrors.From(journalErrorCode(err), err)
+	}
+	audit.Log(ctx, audit.OperationRedone, op.Subject, op.SubjectID, snapshotJSON(op.Before), snapshotJSON(op.After))
+	return ctx.JSON(http.StatusOK, NewResponseData(newOperationResponse(op)))
+}
+
+// record adds the operation to the user's journal. The change was
+// already made, so failing to record it only costs its undo and is
+// logged rather than failing the request.
+func record(ctx echo.Context, jr repositories.JournalRepository, op *models.Operation) {
+	if err := jr.Record(op); err != nil {
+		ctx.Logger().Errorf("journal: recording %s of %s %d: %v", op.Kind, op.Subject, op.SubjectID, err)
+	}
+}
+
+// journalErrorCode is the status code of the error undoing or redoing
+func journalErrorCode(err error) int {
+	switch err {
+	case repositories.ErrNothingToUndo, repositories.ErrNothingToRedo:
+		return http.StatusNotFound
+	case repositories.ErrOperationConflict:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// newOperationResponse is a private function for creating *operationResponse
+func newOperationResponse(op *models.Operation) *operationResponse {
+	return &operationResponse{
+		ID:        op.ID,
+		Kind:      op.Kind,
+		Subject:   op.Subject,
+		SubjectID: op.SubjectID,
+		Before:    snapshotJSON(op.Before),
+		After:     snapshotJSON(op.After),
+		Undone:    op.IsUndone(),
+		CreatedAt: op.CreatedAt,
+	}
+}
+
+// snapshotJSON is the stored snapshot as JSON, null when there's none
+func snapshotJSON(snapshot string) json.RawMessage {
+	if snapshot == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(snapshot)
+}
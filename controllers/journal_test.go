@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type JournalControllerTestSuite struct {
+	suite.Suite
+	repo    *mocks.JournalRepository
+	journal *JournalController
+	server  *echo.Echo
+}
+
+func (suite *JournalControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.JournalRepository{}
+	suite.repo.On("Workspace", mock.Anything).Return(suite.repo)
+	suite.journal = NewJournal(suite.repo)
+	suite.server = echo.New()
+}
+
+// newContext creates a context posting to the target authenticated as
+// the first user
+func (suite *JournalControllerTestSuite) newContext(target string) (echo.Context, *httptest.ResponseRecorder) {
+	return newUserContext(suite.server, httptest.NewRequest(echo.POST, target, nil))
+}
+
+func (suite *JournalControllerTestSuite) TestUndo() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("Undo", uint(1)).Return(&models.Operation{
+		Model:     gorm.Model{ID: 4},
+		Kind:      models.OperationDelete,
+		Subject:   models.OperationTodo,
+		SubjectID: 2,
+		Before:    `{"title":"Ship release"}`,
+	}, nil)
+
+	context, response := suite.newContext("/undo")
+	assert.NoError(test.Serve(context, suite.journal.Undo))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal("delete", data["kind"])
+		assert.Equal("Ship release", data["before"].(map[string]interface{})["title"])
+		assert.Nil(data["after"])
+	}
+}
+
+func (suite *JournalControllerTestSuite) TestUndoConflict() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("Undo", uint(1)).Return(nil, repositories.ErrOperationConflict)
+
+	context, response := suite.newContext("/undo")
+	assert.NoError(test.Serve(context, suite.journal.Undo))
+
+	assert.Equal(http.StatusConflict, response.Code)
+}
+
+func (suite *JournalControllerTestSuite) TestNothingToRedo() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("Redo", uint(1)).Return(nil, repositories.ErrNothingToRedo)
+
+	context, response := suite.newContext("/redo")
+	assert.NoError(test.Serve(context, suite.journal.Redo))
+
+	if assert.Equal(http.StatusNotFound, response.Code) {
+		assert.Equal("Nothing to redo", test.GetResponseProblem(response)["detail"])
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestJournalControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(JournalControllerTestSuite))
+}
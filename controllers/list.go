@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// errListNotFound is returned when the list does not exist or
+// belongs to another user
+var errListNotFound = apperrors.New(http.StatusNotFound, "list_not_found")
+
+// ListController handles the todo lists of the authenticated user
+type ListController struct {
+	lr repositories.ListRepository
+	tr repositories.TodoRepository
+	jr repositories.JournalRepository
+	cr repositories.CommentRepository
+	mr repositories.ListMemberRepository
+}
+
+// listResponse is a private struct for list response,
+// the todos are only listed when showing a single list
+type listResponse struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Version   uint            `json:"version"`
+	Todos     []*todoResponse `json:"todos,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// NewList creates ListController instance which records the changes
+// to the lists in the user's journal
+func NewList(lr repositories.ListRepository, tr repositories.TodoRepository, jr repositories.JournalRepository, cr repositories.CommentRepository, mr repositories.ListMemberRepository) *ListController {
+	return &ListController{lr, tr, jr, cr, mr}
+}
+
+// Index lists the lists of the user
+// GET /lists
+func (lc *ListController) Index(ctx echo.Context) error {
+	lists := listsOf(ctx, lc.lr).ByUser(auth.User(ctx).ID)
+
+	res := make([]*listResponse, 0, len(lists))
+	for i := range lists {
+		res = append(res, newListResponse(&lists[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a list
+// POST /lists
+func (lc *ListController) Store(ctx echo.Context) error {
+	lr := new(requests.ListRequest)
+	if code, err := lr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	list := lr.ListModel(auth.User(ctx).ID)
+	if err := listsOf(ctx, lc.lr).Create(list); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	record(ctx, lc.jr, models.NewListOperation(models.OperationCreate, list, nil, models.NewListSnapshot(list)))
+	audit.Log(ctx, events.ListCreated, "list", list.ID, nil, models.NewListSnapshot(list))
+	withVersion(ctx, list.Version)
+	return ctx.JSON(http.StatusCreated, NewResponseData(newListResponse(list)))
+}
+
+// Show displays a list along with its todos, its members can see it
+// too. Use ?sort= to order the todos, e.g. topological to put every
+// todo after the ones blocking it. The ETag is the one of the body,
+// which changes with the todos as well, so it is not the one updating
+// the list takes; the version field is.
+// GET /lists/:id
+func (lc *ListController) Show(ctx echo.Context) error {
+	list := findVisibleList(ctx, lc.lr, lc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+	order := ctx.QueryParam("sort")
+	if !isTodoSort(order) {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidSort)
+	}
+
+	res := newListResponse(list)
+	todos := todosOf(ctx, lc.tr).ByList(list.ID)
+	sortTodos(todos, order)
+	res.Todos = make([]*todoResponse, 0, len(todos))
+	for i := range todos {
+		res.Todos = append(res.Todos, newTodoResponse(&todos[i]))
+	}
+	withCommentCounts(lc.cr, res.Todos)
+
+	body, err := json.Marshal(NewResponseData(res))
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	etag := etagOf(body)
+	ctx.Response().Header().Set("ETag", etag)
+	if etagMatches(ctx.Request().Header.Get("If-None-Match"), etag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSONBlob(http.StatusOK, body)
+}
+
+// Update renames a list, the version it is made to must be given by
+// If-Match or the version field
+// PUT /lists/:id
+func (lc *ListController) Update(ctx echo.Context) error {
+	list, err := lc.findList(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	lr := new(requests.ListRequest)
+	if code, err := lr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if code, err := checkVersion(ctx, list.Version, lr.Version); err != nil {
+		return versionError(ctx, code, err, list.Version, newListResponse(list))
+	}
+
+	before := models.NewListSnapshot(list)
+	list.Name = lr.Name
+	err = listsOf(ctx, lc.lr).Update(list)
+	if err == repositories.ErrVersionConflict {
+		// it was changed after it was read above
+		if current := listsOf(ctx, lc.lr).ByID(list.ID); current != nil {
+			return versionError(ctx, http.StatusPreconditionFailed, errVersionMismatch, current.Version, newListResponse(current))
+		}
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	if list.Name != before.Name {
+		record(ctx, lc.jr, models.NewListOperation(models.OperationUpdate, list, before, models.NewListSnapshot(list)))
+		audit.Log(ctx, events.ListUpdated, "list", list.ID, before, models.NewListSnapshot(list))
+	}
+	withVersion(ctx, list.Version)
+	return ctx.JSON(http.StatusOK, NewResponseData(newListResponse(list)))
+}
+
+// Destroy moves a list along with its todos to the trash, the version
+// is required as for updating it
+// DELETE /lists/:id
+func (lc *ListController) Destroy(ctx echo.Context) error {
+	list, err := lc.findList(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if code, err := checkVersion(ctx, list.Version, bodyVersion(ctx)); err != nil {
+		return versionError(ctx, code, err, list.Version, newListResponse(list))
+	}
+	if err := listsOf(ctx, lc.lr).Delete(list.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	record(ctx, lc.jr, models.NewListOperation(models.OperationDelete, list, models.NewListSnapshot(list), nil))
+	audit.Log(ctx, events.ListDeleted, "list", list.ID, models.NewListSnapshot(list), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// findList looks up the list from the :id param which the user owns
+func (lc *ListController) findList(ctx echo.Context) (*models.List, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, errListNotFound
+	}
+
+	list := listsOf(ctx, lc.lr).ByID(uint(id))
+	if list == nil || list.UserID != auth.User(ctx).ID {
+		return nil, errListNotFound
+	}
+	return list, nil
+}
+
+// newListResponse is a private function for creating *listResponse
+func newListResponse(l *models.List) *listResponse {
+	return &listResponse{
+		ID:        l.ID,
+		Name:      l.Name,
+		Version:   l.Version,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ListControllerTestSuite struct {
+	suite.Suite
+	lists    *mocks.ListRepository
+	todos    *mocks.TodoRepository
+	comments *mocks.CommentRepository
+	members  *mocks.ListMemberRepository
+	list     *ListController
+	server   *echo.Echo
+}
+
+func (suite *ListControllerTestSuite) SetupTest() {
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Release", Version: 2})
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.comments = &mocks.CommentRepository{}
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+	suite.members = &mocks.ListMemberRepository{}
+	suite.list = NewList(suite.lists, suite.todos, &mocks.JournalRepository{}, suite.comments, suite.members)
+	suite.server = echo.New()
+}
+
+// show shows the list with the If-None-Match header
+func (suite *ListControllerTestSuite) show(etag string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(echo.GET, "/lists/7", nil)
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	context, response := newUserContext(suite.server, request)
+	context.SetParamNames("id")
+	context.SetParamValues("7")
+	suite.NoError(test.Serve(context, suite.list.Show))
+
+	return response
+}
+
+func (suite *ListControllerTestSuite) TestShowETagChangesWithTheTodos() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByList", uint(7)).Return([]models.Todo{{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Ship release"}}).Once()
+	response := suite.show("")
+	etag := response.Header().Get("ETag")
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.NotEmpty(etag)
+		assert.Len(test.GetResponseData(response)["todos"], 1)
+	}
+
+	suite.todos.On("ByList", uint(7)).Return([]models.Todo{{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Ship release"}}).Once()
+	response = suite.show(etag)
+	assert.Equal(http.StatusNotModified, response.Code)
+	assert.Empty(response.Body.String())
+
+	// completing a todo leaves the list's version as it is
+	suite.todos.On("ByList", uint(7)).Return([]models.Todo{{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Ship release", Completed: true}})
+	response = suite.show(etag)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.NotEqual(etag, response.Header().Get("ETag"))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestListControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ListControllerTestSuite))
+}
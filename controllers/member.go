@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// Roles of the users who can see a list
+const (
+	roleOwner  = "owner"
+	roleMember = "member"
+)
+
+var (
+	// errMemberNotFound is returned when the user is not a member of the list
+	errMemberNotFound = apperrors.New(http.StatusNotFound, "member_not_found")
+
+	// errInvalidMember is returned when sharing a list with a user
+	// who does not exist or is not a member of its workspace
+	errInvalidMember = requests.NewFieldError("username", "validation.invalid_selection", nil)
+
+	// errAlreadyMember is returned when sharing a list with a user
+	// who can see it already
+	errAlreadyMember = requests.NewFieldError("username", "validation.list_member", nil)
+)
+
+// MemberController handles the users the lists are shared with
+type MemberController struct {
+	mr    repositories.ListMemberRepository
+	lr    repositories.ListRepository
+	ur    repositories.UserRepository
+	wr    repositories.WorkspaceRepository
+	inbox *notifiers.Inbox
+}
+
+// memberResponse is a private struct for list member response,
+// the owner of the list is listed first
+type memberResponse struct {
+	ID       uint       `json:"id"`
+	Username string     `json:"username"`
+	Name     string     `json:"name"`
+	Role     string     `json:"role"`
+	JoinedAt *time.Time `json:"joined_at,omitempty"`
+}
+
+// NewMember creates MemberController instance which notifies the
+// users the lists are shared with through the inbox
+func NewMember(mr repositories.ListMemberRepository, lr repositories.ListRepository, ur repositories.UserRepository, wr repositories.WorkspaceRepository, inbox *notifiers.Inbox) *MemberController {
+	return &MemberController{mr, lr, ur, wr, inbox}
+}
+
+// Index lists the users who can see a list, its members can too
+// GET /lists/:id/members
+func (mc *MemberController) Index(ctx echo.Context) error {
+	list := findVisibleList(ctx, mc.lr, mc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+
+	members := mc.mr.ByList(list.ID)
+	res := make([]*memberResponse, 0, len(members)+1)
+	if owner := mc.ur.ByID(list.UserID); owner != nil {
+		res = append(res, &memberResponse{ID: owner.ID, Username: owner.Username, Name: owner.Name, Role: roleOwner})
+	}
+	for i := range members {
+		res = append(res, newMemberResponse(&members[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store shares a list with a user of its workspace, only its owner can
+// POST /lists/:id/members
+func (mc *MemberController) Store(ctx echo.Context) error {
+	list := findVisibleList(ctx, mc.lr, mc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+	if list.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	mr := new(requests.MemberRequest)
+	if code, err := mr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	user := mc.ur.ByUsername(mr.Username)
+	if user == nil || mc.wr.Membership(list.WorkspaceID, user.ID) == nil {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidMember)
+	}
+	if isListMember(mc.mr, list, user.ID) {
+		return apperrors.From(http.StatusUnprocessableEntity, errAlreadyMember)
+	}
+
+	member := &models.ListMember{ListID: list.ID, UserID: user.ID}
+	if err := mc.mr.Create(member); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	member.User = *user
+	audit.Log(ctx, audit.ListMemberAdded, "list", list.ID, nil, map[string]uint{"user_id": user.ID})
+	if err := mc.inbox.SharedWithYou(user, auth.User(ctx), list); err != nil {
+		ctx.Logger().Errorf("members: notifying user %d of a share: %v", user.ID, err)
+	}
+	return ctx.JSON(http.StatusCreated, NewResponseData(newMemberResponse(member)))
+}
+
+// Destroy removes a member from a list, its owner can remove anyone
+// and the members can leave. The todos of the list which were
+// assigned to the member are unassigned.
+// DELETE /lists/:id/members/:user
+func (mc *MemberController) Destroy(ctx echo.Context) error {
+	list := findVisibleList(ctx, mc.lr, mc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("user"), 10, 64)
+	userID := uint(id)
+	if user := auth.User(ctx); list.UserID != user.ID && userID != user.ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	if !mc.mr.IsMember(list.ID, userID) {
+		return apperrors.From(http.StatusNotFound, errMemberNotFound)
+	}
+
+	if err := mc.mr.Delete(list.ID, userID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ListMemberRemoved, "list", list.ID, map[string]uint{"user_id": userID}, nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// findVisibleList looks up the list from the :id param which the
+// user owns or is a member of
+func findVisibleList(ctx echo.Context, lr repositories.ListRepository, mr repositories.ListMemberRepository) *models.List {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	list := listsOf(ctx, lr).ByID(uint(id))
+	if list == nil || !isListMember(mr, list, auth.User(ctx).ID) {
+		return nil
+	}
+	return list
+}
+
+// isListMember determines if the user owns the list or is one of its members
+func isListMember(mr repositories.ListMemberRepository, list *models.List, userID uint) bool {
+	return list.UserID == userID || mr.IsMember(list.ID, userID)
+}
+
+// findVisibleTodo looks up the todo from the :id param which the
+// user owns or is a member of the list of
+func findVisibleTodo(ctx echo.Context, tr repositories.TodoRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository) *models.Todo {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := todosOf(ctx, tr).ByID(uint(id))
+	if todo == nil || !canSeeTodo(lr, mr, todo, auth.User(ctx).ID) {
+		return nil
+	}
+	return todo
+}
+
+// canSeeTodo determines if the user owns the todo or is a member of
+// its list
+func canSeeTodo(lr repositories.ListRepository, mr repositories.ListMemberRepository, todo *models.Todo, userID uint) bool {
+	if todo.UserID == userID {
+		return true
+	}
+	if todo.ListID == nil {
+		return false
+	}
+	list := lr.ByID(*todo.ListID)
+	return list != nil && isListMember(mr, list, userID)
+}
+
+// newMemberResponse is a private function for creating *memberResponse
+func newMemberResponse(m *models.ListMember) *memberResponse {
+	return &memberResponse{
+		ID:       m.UserID,
+		Username: m.User.Username,
+		Name:     m.User.Name,
+		Role:     roleMember,
+		JoinedAt: &m.CreatedAt,
+	}
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MemberControllerTestSuite struct {
+	suite.Suite
+	members *mocks.ListMemberRepository
+	lists   *mocks.ListRepository
+	users   *mocks.UserRepository
+	spaces  *mocks.WorkspaceRepository
+	notes   *mocks.NotificationRepository
+	member  *MemberController
+	server  *echo.Echo
+	alice   *models.User
+	bob     *models.User
+	carol   *models.User
+}
+
+func (suite *MemberControllerTestSuite) SetupTest() {
+	suite.members = &mocks.ListMemberRepository{}
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.users = &mocks.UserRepository{}
+	suite.spaces = &mocks.WorkspaceRepository{}
+	suite.notes = &mocks.NotificationRepository{}
+	suite.notes.On("IsEnabled", mock.Anything, mock.Anything).Return(true)
+	suite.notes.On("Create", mock.AnythingOfType("*models.Notification")).Return(nil)
+	suite.member = NewMember(suite.members, suite.lists, suite.users, suite.spaces, notifiers.NewInbox(suite.notes, time.Hour))
+	suite.server = echo.New()
+
+	suite.alice = &models.User{Model: gorm.Model{ID: 1}, Username: "alice", Name: "Alice"}
+	suite.bob = &models.User{Model: gorm.Model{ID: 2}, Username: "bob", Name: "Bob"}
+	suite.carol = &models.User{Model: gorm.Model{ID: 3}, Username: "carol", Name: "Carol"}
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Team"})
+	suite.members.On("IsMember", uint(7), uint(2)).Return(true)
+	suite.members.On("IsMember", uint(7), mock.Anything).Return(false)
+	suite.users.On("ByID", uint(1)).Return(suite.alice)
+	suite.users.On("ByUsername", "alice").Return(suite.alice)
+	suite.users.On("ByUsername", "bob").Return(suite.bob)
+	suite.users.On("ByUsername", "carol").Return(suite.carol)
+	suite.users.On("ByUsername", "dave").Return(&models.User{Model: gorm.Model{ID: 4}, Username: "dave"})
+	suite.users.On("ByUsername", mock.Anything).Return(nil)
+
+	// dave is not a member of the workspace of the list
+	suite.spaces.On("Membership", uint(0), uint(4)).Return(nil)
+	suite.spaces.On("Membership", uint(0), mock.Anything).Return(&models.Membership{Role: models.WorkspaceMember})
+}
+
+// newContext creates a context on the members of the list authenticated as the user
+func (suite *MemberControllerTestSuite) newContext(user *models.User, method string, body string, member string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/lists/7/members", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id", "user")
+	context.SetParamValues("7", member)
+	auth.SetUser(context, user)
+
+	return context, response
+}
+
+func (suite *MemberControllerTestSuite) TestIndexListsTheOwnerFirst() {
+	assert := assert.New(suite.T())
+
+	suite.members.On("ByList", uint(7)).Return([]models.ListMember{{ListID: 7, UserID: 2, User: *suite.bob}})
+
+	context, response := suite.newContext(suite.bob, echo.GET, "", "")
+	assert.NoError(test.Serve(context, suite.member.Index))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Contains(response.Body.String(), `{"id":1,"username":"alice","name":"Alice","role":"owner"}`)
+		assert.Contains(response.Body.String(), `"id":2,"username":"bob","name":"Bob","role":"member"`)
+	}
+
+	context, response = suite.newContext(suite.carol, echo.GET, "", "")
+	assert.NoError(test.Serve(context, suite.member.Index))
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func (suite *MemberControllerTestSuite) TestStore() {
+	assert := assert.New(suite.T())
+
+	suite.members.On("Create", mock.AnythingOfType("*models.ListMember")).Return(nil)
+
+	context, response := suite.newContext(suite.alice, echo.POST, `{"username":"carol"}`, "")
+	assert.NoError(test.Serve(context, suite.member.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		assert.Equal("carol", test.GetResponseData(response)["username"])
+	}
+	suite.members.AssertCalled(suite.T(), "Create", &models.ListMember{ListID: 7, UserID: 3, User: *suite.carol})
+
+	// carol is notified of the share
+	suite.notes.AssertCalled(suite.T(), "Create", mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == 3 && n.Type == models.NotificationShared && n.Title == `Alice shared "Team" with you`
+	}))
+}
+
+func (suite *MemberControllerTestSuite) TestStoreInvalidMembers() {
+	assert := assert.New(suite.T())
+
+	// the owner and the members can see the list already, and it can
+	// only be shared within its workspace
+	for _, username := range []string{"alice", "bob", "nobody", "dave"} {
+		context, response := suite.newContext(suite.alice, echo.POST, `{"username":"`+username+`"}`, "")
+		assert.NoError(test.Serve(context, suite.member.Store))
+
+		if assert.Equal(http.StatusUnprocessableEntity, response.Code, username) {
+			assert.Contains(test.GetResponseErrors(response), "username")
+		}
+	}
+	suite.members.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *MemberControllerTestSuite) TestStoreByMember() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(suite.bob, echo.POST, `{"username":"carol"}`, "")
+	assert.NoError(test.Serve(context, suite.member.Store))
+
+	assert.Equal(http.StatusForbidden, response.Code)
+}
+
+func (suite *MemberControllerTestSuite) TestDestroy() {
+	assert := assert.New(suite.T())
+
+	suite.members.On("Delete", uint(7), uint(2)).Return(nil)
+
+	// members can leave, and the owner can remove them
+	for _, user := range []*models.User{suite.bob, suite.alice} {
+		context, response := suite.newContext(user, echo.DELETE, "", "2")
+		assert.NoError(test.Serve(context, suite.member.Destroy))
+		assert.Equal(http.StatusNoContent, response.Code)
+	}
+	suite.members.AssertNumberOfCalls(suite.T(), "Delete", 2)
+}
+
+func (suite *MemberControllerTestSuite) TestDestroyOtherMember() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(suite.bob, echo.DELETE, "", "3")
+	assert.NoError(test.Serve(context, suite.member.Destroy))
+
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.members.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestMemberControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(MemberControllerTestSuite))
+}
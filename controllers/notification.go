@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// NotificationController handles the in-app notification inbox
+type NotificationController struct {
+	nr repositories.NotificationRepository
+}
+
+// notificationResponse is a private struct for notification response
+type notificationResponse struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	ActorID   *uint      `json:"actor_id,omitempty"`
+	TodoID    *uint      `json:"todo_id,omitempty"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// notificationsMeta is a private struct for the inbox pagination
+type notificationsMeta struct {
+	paginationMeta
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NewNotification creates NotificationController instance
+func NewNotification(nr repositories.NotificationRepository) *NotificationController {
+	return &NotificationController{nr}
+}
+
+// Index lists the notifications of the user, newest first.
+// Use ?unread=true to only list the unread ones.
+// GET /notifications
+func (nc *NotificationController) Index(ctx echo.Context) error {
+	user := auth.User(ctx)
+	page := requests.NewPagination(ctx)
+	unreadOnly, _ := strconv.ParseBool(ctx.QueryParam("unread"))
+
+	notifications, total := nc.nr.ByUser(user.ID, unreadOnly, page.Offset(), page.PerPage)
+
+	res := make([]*notificationResponse, 0, len(notifications))
+	for i := range notifications {
+		res = append(res, newNotificationResponse(&notifications[i]))
+	}
+	meta := &notificationsMeta{
+		paginationMeta: paginationMeta{Pagination: page, Total: total},
+		UnreadCount:    nc.nr.UnreadCount(user.ID),
+	}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, meta))
+}
+
+// Read marks a notification as read
+// POST /notifications/:id/read
+func (nc *NotificationController) Read(ctx echo.Context) error {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	n := nc.nr.ByID(uint(id))
+	if n == nil || n.UserID != auth.User(ctx).ID {
+		return apperrors.New(http.StatusNotFound, "notification_not_found")
+	}
+
+	if err := nc.nr.MarkRead(n.ID, time.Now()); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.NotificationsRead, "notification", n.ID, nil, nil)
+	if updated := nc.nr.ByID(n.ID); updated != nil {
+		n = updated
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newNotificationResponse(n)))
+}
+
+// ReadAll marks all the notifications of the user as read
+// POST /notifications/read-all
+func (nc *NotificationController) ReadAll(ctx echo.Context) error {
+	count, err := nc.nr.MarkAllRead(auth.User(ctx).ID, time.Now())
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.NotificationsRead, "notification", 0, nil, map[string]int64{"updated": count})
+	return ctx.JSON(http.StatusOK, NewResponseData(map[string]int64{"updated": count}))
+}
+
+// Preferences shows which notification types the user receives
+// GET /notifications/preferences
+func (nc *NotificationController) Preferences(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, NewResponseData(nc.preferences(auth.User(ctx).ID)))
+}
+
+// UpdatePreferences changes which notification types the user receives
+// PUT /notifications/preferences
+func (nc *NotificationController) UpdatePreferences(ctx echo.Context) error {
+	user := auth.User(ctx)
+	nr := new(requests.NotificationPreferencesRequest)
+	if code, err := nr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := nc.preferences(user.ID)
+	for t, enabled := range nr.Preferences {
+		if err := nc.nr.SetPreference(user.ID, t, enabled); err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+	}
+
+	after := nc.preferences(user.ID)
+	audit.Log(ctx, audit.PreferencesUpdated, "user", user.ID, before, after)
+	return ctx.JSON(http.StatusOK, NewResponseData(after))
+}
+
+// preferences returns every notification type with whether it's enabled
+func (nc *NotificationController) preferences(userID uint) map[string]bool {
+	prefs := make(map[string]bool, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		prefs[t] = true
+	}
+	for _, p := range nc.nr.Preferences(userID) {
+		prefs[p.Type] = p.Enabled
+	}
+	return prefs
+}
+
+// newNotificationResponse is a private function for creating *notificationResponse
+func newNotificationResponse(n *models.Notification) *notificationResponse {
+	return &notificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		ActorID:   n.ActorID,
+		TodoID:    n.TodoID,
+		Read:      n.IsRead(),
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
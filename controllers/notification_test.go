@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type NotificationControllerTestSuite struct {
+	suite.Suite
+	repo         *mocks.NotificationRepository
+	notification *NotificationController
+	server       *echo.Echo
+	user         *models.User
+}
+
+func (suite *NotificationControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.NotificationRepository{}
+	suite.notification = NewNotification(suite.repo)
+	suite.server = echo.New()
+	suite.user = &models.User{Model: gorm.Model{ID: 1}, Username: "alice"}
+}
+
+// newContext creates a context authenticated as the suite's user
+func (suite *NotificationControllerTestSuite) newContext(method string, target string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, suite.user)
+
+	return context, response
+}
+
+func (suite *NotificationControllerTestSuite) TestIndex() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.GET, "/notifications?unread=true&page=2&per_page=1", "")
+	suite.repo.On("ByUser", uint(1), true, 1, 1).Return([]models.Notification{
+		{Model: gorm.Model{ID: 5}, UserID: 1, Type: models.NotificationShared, Title: "Bob shared a todo"},
+	}, int64(2))
+	suite.repo.On("UnreadCount", uint(1)).Return(int64(2))
+
+	assert.NoError(test.Serve(context, suite.notification.Index))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		meta := test.GetResponse(response, "meta")
+		assert.Equal(float64(2), meta["page"])
+		assert.Equal(float64(2), meta["total"])
+		assert.Equal(float64(2), meta["unread_count"])
+	}
+}
+
+func (suite *NotificationControllerTestSuite) TestReadNotificationOfAnotherUser() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/notifications/5/read", "")
+	context.SetParamNames("id")
+	context.SetParamValues("5")
+	suite.repo.On("ByID", uint(5)).Return(&models.Notification{Model: gorm.Model{ID: 5}, UserID: 2})
+
+	assert.NoError(test.Serve(context, suite.notification.Read))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.repo.AssertNotCalled(suite.T(), "MarkRead", mock.Anything, mock.Anything)
+}
+
+func (suite *NotificationControllerTestSuite) TestUpdatePreferencesValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.PUT, "/notifications/preferences", `{
+		"preferences": {"birthday": false}
+	}`)
+
+	assert.NoError(test.Serve(context, suite.notification.UpdatePreferences))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.NotEmpty(err["preferences"])
+	}
+}
+
+func (suite *NotificationControllerTestSuite) TestUpdatePreferences() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.PUT, "/notifications/preferences", `{
+		"preferences": {"shared": false}
+	}`)
+	suite.repo.On("SetPreference", uint(1), models.NotificationShared, false).Return(nil)
+	suite.repo.On("Preferences", uint(1)).Return([]models.NotificationPreference{
+		{UserID: 1, Type: models.NotificationShared, Enabled: false},
+	})
+
+	assert.NoError(test.Serve(context, suite.notification.UpdatePreferences))
+
+	suite.repo.AssertCalled(suite.T(), "SetPreference", uint(1), models.NotificationShared, false)
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal(false, data["shared"])
+		assert.Equal(true, data["assigned"])
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestNotificationControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationControllerTestSuite))
+}
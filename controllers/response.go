@@ -1,8 +1,16 @@
 package controllers
 
+import (
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
 // ResponseData is a struct for response with data.
 type ResponseData struct {
 	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
 }
 
 // NewResponseData creates a response with "data" as parent node.
@@ -10,3 +18,27 @@ func NewResponseData(data interface{}) ResponseData {
 	r := ResponseData{Data: data}
 	return r
 }
+
+// NewResponseDataWithMeta creates a response with "data" and "meta" as
+// parent nodes, meta is usually used to describe the pagination.
+func NewResponseDataWithMeta(data interface{}, meta interface{}) ResponseData {
+	r := ResponseData{Data: data, Meta: meta}
+	return r
+}
+
+// paginationMeta is a private struct for the meta of paginated responses
+type paginationMeta struct {
+	requests.Pagination
+	Total int64 `json:"total"`
+}
+
+// itemErrors are the errors of an item of a response made of many, in
+// the language of the request and keeping the causes of the errors of
+// the server from the clients, like problems do
+func itemErrors(ctx echo.Context, code int, err error) interface{} {
+	message, fields := apperrors.From(code, err).Localize(i18n.Locale(ctx))
+	if fields != nil {
+		return fields
+	}
+	return map[string]string{"message": message}
+}
@@ -0,0 +1,481 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// maxSyncChanges is the most logged changes a sync returns, the client
+// syncs again for the next ones
+const maxSyncChanges = 500
+
+// Statuses of the changes a client pushed
+const (
+	syncCreated    = "created"
+	syncUpdated    = "updated"
+	syncDeleted    = "deleted"
+	syncUnchanged  = "unchanged"
+	syncConflicted = "conflict"
+	syncFailed     = "failed"
+)
+
+// errRecordNotFound is returned when a client changes a record which
+// does not exist or belongs to another user
+var errRecordNotFound = apperrors.New(http.StatusNotFound, "record_not_found")
+
+// SyncController syncs the todos and the lists of offline clients
+type SyncController struct {
+	cr repositories.ChangeRepository
+	tr repositories.TodoRepository
+	lr repositories.ListRepository
+	jr repositories.JournalRepository
+}
+
+// syncResponse is a private struct for the response of a sync, the
+// results of the pushed changes and the changes logged since the token
+type syncResponse struct {
+	Results []*syncResult `json:"results"`
+	Changes []*syncChange `json:"changes"`
+}
+
+// syncMeta is a private struct for the token to sync from next time,
+// More tells there are more changes to sync right away
+type syncMeta struct {
+	Token string `json:"token"`
+	More  bool   `json:"more"`
+}
+
+// syncResult is a private struct for the result of a pushed change.
+// Applied are the fields which were written, and Conflicts the ones
+// which were changed later on the server and kept their value. A
+// failed change has the status code it would have had on its own.
+type syncResult struct {
+	Index     int             `json:"index"`
+	Resource  string          `json:"resource"`
+	ID        uint            `json:"id,omitempty"`
+	ClientID  string          `json:"client_id,omitempty"`
+	Status    string          `json:"status"`
+	Code      int             `json:"code,omitempty"`
+	Applied   []string        `json:"applied,omitempty"`
+	Conflicts []*syncConflict `json:"conflicts,omitempty"`
+	Errors    interface{}     `json:"errors,omitempty"`
+}
+
+// syncConflict is a private struct for a field of a pushed change
+// which lost to a later change, along with the value it has and when
+// it was changed. A record deleted on the server has a deleted field.
+type syncConflict struct {
+	Field     string      `json:"field"`
+	Value     interface{} `json:"value"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// syncChange is a private struct for a logged change, a deleted one
+// being the tombstone of its record. The tombstone of a list stands
+// for the todos in it as well.
+type syncChange struct {
+	Seq       uint64               `json:"seq"`
+	Resource  string               `json:"resource"`
+	ID        uint                 `json:"id"`
+	Deleted   bool                 `json:"deleted"`
+	Data      json.RawMessage      `json:"data,omitempty"`
+	UpdatedAt map[string]time.Time `json:"updated_at"`
+}
+
+// NewSync creates SyncController instance which records the changes
+// it makes in the user's journal
+func NewSync(cr repositories.ChangeRepository, tr repositories.TodoRepository, lr repositories.ListRepository, jr repositories.JournalRepository) *SyncController {
+	return &SyncController{cr, tr, lr, jr}
+}
+
+// Sync applies the changes an offline client made and returns the
+// ones logged since its token, its own included. Every field of a
+// change is applied unless it was changed later on the server, and
+// a record is only deleted when none of its fields were. Every
+// change succeeds or fails on its own, its result is in the response.
+// POST /sync
+func (sc *SyncController) Sync(ctx echo.Context) error {
+	sr := new(requests.SyncRequest)
+	if code, err := sr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	res := &syncResponse{
+		Results: make([]*syncResult, len(sr.Changes)),
+		Changes: make([]*syncChange, 0),
+	}
+	for i := range sr.Changes {
+		change := &sr.Changes[i]
+		result := &syncResult{Index: i, Resource: change.Resource, ClientID: change.ClientID}
+		if change.Resource == models.ChangeTodo {
+			sc.syncTodo(ctx, change, result)
+		} else {
+			sc.syncList(ctx, change, result)
+		}
+		res.Results[i] = result
+	}
+
+	seq := sr.Seq()
+	logged := sc.cr.Since(auth.User(ctx).ID, auth.WorkspaceID(ctx), seq, maxSyncChanges+1)
+	meta := &syncMeta{More: len(logged) > maxSyncChanges}
+	if meta.More {
+		logged = logged[:maxSyncChanges]
+	}
+	if len(logged) > 0 {
+		seq = logged[len(logged)-1].Seq
+	}
+	meta.Token = strconv.FormatUint(seq, 10)
+	res.Changes = newSyncChanges(logged)
+
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, meta))
+}
+
+// syncTodo applies the change to the todo it names, or creates it
+func (sc *SyncController) syncTodo(ctx echo.Context, change *requests.SyncChange, result *syncResult) {
+	user := auth.User(ctx)
+	todos := todosOf(ctx, sc.tr)
+
+	var todo *models.Todo
+	if id := sc.recordID(ctx, change); id != 0 {
+		result.ID = id
+		if todo = todos.ByID(id); todo == nil || todo.UserID != user.ID {
+			sc.notFound(ctx, change, result)
+			return
+		}
+	}
+	if change.DeletedAt != nil {
+		if todo == nil {
+			result.Status = syncUnchanged
+			return
+		}
+		if !sc.deletable(ctx, change, result, currentFields(requests.NewTodoRequest(todo))) {
+			return
+		}
+		mark := sc.cr.LastID()
+		if err := todos.Delete(todo.ID); err != nil {
+			syncError(ctx, result, http.StatusInternalServerError, err)
+			return
+		}
+		sc.stamp(ctx, change.Resource, todo.ID, mark, map[string]time.Time{models.ChangeDeleted: *change.DeletedAt})
+		record(ctx, sc.jr, models.NewTodoOperation(models.OperationDelete, todo, models.NewTodoSnapshot(todo), nil))
+		audit.Log(ctx, events.TodoDeleted, "todo", todo.ID, models.NewTodoSnapshot(todo), nil)
+		result.Status = syncDeleted
+		return
+	}
+
+	if clientID, ok := change.ListClientID(); ok {
+		r := sc.cr.ClientRecord(user.ID, models.ChangeList, clientID)
+		if r == nil {
+			syncError(ctx, result, http.StatusUnprocessableEntity, errInvalidList)
+			return
+		}
+		change.SetListID(r.ResourceID)
+	}
+
+	var current map[string]interface{}
+	if todo != nil {
+		current = currentFields(requests.NewTodoRequest(todo))
+	}
+	fields := sc.resolve(ctx, change, result, current)
+	if len(fields) == 0 {
+		return
+	}
+	tr, code, err := change.Todo(todo, fields)
+	if err != nil {
+		syncError(ctx, result, code, err)
+		return
+	}
+	if tr.ListID != nil {
+		list := listsOf(ctx, sc.lr).ByID(*tr.ListID)
+		if list == nil || list.UserID != user.ID {
+			syncError(ctx, result, http.StatusUnprocessableEntity, errInvalidList)
+			return
+		}
+	}
+
+	mark := sc.cr.LastID()
+	if todo == nil {
+		todo = tr.TodoModel(user.ID)
+		if claim := sc.clientRecord(ctx, change); claim != nil {
+			err = todos.CreateClaimed(todo, claim)
+		} else {
+			err = todos.Create(todo)
+		}
+		if err != nil {
+			if !sc.claimed(ctx, change, result) {
+				syncError(ctx, result, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		record(ctx, sc.jr, models.NewTodoOperation(models.OperationCreate, todo, nil, models.NewTodoSnapshot(todo)))
+		audit.Log(ctx, events.TodoCreated, "todo", todo.ID, nil, models.NewTodoSnapshot(todo))
+		result.Status = syncCreated
+	} else {
+		before := models.NewTodoSnapshot(todo)
+		tr.Fill(todo)
+		if err := todos.Update(todo); err != nil {
+			syncError(ctx, result, http.StatusInternalServerError, err)
+			return
+		}
+		if after := models.NewTodoSnapshot(todo); !after.Equal(before) {
+			record(ctx, sc.jr, models.NewTodoOperation(todoChange(before, after), todo, before, after))
+			audit.Log(ctx, events.TodoUpdated, "todo", todo.ID, before, after)
+		}
+		if result.Status == "" {
+			result.Status = syncUpdated
+		}
+	}
+	result.ID = todo.ID
+	result.Applied = fields
+	sc.stamp(ctx, change.Resource, todo.ID, mark, change.UpdatedAt)
+}
+
+// syncList applies the change to the list it names, or creates it
+func (sc *SyncController) syncList(ctx echo.Context, change *requests.SyncChange, result *syncResult) {
+	user := auth.User(ctx)
+	lists := listsOf(ctx, sc.lr)
+
+	var list *models.List
+	if id := sc.recordID(ctx, change); id != 0 {
+		result.ID = id
+		if list = lists.ByID(id); list == nil || list.UserID != user.ID {
+			sc.notFound(ctx, change, result)
+			return
+		}
+	}
+	if change.DeletedAt != nil {
+		if list == nil {
+			result.Status = syncUnchanged
+			return
+		}
+		if !sc.deletable(ctx, change, result, map[string]interface{}{"name": list.Name}) {
+			return
+		}
+		mark := sc.cr.LastID()
+		if err := lists.Delete(list.ID); err != nil {
+			syncError(ctx, result, http.StatusInternalServerError, err)
+			return
+		}
+		sc.stamp(ctx, change.Resource, list.ID, mark, map[string]time.Time{models.ChangeDeleted: *change.DeletedAt})
+		record(ctx, sc.jr, models.NewListOperation(models.OperationDelete, list, models.NewListSnapshot(list), nil))
+		audit.Log(ctx, events.ListDeleted, "list", list.ID, models.NewListSnapshot(list), nil)
+		result.Status = syncDeleted
+		return
+	}
+
+	var current map[string]interface{}
+	if list != nil {
+		current = map[string]interface{}{"name": list.Name}
+	}
+	fields := sc.resolve(ctx, change, result, current)
+	if len(fields) == 0 {
+		return
+	}
+	lr, code, err := change.List(list, fields)
+	if err != nil {
+		syncError(ctx, result, code, err)
+		return
+	}
+
+	mark := sc.cr.LastID()
+	if list == nil {
+		list = lr.ListModel(user.ID)
+		if claim := sc.clientRecord(ctx, change); claim != nil {
+			err = lists.CreateClaimed(list, claim)
+		} else {
+			err = lists.Create(list)
+		}
+		if err != nil {
+			if !sc.claimed(ctx, change, result) {
+				syncError(ctx, result, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		record(ctx, sc.jr, models.NewListOperation(models.OperationCreate, list, nil, models.NewListSnapshot(list)))
+		audit.Log(ctx, events.ListCreated, "list", list.ID, nil, models.NewListSnapshot(list))
+		result.Status = syncCreated
+	} else {
+		before := models.NewListSnapshot(list)
+		list.Name = lr.Name
+		if err := lists.Update(list); err != nil {
+			syncError(ctx, result, http.StatusInternalServerError, err)
+			return
+		}
+		if list.Name != before.Name {
+			record(ctx, sc.jr, models.NewListOperation(models.OperationUpdate, list, before, models.NewListSnapshot(list)))
+			audit.Log(ctx, events.ListUpdated, "list", list.ID, before, models.NewListSnapshot(list))
+		}
+		if result.Status == "" {
+			result.Status = syncUpdated
+		}
+	}
+	result.ID = list.ID
+	result.Applied = fields
+	sc.stamp(ctx, change.Resource, list.ID, mark, change.UpdatedAt)
+}
+
+// recordID is the ID of the record the change names, the one its
+// client ID was given to if it has none. It is 0 for a new record.
+func (sc *SyncController) recordID(ctx echo.Context, change *requests.SyncChange) uint {
+	if change.ID != 0 {
+		return change.ID
+	}
+	if r := sc.cr.ClientRecord(auth.User(ctx).ID, change.Resource, change.ClientID); r != nil {
+		return r.ResourceID
+	}
+	return 0
+}
+
+// notFound fails the change to a record which is not found, it is a
+// conflict when the record was deleted on the server
+func (sc *SyncController) notFound(ctx echo.Context, change *requests.SyncChange, result *syncResult) {
+	clock := sc.cr.Clock(auth.User(ctx).ID, change.Resource, result.ID)
+	if at, ok := clock[models.ChangeDeleted]; ok {
+		result.Status = syncConflicted
+		result.Conflicts = []*syncConflict{{Field: models.ChangeDeleted, Value: true, UpdatedAt: at}}
+		return
+	}
+	syncError(ctx, result, http.StatusNotFound, errRecordNotFound)
+}
+
+// resolve returns the fields of the change which were changed after
+// their current value, the fields of a new record all are. The other
+// ones are the conflicts of the result.
+func (sc *SyncController) resolve(ctx echo.Context, change *requests.SyncChange, result *syncResult, current map[string]interface{}) []string {
+	var clock map[string]time.Time
+	if current != nil {
+		clock = sc.cr.Clock(auth.User(ctx).ID, change.Resource, result.ID)
+	}
+
+	fields := make([]string, 0, len(change.Fields))
+	for _, field := range requests.SyncFields[change.Resource] {
+		if _, ok := change.Fields[field]; !ok {
+			continue
+		}
+		if at, ok := clock[field]; ok && !change.UpdatedAt[field].After(at) {
+			result.Conflicts = append(result.Conflicts, &syncConflict{Field: field, Value: current[field], UpdatedAt: at})
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(result.Conflicts) > 0 {
+		result.Status = syncConflicted
+	}
+	return fields
+}
+
+// deletable determines if the record of the change can be deleted,
+// which it can't when some of its fields were changed on the server
+// after the client deleted it. They are the conflicts of the result.
+func (sc *SyncController) deletable(ctx echo.Context, change *requests.SyncChange, result *syncResult, current map[string]interface{}) bool {
+	clock := sc.cr.Clock(auth.User(ctx).ID, change.Resource, result.ID)
+	for _, field := range requests.SyncFields[change.Resource] {
+		if at, ok := clock[field]; ok && !change.DeletedAt.After(at) {
+			result.Conflicts = append(result.Conflicts, &syncConflict{Field: field, Value: current[field], UpdatedAt: at})
+		}
+	}
+	if len(result.Conflicts) > 0 {
+		result.Status = syncConflicted
+		return false
+	}
+	return true
+}
+
+// clientRecord is the record of the client ID of the change, which is
+// claimed along with the record the change creates. It is nil when the
+// change has no client ID.
+func (sc *SyncController) clientRecord(ctx echo.Context, change *requests.SyncChange) *models.ClientRecord {
+	if change.ClientID == "" {
+		return nil
+	}
+	return &models.ClientRecord{UserID: auth.User(ctx).ID, Resource: change.Resource, ClientID: change.ClientID}
+}
+
+// claimed determines if the client ID of the change was claimed by a
+// concurrent sync, e.g. a retry of the same one, while creating its
+// record. The result is the record created by it then.
+func (sc *SyncController) claimed(ctx echo.Context, change *requests.SyncChange, result *syncResult) bool {
+	if change.ClientID == "" {
+		return false
+	}
+	r := sc.cr.ClientRecord(auth.User(ctx).ID, change.Resource, change.ClientID)
+	if r == nil {
+		return false
+	}
+	result.ID = r.ResourceID
+	result.Status = syncUnchanged
+	return true
+}
+
+// stamp sets the times the client changed the fields at on the changes
+// logged for the record after the mark
+func (sc *SyncController) stamp(ctx echo.Context, resource string, id uint, mark uint, times map[string]time.Time) {
+	if err := sc.cr.Stamp(resource, id, mark, times); err != nil {
+		ctx.Logger().Errorf("sync: stamping %s %d: %v", resource, id, err)
+	}
+}
+
+// syncError fails the result with the error of the status code
+func syncError(ctx echo.Context, result *syncResult, code int, err error) {
+	result.Status = syncFailed
+	result.Code = code
+	result.Conflicts = nil
+	result.Errors = itemErrors(ctx, code, err)
+}
+
+// currentFields is a private function decoding the request of a record
+// as it is into its fields
+func currentFields(request interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	b, _ := json.Marshal(request)
+	json.Unmarshal(b, &fields)
+	return fields
+}
+
+// newSyncChanges is a private function creating the *syncChange of the
+// logged changes, the ones of the same record are folded into the last
+// one of them
+func newSyncChanges(logged []models.Change) []*syncChange {
+	type key struct {
+		resource string
+		id       uint
+	}
+	last := make(map[key]int, len(logged))
+	for i := range logged {
+		last[key{logged[i].Resource, logged[i].ResourceID}] = i
+	}
+
+	changes := make([]*syncChange, 0, len(last))
+	clocks := make(map[key]map[string]time.Time, len(last))
+	for i := range logged {
+		c := &logged[i]
+		k := key{c.Resource, c.ResourceID}
+		if clocks[k] == nil {
+			clocks[k] = make(map[string]time.Time)
+		}
+		for field, at := range c.Times() {
+			clocks[k][field] = at
+		}
+		if last[k] != i {
+			continue
+		}
+
+		change := &syncChange{Seq: c.Seq, Resource: c.Resource, ID: c.ResourceID, Deleted: c.Deleted, UpdatedAt: clocks[k]}
+		if c.Data != "" {
+			change.Data = json.RawMessage(c.Data)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type SyncControllerTestSuite struct {
+	suite.Suite
+	changes *mocks.ChangeRepository
+	todos   *mocks.TodoRepository
+	lists   *mocks.ListRepository
+	journal *mocks.JournalRepository
+	sync    *SyncController
+	server  *echo.Echo
+}
+
+// syncBody is the body of a sync response
+type syncBody struct {
+	Data syncResponse `json:"data"`
+	Meta syncMeta     `json:"meta"`
+}
+
+func (suite *SyncControllerTestSuite) SetupTest() {
+	suite.changes = &mocks.ChangeRepository{}
+	suite.changes.On("LastID").Return(uint(10))
+	suite.changes.On("Stamp", mock.Anything, mock.Anything, uint(10), mock.Anything).Return(nil)
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.journal = &mocks.JournalRepository{}
+	suite.journal.On("Record", mock.AnythingOfType("*models.Operation")).Return(nil)
+	suite.sync = NewSync(suite.changes, suite.todos, suite.lists, suite.journal)
+	suite.server = echo.New()
+}
+
+// newContext creates a context with the body authenticated as the
+// first user
+func (suite *SyncControllerTestSuite) newContext(body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(echo.POST, "/sync", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}})
+
+	return context, response
+}
+
+// decode reads the sync response
+func (suite *SyncControllerTestSuite) decode(response *httptest.ResponseRecorder) syncBody {
+	var body syncBody
+	json.Unmarshal(response.Body.Bytes(), &body)
+
+	return body
+}
+
+func (suite *SyncControllerTestSuite) TestSyncValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(`{"token": "abc", "changes": []}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "token")
+	}
+
+	context, response = suite.newContext(`{"changes": [
+		{"resource": "reminder", "id": 1, "fields": {"title": "Ship it"}, "updated_at": {"title": "2026-01-02T09:00:00Z"}},
+		{"resource": "todo", "fields": {"title": "Ship it", "column_id": 2}, "updated_at": {"title": "2026-01-02T09:00:00Z"}},
+		{"resource": "list", "id": 3}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errs := test.GetResponseErrors(response)
+		assert.Contains(errs, "changes.0.resource")
+		assert.Contains(errs, "changes.1.id")
+		assert.Contains(errs, "changes.1.fields.column_id")
+		assert.Contains(errs, "changes.1.updated_at.column_id")
+		assert.Contains(errs, "changes.2.fields")
+	}
+	suite.changes.AssertNotCalled(suite.T(), "Since", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *SyncControllerTestSuite) TestSyncCreatesTheRecordsOfClients() {
+	assert := assert.New(suite.T())
+
+	suite.changes.On("ClientRecord", uint(1), models.ChangeList, "l-1").Return(nil).Once()
+	suite.changes.On("ClientRecord", uint(1), models.ChangeList, "l-1").Return(&models.ClientRecord{ResourceID: 3})
+	suite.changes.On("ClientRecord", uint(1), models.ChangeTodo, "t-1").Return(nil)
+	suite.lists.On("CreateClaimed", mock.AnythingOfType("*models.List"), mock.AnythingOfType("*models.ClientRecord")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.List).ID = 3
+	})
+	suite.lists.On("ByID", uint(3)).Return(&models.List{Model: gorm.Model{ID: 3}, UserID: 1, Name: "Groceries"})
+	suite.todos.On("CreateClaimed", mock.AnythingOfType("*models.Todo"), mock.AnythingOfType("*models.ClientRecord")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Todo).ID = 4
+	})
+	suite.changes.On("Since", uint(1), uint(0), uint64(7), maxSyncChanges+1).Return([]models.Change{
+		{Seq: 8, Resource: models.ChangeList, ResourceID: 3, Data: `{"id": 3, "name": "Groceries"}`, Clock: `{"name": "2026-01-02T09:00:00Z"}`},
+		{Seq: 9, Resource: models.ChangeTodo, ResourceID: 4, Data: `{"id": 4, "title": "Milk"}`, Clock: `{"title": "2026-01-02T09:01:00Z"}`},
+		{Seq: 10, Resource: models.ChangeTodo, ResourceID: 4, Data: `{"id": 4, "title": "Oat milk"}`, Clock: `{"title": "2026-01-02T09:02:00Z"}`},
+		{Seq: 11, Resource: models.ChangeTodo, ResourceID: 2, Deleted: true, Clock: `{"deleted": "2026-01-02T08:00:00Z"}`},
+	})
+
+	context, response := suite.newContext(`{"token": "7", "changes": [
+		{"resource": "list", "client_id": "l-1", "fields": {"name": "Groceries"}, "updated_at": {"name": "2026-01-02T09:00:00Z"}},
+		{"resource": "todo", "client_id": "t-1", "fields": {"title": "Oat milk", "list_id": "l-1", "estimate": null},
+			"updated_at": {"title": "2026-01-02T09:02:00Z", "list_id": "2026-01-02T09:01:00Z", "estimate": "2026-01-02T09:01:00Z"}}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	body := suite.decode(response)
+	if assert.Len(body.Data.Results, 2) {
+		assert.Equal(syncCreated, body.Data.Results[0].Status)
+		assert.Equal(uint(3), body.Data.Results[0].ID)
+		assert.Equal(syncCreated, body.Data.Results[1].Status)
+		assert.Equal(uint(4), body.Data.Results[1].ID)
+		assert.Equal("t-1", body.Data.Results[1].ClientID)
+	}
+	todo := suite.todos.Calls[1].Arguments.Get(0).(*models.Todo)
+	assert.Equal("Oat milk", todo.Title)
+	assert.Equal(uint(3), *todo.ListID)
+	assert.Equal(&models.ClientRecord{UserID: 1, Resource: models.ChangeTodo, ClientID: "t-1"}, suite.todos.Calls[1].Arguments.Get(1))
+
+	// the changes of the same record are folded into the last one
+	if assert.Len(body.Data.Changes, 3) {
+		assert.Equal(uint64(8), body.Data.Changes[0].Seq)
+		assert.Equal(uint64(10), body.Data.Changes[1].Seq)
+		assert.JSONEq(`{"id": 4, "title": "Oat milk"}`, string(body.Data.Changes[1].Data))
+		assert.True(body.Data.Changes[2].Deleted)
+		assert.Empty(body.Data.Changes[2].Data)
+	}
+	assert.Equal(syncMeta{Token: "11", More: false}, body.Meta)
+}
+
+func (suite *SyncControllerTestSuite) TestSyncReportsTheRecordOfAConcurrentRetry() {
+	assert := assert.New(suite.T())
+
+	// a retry of the same sync created the todo in the meantime
+	suite.changes.On("ClientRecord", uint(1), models.ChangeTodo, "t-1").Return(nil).Once()
+	suite.changes.On("ClientRecord", uint(1), models.ChangeTodo, "t-1").Return(&models.ClientRecord{ResourceID: 4})
+	suite.todos.On("CreateClaimed", mock.AnythingOfType("*models.Todo"), mock.AnythingOfType("*models.ClientRecord")).
+		Return(repositories.ErrClientIDClaimed)
+	suite.changes.On("Since", uint(1), uint(0), uint64(0), maxSyncChanges+1).Return([]models.Change{})
+
+	context, response := suite.newContext(`{"changes": [
+		{"resource": "todo", "client_id": "t-1", "fields": {"title": "Milk"}, "updated_at": {"title": "2026-01-02T09:00:00Z"}}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if body := suite.decode(response); assert.Len(body.Data.Results, 1) {
+		assert.Equal(syncUnchanged, body.Data.Results[0].Status)
+		assert.Equal(uint(4), body.Data.Results[0].ID)
+	}
+	suite.journal.AssertNotCalled(suite.T(), "Record", mock.Anything)
+}
+
+func (suite *SyncControllerTestSuite) TestSyncResolvesConflictsPerField() {
+	assert := assert.New(suite.T())
+
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 3})
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+	suite.changes.On("Clock", uint(1), models.ChangeTodo, uint(2)).Return(map[string]time.Time{"title": at, "completed": at})
+	suite.changes.On("Since", uint(1), uint(0), uint64(0), maxSyncChanges+1).Return([]models.Change{})
+
+	context, response := suite.newContext(`{"changes": [
+		{"resource": "todo", "id": 2, "fields": {"title": "Ship it", "completed": true, "description": "v2"},
+			"updated_at": {"title": "2026-01-02T08:59:00Z", "completed": "2026-01-02T09:01:00Z", "description": "2026-01-02T08:00:00Z"}}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	body := suite.decode(response)
+	if assert.Len(body.Data.Results, 1) {
+		result := body.Data.Results[0]
+		assert.Equal(syncConflicted, result.Status)
+		// the description was never changed on the server
+		assert.Equal([]string{"description", "completed"}, result.Applied)
+		if assert.Len(result.Conflicts, 1) {
+			assert.Equal("title", result.Conflicts[0].Field)
+			assert.Equal("Ship release", result.Conflicts[0].Value)
+			assert.True(at.Equal(result.Conflicts[0].UpdatedAt))
+		}
+	}
+	assert.Equal(syncMeta{Token: "0"}, body.Meta)
+
+	var updated *models.Todo
+	for _, call := range suite.todos.Calls {
+		if call.Method == "Update" {
+			updated = call.Arguments.Get(0).(*models.Todo)
+		}
+	}
+	if assert.NotNil(updated) {
+		assert.Equal("Ship release", updated.Title)
+		assert.Equal("v2", updated.Description)
+		assert.True(updated.Completed)
+	}
+	suite.changes.AssertCalled(suite.T(), "Stamp", models.ChangeTodo, uint(2), uint(10), mock.Anything)
+}
+
+func (suite *SyncControllerTestSuite) TestSyncDeletes() {
+	assert := assert.New(suite.T())
+
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release"})
+	suite.todos.On("ByID", uint(5)).Return(nil)
+	suite.todos.On("Delete", uint(2)).Return(nil)
+	suite.changes.On("Clock", uint(1), models.ChangeTodo, uint(2)).Return(map[string]time.Time{"title": at})
+	suite.changes.On("Clock", uint(1), models.ChangeTodo, uint(5)).Return(map[string]time.Time{models.ChangeDeleted: at})
+	suite.changes.On("Since", uint(1), uint(0), uint64(0), maxSyncChanges+1).Return([]models.Change{})
+
+	// the todo was changed on the server after the client deleted it
+	context, response := suite.newContext(`{"changes": [
+		{"resource": "todo", "id": 2, "deleted_at": "2026-01-02T08:00:00Z"}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if body := suite.decode(response); assert.Len(body.Data.Results, 1) {
+		assert.Equal(syncConflicted, body.Data.Results[0].Status)
+		assert.Equal("title", body.Data.Results[0].Conflicts[0].Field)
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Delete", mock.Anything)
+
+	context, response = suite.newContext(`{"changes": [
+		{"resource": "todo", "id": 2, "deleted_at": "2026-01-02T10:00:00Z"},
+		{"resource": "todo", "id": 5, "fields": {"title": "Ship it"}, "updated_at": {"title": "2026-01-02T10:00:00Z"}}
+	]}`)
+	assert.NoError(test.Serve(context, suite.sync.Sync))
+
+	if body := suite.decode(response); assert.Len(body.Data.Results, 2) {
+		assert.Equal(syncDeleted, body.Data.Results[0].Status)
+		// the other one was deleted on the server
+		assert.Equal(syncConflicted, body.Data.Results[1].Status)
+		assert.Equal(models.ChangeDeleted, body.Data.Results[1].Conflicts[0].Field)
+	}
+	suite.todos.AssertCalled(suite.T(), "Delete", uint(2))
+	suite.changes.AssertCalled(suite.T(), "Stamp", models.ChangeTodo, uint(2), uint(10),
+		map[string]time.Time{models.ChangeDeleted: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)})
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestSyncControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(SyncControllerTestSuite))
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// TagController handles the tags of the todos
+type TagController struct {
+	tr repositories.TodoRepository
+	lr repositories.ListRepository
+	mr repositories.ListMemberRepository
+	cr repositories.CommentRepository
+}
+
+// NewTag creates TagController instance
+func NewTag(tr repositories.TodoRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, cr repositories.CommentRepository) *TagController {
+	return &TagController{tr, lr, mr, cr}
+}
+
+// Update replaces the tags of a todo, their names are trimmed and
+// lowercase. Only the owner of the todo can, the members can see it.
+// PUT /todos/:id/tags
+func (tc *TagController) Update(ctx echo.Context) error {
+	todo := findVisibleTodo(ctx, tc.tr, tc.lr, tc.mr)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+	if todo.UserID != auth.User(ctx).ID {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	tr := new(requests.TagRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := todo.TagNames()
+	version := todo.Version
+	if err := todosOf(ctx, tc.tr).Tag(todo, tr.Names()); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	if todo.Version != version {
+		audit.Log(ctx, events.TodoUpdated, "todo", todo.ID,
+			map[string][]string{"tags": before}, map[string][]string{"tags": todo.TagNames()})
+	}
+
+	res := newTodoResponse(todo)
+	withCommentCounts(tc.cr, []*todoResponse{res})
+	withVersion(ctx, todo.Version)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TagControllerTestSuite struct {
+	suite.Suite
+	todos   *mocks.TodoRepository
+	lists   *mocks.ListRepository
+	members *mocks.ListMemberRepository
+	tag     *TagController
+	server  *echo.Echo
+	todo    *models.Todo
+}
+
+func (suite *TagControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.members = &mocks.ListMemberRepository{}
+	comments := &mocks.CommentRepository{}
+	comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+	suite.tag = NewTag(suite.todos, suite.lists, suite.members, comments)
+	suite.server = echo.New()
+
+	listID := uint(7)
+	suite.todo = &models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, ListID: &listID, Title: "Ship release", Version: 2}
+	suite.todos.On("ByID", uint(3)).Return(suite.todo)
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Team"})
+	suite.members.On("IsMember", uint(7), uint(2)).Return(true)
+}
+
+// newContext creates a context on the tags of the todo authenticated as the user
+func (suite *TagControllerTestSuite) newContext(userID uint, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(echo.PUT, "/todos/3/tags", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	context.SetParamNames("id")
+	context.SetParamValues("3")
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: userID}})
+
+	return context, response
+}
+
+func (suite *TagControllerTestSuite) TestUpdate() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("Tag", suite.todo, []string{"billable", "release"}).Return(nil).Run(func(args mock.Arguments) {
+		suite.todo.Tags = []models.TodoTag{{Name: "billable"}, {Name: "release"}}
+		suite.todo.Version++
+	})
+
+	// the names are trimmed and lowercase, once each
+	context, response := suite.newContext(1, `{"tags":[" Billable","release","billable"]}`)
+	assert.NoError(test.Serve(context, suite.tag.Update))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal([]interface{}{"billable", "release"}, test.GetResponseData(response)["tags"])
+		assert.Equal(`"3"`, response.Header().Get("ETag"))
+	}
+}
+
+func (suite *TagControllerTestSuite) TestUpdateValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(1, `{"tags":["release","  ","`+strings.Repeat("a", 51)+`"]}`)
+	assert.NoError(test.Serve(context, suite.tag.Update))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errors := test.GetResponseErrors(response)
+		assert.NotContains(errors, "tags.0")
+		assert.Contains(errors, "tags.1")
+		assert.Contains(errors, "tags.2")
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Tag", mock.Anything, mock.Anything)
+}
+
+func (suite *TagControllerTestSuite) TestUpdateOthersTodo() {
+	assert := assert.New(suite.T())
+
+	// members can see the todos of the list, only its owner tags them
+	context, response := suite.newContext(2, `{"tags":["release"]}`)
+	assert.NoError(test.Serve(context, suite.tag.Update))
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Tag", mock.Anything, mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTagControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TagControllerTestSuite))
+}
@@ -0,0 +1,329 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errTemplateNotFound is returned when the template does not exist,
+	// is in another workspace or is neither the user's nor shared
+	errTemplateNotFound = apperrors.New(http.StatusNotFound, "template_not_found")
+
+	// errInvalidTemplateList is returned when instantiating a template
+	// into a list the user does not own
+	errInvalidTemplateList = requests.NewFieldError("list_id", "validation.invalid_selection", nil)
+)
+
+// TemplateController handles the templates of the lists which are
+// created again and again, e.g. onboarding checklists
+type TemplateController struct {
+	tpr repositories.TemplateRepository
+	lr  repositories.ListRepository
+	tr  repositories.TodoRepository
+	mr  repositories.ListMemberRepository
+}
+
+// templateResponse is a private struct for template response
+type templateResponse struct {
+	ID        uint                    `json:"id"`
+	UserID    uint                    `json:"user_id"`
+	Name      string                  `json:"name"`
+	ListName  string                  `json:"list_name"`
+	Shared    bool                    `json:"shared"`
+	Variables []string                `json:"variables"`
+	Todos     []*templateTodoResponse `json:"todos"`
+	CreatedAt time.Time               `json:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// templateTodoResponse is a private struct for the todos of a template
+type templateTodoResponse struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority"`
+	Estimate    *int     `json:"estimate"`
+	DueOffset   *int     `json:"due_offset"`
+	Tags        []string `json:"tags"`
+}
+
+// NewTemplate creates TemplateController instance
+func NewTemplate(tpr repositories.TemplateRepository, lr repositories.ListRepository, tr repositories.TodoRepository, mr repositories.ListMemberRepository) *TemplateController {
+	return &TemplateController{tpr, lr, tr, mr}
+}
+
+// Index lists the templates the user can use in the workspace, their
+// own and the shared ones
+// GET /templates
+func (tc *TemplateController) Index(ctx echo.Context) error {
+	templates := tc.tpr.Available(auth.WorkspaceID(ctx), auth.User(ctx).ID)
+
+	res := make([]*templateResponse, 0, len(templates))
+	for i := range templates {
+		res = append(res, newTemplateResponse(&templates[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a template in the workspace
+// POST /templates
+func (tc *TemplateController) Store(ctx echo.Context) error {
+	tr := new(requests.TemplateRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	template := &models.Template{WorkspaceID: auth.WorkspaceID(ctx), UserID: auth.User(ctx).ID}
+	tr.Fill(template)
+	if err := tc.tpr.Create(template); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.TemplateCreated, "template", template.ID, nil, newTemplateResponse(template))
+	return ctx.JSON(http.StatusCreated, NewResponseData(newTemplateResponse(template)))
+}
+
+// Capture creates a template of a list and its open todos with their
+// tags, their due dates become offsets from the earliest one
+// POST /lists/:id/template
+func (tc *TemplateController) Capture(ctx echo.Context) error {
+	list := findVisibleList(ctx, tc.lr, tc.mr)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+
+	cr := new(requests.CaptureRequest)
+	if code, err := cr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	template := &models.Template{
+		WorkspaceID: auth.WorkspaceID(ctx),
+		UserID:      auth.User(ctx).ID,
+		Name:        cr.Name,
+		ListName:    list.Name,
+		Shared:      cr.Shared,
+		Todos:       captureTodos(todosOf(ctx, tc.tr).ByList(list.ID)),
+	}
+	if err := tc.tpr.Create(template); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.TemplateCreated, "template", template.ID, nil, newTemplateResponse(template))
+	return ctx.JSON(http.StatusCreated, NewResponseData(newTemplateResponse(template)))
+}
+
+// Show displays a template along with its todos
+// GET /templates/:id
+func (tc *TemplateController) Show(ctx echo.Context) error {
+	template := tc.findTemplate(ctx)
+	if template == nil {
+		return apperrors.From(http.StatusNotFound, errTemplateNotFound)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newTemplateResponse(template)))
+}
+
+// Update changes a template and replaces its todos, only its owner can
+// PUT /templates/:id
+func (tc *TemplateController) Update(ctx echo.Context) error {
+	template, code, err := tc.findOwnTemplate(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+
+	tr := new(requests.TemplateRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := newTemplateResponse(template)
+	tr.Fill(template)
+	if err := tc.tpr.Update(template); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.TemplateUpdated, "template", template.ID, before, newTemplateResponse(template))
+	return ctx.JSON(http.StatusOK, NewResponseData(newTemplateResponse(template)))
+}
+
+// Destroy deletes a template, only its owner can
+// DELETE /templates/:id
+func (tc *TemplateController) Destroy(ctx echo.Context) error {
+	template, code, err := tc.findOwnTemplate(ctx)
+	if err != nil {
+		return apperrors.From(code, err)
+	}
+	if err := tc.tpr.Delete(template.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.TemplateDeleted, "template", template.ID, newTemplateResponse(template), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Instantiate creates a list with the todos of a template, or adds
+// them to a list of the user, with its variables filled in. Every
+// variable of the template must be given a value.
+// POST /templates/:id/instantiate
+func (tc *TemplateController) Instantiate(ctx echo.Context) error {
+	template := tc.findTemplate(ctx)
+	if template == nil {
+		return apperrors.From(http.StatusNotFound, errTemplateNotFound)
+	}
+
+	ir := new(requests.InstantiateRequest)
+	if code, err := ir.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	var missing []string
+	for _, name := range template.Variables() {
+		if _, ok := ir.Variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		err := requests.NewFieldError("variables", "validation.missing_variables", i18n.Args{"variables": strings.Join(missing, ", ")})
+		return apperrors.From(http.StatusUnprocessableEntity, err)
+	}
+
+	user := auth.User(ctx)
+	timezone := ir.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, _ := time.LoadLocation(timezone)
+	start := startOfDay(time.Now().In(loc))
+	if ir.Start != "" {
+		start, _ = requests.ParseDateTime(ir.Start, timezone)
+	}
+	list, todos := template.Instantiate(user.ID, start, ir.Timezone, ir.Variables)
+
+	code := http.StatusOK
+	if ir.ListID != nil {
+		list = listsOf(ctx, tc.lr).ByID(*ir.ListID)
+		if list == nil || list.UserID != user.ID {
+			return apperrors.From(http.StatusUnprocessableEntity, errInvalidTemplateList)
+		}
+	} else {
+		if err := listsOf(ctx, tc.lr).Create(list); err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+		code = http.StatusCreated
+	}
+
+	res := newListResponse(list)
+	res.Todos = make([]*todoResponse, 0, len(todos))
+	for _, todo := range todos {
+		todo.ListID = &list.ID
+		if err := todosOf(ctx, tc.tr).Create(todo); err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+		res.Todos = append(res.Todos, newTodoResponse(todo))
+	}
+	audit.Log(ctx, audit.TemplateApplied, "template", template.ID, nil,
+		map[string]interface{}{"list_id": list.ID, "todos": len(todos)})
+	return ctx.JSON(code, NewResponseData(res))
+}
+
+// findTemplate looks up the template from the :id param which the
+// user can use in the workspace
+func (tc *TemplateController) findTemplate(ctx echo.Context) *models.Template {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	template := tc.tpr.ByID(uint(id))
+	if template == nil || template.WorkspaceID != auth.WorkspaceID(ctx) {
+		return nil
+	}
+	if !template.Shared && template.UserID != auth.User(ctx).ID {
+		return nil
+	}
+	return template
+}
+
+// findOwnTemplate looks up the template from the :id param which the
+// user owns, the other members of the workspace are forbidden to
+// change the shared ones
+func (tc *TemplateController) findOwnTemplate(ctx echo.Context) (*models.Template, int, error) {
+	template := tc.findTemplate(ctx)
+	if template == nil {
+		return nil, http.StatusNotFound, errTemplateNotFound
+	}
+	if template.UserID != auth.User(ctx).ID {
+		return nil, http.StatusForbidden, auth.ErrForbidden
+	}
+	return template, http.StatusOK, nil
+}
+
+// captureTodos makes the template todos of the open todos of a list
+// by due date, the ones without a due date last. The offsets are
+// counted in days from the earliest due date.
+func captureTodos(todos []models.Todo) []models.TemplateTodo {
+	open := make([]models.Todo, 0, len(todos))
+	for _, t := range todos {
+		if !t.Completed {
+			open = append(open, t)
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		a, b := open[i].DueAt, open[j].DueAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+
+	var first time.Time
+	captured := make([]models.TemplateTodo, 0, len(open))
+	for i := range open {
+		t := &open[i]
+		tt := models.TemplateTodo{
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			Estimate:    t.Estimate,
+		}
+		for _, tag := range t.Tags {
+			tt.Tags = append(tt.Tags, models.TemplateTodoTag{Name: tag.Name})
+		}
+		if due := t.LocalDueAt(); due != nil {
+			day := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+			if first.IsZero() {
+				first = day
+			}
+			offset := int(day.Sub(first).Hours() / 24)
+			tt.DueOffset = &offset
+		}
+		captured = append(captured, tt)
+	}
+	return captured
+}
+
+// newTemplateResponse is a private function for creating *templateResponse
+func newTemplateResponse(t *models.Template) *templateResponse {
+	res := &templateResponse{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Name:      t.Name,
+		ListName:  t.ListName,
+		Shared:    t.Shared,
+		Variables: t.Variables(),
+		Todos:     make([]*templateTodoResponse, 0, len(t.Todos)),
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+	for _, todo := range t.Todos {
+		res.Todos = append(res.Todos, &templateTodoResponse{
+			Title:       todo.Title,
+			Description: todo.Description,
+			Priority:    models.PriorityNames[todo.Priority],
+			Estimate:    todo.Estimate,
+			DueOffset:   todo.DueOffset,
+			Tags:        todo.TagNames(),
+		})
+	}
+	return res
+}
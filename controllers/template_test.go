@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TemplateControllerTestSuite struct {
+	suite.Suite
+	templates *mocks.TemplateRepository
+	lists     *mocks.ListRepository
+	todos     *mocks.TodoRepository
+	members   *mocks.ListMemberRepository
+	template  *TemplateController
+	server    *echo.Echo
+}
+
+func (suite *TemplateControllerTestSuite) SetupTest() {
+	suite.templates = &mocks.TemplateRepository{}
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.members = &mocks.ListMemberRepository{}
+	suite.template = NewTemplate(suite.templates, suite.lists, suite.todos, suite.members)
+	suite.server = echo.New()
+
+	one, three := 1, 3
+	suite.templates.On("ByID", uint(1)).Return(&models.Template{
+		Model:       gorm.Model{ID: 1},
+		WorkspaceID: 5,
+		UserID:      1,
+		Name:        "Onboarding",
+		ListName:    "Onboarding {{employee_name}}",
+		Shared:      true,
+		Todos: []models.TemplateTodo{
+			{Title: "Order a laptop for {{ employee_name }}", DueOffset: &one, Tags: []models.TemplateTodoTag{{Name: "it"}}},
+			{Title: "Intro call with {{manager}}", DueOffset: &three},
+			{Title: "Read the handbook"},
+		},
+	})
+	suite.templates.On("ByID", uint(2)).Return(&models.Template{Model: gorm.Model{ID: 2}, WorkspaceID: 5, UserID: 1, Name: "Private", ListName: "Private"})
+	suite.templates.On("ByID", uint(3)).Return(&models.Template{Model: gorm.Model{ID: 3}, WorkspaceID: 6, UserID: 2, Name: "Elsewhere", Shared: true})
+	suite.templates.On("ByID", mock.Anything).Return(nil)
+}
+
+// newContext creates a context with the params authenticated as the
+// user in the workspace 5
+func (suite *TemplateControllerTestSuite) newContext(userID uint, method string, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	var names, values []string
+	for i := 0; i < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	context.SetParamNames(names...)
+	context.SetParamValues(values...)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: userID}})
+	auth.SetMembership(context, &models.Membership{WorkspaceID: 5, UserID: userID, Role: models.WorkspaceMember})
+
+	return context, response
+}
+
+func (suite *TemplateControllerTestSuite) TestStoreValidatesTheTodos() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(1, echo.POST, `{
+		"name": "Onboarding",
+		"todos": [{"title": "Laptop", "due_offset": 1, "tags": ["it"]}, {"title": "", "due_offset": -2, "tags": ["it", " "]}]
+	}`)
+	assert.NoError(test.Serve(context, suite.template.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errs := test.GetResponseErrors(response)
+		assert.Contains(errs, "todos.1.title")
+		assert.Contains(errs, "todos.1.due_offset")
+		assert.Contains(errs, "todos.1.tags.1")
+		assert.NotContains(errs, "todos.0.title")
+		assert.NotContains(errs, "todos.0.tags.0")
+	}
+	suite.templates.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *TemplateControllerTestSuite) TestStore() {
+	assert := assert.New(suite.T())
+
+	suite.templates.On("Create", mock.AnythingOfType("*models.Template")).Return(nil)
+
+	context, response := suite.newContext(1, echo.POST, `{
+		"name": "Onboarding",
+		"shared": true,
+		"todos": [{"title": "Laptop for {{employee_name}}", "priority": "high", "due_offset": 1, "tags": ["IT", "it", "day one"]}]
+	}`)
+	assert.NoError(test.Serve(context, suite.template.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		template := suite.templates.Calls[0].Arguments.Get(0).(*models.Template)
+		assert.Equal(uint(5), template.WorkspaceID)
+		assert.Equal("Onboarding", template.ListName)
+		assert.Equal(models.PriorityHigh, template.Todos[0].Priority)
+		assert.Equal([]string{"it", "day one"}, template.Todos[0].TagNames())
+		data := test.GetResponseData(response)
+		assert.Equal([]interface{}{"employee_name"}, data["variables"])
+		assert.Equal([]interface{}{"it", "day one"}, data["todos"].([]interface{})[0].(map[string]interface{})["tags"])
+	}
+}
+
+func (suite *TemplateControllerTestSuite) TestSharing() {
+	assert := assert.New(suite.T())
+
+	// the shared template can be seen by the other members of its
+	// workspace but only changed by its owner
+	context, response := suite.newContext(2, echo.GET, "", "id", "1")
+	assert.NoError(test.Serve(context, suite.template.Show))
+	assert.Equal(http.StatusOK, response.Code)
+
+	context, response = suite.newContext(2, echo.DELETE, "", "id", "1")
+	assert.NoError(test.Serve(context, suite.template.Destroy))
+	assert.Equal(http.StatusForbidden, response.Code)
+
+	for _, id := range []string{"2", "3"} {
+		context, response = suite.newContext(2, echo.GET, "", "id", id)
+		assert.NoError(test.Serve(context, suite.template.Show))
+		assert.Equal(http.StatusNotFound, response.Code, id)
+	}
+}
+
+func (suite *TemplateControllerTestSuite) TestInstantiateRequiresTheVariables() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(2, echo.POST, `{"variables": {"employee_name": "Ana"}}`, "id", "1")
+	assert.NoError(test.Serve(context, suite.template.Instantiate))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Equal([]interface{}{"The variables field is missing manager"}, test.GetResponseErrors(response)["variables"])
+	}
+	suite.lists.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *TemplateControllerTestSuite) TestInstantiate() {
+	assert := assert.New(suite.T())
+
+	suite.lists.On("Create", mock.AnythingOfType("*models.List")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.List).ID = 9
+	})
+	suite.todos.On("Create", mock.AnythingOfType("*models.Todo")).Return(nil)
+
+	context, response := suite.newContext(2, echo.POST, `{
+		"start": "2026-03-02T09:00",
+		"timezone": "Asia/Manila",
+		"variables": {"employee_name": "Ana", "manager": "Ben"}
+	}`, "id", "1")
+	assert.NoError(test.Serve(context, suite.template.Instantiate))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		list := suite.lists.Calls[1].Arguments.Get(0).(*models.List)
+		assert.Equal("Onboarding Ana", list.Name)
+		assert.Equal(uint(2), list.UserID)
+
+		var todos []*models.Todo
+		for _, call := range suite.todos.Calls {
+			if call.Method == "Create" {
+				todos = append(todos, call.Arguments.Get(0).(*models.Todo))
+			}
+		}
+		if assert.Len(todos, 3) {
+			assert.Equal("Order a laptop for Ana", todos[0].Title)
+			assert.Equal([]string{"it"}, todos[0].TagNames())
+			assert.Empty(todos[1].Tags)
+			assert.Equal("Intro call with Ben", todos[1].Title)
+			assert.True(time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC).Equal(*todos[0].DueAt))
+			assert.True(time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC).Equal(*todos[1].DueAt))
+			assert.Nil(todos[2].DueAt)
+			assert.Equal(uint(9), *todos[2].ListID)
+		}
+		assert.Len(test.GetResponseData(response)["todos"], 3)
+	}
+}
+
+func (suite *TemplateControllerTestSuite) TestInstantiateIntoAnotherUsersList() {
+	assert := assert.New(suite.T())
+
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Team"})
+
+	context, response := suite.newContext(2, echo.POST, `{"list_id": 7, "variables": {"employee_name": "Ana", "manager": "Ben"}}`, "id", "1")
+	assert.NoError(test.Serve(context, suite.template.Instantiate))
+
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *TemplateControllerTestSuite) TestCapture() {
+	assert := assert.New(suite.T())
+
+	listID := uint(7)
+	monday := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+	thursday := time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC)
+	suite.lists.On("ByID", listID).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Onboarding"})
+	suite.todos.On("ByList", listID).Return([]models.Todo{
+		{Model: gorm.Model{ID: 1}, ListID: &listID, Title: "Intro call", DueAt: &thursday},
+		{Model: gorm.Model{ID: 2}, ListID: &listID, Title: "Handbook"},
+		{Model: gorm.Model{ID: 3}, ListID: &listID, Title: "Laptop", DueAt: &monday, Tags: []models.TodoTag{{Name: "it"}}},
+		{Model: gorm.Model{ID: 4}, ListID: &listID, Title: "Done already", Completed: true},
+	})
+	suite.templates.On("Create", mock.AnythingOfType("*models.Template")).Return(nil)
+
+	context, response := suite.newContext(1, echo.POST, `{"name": "Onboarding"}`, "id", "7")
+	assert.NoError(test.Serve(context, suite.template.Capture))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		template := suite.templates.Calls[0].Arguments.Get(0).(*models.Template)
+		if assert.Len(template.Todos, 3) {
+			assert.Equal("Laptop", template.Todos[0].Title)
+			assert.Equal([]string{"it"}, template.Todos[0].TagNames())
+			assert.Equal(0, *template.Todos[0].DueOffset)
+			assert.Equal("Intro call", template.Todos[1].Title)
+			assert.Equal(3, *template.Todos[1].DueOffset)
+			assert.Nil(template.Todos[2].DueOffset)
+		}
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTemplateControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TemplateControllerTestSuite))
+}
@@ -0,0 +1,359 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// maxReportRange is the longest time range a report covers
+const maxReportRange = 366 * 24 * time.Hour
+
+var (
+	// errNoRunningTimer is returned when stopping the timer while none runs
+	errNoRunningTimer = apperrors.New(http.StatusNotFound, "timer_not_running")
+
+	// errInvalidReportRange is returned when the report would cover
+	// no time or too much of it
+	errInvalidReportRange = requests.NewFieldError("to", "validation.report_range", nil)
+)
+
+// TimeController handles the time the users track on the todos
+// they own or are assigned to
+type TimeController struct {
+	ter repositories.TimeEntryRepository
+	tr  repositories.TodoRepository
+	lr  repositories.ListRepository
+}
+
+// timeEntryResponse is a private struct for time entry response,
+// the seconds of a running timer are counted up to now
+type timeEntryResponse struct {
+	ID        uint       `json:"id"`
+	TodoID    uint       `json:"todo_id"`
+	UserID    uint       `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+	Running   bool       `json:"running"`
+	Seconds   int64      `json:"seconds"`
+}
+
+// timeTotalMeta is a private struct for the time tracked on a todo
+// in all, next to the minutes it was estimated to take
+type timeTotalMeta struct {
+	Seconds  int64 `json:"seconds"`
+	Estimate *int  `json:"estimate"`
+}
+
+// timeReportResponse is a private struct for the time report
+type timeReportResponse struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Timezone string           `json:"timezone"`
+	Group    string           `json:"group"`
+	Seconds  int64            `json:"seconds"`
+	Periods  []*periodTotal   `json:"periods"`
+	Todos    []*todoTimeTotal `json:"todos"`
+	Lists    []*listTimeTotal `json:"lists"`
+	Tags     []*tagTimeTotal  `json:"tags"`
+}
+
+// periodTotal is a private struct for the time tracked in a day or
+// week, which starts on the date
+type periodTotal struct {
+	Start   string `json:"start"`
+	Seconds int64  `json:"seconds"`
+}
+
+// todoTimeTotal is a private struct for the time tracked on a todo
+type todoTimeTotal struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	ListID   *uint  `json:"list_id"`
+	Estimate *int   `json:"estimate"`
+	Seconds  int64  `json:"seconds"`
+}
+
+// listTimeTotal is a private struct for the time tracked on the
+// todos of a list, the todos without a list have no ID
+type listTimeTotal struct {
+	ID      *uint  `json:"id"`
+	Name    string `json:"name"`
+	Seconds int64  `json:"seconds"`
+}
+
+// tagTimeTotal is a private struct for the time tracked on the todos
+// with a tag, a todo with several tags counts in each of them
+type tagTimeTotal struct {
+	Name    string `json:"name"`
+	Seconds int64  `json:"seconds"`
+}
+
+// NewTime creates TimeController instance
+func NewTime(ter repositories.TimeEntryRepository, tr repositories.TodoRepository, lr repositories.ListRepository) *TimeController {
+	return &TimeController{ter, tr, lr}
+}
+
+// Current shows the running timer of the user, null when none runs
+// GET /timer
+func (tc *TimeController) Current(ctx echo.Context) error {
+	var res *timeEntryResponse
+	if entry := tc.ter.Running(auth.User(ctx).ID); entry != nil {
+		res = newTimeEntryResponse(entry, time.Now())
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Start starts the timer on a todo, the timer which was running is
+// stopped since every user has one at most
+// POST /todos/:id/timer
+func (tc *TimeController) Start(ctx echo.Context) error {
+	todo := tc.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	now := time.Now()
+	entry := &models.TimeEntry{UserID: auth.User(ctx).ID, TodoID: todo.ID, StartedAt: now}
+	if _, err := tc.ter.Start(entry); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	return ctx.JSON(http.StatusCreated, NewResponseData(newTimeEntryResponse(entry, now)))
+}
+
+// Stop stops the running timer of the user
+// POST /timer/stop
+func (tc *TimeController) Stop(ctx echo.Context) error {
+	entry := tc.ter.Running(auth.User(ctx).ID)
+	if entry == nil {
+		return apperrors.From(http.StatusNotFound, errNoRunningTimer)
+	}
+
+	now := time.Now()
+	if err := tc.ter.Stop(entry, now); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newTimeEntryResponse(entry, now)))
+}
+
+// Index lists the time tracked on a todo, the latest first, along
+// with its total
+// GET /todos/:id/time-entries
+func (tc *TimeController) Index(ctx echo.Context) error {
+	todo := tc.findTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+
+	now := time.Now()
+	entries := tc.ter.ByTodo(todo.ID)
+	res := make([]*timeEntryResponse, 0, len(entries))
+	var total int64
+	for i := range entries {
+		r := newTimeEntryResponse(&entries[i], now)
+		total += r.Seconds
+		res = append(res, r)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, &timeTotalMeta{Seconds: total, Estimate: todo.Estimate}))
+}
+
+// Report sums up the time the user tracked on the todos of the
+// workspace by day or week in the timezone, as well as by todo, list
+// and tag. The timezone is the user's own unless it is given, and UTC
+// when they have none. It covers the last 7 days unless from and to
+// are given.
+// GET /time/report?from=&to=&group=&timezone=&list_id=&todo_id=&tag=
+func (tc *TimeController) Report(ctx echo.Context) error {
+	tr := new(requests.TimeReportRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	res := &timeReportResponse{Timezone: tr.Timezone, Group: tr.Group}
+	if res.Timezone == "" {
+		res.Timezone = auth.User(ctx).Timezone
+	}
+	loc, err := time.LoadLocation(res.Timezone)
+	if res.Timezone == "" || err != nil {
+		res.Timezone, loc = "UTC", time.UTC
+	}
+	if res.Group == "" {
+		res.Group = "day"
+	}
+
+	now := time.Now()
+	res.To = startOfDay(now.In(loc)).AddDate(0, 0, 1)
+	if tr.To != "" {
+		res.To, _ = requests.ParseDateTime(tr.To, res.Timezone)
+	}
+	res.From = res.To.AddDate(0, 0, -7)
+	if tr.From != "" {
+		res.From, _ = requests.ParseDateTime(tr.From, res.Timezone)
+	}
+	if !res.To.After(res.From) || res.To.Sub(res.From) > maxReportRange {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidReportRange)
+	}
+
+	listID, _ := strconv.ParseUint(tr.ListID, 10, 64)
+	todoID, _ := strconv.ParseUint(tr.TodoID, 10, 64)
+	tag := requests.NormalizeTag(tr.Tag)
+	var entries []models.TimeEntry
+	for _, e := range tc.ter.Between(auth.User(ctx).ID, res.From, res.To) {
+		if e.Todo.WorkspaceID != auth.WorkspaceID(ctx) {
+			continue
+		}
+		if todoID != 0 && e.TodoID != uint(todoID) {
+			continue
+		}
+		if listID != 0 && (e.Todo.ListID == nil || *e.Todo.ListID != uint(listID)) {
+			continue
+		}
+		if tag != "" && !e.Todo.HasTag(tag) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	res.Periods = reportPeriods(entries, res.From, res.To, res.Group, loc, now)
+	res.Todos, res.Lists, res.Tags = tc.reportTotals(entries, res.From, res.To, now)
+	for _, t := range res.Todos {
+		res.Seconds += t.Seconds
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// findTodo looks up the todo from the :id param which the user owns
+// or is assigned to
+func (tc *TimeController) findTodo(ctx echo.Context) *models.Todo {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := todosOf(ctx, tc.tr).ByID(uint(id))
+	if todo == nil {
+		return nil
+	}
+
+	user := auth.User(ctx)
+	if todo.UserID == user.ID || containsID(todo.AssigneeIDs(), user.ID) {
+		return todo
+	}
+	return nil
+}
+
+// reportTotals sums up the time tracked by todo, by list and by tag,
+// the most time first
+func (tc *TimeController) reportTotals(entries []models.TimeEntry, from, to, now time.Time) ([]*todoTimeTotal, []*listTimeTotal, []*tagTimeTotal) {
+	todos := []*todoTimeTotal{}
+	byTodo := make(map[uint]*todoTimeTotal)
+	lists := []*listTimeTotal{}
+	byList := make(map[uint]*listTimeTotal)
+	tags := []*tagTimeTotal{}
+	byTag := make(map[string]*tagTimeTotal)
+
+	for i := range entries {
+		e := &entries[i]
+		seconds := int64(e.Between(from, to, now) / time.Second)
+
+		t, ok := byTodo[e.TodoID]
+		if !ok {
+			t = &todoTimeTotal{ID: e.TodoID, Title: e.Todo.Title, ListID: e.Todo.ListID, Estimate: e.Todo.Estimate}
+			byTodo[e.TodoID] = t
+			todos = append(todos, t)
+		}
+		t.Seconds += seconds
+
+		var id uint
+		if e.Todo.ListID != nil {
+			id = *e.Todo.ListID
+		}
+		l, ok := byList[id]
+		if !ok {
+			l = &listTimeTotal{}
+			if id != 0 {
+				l.ID = &id
+				if list := tc.lr.ByID(id); list != nil {
+					l.Name = list.Name
+				}
+			}
+			byList[id] = l
+			lists = append(lists, l)
+		}
+		l.Seconds += seconds
+
+		for _, name := range e.Todo.TagNames() {
+			t, ok := byTag[name]
+			if !ok {
+				t = &tagTimeTotal{Name: name}
+				byTag[name] = t
+				tags = append(tags, t)
+			}
+			t.Seconds += seconds
+		}
+	}
+
+	sort.SliceStable(todos, func(i, j int) bool { return todos[i].Seconds > todos[j].Seconds })
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Seconds > lists[j].Seconds })
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Seconds > tags[j].Seconds })
+	return todos, lists, tags
+}
+
+// reportPeriods sums up the time tracked in every day or week between
+// from and to, an entry spanning several of them counts in each
+func reportPeriods(entries []models.TimeEntry, from, to time.Time, group string, loc *time.Location, now time.Time) []*periodTotal {
+	periods := []*periodTotal{}
+	for start := periodStart(from.In(loc), group); start.Before(to); {
+		end := start.AddDate(0, 0, 1)
+		if group == "week" {
+			end = start.AddDate(0, 0, 7)
+		}
+
+		p := &periodTotal{Start: start.Format("2006-01-02")}
+		lo, hi := start, end
+		if lo.Before(from) {
+			lo = from
+		}
+		if hi.After(to) {
+			hi = to
+		}
+		for i := range entries {
+			p.Seconds += int64(entries[i].Between(lo, hi, now) / time.Second)
+		}
+		periods = append(periods, p)
+		start = end
+	}
+	return periods
+}
+
+// periodStart is the start of the day or the week, on Monday, of the time
+func periodStart(t time.Time, group string) time.Time {
+	day := startOfDay(t)
+	if group != "week" {
+		return day
+	}
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// startOfDay is the midnight starting the day of the time in its location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// newTimeEntryResponse is a private function for creating *timeEntryResponse
+func newTimeEntryResponse(e *models.TimeEntry, now time.Time) *timeEntryResponse {
+	return &timeEntryResponse{
+		ID:        e.ID,
+		TodoID:    e.TodoID,
+		UserID:    e.UserID,
+		StartedAt: e.StartedAt,
+		StoppedAt: e.StoppedAt,
+		Running:   e.IsRunning(),
+		Seconds:   int64(e.Duration(now) / time.Second),
+	}
+}
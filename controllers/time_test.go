@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TimeControllerTestSuite struct {
+	suite.Suite
+	entries *mocks.TimeEntryRepository
+	todos   *mocks.TodoRepository
+	lists   *mocks.ListRepository
+	time    *TimeController
+	server  *echo.Echo
+}
+
+func (suite *TimeControllerTestSuite) SetupTest() {
+	suite.entries = &mocks.TimeEntryRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.time = NewTime(suite.entries, suite.todos, suite.lists)
+	suite.server = echo.New()
+
+	suite.todos.On("ByID", uint(3)).Return(&models.Todo{Model: gorm.Model{ID: 3}, UserID: 2, Title: "Ship release",
+		Assignees: []models.TodoAssignee{{TodoID: 3, UserID: 1}}})
+	suite.todos.On("ByID", uint(4)).Return(&models.Todo{Model: gorm.Model{ID: 4}, UserID: 2, Title: "Theirs"})
+	suite.lists.On("ByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Work"})
+}
+
+// newContext creates a context of the request to the todo
+// authenticated as the first user
+func (suite *TimeControllerTestSuite) newContext(method string, target string, todo string) (echo.Context, *httptest.ResponseRecorder) {
+	context, response := newUserContext(suite.server, httptest.NewRequest(method, target, nil))
+	context.SetParamNames("id")
+	context.SetParamValues(todo)
+
+	return context, response
+}
+
+func (suite *TimeControllerTestSuite) TestStartOnAssignedTodo() {
+	assert := assert.New(suite.T())
+
+	suite.entries.On("Start", mock.AnythingOfType("*models.TimeEntry")).Return(nil, nil)
+
+	context, response := suite.newContext(echo.POST, "/todos/3/timer", "3")
+	assert.NoError(test.Serve(context, suite.time.Start))
+	assert.Equal(http.StatusCreated, response.Code)
+
+	// the todos of others can't be tracked unless assigned
+	context, response = suite.newContext(echo.POST, "/todos/4/timer", "4")
+	assert.NoError(test.Serve(context, suite.time.Start))
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	suite.entries.AssertNumberOfCalls(suite.T(), "Start", 1)
+	entry := suite.entries.Calls[0].Arguments.Get(0).(*models.TimeEntry)
+	assert.Equal(uint(1), entry.UserID)
+	assert.Equal(uint(3), entry.TodoID)
+}
+
+func (suite *TimeControllerTestSuite) TestStopWithoutTimer() {
+	assert := assert.New(suite.T())
+
+	suite.entries.On("Running", uint(1)).Return(nil)
+
+	context, response := suite.newContext(echo.POST, "/timer/stop", "")
+	assert.NoError(test.Serve(context, suite.time.Stop))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func (suite *TimeControllerTestSuite) TestReportByDayInTimezone() {
+	assert := assert.New(suite.T())
+
+	listID := uint(7)
+	work := models.Todo{Model: gorm.Model{ID: 3}, ListID: &listID, Title: "Ship release",
+		Tags: []models.TodoTag{{Name: "billable"}, {Name: "release"}}}
+	home := models.Todo{Model: gorm.Model{ID: 5}, Title: "Pay rent"}
+	at := func(s string) *time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return &t
+	}
+	entries := []models.TimeEntry{
+		// 23:00 to 01:00 in Manila, an hour on each day
+		{TodoID: 3, Todo: work, StartedAt: *at("2026-03-02T15:00:00Z"), StoppedAt: at("2026-03-02T17:00:00Z")},
+		{TodoID: 5, Todo: home, StartedAt: *at("2026-03-03T01:00:00Z"), StoppedAt: at("2026-03-03T01:30:00Z")},
+		// started before the report, only its last 15 minutes count
+		{TodoID: 3, Todo: work, StartedAt: *at("2026-03-01T15:45:00Z"), StoppedAt: at("2026-03-01T16:15:00Z")},
+	}
+	from, to := *at("2026-03-01T16:00:00Z"), *at("2026-03-04T16:00:00Z")
+	suite.entries.On("Between", uint(1), mock.Anything, mock.Anything).Return(entries).Run(func(args mock.Arguments) {
+		assert.True(from.Equal(args.Get(1).(time.Time)))
+		assert.True(to.Equal(args.Get(2).(time.Time)))
+	})
+
+	context, response := suite.newContext(echo.GET, "/time/report?from=2026-03-02&to=2026-03-05&timezone=Asia/Manila", "")
+	assert.NoError(test.Serve(context, suite.time.Report))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data timeReportResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	report := body.Data
+
+	assert.Equal(int64(2*3600+45*60), report.Seconds)
+	if assert.Len(report.Periods, 3) {
+		assert.Equal(periodTotal{Start: "2026-03-02", Seconds: 3600 + 15*60}, *report.Periods[0])
+		assert.Equal(periodTotal{Start: "2026-03-03", Seconds: 3600 + 30*60}, *report.Periods[1])
+		assert.Equal(periodTotal{Start: "2026-03-04", Seconds: 0}, *report.Periods[2])
+	}
+	if assert.Len(report.Todos, 2) {
+		assert.Equal(uint(3), report.Todos[0].ID)
+		assert.Equal(int64(2*3600+15*60), report.Todos[0].Seconds)
+	}
+	if assert.Len(report.Lists, 2) {
+		assert.Equal("Work", report.Lists[0].Name)
+		assert.Nil(report.Lists[1].ID)
+	}
+	// the todos without tags are in no tag's total
+	assert.Equal([]*tagTimeTotal{{Name: "billable", Seconds: 2*3600 + 15*60}, {Name: "release", Seconds: 2*3600 + 15*60}}, report.Tags)
+}
+
+func (suite *TimeControllerTestSuite) TestReportInTheUsersTimezone() {
+	assert := assert.New(suite.T())
+
+	billable := models.Todo{Model: gorm.Model{ID: 3}, Title: "Ship release", Tags: []models.TodoTag{{Name: "billable"}}}
+	other := models.Todo{Model: gorm.Model{ID: 5}, Title: "Pay rent"}
+	at := func(s string) time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t
+	}
+	stopped := at("2026-03-02T17:00:00Z")
+	entries := []models.TimeEntry{
+		{TodoID: 3, Todo: billable, StartedAt: at("2026-03-02T16:00:00Z"), StoppedAt: &stopped},
+		{TodoID: 5, Todo: other, StartedAt: at("2026-03-02T16:00:00Z"), StoppedAt: &stopped},
+	}
+	suite.entries.On("Between", uint(1), mock.Anything, mock.Anything).Return(entries).Run(func(args mock.Arguments) {
+		assert.True(at("2026-03-01T16:00:00Z").Equal(args.Get(1).(time.Time)))
+	})
+
+	request := httptest.NewRequest(echo.GET, "/time/report?from=2026-03-02&to=2026-03-05&tag=Billable", nil)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: 1}, Timezone: "Asia/Manila"})
+	assert.NoError(test.Serve(context, suite.time.Report))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data timeReportResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	assert.Equal("Asia/Manila", body.Data.Timezone)
+	assert.Equal(int64(3600), body.Data.Seconds)
+	if assert.Len(body.Data.Todos, 1) {
+		assert.Equal(uint(3), body.Data.Todos[0].ID)
+	}
+}
+
+func (suite *TimeControllerTestSuite) TestReportByWeek() {
+	assert := assert.New(suite.T())
+
+	suite.entries.On("Between", uint(1), mock.Anything, mock.Anything).Return([]models.TimeEntry{})
+
+	// 2026-03-04 is a Wednesday, weeks start on Monday
+	context, response := suite.newContext(echo.GET, "/time/report?from=2026-03-04&to=2026-03-17&group=week", "")
+	assert.NoError(test.Serve(context, suite.time.Report))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data timeReportResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+
+	var starts []string
+	for _, p := range body.Data.Periods {
+		starts = append(starts, p.Start)
+	}
+	assert.Equal([]string{"2026-03-02", "2026-03-09", "2026-03-16"}, starts)
+}
+
+func (suite *TimeControllerTestSuite) TestReportInvalidRange() {
+	assert := assert.New(suite.T())
+
+	for _, query := range []string{"from=2026-03-05&to=2026-03-01", "from=2024-01-01&to=2026-01-01", "group=month"} {
+		context, response := suite.newContext(echo.GET, "/time/report?"+query, "")
+		assert.NoError(test.Serve(context, suite.time.Report))
+		assert.Equal(http.StatusUnprocessableEntity, response.Code, query)
+	}
+	suite.entries.AssertNotCalled(suite.T(), "Between", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTimeControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeControllerTestSuite))
+}
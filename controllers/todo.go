@@ -0,0 +1,452 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// errTodoNotFound is returned when the todo does not exist or
+// belongs to another user, so their existence won't leak.
+var errTodoNotFound = apperrors.New(http.StatusNotFound, "todo_not_found")
+
+// errInvalidList is returned when a todo is put in a list which
+// does not exist or belongs to another user
+var errInvalidList = requests.NewFieldError("list_id", "validation.invalid_selection", nil)
+
+// errInvalidSort is returned when sorting the todos by an unknown order
+var errInvalidSort = requests.NewFieldError("sort", "validation.invalid_sort", nil)
+
+// TodoController handles the todos of the authenticated user
+type TodoController struct {
+	tr repositories.TodoRepository
+	rr repositories.ReminderRepository
+	lr repositories.ListRepository
+	mr repositories.ListMemberRepository
+	jr repositories.JournalRepository
+	cr repositories.CommentRepository
+}
+
+// todoResponse is a private struct for todo response
+type todoResponse struct {
+	ID            uint                `json:"id"`
+	ListID        *uint               `json:"list_id"`
+	Title         string              `json:"title"`
+	Description   string              `json:"description"`
+	Completed     bool                `json:"completed"`
+	DueAt         *time.Time          `json:"due_at"`
+	Timezone      string              `json:"timezone,omitempty"`
+	Recurrence    string              `json:"recurrence,omitempty"`
+	Priority      string              `json:"priority"`
+	PriorityValue int                 `json:"priority_value"`
+	Estimate      *int                `json:"estimate"`
+	BlockedBy     []uint              `json:"blocked_by"`
+	ColumnID      *uint               `json:"column_id"`
+	Position      int                 `json:"position"`
+	Version       uint                `json:"version"`
+	Reminders     []reminderResponse  `json:"reminders"`
+	Assignees     []*assigneeResponse `json:"assignees"`
+	Tags          []string            `json:"tags"`
+	Comments      int64               `json:"comments"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// reminderResponse is a private struct for reminder response
+type reminderResponse struct {
+	ID      uint       `json:"id"`
+	Channel string     `json:"channel"`
+	Target  string     `json:"target,omitempty"`
+	At      *time.Time `json:"at,omitempty"`
+	Before  *int       `json:"before,omitempty"`
+	FireAt  *time.Time `json:"fire_at"`
+	SentAt  *time.Time `json:"sent_at"`
+}
+
+// NewTodo creates TodoController instance which records the changes
+// to the todos in the user's journal
+func NewTodo(tr repositories.TodoRepository, rr repositories.ReminderRepository, lr repositories.ListRepository, mr repositories.ListMemberRepository, jr repositories.JournalRepository, cr repositories.CommentRepository) *TodoController {
+	return &TodoController{tr, rr, lr, mr, jr, cr}
+}
+
+// Index lists the todos of the user, use ?list_id= to only list the
+// todos of one of the lists they can see and ?sort= to order them
+// GET /todos
+func (tc *TodoController) Index(ctx echo.Context) error {
+	order := ctx.QueryParam("sort")
+	if !isTodoSort(order) {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidSort)
+	}
+
+	var todos []models.Todo
+	if param := ctx.QueryParam("list_id"); param != "" {
+		id, _ := strconv.ParseUint(param, 10, 64)
+		listID := uint(id)
+		if list := listsOf(ctx, tc.lr).ByID(listID); list == nil || !isListMember(tc.mr, list, auth.User(ctx).ID) {
+			return apperrors.From(http.StatusUnprocessableEntity, errInvalidList)
+		}
+		todos = todosOf(ctx, tc.tr).ByList(listID)
+	} else {
+		todos = todosOf(ctx, tc.tr).ByUser(auth.User(ctx).ID)
+	}
+	sortTodos(todos, order)
+
+	res := make([]*todoResponse, 0, len(todos))
+	for i := range todos {
+		res = append(res, newTodoResponse(&todos[i]))
+	}
+	withCommentCounts(tc.cr, res)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a todo
+// POST /todos
+func (tc *TodoController) Store(ctx echo.Context) error {
+	tr := new(requests.TodoRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if !tc.ownsList(ctx, tr.ListID) {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidList)
+	}
+
+	todo := tr.TodoModel(auth.User(ctx).ID)
+	if err := todosOf(ctx, tc.tr).Create(todo); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	record(ctx, tc.jr, models.NewTodoOperation(models.OperationCreate, todo, nil, models.NewTodoSnapshot(todo)))
+	audit.Log(ctx, events.TodoCreated, "todo", todo.ID, nil, models.NewTodoSnapshot(todo))
+	withVersion(ctx, todo.Version)
+	return ctx.JSON(http.StatusCreated, NewResponseData(newTodoResponse(todo)))
+}
+
+// Show displays a todo along with the ETag of its version, it is not
+// sent again while If-None-Match has that version
+// GET /todos/:id
+func (tc *TodoController) Show(ctx echo.Context) error {
+	todo, err := tc.findTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if notModified(ctx, todo.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	res := newTodoResponse(todo)
+	withCommentCounts(tc.cr, []*todoResponse{res})
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Update updates a todo, relative reminders follow its new due date.
+// The version it is made to must be given by If-Match or the version
+// field, a todo which was changed since is not updated.
+// PUT /todos/:id
+func (tc *TodoController) Update(ctx echo.Context) error {
+	todo, err := tc.findOwnTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	tr := new(requests.TodoRequest)
+	if code, err := tr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	return tc.update(ctx, todo, tr)
+}
+
+// Patch updates a todo with a JSON merge patch or a JSON patch of its
+// fields, the patched todo is validated as a whole. Its version is
+// given by If-Match or the version field of the patched todo.
+// PATCH /todos/:id
+func (tc *TodoController) Patch(ctx echo.Context) error {
+	todo, err := tc.findOwnTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	tr := new(requests.TodoRequest)
+	if code, err := tr.Patch(ctx, todo); err != nil {
+		return apperrors.From(code, err)
+	}
+	return tc.update(ctx, todo, tr)
+}
+
+// update makes the changes of the request to the todo, provided they
+// are made to its current version
+func (tc *TodoController) update(ctx echo.Context, todo *models.Todo, tr *requests.TodoRequest) error {
+	if code, err := checkVersion(ctx, todo.Version, tr.Version); err != nil {
+		return versionError(ctx, code, err, todo.Version, newTodoResponse(todo))
+	}
+	if !tc.ownsList(ctx, tr.ListID) {
+		return apperrors.From(http.StatusUnprocessableEntity, errInvalidList)
+	}
+	if tr.Completed && !todo.Completed && len(todo.Blockers) > 0 && !tr.IgnoreBlockers {
+		if blockers := todosOf(ctx, tc.tr).OpenBlockers(todo.ID); len(blockers) > 0 {
+			err := requests.NewFieldError("completed", "validation.blocked", i18n.Args{"count": len(blockers)})
+			return apperrors.From(http.StatusUnprocessableEntity, err)
+		}
+	}
+
+	before := models.NewTodoSnapshot(todo)
+	tr.Fill(todo)
+	err := todosOf(ctx, tc.tr).Update(todo)
+	if err == repositories.ErrVersionConflict {
+		// it was changed after it was read above
+		if current := todosOf(ctx, tc.tr).ByID(todo.ID); current != nil {
+			return versionError(ctx, http.StatusPreconditionFailed, errVersionMismatch, current.Version, newTodoResponse(current))
+		}
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	if updated := todosOf(ctx, tc.tr).ByID(todo.ID); updated != nil {
+		todo = updated
+	}
+	if after := models.NewTodoSnapshot(todo); !after.Equal(before) {
+		record(ctx, tc.jr, models.NewTodoOperation(todoChange(before, after), todo, before, after))
+		audit.Log(ctx, events.TodoUpdated, "todo", todo.ID, before, after)
+	}
+	withVersion(ctx, todo.Version)
+	return ctx.JSON(http.StatusOK, NewResponseData(newTodoResponse(todo)))
+}
+
+// Destroy moves a todo along with its reminders to the trash, the
+// version is required as for updating it
+// DELETE /todos/:id
+func (tc *TodoController) Destroy(ctx echo.Context) error {
+	todo, err := tc.findOwnTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if code, err := checkVersion(ctx, todo.Version, bodyVersion(ctx)); err != nil {
+		return versionError(ctx, code, err, todo.Version, newTodoResponse(todo))
+	}
+	if err := todosOf(ctx, tc.tr).Delete(todo.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	record(ctx, tc.jr, models.NewTodoOperation(models.OperationDelete, todo, models.NewTodoSnapshot(todo), nil))
+	audit.Log(ctx, events.TodoDeleted, "todo", todo.ID, models.NewTodoSnapshot(todo), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// StoreReminder adds a reminder to a todo
+// POST /todos/:id/reminders
+func (tc *TodoController) StoreReminder(ctx echo.Context) error {
+	todo, err := tc.findOwnTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	rr := new(requests.ReminderRequest)
+	if code, err := rr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	reminder := rr.ReminderModel(todo)
+	if err := tc.rr.Create(reminder); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.ReminderCreated, "reminder", reminder.ID, nil, newReminderResponse(reminder))
+	return ctx.JSON(http.StatusCreated, NewResponseData(newReminderResponse(reminder)))
+}
+
+// DestroyReminder removes a reminder from a todo
+// DELETE /todos/:id/reminders/:reminder
+func (tc *TodoController) DestroyReminder(ctx echo.Context) error {
+	todo, err := tc.findOwnTodo(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("reminder"), 10, 64)
+	reminder := tc.rr.ByID(uint(id))
+	if reminder == nil || reminder.TodoID != todo.ID {
+		return apperrors.New(http.StatusNotFound, "reminder_not_found")
+	}
+	if err := tc.rr.Delete(reminder.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.ReminderDeleted, "reminder", reminder.ID, newReminderResponse(reminder), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// findTodo looks up the todo from the :id param which the user can
+// see, its owner and the members of its list can
+func (tc *TodoController) findTodo(ctx echo.Context) (*models.Todo, error) {
+	todo := findVisibleTodo(ctx, tc.tr, tc.lr, tc.mr)
+	if todo == nil {
+		return nil, errTodoNotFound
+	}
+	return todo, nil
+}
+
+// findOwnTodo looks up the todo from the :id param which the user
+// owns, the members of its list can see it but not change it
+func (tc *TodoController) findOwnTodo(ctx echo.Context) (*models.Todo, error) {
+	todo, err := tc.findTodo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if todo.UserID != auth.User(ctx).ID {
+		return nil, auth.ErrForbidden
+	}
+	return todo, nil
+}
+
+// ownsList determines if the list, when one is given, is one of the user's
+func (tc *TodoController) ownsList(ctx echo.Context, listID *uint) bool {
+	if listID == nil {
+		return true
+	}
+
+	list := listsOf(ctx, tc.lr).ByID(*listID)
+	return list != nil && list.UserID == auth.User(ctx).ID
+}
+
+// todoChange is the kind of operation which changed the todo, moving
+// it to another list and completing it are told apart from other updates
+func todoChange(before, after *models.TodoSnapshot) string {
+	moved, completed := *after, *after
+	moved.ListID = before.ListID
+	completed.Completed = before.Completed
+	switch {
+	case moved.Equal(before):
+		return models.OperationMove
+	case completed.Equal(before):
+		return models.OperationComplete
+	}
+	return models.OperationUpdate
+}
+
+// isTodoSort determines if the todos can be sorted by the order,
+// none keeps the order they were created in
+func isTodoSort(by string) bool {
+	switch by {
+	case "", "priority", "due_at", "topological":
+		return true
+	}
+	return false
+}
+
+// sortTodos orders the todos in place. By priority puts the most urgent
+// first, by due_at the ones due first, and topological puts every todo
+// after the todos blocking it. Ties keep their order.
+func sortTodos(todos []models.Todo, by string) {
+	switch by {
+	case "priority":
+		sort.SliceStable(todos, func(i, j int) bool {
+			return todos[i].Priority > todos[j].Priority
+		})
+	case "due_at":
+		sort.SliceStable(todos, func(i, j int) bool {
+			a, b := todos[i].DueAt, todos[j].DueAt
+			return a != nil && (b == nil || a.Before(*b))
+		})
+	case "topological":
+		copy(todos, topological(todos))
+	}
+}
+
+// topological orders the todos so the ones blocking others come
+// first, otherwise keeping their order. Blockers which aren't among
+// the todos are left out of it.
+func topological(todos []models.Todo) []models.Todo {
+	index := make(map[uint]int, len(todos))
+	for i := range todos {
+		index[todos[i].ID] = i
+	}
+
+	blocking := make([][]int, len(todos))
+	waiting := make([]int, len(todos))
+	for i := range todos {
+		for _, id := range todos[i].BlockerIDs() {
+			if j, ok := index[id]; ok {
+				blocking[j] = append(blocking[j], i)
+				waiting[i]++
+			}
+		}
+	}
+
+	sorted := make([]models.Todo, 0, len(todos))
+	done := make([]bool, len(todos))
+	for len(sorted) < len(todos) {
+		next := -1
+		for i := range todos {
+			if !done[i] && waiting[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// only a cycle is left, which inserting prevents
+			for i := range todos {
+				if !done[i] {
+					sorted = append(sorted, todos[i])
+				}
+			}
+			break
+		}
+
+		done[next] = true
+		sorted = append(sorted, todos[next])
+		for _, i := range blocking[next] {
+			waiting[i]--
+		}
+	}
+	return sorted
+}
+
+// newTodoResponse is a private function for creating *todoResponse
+func newTodoResponse(t *models.Todo) *todoResponse {
+	r := &todoResponse{
+		ID:            t.ID,
+		ListID:        t.ListID,
+		Title:         t.Title,
+		Description:   t.Description,
+		Completed:     t.Completed,
+		DueAt:         t.LocalDueAt(),
+		Timezone:      t.Timezone,
+		Recurrence:    t.Recurrence,
+		Priority:      t.PriorityName(),
+		PriorityValue: t.Priority,
+		Estimate:      t.Estimate,
+		BlockedBy:     t.BlockerIDs(),
+		ColumnID:      t.ColumnID,
+		Position:      t.Position,
+		Version:       t.Version,
+		Reminders:     make([]reminderResponse, 0, len(t.Reminders)),
+		Assignees:     newAssigneeResponses(t.Assignees),
+		Tags:          t.TagNames(),
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+	for i := range t.Reminders {
+		r.Reminders = append(r.Reminders, *newReminderResponse(&t.Reminders[i]))
+	}
+
+	return r
+}
+
+// newReminderResponse is a private function for creating *reminderResponse
+func newReminderResponse(r *models.Reminder) *reminderResponse {
+	return &reminderResponse{
+		ID:      r.ID,
+		Channel: r.Channel,
+		Target:  r.Target,
+		At:      r.RemindAt,
+		Before:  r.OffsetMinutes,
+		FireAt:  r.FireAt,
+		SentAt:  r.SentAt,
+	}
+}
@@ -0,0 +1,523 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TodoControllerTestSuite struct {
+	suite.Suite
+	todos     *mocks.TodoRepository
+	reminders *mocks.ReminderRepository
+	lists     *mocks.ListRepository
+	members   *mocks.ListMemberRepository
+	journal   *mocks.JournalRepository
+	comments  *mocks.CommentRepository
+	todo      *TodoController
+	server    *echo.Echo
+	user      *models.User
+}
+
+func (suite *TodoControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.reminders = &mocks.ReminderRepository{}
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.members = &mocks.ListMemberRepository{}
+	suite.journal = &mocks.JournalRepository{}
+	suite.journal.On("Workspace", mock.Anything).Return(suite.journal)
+	suite.journal.On("Record", mock.AnythingOfType("*models.Operation")).Return(nil)
+	suite.comments = &mocks.CommentRepository{}
+	suite.todo = NewTodo(suite.todos, suite.reminders, suite.lists, suite.members, suite.journal, suite.comments)
+	suite.server = echo.New()
+	suite.user = &models.User{Model: gorm.Model{ID: 1}, Username: "alice"}
+}
+
+// newContext creates a context authenticated as the suite's user
+func (suite *TodoControllerTestSuite) newContext(method string, target string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, suite.user)
+
+	return context, response
+}
+
+func (suite *TodoControllerTestSuite) TestStoreValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/todos", `{
+		"title": "",
+		"due_at": "tomorrow",
+		"timezone": "Mars/Olympus"
+	}`)
+
+	assert.NoError(test.Serve(context, suite.todo.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.NotEmpty(err["title"])
+		assert.NotEmpty(err["due_at"])
+		assert.NotEmpty(err["timezone"])
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestStoreReadsDueDateInTimezone() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/todos", `{
+		"title": "Ship release",
+		"due_at": "2026-01-02T09:00",
+		"timezone": "Asia/Manila"
+	}`)
+	suite.todos.On("Create", mock.AnythingOfType("*models.Todo")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.todo.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		// the repository is scoped to the workspace before the todo is created
+		todo := suite.todos.Calls[1].Arguments.Get(0).(*models.Todo)
+		assert.Equal(uint(1), todo.UserID)
+		assert.True(time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC).Equal(*todo.DueAt))
+
+		data := test.GetResponseData(response)
+		assert.Equal("2026-01-02T09:00:00+08:00", data["due_at"])
+		assert.Equal("Asia/Manila", data["timezone"])
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestUpdateRecordsTheChangeInTheJournal() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship release", "completed": true, "version": 1}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 1})
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.todo.Update))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		op := suite.journal.Calls[0].Arguments.Get(0).(*models.Operation)
+		assert.Equal(models.OperationComplete, op.Kind)
+		assert.Equal(uint(2), op.SubjectID)
+		assert.Contains(op.Before, `"completed":false`)
+		assert.Contains(op.After, `"completed":true`)
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestIndexCountsComments() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByUser", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release"},
+		{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Pay rent"},
+	})
+	suite.comments.On("Counts", []uint{2, 3}).Return(map[uint]int64{2: 4})
+
+	context, response := suite.newContext(echo.GET, "/todos", "")
+	assert.NoError(test.Serve(context, suite.todo.Index))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data []todoResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if assert.Len(body.Data, 2) {
+		assert.Equal(int64(4), body.Data[0].Comments)
+		assert.Equal(int64(0), body.Data[1].Comments)
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestIndexSortsByPriority() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByUser", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Water plants", Priority: models.PriorityLow},
+		{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Fix outage", Priority: models.PriorityUrgent},
+		{Model: gorm.Model{ID: 4}, UserID: 1, Title: "Read book"},
+		{Model: gorm.Model{ID: 5}, UserID: 1, Title: "Pay rent", Priority: models.PriorityUrgent},
+	})
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+
+	context, response := suite.newContext(echo.GET, "/todos?sort=priority", "")
+	assert.NoError(test.Serve(context, suite.todo.Index))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+	var body struct {
+		Data []todoResponse `json:"data"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+	var ids []uint
+	for _, t := range body.Data {
+		ids = append(ids, t.ID)
+	}
+	assert.Equal([]uint{3, 5, 2, 4}, ids)
+	assert.Equal("urgent", body.Data[0].Priority)
+	assert.Equal(models.PriorityUrgent, body.Data[0].PriorityValue)
+
+	context, response = suite.newContext(echo.GET, "/todos?sort=title", "")
+	assert.NoError(test.Serve(context, suite.todo.Index))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+}
+
+func (suite *TodoControllerTestSuite) TestUpdateCompletingBlockedTodo() {
+	assert := assert.New(suite.T())
+
+	todo := &models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 1,
+		Blockers: []models.TodoDependency{{TodoID: 2, BlockerID: 3}}}
+	suite.todos.On("ByID", uint(2)).Return(todo)
+	suite.todos.On("OpenBlockers", uint(2)).Return([]models.Todo{{Model: gorm.Model{ID: 3}, UserID: 1}})
+	suite.todos.On("Update", todo).Return(nil)
+
+	context, response := suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship release", "completed": true, "version": 1}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Update))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Contains(test.GetResponseErrors(response), "completed")
+	}
+	suite.todos.AssertNotCalled(suite.T(), "Update", mock.Anything)
+
+	context, response = suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship release", "completed": true, "ignore_blockers": true, "version": 1}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Update))
+
+	assert.Equal(http.StatusOK, response.Code)
+	suite.todos.AssertCalled(suite.T(), "Update", todo)
+}
+
+func (suite *TodoControllerTestSuite) TestSortTopological() {
+	assert := assert.New(suite.T())
+
+	blockedBy := func(ids ...uint) []models.TodoDependency {
+		var deps []models.TodoDependency
+		for _, id := range ids {
+			deps = append(deps, models.TodoDependency{BlockerID: id})
+		}
+		return deps
+	}
+	todos := []models.Todo{
+		{Model: gorm.Model{ID: 1}, Blockers: blockedBy(3)},
+		{Model: gorm.Model{ID: 2}},
+		{Model: gorm.Model{ID: 3}, Blockers: blockedBy(4, 99)},
+		{Model: gorm.Model{ID: 4}},
+		{Model: gorm.Model{ID: 5}, Blockers: blockedBy(1, 2)},
+	}
+	sortTodos(todos, "topological")
+
+	var ids []uint
+	for _, t := range todos {
+		ids = append(ids, t.ID)
+	}
+	assert.Equal([]uint{2, 4, 3, 1, 5}, ids)
+}
+
+func (suite *TodoControllerTestSuite) TestShowTodoOfAnotherUser() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.GET, "/todos/2", "")
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 99})
+
+	assert.NoError(test.Serve(context, suite.todo.Show))
+
+	if assert.Equal(http.StatusNotFound, response.Code) {
+		problem := test.GetResponseProblem(response)
+		assert.Equal("Todo not found", problem["detail"])
+		assert.Equal("todo_not_found", problem["code"])
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestShowTodoOfSharedList() {
+	assert := assert.New(suite.T())
+
+	// alice is a member of the list bob shared
+	listID := uint(7)
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 2, ListID: &listID, Title: "Ship release", Version: 3})
+	suite.lists.On("ByID", listID).Return(&models.List{Model: gorm.Model{ID: listID}, UserID: 2})
+	suite.members.On("IsMember", listID, uint(1)).Return(true)
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+
+	context, response := suite.newContext(echo.GET, "/todos/2", "")
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Show))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal("Ship release", test.GetResponseData(response)["title"])
+	}
+
+	// only bob changes it
+	context, response = suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship it", "version": 3}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Update))
+
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *TodoControllerTestSuite) TestShowNotModified() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Version: 3})
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+
+	context, response := suite.newContext(echo.GET, "/todos/2", "")
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	context.Request().Header.Set("If-None-Match", `"2"`)
+	assert.NoError(test.Serve(context, suite.todo.Show))
+
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal(`"3"`, response.Header().Get("ETag"))
+
+	context, response = suite.newContext(echo.GET, "/todos/2", "")
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	context.Request().Header.Set("If-None-Match", `"3"`)
+	assert.NoError(test.Serve(context, suite.todo.Show))
+
+	assert.Equal(http.StatusNotModified, response.Code)
+	assert.Empty(response.Body.String())
+}
+
+func (suite *TodoControllerTestSuite) TestUpdateChecksTheVersion() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 3})
+
+	// the version is required
+	context, response := suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship it"}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Update))
+	assert.Equal(http.StatusPreconditionRequired, response.Code)
+
+	// an outdated one gets the current todo, weak tags never match
+	for _, etag := range []string{`"2"`, `W/"3"`} {
+		context, response = suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship it"}`)
+		context.SetParamNames("id")
+		context.SetParamValues("2")
+		context.Request().Header.Set("If-Match", etag)
+		assert.NoError(test.Serve(context, suite.todo.Update))
+
+		if assert.Equal(http.StatusPreconditionFailed, response.Code, etag) {
+			assert.Equal(`"3"`, response.Header().Get("ETag"))
+			assert.Equal("Ship release", test.GetResponseData(response)["title"])
+			assert.Equal("version_mismatch", test.GetResponseProblem(response)["code"])
+		}
+	}
+
+	// so does the version field
+	context, response = suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship it", "version": 2}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	assert.NoError(test.Serve(context, suite.todo.Update))
+	assert.Equal(http.StatusPreconditionFailed, response.Code)
+
+	suite.todos.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *TodoControllerTestSuite) TestUpdateChangedInTheMeantime() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 3}).Once()
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship v2", Version: 4})
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(repositories.ErrVersionConflict)
+
+	context, response := suite.newContext(echo.PUT, "/todos/2", `{"title": "Ship it"}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	context.Request().Header.Set("If-Match", `"3"`)
+	assert.NoError(test.Serve(context, suite.todo.Update))
+
+	if assert.Equal(http.StatusPreconditionFailed, response.Code) {
+		assert.Equal(`"4"`, response.Header().Get("ETag"))
+		assert.Equal("Ship v2", test.GetResponseData(response)["title"])
+	}
+	suite.journal.AssertNotCalled(suite.T(), "Record", mock.Anything)
+}
+
+// newPatchContext creates a context patching the todo of ID with the
+// patch of the media type
+func (suite *TodoControllerTestSuite) newPatchContext(id string, mediaType string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	context, response := suite.newContext(echo.PATCH, "/todos/"+id, body)
+	context.Request().Header.Set(echo.HeaderContentType, mediaType)
+	context.SetParamNames("id")
+	context.SetParamValues(id)
+
+	return context, response
+}
+
+// updated returns the todo the repository was last asked to update
+func (suite *TodoControllerTestSuite) updated() *models.Todo {
+	var todo *models.Todo
+	for _, call := range suite.todos.Calls {
+		if call.Method == "Update" {
+			todo = call.Arguments.Get(0).(*models.Todo)
+		}
+	}
+	return todo
+}
+
+func (suite *TodoControllerTestSuite) TestPatchMergeClearsNullFields() {
+	assert := assert.New(suite.T())
+
+	due, estimate := time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC), 30
+	todo := &models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Description: "v2",
+		DueAt: &due, Timezone: "Asia/Manila", Priority: models.PriorityHigh, Estimate: &estimate, Version: 3}
+	suite.todos.On("ByID", uint(2)).Return(todo)
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+	suite.comments.On("Counts", mock.Anything).Return(map[uint]int64{})
+
+	context, response := suite.newPatchContext("2", "application/merge-patch+json; charset=utf-8",
+		`{"title": "Ship it", "due_at": null, "estimate": null, "version": 3}`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		updated := suite.updated()
+		assert.Equal("Ship it", updated.Title)
+		assert.Nil(updated.DueAt)
+		assert.Nil(updated.Estimate)
+		// the fields which are left out of the patch are kept
+		assert.Equal("v2", updated.Description)
+		assert.Equal("Asia/Manila", updated.Timezone)
+		assert.Equal(models.PriorityHigh, updated.Priority)
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestPatchJSONPatch() {
+	assert := assert.New(suite.T())
+
+	due := time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC)
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release",
+		DueAt: &due, Timezone: "Asia/Manila", Version: 3})
+	suite.todos.On("Update", mock.AnythingOfType("*models.Todo")).Return(nil)
+
+	// a failing test operation leaves the todo as it is
+	context, response := suite.newPatchContext("2", requests.MIMEJSONPatch, `[
+		{"op": "test", "path": "/title", "value": "Ship it"},
+		{"op": "replace", "path": "/completed", "value": true}
+	]`)
+	context.Request().Header.Set("If-Match", `"3"`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+	assert.Equal(http.StatusConflict, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Update", mock.Anything)
+
+	context, response = suite.newPatchContext("2", requests.MIMEJSONPatch, `[
+		{"op": "test", "path": "/due_at", "value": "2026-01-02T09:00:00+08:00"},
+		{"op": "replace", "path": "/completed", "value": true},
+		{"op": "replace", "path": "/due_at", "value": null}
+	]`)
+	context.Request().Header.Set("If-Match", `"3"`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		updated := suite.updated()
+		assert.True(updated.Completed)
+		assert.Nil(updated.DueAt)
+		assert.Equal("Ship release", updated.Title)
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestPatchValidatesThePatchedTodo() {
+	assert := assert.New(suite.T())
+
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release", Version: 3})
+
+	// the title is required, removing it is as invalid as leaving it empty
+	context, response := suite.newPatchContext("2", requests.MIMEMergePatch, `{"title": null, "priority": "soon", "version": 3}`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errs := test.GetResponseErrors(response)
+		assert.Contains(errs, "title")
+		assert.Contains(errs, "priority")
+	}
+
+	context, response = suite.newPatchContext("2", requests.MIMEJSONPatch, `[{"op": "remove", "path": "/nothing"}]`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+
+	context, response = suite.newPatchContext("2", echo.MIMEApplicationJSON, `{"title": "Ship it"}`)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+	assert.Equal(http.StatusUnsupportedMediaType, response.Code)
+
+	context, response = suite.newPatchContext("2", requests.MIMEMergePatch, `{"title": `)
+	assert.NoError(test.Serve(context, suite.todo.Patch))
+	assert.Equal(http.StatusBadRequest, response.Code)
+
+	suite.todos.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *TodoControllerTestSuite) TestStoreReminderRelativeToDueDate() {
+	assert := assert.New(suite.T())
+
+	due := time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC)
+	context, response := suite.newContext(echo.POST, "/todos/2/reminders", `{
+		"channel": "email",
+		"before": 15
+	}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, DueAt: &due})
+	suite.reminders.On("Create", mock.AnythingOfType("*models.Reminder")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.todo.StoreReminder))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		reminder := suite.reminders.Calls[0].Arguments.Get(0).(*models.Reminder)
+		assert.Equal(uint(2), reminder.TodoID)
+		assert.True(due.Add(-15 * time.Minute).Equal(*reminder.FireAt))
+	}
+}
+
+func (suite *TodoControllerTestSuite) TestStoreReminderRequiresATime() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/todos/2/reminders", `{"channel": "email"}`)
+	context.SetParamNames("id")
+	context.SetParamValues("2")
+	suite.todos.On("ByID", uint(2)).Return(&models.Todo{Model: gorm.Model{ID: 2}, UserID: 1})
+
+	assert.NoError(test.Serve(context, suite.todo.StoreReminder))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.NotEmpty(err["at"])
+	}
+	suite.reminders.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTodoControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TodoControllerTestSuite))
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// errFileRequired is returned when a multipart import has no file
+var errFileRequired = requests.NewFieldError("file", "validation.required", nil)
+
+// errUnknownFormat is returned when the format of a transfer is not
+// supported
+var errUnknownFormat = requests.NewFieldError("format", "validation.in", i18n.Args{
+	"values": "csv, json, todotxt, markdown, ics",
+})
+
+// TransferController imports and exports the todos of the authenticated user
+type TransferController struct {
+	importer *transfer.Importer
+	exporter *transfer.Exporter
+}
+
+// NewTransfer creates TransferController instance
+func NewTransfer(importer *transfer.Importer, exporter *transfer.Exporter) *TransferController {
+	return &TransferController{importer, exporter}
+}
+
+// Export downloads the todos of the workspace in the format of the format
+// query parameter, JSON by default
+// GET /todos/export
+func (xc *TransferController) Export(ctx echo.Context) error {
+	format := transfer.FormatJSON
+	if name := ctx.QueryParam("format"); name != "" {
+		f, err := transfer.ParseFormat(name)
+		if err != nil {
+			return apperrors.From(http.StatusUnprocessableEntity, errUnknownFormat)
+		}
+		format = f
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+format.Filename()+`"`)
+	res.WriteHeader(http.StatusOK)
+
+	return xc.exporter.Workspace(auth.WorkspaceID(ctx)).Export(auth.User(ctx).ID, format, res)
+}
+
+// Import creates todos in the workspace from the file sent as the request body or
+// as the file field of a multipart form. The format is taken from
+// the format query parameter, the file name or the content type.
+// With dry_run=true nothing is created, and allow_duplicates=true
+// creates todos which already exist.
+// POST /todos/import
+func (xc *TransferController) Import(ctx echo.Context) error {
+	body, filename, err := importFile(ctx.Request())
+	if err != nil {
+		return apperrors.From(http.StatusUnprocessableEntity, err)
+	}
+
+	opts := transfer.Options{Timezone: ctx.QueryParam("timezone")}
+	opts.DryRun, _ = strconv.ParseBool(ctx.QueryParam("dry_run"))
+	opts.AllowDuplicates, _ = strconv.ParseBool(ctx.QueryParam("allow_duplicates"))
+
+	opts.Format, err = importFormat(ctx.QueryParam("format"), filename, ctx.Request().Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return apperrors.From(http.StatusUnprocessableEntity, errUnknownFormat)
+	}
+	if _, err := time.LoadLocation(opts.Timezone); err != nil {
+		return apperrors.From(http.StatusUnprocessableEntity, requests.NewFieldError("timezone", "validation.timezone", nil))
+	}
+
+	report, err := xc.importer.Workspace(auth.WorkspaceID(ctx)).Import(auth.User(ctx).ID, body, opts)
+	var decodeErr *transfer.DecodeError
+	switch {
+	case errors.Is(err, transfer.ErrTooLarge), errors.Is(err, transfer.ErrTooManyRows):
+		return apperrors.From(http.StatusRequestEntityTooLarge, err)
+	case errors.As(err, &decodeErr):
+		return apperrors.From(http.StatusUnprocessableEntity, fileError(err, opts.Format))
+	case err != nil:
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	code := http.StatusOK
+	if !report.DryRun && report.Created > 0 {
+		code = http.StatusCreated
+		audit.Log(ctx, audit.TodosImported, "todo", 0, nil, map[string]interface{}{
+			"format":  report.Format,
+			"created": report.Created,
+		})
+	}
+	return ctx.JSON(code, NewResponseData(report))
+}
+
+// importFile returns the file to import and its name. A multipart
+// form is streamed, so the file is never held in memory.
+func importFile(req *http.Request) (io.Reader, string, error) {
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return req.Body, "", nil
+	}
+
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, "", errFileRequired
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, "", errFileRequired
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// fileError is the validation error of an imported file which can't
+// be read in its format
+func fileError(err error, format transfer.Format) error {
+	switch {
+	case errors.Is(err, transfer.ErrCSVHeader):
+		return requests.NewFieldError("file", "validation.csv_header", nil)
+	case errors.Is(err, transfer.ErrJSONArray):
+		return requests.NewFieldError("file", "validation.json_array", nil)
+	case errors.Is(err, transfer.ErrICalendar):
+		return requests.NewFieldError("file", "validation.icalendar", nil)
+	}
+	return requests.NewFieldError("file", "validation.unreadable", i18n.Args{"format": string(format)})
+}
+
+// importFormat resolves the format of an imported file
+func importFormat(name string, filename string, contentType string) (transfer.Format, error) {
+	if name != "" {
+		return transfer.ParseFormat(name)
+	}
+	if format, err := transfer.FormatFromFilename(filename); err == nil {
+		return format, nil
+	}
+	return transfer.FormatFromContentType(contentType)
+}
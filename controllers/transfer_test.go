@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/ksungcaya/todo-echo/transfer"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TransferControllerTestSuite struct {
+	suite.Suite
+	repo     *mocks.TodoRepository
+	transfer *TransferController
+	server   *echo.Echo
+}
+
+func (suite *TransferControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.TodoRepository{}
+	suite.repo.On("Workspace", mock.Anything).Return(suite.repo)
+	suite.repo.On("Transaction", mock.Anything).Return(func(fn func(repositories.TodoRepository) error) error {
+		return fn(suite.repo)
+	})
+	suite.repo.On("ByUser", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 1}, UserID: 1, Title: "Pay rent", Completed: true},
+	})
+
+	limits := configs.ImportConfig{MaxBytes: 1024, MaxRows: 10}
+	suite.transfer = NewTransfer(transfer.NewImporter(suite.repo, limits), transfer.NewExporter(suite.repo))
+	suite.server = echo.New()
+}
+
+func (suite *TransferControllerTestSuite) TestExport() {
+	assert := assert.New(suite.T())
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.GET, "/todos/export?format=md", nil))
+
+	assert.NoError(test.Serve(context, suite.transfer.Export))
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("text/markdown; charset=UTF-8", response.Header().Get(echo.HeaderContentType))
+	assert.Contains(response.Header().Get(echo.HeaderContentDisposition), "todos.md")
+	assert.Equal("- [x] Pay rent\n", response.Body.String())
+}
+
+func (suite *TransferControllerTestSuite) TestExportUnknownFormat() {
+	assert := assert.New(suite.T())
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.GET, "/todos/export?format=xlsx", nil))
+
+	assert.NoError(test.Serve(context, suite.transfer.Export))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+	assert.NotEmpty(test.GetResponseErrors(response)["format"])
+}
+
+func (suite *TransferControllerTestSuite) TestImportDryRunFromMultipartForm() {
+	assert := assert.New(suite.T())
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "todo.txt")
+	file.Write([]byte("x Pay rent\nCall mom due:2026-01-05\n(B) \n"))
+	form.Close()
+
+	req := httptest.NewRequest(echo.POST, "/todos/import?dry_run=true", &body)
+	req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+	context, response := newUserContext(suite.server, req)
+
+	assert.NoError(test.Serve(context, suite.transfer.Import))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal(string(transfer.FormatTodoTxt), data["format"])
+		assert.Equal(float64(1), data["created"])
+		assert.Equal(float64(1), data["duplicates"])
+		assert.Equal(float64(1), data["invalid"])
+
+		rows := data["rows"].([]interface{})
+		invalid := rows[2].(map[string]interface{})
+		assert.Equal(float64(3), invalid["row"])
+		assert.NotEmpty(invalid["errors"].(map[string]interface{})["title"])
+	}
+	suite.repo.AssertNotCalled(suite.T(), "Create")
+}
+
+func (suite *TransferControllerTestSuite) TestImportFailingToCreate() {
+	assert := assert.New(suite.T())
+
+	suite.repo.On("Create", mock.AnythingOfType("*models.Todo")).Return(errors.New("Error 1213: Deadlock found"))
+
+	req := httptest.NewRequest(echo.POST, "/todos/import", strings.NewReader("Call mom\n"))
+	req.Header.Set(echo.HeaderContentType, "text/plain")
+	context, response := newUserContext(suite.server, req)
+
+	assert.NoError(test.Serve(context, suite.transfer.Import))
+
+	// the file is fine and the cause is kept from the client
+	if assert.Equal(http.StatusInternalServerError, response.Code) {
+		assert.Equal("internal_error", test.GetResponseProblem(response)["code"])
+		assert.NotContains(response.Body.String(), "Deadlock")
+	}
+}
+
+func (suite *TransferControllerTestSuite) TestImportTooLarge() {
+	assert := assert.New(suite.T())
+
+	req := httptest.NewRequest(echo.POST, "/todos/import", strings.NewReader(strings.Repeat("Call mom\n", 200)))
+	req.Header.Set(echo.HeaderContentType, "text/plain")
+	context, response := newUserContext(suite.server, req)
+
+	assert.NoError(test.Serve(context, suite.transfer.Import))
+	assert.Equal(http.StatusRequestEntityTooLarge, response.Code)
+}
+
+func (suite *TransferControllerTestSuite) TestImportMalformedFile() {
+	assert := assert.New(suite.T())
+
+	req := httptest.NewRequest(echo.POST, "/todos/import", strings.NewReader(`[{"title": `))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Accept-Language", "es")
+	context, response := newUserContext(suite.server, req)
+
+	assert.NoError(test.Serve(context, suite.transfer.Import))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+	assert.Equal([]interface{}{"No se puede leer el archivo como json"}, test.GetResponseErrors(response)["file"])
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTransferControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferControllerTestSuite))
+}
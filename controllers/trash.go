@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// Types of the items in the trash
+const (
+	trashTodo = "todo"
+	trashList = "list"
+)
+
+// TrashController handles the deleted todos and lists of the user
+type TrashController struct {
+	tr        repositories.TodoRepository
+	lr        repositories.ListRepository
+	retention time.Duration
+}
+
+// trashItemResponse is a private struct for trash item response.
+// Todos deleted along with a list are counted in the list's item.
+type trashItemResponse struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	ListID    *uint     `json:"list_id,omitempty"`
+	Todos     int       `json:"todos,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// NewTrash creates TrashController instance. Items are permanently
+// deleted once they've been in the trash for the retention.
+func NewTrash(tr repositories.TodoRepository, lr repositories.ListRepository, retention time.Duration) *TrashController {
+	return &TrashController{tr, lr, retention}
+}
+
+// Index lists the items in the trash, the most recently deleted first
+// GET /trash
+func (tc *TrashController) Index(ctx echo.Context) error {
+	user := auth.User(ctx)
+	page := requests.NewPagination(ctx)
+
+	lists := listsOf(ctx, tc.lr).Trashed(user.ID)
+	items := make([]*trashItemResponse, 0, len(lists))
+	byList := make(map[uint]*trashItemResponse, len(lists))
+	for i := range lists {
+		item := tc.newItem(trashList, lists[i].ID, lists[i].Name, lists[i].DeletedAt.Time)
+		byList[lists[i].ID] = item
+		items = append(items, item)
+	}
+
+	for _, todo := range todosOf(ctx, tc.tr).Trashed(user.ID) {
+		if todo.ListID != nil {
+			if list, ok := byList[*todo.ListID]; ok && list.DeletedAt.Equal(todo.DeletedAt.Time) {
+				list.Todos++
+				continue
+			}
+		}
+
+		item := tc.newItem(trashTodo, todo.ID, todo.Title, todo.DeletedAt.Time)
+		item.ListID = todo.ListID
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	total := len(items)
+	from, to := page.Offset(), page.Offset()+page.PerPage
+	if from > total {
+		from = total
+	}
+	if to > total {
+		to = total
+	}
+
+	meta := &paginationMeta{Pagination: page, Total: int64(total)}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(items[from:to], meta))
+}
+
+// RestoreTodo takes a todo out of the trash along with its reminders
+// POST /trash/todos/:id/restore
+func (tc *TrashController) RestoreTodo(ctx echo.Context) error {
+	todo := tc.trashedTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+	if err := todosOf(ctx, tc.tr).Restore(todo); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.TodoRestored, "todo", todo.ID, nil, models.NewTodoSnapshot(todo))
+	if restored := todosOf(ctx, tc.tr).ByID(todo.ID); restored != nil {
+		todo = restored
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newTodoResponse(todo)))
+}
+
+// RestoreList takes a list out of the trash along with the todos
+// which were deleted with it
+// POST /trash/lists/:id/restore
+func (tc *TrashController) RestoreList(ctx echo.Context) error {
+	list := tc.trashedList(ctx)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+	if err := listsOf(ctx, tc.lr).Restore(list); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, events.ListRestored, "list", list.ID, nil, models.NewListSnapshot(list))
+	return ctx.JSON(http.StatusOK, NewResponseData(newListResponse(list)))
+}
+
+// DestroyTodo permanently deletes a todo in the trash
+// DELETE /trash/todos/:id
+func (tc *TrashController) DestroyTodo(ctx echo.Context) error {
+	todo := tc.trashedTodo(ctx)
+	if todo == nil {
+		return apperrors.From(http.StatusNotFound, errTodoNotFound)
+	}
+	if err := todosOf(ctx, tc.tr).ForceDelete(todo.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.TodoDestroyed, "todo", todo.ID, models.NewTodoSnapshot(todo), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DestroyList permanently deletes a list in the trash and its todos
+// DELETE /trash/lists/:id
+func (tc *TrashController) DestroyList(ctx echo.Context) error {
+	list := tc.trashedList(ctx)
+	if list == nil {
+		return apperrors.From(http.StatusNotFound, errListNotFound)
+	}
+	if err := listsOf(ctx, tc.lr).ForceDelete(list.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.ListDestroyed, "list", list.ID, models.NewListSnapshot(list), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Empty permanently deletes everything in the trash
+// DELETE /trash
+func (tc *TrashController) Empty(ctx echo.Context) error {
+	user := auth.User(ctx)
+
+	lists := listsOf(ctx, tc.lr).Trashed(user.ID)
+	for _, list := range lists {
+		if err := listsOf(ctx, tc.lr).ForceDelete(list.ID); err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+	}
+	todos := todosOf(ctx, tc.tr).Trashed(user.ID)
+	for _, todo := range todos {
+		if err := todosOf(ctx, tc.tr).ForceDelete(todo.ID); err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+	}
+
+	audit.Log(ctx, audit.TrashEmptied, "trash", 0, nil, map[string]int{"lists": len(lists), "todos": len(todos)})
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// trashedTodo looks up the todo in the trash from the :id param which the user owns
+func (tc *TrashController) trashedTodo(ctx echo.Context) *models.Todo {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	todo := todosOf(ctx, tc.tr).TrashedByID(uint(id))
+	if todo == nil || todo.UserID != auth.User(ctx).ID {
+		return nil
+	}
+	return todo
+}
+
+// trashedList looks up the list in the trash from the :id param which the user owns
+func (tc *TrashController) trashedList(ctx echo.Context) *models.List {
+	id, _ := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	list := listsOf(ctx, tc.lr).TrashedByID(uint(id))
+	if list == nil || list.UserID != auth.User(ctx).ID {
+		return nil
+	}
+	return list
+}
+
+// newItem is a private function for creating *trashItemResponse
+func (tc *TrashController) newItem(itemType string, id uint, title string, deletedAt time.Time) *trashItemResponse {
+	return &trashItemResponse{
+		Type:      itemType,
+		ID:        id,
+		Title:     title,
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(tc.retention),
+	}
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TrashControllerTestSuite struct {
+	suite.Suite
+	todos  *mocks.TodoRepository
+	lists  *mocks.ListRepository
+	trash  *TrashController
+	server *echo.Echo
+}
+
+func (suite *TrashControllerTestSuite) SetupTest() {
+	suite.todos = &mocks.TodoRepository{}
+	suite.todos.On("Workspace", mock.Anything).Return(suite.todos)
+	suite.lists = &mocks.ListRepository{}
+	suite.lists.On("Workspace", mock.Anything).Return(suite.lists)
+	suite.trash = NewTrash(suite.todos, suite.lists, 30*24*time.Hour)
+	suite.server = echo.New()
+}
+
+func (suite *TrashControllerTestSuite) TestIndexGroupsTodosDeletedWithTheirList() {
+	assert := assert.New(suite.T())
+
+	listDeleted := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+	todoDeleted := listDeleted.Add(-time.Hour)
+	listID := uint(7)
+	deletedAt := func(at time.Time) gorm.DeletedAt {
+		return gorm.DeletedAt{Time: at, Valid: true}
+	}
+
+	suite.lists.On("Trashed", uint(1)).Return([]models.List{
+		{Model: gorm.Model{ID: listID, DeletedAt: deletedAt(listDeleted)}, UserID: 1, Name: "Groceries"},
+	})
+	suite.todos.On("Trashed", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 1, DeletedAt: deletedAt(listDeleted)}, UserID: 1, ListID: &listID, Title: "Milk"},
+		{Model: gorm.Model{ID: 2, DeletedAt: deletedAt(listDeleted)}, UserID: 1, ListID: &listID, Title: "Bread"},
+		{Model: gorm.Model{ID: 3, DeletedAt: deletedAt(todoDeleted)}, UserID: 1, ListID: &listID, Title: "Eggs"},
+	})
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.GET, "/trash", nil))
+	assert.NoError(test.Serve(context, suite.trash.Index))
+
+	if !assert.Equal(http.StatusOK, response.Code) {
+		return
+	}
+
+	var body struct {
+		Data []trashItemResponse `json:"data"`
+		Meta struct {
+			Total int `json:"total"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &body)
+
+	assert.Equal(2, body.Meta.Total)
+	if assert.Len(body.Data, 2) {
+		assert.Equal(trashList, body.Data[0].Type)
+		assert.Equal(2, body.Data[0].Todos)
+		assert.True(listDeleted.AddDate(0, 0, 30).Equal(body.Data[0].PurgeAt))
+
+		assert.Equal(trashTodo, body.Data[1].Type)
+		assert.Equal(uint(3), body.Data[1].ID)
+		assert.Equal(&listID, body.Data[1].ListID)
+	}
+}
+
+func (suite *TrashControllerTestSuite) TestRestoreOthersTodo() {
+	assert := assert.New(suite.T())
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.POST, "/trash/todos/5/restore", nil))
+	context.SetParamNames("id")
+	context.SetParamValues("5")
+	suite.todos.On("TrashedByID", uint(5)).Return(&models.Todo{Model: gorm.Model{ID: 5}, UserID: 2})
+
+	assert.NoError(test.Serve(context, suite.trash.RestoreTodo))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.todos.AssertNotCalled(suite.T(), "Restore", mock.Anything)
+}
+
+func (suite *TrashControllerTestSuite) TestDestroyList() {
+	assert := assert.New(suite.T())
+
+	context, response := newUserContext(suite.server, httptest.NewRequest(echo.DELETE, "/trash/lists/7", nil))
+	context.SetParamNames("id")
+	context.SetParamValues("7")
+	suite.lists.On("TrashedByID", uint(7)).Return(&models.List{Model: gorm.Model{ID: 7}, UserID: 1})
+	suite.lists.On("ForceDelete", uint(7)).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.trash.DestroyList))
+
+	assert.Equal(http.StatusNoContent, response.Code)
+	suite.lists.AssertCalled(suite.T(), "ForceDelete", uint(7))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTrashControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TrashControllerTestSuite))
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// UserController handles the profile of the authenticated user
+type UserController struct {
+	ur repositories.UserRepository
+}
+
+// NewUser creates UserController instance
+func NewUser(ur repositories.UserRepository) *UserController {
+	return &UserController{ur}
+}
+
+// Show displays the profile of the user along with the ETag of its
+// version, it is not sent again while If-None-Match has that version
+// GET /user
+func (uc *UserController) Show(ctx echo.Context) error {
+	user := auth.User(ctx)
+	if notModified(ctx, user.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// Update changes the profile of the user. The version it is made to
+// must be given by If-Match or the version field.
+// PUT /user
+func (uc *UserController) Update(ctx echo.Context) error {
+	user := auth.User(ctx)
+
+	ur := &requests.UserRequest{ID: user.ID}
+	if code, err := ur.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	return uc.update(ctx, user, ur)
+}
+
+// Patch changes the profile of the user with a JSON merge patch or a
+// JSON patch of it, the patched profile is validated as a whole
+// PATCH /user
+func (uc *UserController) Patch(ctx echo.Context) error {
+	user := auth.User(ctx)
+
+	ur := new(requests.UserRequest)
+	if code, err := ur.Patch(ctx, user); err != nil {
+		return apperrors.From(code, err)
+	}
+	return uc.update(ctx, user, ur)
+}
+
+// update makes the changes of the request to the user, provided they
+// are made to its current version
+func (uc *UserController) update(ctx echo.Context, user *models.User, ur *requests.UserRequest) error {
+	if code, err := checkVersion(ctx, user.Version, ur.Version); err != nil {
+		return versionError(ctx, code, err, user.Version, newUserResponse(user).Data)
+	}
+
+	before := newAuditUser(user)
+	ur.Fill(user)
+	if err := uc.ur.Update(user); err != nil {
+		if err == repositories.ErrVersionConflict {
+			if current := uc.ur.ByID(user.ID); current != nil {
+				return versionError(ctx, http.StatusPreconditionFailed, errVersionMismatch, current.Version, newUserResponse(current).Data)
+			}
+		}
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	audit.Log(ctx, audit.ProfileUpdated, "user", user.ID, before, newAuditUser(user))
+	withVersion(ctx, user.Version)
+	return ctx.JSON(http.StatusOK, newUserResponse(user))
+}
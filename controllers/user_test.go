@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type UserControllerTestSuite struct {
+	suite.Suite
+	users  *mocks.UserRepository
+	user   *UserController
+	server *echo.Echo
+	alice  *models.User
+}
+
+func (suite *UserControllerTestSuite) SetupTest() {
+	suite.users = &mocks.UserRepository{}
+	requests.RegisterUnique("users", suite.users)
+	suite.user = NewUser(suite.users)
+	suite.server = echo.New()
+	suite.alice = &models.User{Model: gorm.Model{ID: 1}, Username: "alice", Email: "alice@realworld.io", Name: "Alice Wonder", Version: 2}
+}
+
+// newContext creates a context authenticated as alice
+func (suite *UserControllerTestSuite) newContext(method string, mediaType string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/user", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, mediaType)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, suite.alice)
+
+	return context, response
+}
+
+func (suite *UserControllerTestSuite) TestShowNotModified() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.GET, echo.MIMEApplicationJSON, "")
+	context.Request().Header.Set("If-None-Match", `"2"`)
+	assert.NoError(test.Serve(context, suite.user.Show))
+	assert.Equal(http.StatusNotModified, response.Code)
+
+	context, response = suite.newContext(echo.GET, echo.MIMEApplicationJSON, "")
+	context.Request().Header.Set("If-None-Match", `"1"`)
+	assert.NoError(test.Serve(context, suite.user.Show))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal(`"2"`, response.Header().Get("ETag"))
+		assert.Equal("alice", test.GetResponseData(response)["username"])
+	}
+}
+
+func (suite *UserControllerTestSuite) TestPatch() {
+	assert := assert.New(suite.T())
+
+	suite.users.On("Taken", mock.Anything, mock.Anything, uint(1)).Return(false)
+	suite.users.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+
+	context, response := suite.newContext(echo.PATCH, requests.MIMEMergePatch, `{"email": "alice@wonder.land", "version": 2}`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal("alice@wonder.land", data["email"])
+		assert.Equal("Alice Wonder", data["name"])
+	}
+	suite.users.AssertCalled(suite.T(), "Taken", "email", "alice@wonder.land", uint(1))
+	suite.users.AssertCalled(suite.T(), "Update", suite.alice)
+}
+
+func (suite *UserControllerTestSuite) TestPatchLocale() {
+	assert := assert.New(suite.T())
+
+	suite.users.On("Taken", mock.Anything, mock.Anything, uint(1)).Return(false)
+	suite.users.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+
+	context, response := suite.newContext(echo.PATCH, requests.MIMEMergePatch, `{"locale": "fr", "version": 2}`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Equal([]interface{}{"The language field must be one of en, es"}, test.GetResponseErrors(response)["locale"])
+	}
+
+	context, response = suite.newContext(echo.PATCH, requests.MIMEMergePatch, `{"locale": "es", "version": 2}`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal("es", test.GetResponseData(response)["locale"])
+	}
+	assert.Equal("es", suite.alice.Locale)
+}
+
+func (suite *UserControllerTestSuite) TestPatchValidation() {
+	assert := assert.New(suite.T())
+
+	suite.users.On("Taken", "username", "bob", uint(1)).Return(true)
+	suite.users.On("Taken", mock.Anything, mock.Anything, uint(1)).Return(false)
+
+	// the name is required, an explicit null clears it
+	context, response := suite.newContext(echo.PATCH, requests.MIMEJSONPatch, `[
+		{"op": "replace", "path": "/name", "value": null},
+		{"op": "replace", "path": "/email", "value": "alice"}
+	]`)
+	context.Request().Header.Set("If-Match", `"2"`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		errs := test.GetResponseErrors(response)
+		assert.Contains(errs, "name")
+		assert.Contains(errs, "email")
+	}
+
+	// the username is taken by another user
+	context, response = suite.newContext(echo.PATCH, requests.MIMEMergePatch, `{"username": "bob"}`)
+	context.Request().Header.Set("If-Match", `"2"`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		assert.Equal([]interface{}{"The username has already been taken"}, test.GetResponseErrors(response)["username"])
+	}
+
+	// and the version is required
+	context, response = suite.newContext(echo.PATCH, requests.MIMEMergePatch, `{"name": "Alice Liddell"}`)
+	assert.NoError(test.Serve(context, suite.user.Patch))
+	assert.Equal(http.StatusPreconditionRequired, response.Code)
+
+	suite.users.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestUserControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(UserControllerTestSuite))
+}
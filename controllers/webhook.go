@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/ksungcaya/todo-echo/webhooks"
+	"github.com/labstack/echo/v4"
+)
+
+// errWebhookNotFound is returned when the webhook does not
+// exist or belongs to another user
+var errWebhookNotFound = apperrors.New(http.StatusNotFound, "webhook_not_found")
+
+// WebhookController handles the webhooks of the authenticated user in
+// the workspace of the request
+type WebhookController struct {
+	wr         repositories.WebhookRepository
+	dispatcher *webhooks.Dispatcher
+}
+
+// webhookResponse is a private struct for webhook response.
+// The secret is only shown once, right after creating it.
+type webhookResponse struct {
+	ID           uint       `json:"id"`
+	URL          string     `json:"url"`
+	Events       []string   `json:"events"`
+	Description  string     `json:"description"`
+	Active       bool       `json:"active"`
+	FailureCount int        `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at"`
+	Secret       string     `json:"secret,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// deliveryResponse is a private struct for webhook delivery response
+type deliveryResponse struct {
+	ID             uint            `json:"id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	Payload        json.RawMessage `json:"payload"`
+	ResponseStatus int             `json:"response_status"`
+	ResponseBody   string          `json:"response_body"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// NewWebhook creates WebhookController instance
+func NewWebhook(wr repositories.WebhookRepository, dispatcher *webhooks.Dispatcher) *WebhookController {
+	return &WebhookController{wr, dispatcher}
+}
+
+// Index lists the webhooks of the user
+// GET /webhooks
+func (wc *WebhookController) Index(ctx echo.Context) error {
+	hooks := webhooksOf(ctx, wc.wr).ByUser(auth.User(ctx).ID)
+
+	res := make([]*webhookResponse, 0, len(hooks))
+	for i := range hooks {
+		res = append(res, newWebhookResponse(&hooks[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a webhook with a new signing secret
+// POST /webhooks
+func (wc *WebhookController) Store(ctx echo.Context) error {
+	wr := new(requests.WebhookRequest)
+	if code, err := wr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	webhook := wr.WebhookModel(auth.User(ctx).ID, webhooks.NewSecret())
+	if err := webhooksOf(ctx, wc.wr).Create(webhook); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.WebhookCreated, "webhook", webhook.ID, nil, newWebhookResponse(webhook))
+
+	res := newWebhookResponse(webhook)
+	res.Secret = webhook.Secret
+	return ctx.JSON(http.StatusCreated, NewResponseData(res))
+}
+
+// Show displays a webhook
+// GET /webhooks/:id
+func (wc *WebhookController) Show(ctx echo.Context) error {
+	webhook, err := wc.findWebhook(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newWebhookResponse(webhook)))
+}
+
+// Update updates a webhook, activating a disabled one resets its failures
+// PUT /webhooks/:id
+func (wc *WebhookController) Update(ctx echo.Context) error {
+	webhook, err := wc.findWebhook(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	wr := new(requests.WebhookRequest)
+	if code, err := wr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := newWebhookResponse(webhook)
+	wr.Fill(webhook)
+	if err := wc.wr.Update(webhook); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.WebhookUpdated, "webhook", webhook.ID, before, newWebhookResponse(webhook))
+	return ctx.JSON(http.StatusOK, NewResponseData(newWebhookResponse(webhook)))
+}
+
+// Destroy deletes a webhook along with its delivery history
+// DELETE /webhooks/:id
+func (wc *WebhookController) Destroy(ctx echo.Context) error {
+	webhook, err := wc.findWebhook(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+	if err := wc.wr.Delete(webhook.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.WebhookDeleted, "webhook", webhook.ID, newWebhookResponse(webhook), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Deliveries lists the delivery history of a webhook, newest first
+// GET /webhooks/:id/deliveries
+func (wc *WebhookController) Deliveries(ctx echo.Context) error {
+	webhook, err := wc.findWebhook(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	page := requests.NewPagination(ctx)
+	deliveries, total := wc.wr.Deliveries(webhook.ID, page.Offset(), page.PerPage)
+
+	res := make([]*deliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		res = append(res, newDeliveryResponse(&deliveries[i]))
+	}
+	meta := &paginationMeta{Pagination: page, Total: total}
+	return ctx.JSON(http.StatusOK, NewResponseDataWithMeta(res, meta))
+}
+
+// Redeliver queues the payload of a delivery to be sent again
+// POST /webhooks/:id/deliveries/:delivery/redeliver
+func (wc *WebhookController) Redeliver(ctx echo.Context) error {
+	webhook, err := wc.findWebhook(ctx)
+	if err != nil {
+		return apperrors.From(http.StatusNotFound, err)
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("delivery"), 10, 64)
+	previous := wc.wr.DeliveryByID(uint(id))
+	if previous == nil || previous.WebhookID != webhook.ID {
+		return apperrors.New(http.StatusNotFound, "delivery_not_found")
+	}
+
+	delivery, err := wc.dispatcher.Redeliver(previous, time.Now())
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.WebhookRedelivered, "webhook", webhook.ID, nil, map[string]uint{"delivery_id": previous.ID})
+	return ctx.JSON(http.StatusAccepted, NewResponseData(newDeliveryResponse(delivery)))
+}
+
+// findWebhook looks up the webhook from the :id param which the user owns
+func (wc *WebhookController) findWebhook(ctx echo.Context) (*models.Webhook, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, errWebhookNotFound
+	}
+
+	webhook := webhooksOf(ctx, wc.wr).ByID(uint(id))
+	if webhook == nil || webhook.UserID != auth.User(ctx).ID {
+		return nil, errWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// newWebhookResponse is a private function for creating *webhookResponse
+func newWebhookResponse(w *models.Webhook) *webhookResponse {
+	return &webhookResponse{
+		ID:           w.ID,
+		URL:          w.URL,
+		Events:       w.EventTypes(),
+		Description:  w.Description,
+		Active:       w.Active,
+		FailureCount: w.FailureCount,
+		DisabledAt:   w.DisabledAt,
+		CreatedAt:    w.CreatedAt,
+	}
+}
+
+// newDeliveryResponse is a private function for creating *deliveryResponse
+func newDeliveryResponse(d *models.WebhookDelivery) *deliveryResponse {
+	return &deliveryResponse{
+		ID:             d.ID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		Payload:        json.RawMessage(d.Payload),
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/ksungcaya/todo-echo/webhooks"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type WebhookControllerTestSuite struct {
+	suite.Suite
+	repo    *mocks.WebhookRepository
+	webhook *WebhookController
+	server  *echo.Echo
+	user    *models.User
+}
+
+func (suite *WebhookControllerTestSuite) SetupTest() {
+	suite.repo = &mocks.WebhookRepository{}
+	suite.repo.On("Workspace", mock.Anything).Return(suite.repo)
+	suite.webhook = NewWebhook(suite.repo, webhooks.NewDispatcher(suite.repo))
+	suite.server = echo.New()
+	suite.user = &models.User{Model: gorm.Model{ID: 1}, Username: "alice"}
+}
+
+// newContext creates a context authenticated as the suite's user
+func (suite *WebhookControllerTestSuite) newContext(method string, target string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	auth.SetUser(context, suite.user)
+
+	return context, response
+}
+
+func (suite *WebhookControllerTestSuite) TestStoreValidation() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/webhooks", `{"url": "not a url", "events": []}`)
+
+	assert.NoError(test.Serve(context, suite.webhook.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.NotEmpty(err["url"])
+		assert.NotEmpty(err["events"])
+	}
+}
+
+func (suite *WebhookControllerTestSuite) TestStoreRejectsUnknownEvents() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/webhooks", `{
+		"url": "https://example.com/hook",
+		"events": ["todo.created", "todo.exploded"]
+	}`)
+
+	assert.NoError(test.Serve(context, suite.webhook.Store))
+
+	if assert.Equal(http.StatusUnprocessableEntity, response.Code) {
+		err := test.GetResponseErrors(response)
+		assert.Contains(err["events"].([]interface{})[0], "todo.exploded")
+	}
+}
+
+func (suite *WebhookControllerTestSuite) TestStoreShowsTheSecretOnce() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/webhooks", `{
+		"url": "https://example.com/hook",
+		"events": ["todo.created", "todo.updated"]
+	}`)
+	suite.repo.On("Create", mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.webhook.Store))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		webhook := suite.repo.Calls[1].Arguments.Get(0).(*models.Webhook)
+		assert.Equal("todo.created,todo.updated", webhook.Events)
+		assert.True(webhook.Active)
+
+		data := test.GetResponseData(response)
+		assert.Equal(webhook.Secret, data["secret"])
+	}
+
+	// but never again
+	suite.repo.On("ByID", uint(1)).Return(&models.Webhook{Model: gorm.Model{ID: 1}, UserID: 1, Secret: "whsec_test"})
+	context, response = suite.newContext(echo.GET, "/webhooks/1", "")
+	context.SetParamNames("id")
+	context.SetParamValues("1")
+
+	assert.NoError(test.Serve(context, suite.webhook.Show))
+	assert.Nil(test.GetResponseData(response)["secret"])
+}
+
+func (suite *WebhookControllerTestSuite) TestRedeliver() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(echo.POST, "/webhooks/1/deliveries/5/redeliver", "")
+	context.SetParamNames("id", "delivery")
+	context.SetParamValues("1", "5")
+	suite.repo.On("ByID", uint(1)).Return(&models.Webhook{Model: gorm.Model{ID: 1}, UserID: 1})
+	suite.repo.On("DeliveryByID", uint(5)).Return(&models.WebhookDelivery{
+		Model:     gorm.Model{ID: 5},
+		WebhookID: 1,
+		EventType: "todo.created",
+		Payload:   `{"event":"todo.created"}`,
+		Status:    models.DeliveryFailed,
+	})
+	suite.repo.On("CreateDelivery", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+
+	assert.NoError(test.Serve(context, suite.webhook.Redeliver))
+
+	if assert.Equal(http.StatusAccepted, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal(models.DeliveryPending, data["status"])
+		assert.Equal("todo.created", data["payload"].(map[string]interface{})["event"])
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWebhookControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookControllerTestSuite))
+}
@@ -0,0 +1,409 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+)
+
+// inviteTokenPrefix tells the invite tokens apart from other secrets
+const inviteTokenPrefix = "inv_"
+
+// inviteTTL is how long an invite can be accepted for
+const inviteTTL = 7 * 24 * time.Hour
+
+var (
+	// errWorkspaceMemberNotFound is returned when the user is not a
+	// member of the workspace
+	errWorkspaceMemberNotFound = apperrors.New(http.StatusNotFound, "workspace_member_not_found")
+
+	// errInviteNotFound is returned when the invite does not exist,
+	// was accepted already or expired
+	errInviteNotFound = apperrors.New(http.StatusNotFound, "invite_not_found")
+
+	// errLastOwner is returned when the only owner of a workspace
+	// would leave it or stop being its owner
+	errLastOwner = requests.NewFieldError("role", "validation.last_owner", nil)
+
+	// errAlreadyWorkspaceMember is returned when inviting someone who
+	// is a member of the workspace already
+	errAlreadyWorkspaceMember = requests.NewFieldError("email", "validation.workspace_member", nil)
+)
+
+// WorkspaceController handles the workspaces, their members and
+// the invites to join them
+type WorkspaceController struct {
+	wr repositories.WorkspaceRepository
+	ir repositories.InviteRepository
+	ur repositories.UserRepository
+}
+
+// workspaceResponse is a private struct for workspace response along
+// with the role of the user in it
+type workspaceResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// workspaceMemberResponse is a private struct for workspace member response
+type workspaceMemberResponse struct {
+	ID       uint      `json:"id"`
+	Username string    `json:"username"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// inviteResponse is a private struct for invite response, the token
+// is only shown once when the invite is created
+type inviteResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Token     string    `json:"token,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWorkspace creates WorkspaceController instance
+func NewWorkspace(wr repositories.WorkspaceRepository, ir repositories.InviteRepository, ur repositories.UserRepository) *WorkspaceController {
+	return &WorkspaceController{wr, ir, ur}
+}
+
+// Index lists the workspaces the user is a member of, the personal
+// workspace first
+// GET /workspaces
+func (wc *WorkspaceController) Index(ctx echo.Context) error {
+	user := auth.User(ctx)
+	if _, err := wc.wr.Personal(user); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	memberships := wc.wr.ByUser(user.ID)
+	res := make([]*workspaceResponse, 0, len(memberships))
+	for i := range memberships {
+		res = append(res, newWorkspaceResponse(&memberships[i].Workspace, &memberships[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Store creates a workspace the user owns
+// POST /workspaces
+func (wc *WorkspaceController) Store(ctx echo.Context) error {
+	wr := new(requests.WorkspaceRequest)
+	if code, err := wr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	user := auth.User(ctx)
+	workspace := &models.Workspace{Name: wr.Name}
+	if err := wc.wr.Create(workspace, user.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	res := newWorkspaceResponse(workspace, &models.Membership{Role: models.WorkspaceOwner})
+	audit.Log(ctx, audit.WorkspaceCreated, "workspace", workspace.ID, nil, res)
+	return ctx.JSON(http.StatusCreated, NewResponseData(res))
+}
+
+// Show displays a workspace the user is a member of
+// GET /workspaces/:workspace
+func (wc *WorkspaceController) Show(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	workspace := wc.wr.ByID(membership.WorkspaceID)
+	if workspace == nil {
+		return apperrors.From(http.StatusNotFound, auth.ErrWorkspaceNotFound)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newWorkspaceResponse(workspace, membership)))
+}
+
+// Update renames a workspace, only its owners and admins can
+// PUT /workspaces/:workspace
+func (wc *WorkspaceController) Update(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	if !membership.CanManage() {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	workspace := wc.wr.ByID(membership.WorkspaceID)
+	if workspace == nil {
+		return apperrors.From(http.StatusNotFound, auth.ErrWorkspaceNotFound)
+	}
+
+	wr := new(requests.WorkspaceRequest)
+	if code, err := wr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+
+	before := newWorkspaceResponse(workspace, membership)
+	workspace.Name = wr.Name
+	if err := wc.wr.Update(workspace); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+
+	res := newWorkspaceResponse(workspace, membership)
+	audit.Log(ctx, audit.WorkspaceUpdated, "workspace", workspace.ID, before, res)
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// Members lists the members of a workspace, the first to join first
+// GET /workspaces/:workspace/members
+func (wc *WorkspaceController) Members(ctx echo.Context) error {
+	members := wc.wr.Members(auth.WorkspaceID(ctx))
+
+	res := make([]*workspaceMemberResponse, 0, len(members))
+	for i := range members {
+		res = append(res, newWorkspaceMemberResponse(&members[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// UpdateMember changes the role of a member, only the owners can and
+// the workspace must keep one of them
+// PUT /workspaces/:workspace/members/:user
+func (wc *WorkspaceController) UpdateMember(ctx echo.Context) error {
+	if auth.Membership(ctx).Role != models.WorkspaceOwner {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	member := wc.findMember(ctx)
+	if member == nil {
+		return apperrors.From(http.StatusNotFound, errWorkspaceMemberNotFound)
+	}
+
+	rr := new(requests.RoleRequest)
+	if code, err := rr.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if member.Role == models.WorkspaceOwner && rr.Role != models.WorkspaceOwner && wc.isLastOwner(member) {
+		return apperrors.From(http.StatusUnprocessableEntity, errLastOwner)
+	}
+
+	before := member.Role
+	member.Role = rr.Role
+	if err := wc.wr.UpdateMember(member); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.MemberRoleChanged, "workspace", member.WorkspaceID,
+		map[string]interface{}{"user_id": member.UserID, "role": before},
+		map[string]interface{}{"user_id": member.UserID, "role": member.Role})
+	return ctx.JSON(http.StatusOK, NewResponseData(newWorkspaceMemberResponse(member)))
+}
+
+// DestroyMember removes a member from a workspace, its owners and
+// admins can remove the other members and anyone can leave. Only an
+// owner can remove an owner, and the last one can't leave.
+// DELETE /workspaces/:workspace/members/:user
+func (wc *WorkspaceController) DestroyMember(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	member := wc.findMember(ctx)
+	if member == nil {
+		return apperrors.From(http.StatusNotFound, errWorkspaceMemberNotFound)
+	}
+
+	self := member.UserID == membership.UserID
+	if !self && (!membership.CanManage() || member.Role == models.WorkspaceOwner && membership.Role != models.WorkspaceOwner) {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	if member.Role == models.WorkspaceOwner && wc.isLastOwner(member) {
+		return apperrors.From(http.StatusUnprocessableEntity, errLastOwner)
+	}
+
+	if err := wc.wr.RemoveMember(member.WorkspaceID, member.UserID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.MemberRemoved, "workspace", member.WorkspaceID,
+		map[string]uint{"user_id": member.UserID}, nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Invites lists the pending invites of a workspace, only its owners
+// and admins can see them
+// GET /workspaces/:workspace/invites
+func (wc *WorkspaceController) Invites(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	if !membership.CanManage() {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	invites := wc.ir.Pending(membership.WorkspaceID, time.Now())
+	res := make([]*inviteResponse, 0, len(invites))
+	for i := range invites {
+		res = append(res, newInviteResponse(&invites[i]))
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(res))
+}
+
+// StoreInvite invites someone to join a workspace by email, only its
+// owners and admins can. The token to accept the invite with is only
+// shown in the response, it's up to the inviter to pass it on. The
+// personal workspaces are for their owner alone.
+// POST /workspaces/:workspace/invites
+func (wc *WorkspaceController) StoreInvite(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	workspace := wc.wr.ByID(membership.WorkspaceID)
+	if workspace == nil {
+		return apperrors.From(http.StatusNotFound, auth.ErrWorkspaceNotFound)
+	}
+	if !membership.CanManage() || workspace.Personal {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	ir := new(requests.InviteRequest)
+	if code, err := ir.Validate(ctx); err != nil {
+		return apperrors.From(code, err)
+	}
+	if user := wc.ur.ByEmail(ir.Email); user != nil && wc.wr.Membership(workspace.ID, user.ID) != nil {
+		return apperrors.From(http.StatusUnprocessableEntity, errAlreadyWorkspaceMember)
+	}
+
+	token := auth.NewToken(inviteTokenPrefix)
+	invite := &models.Invite{
+		WorkspaceID: workspace.ID,
+		InviterID:   membership.UserID,
+		Email:       ir.Email,
+		Role:        ir.Role,
+		TokenHash:   auth.HashToken(token),
+		ExpiresAt:   time.Now().Add(inviteTTL),
+	}
+	if err := wc.ir.Create(invite); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.InviteCreated, "invite", invite.ID, nil, newInviteResponse(invite))
+
+	res := newInviteResponse(invite)
+	res.Token = token
+	return ctx.JSON(http.StatusCreated, NewResponseData(res))
+}
+
+// DestroyInvite revokes an invite, only the owners and admins of its
+// workspace can
+// DELETE /workspaces/:workspace/invites/:invite
+func (wc *WorkspaceController) DestroyInvite(ctx echo.Context) error {
+	membership := auth.Membership(ctx)
+	if !membership.CanManage() {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+
+	id, _ := strconv.ParseUint(ctx.Param("invite"), 10, 64)
+	invite := wc.ir.ByID(uint(id))
+	if invite == nil || invite.WorkspaceID != membership.WorkspaceID {
+		return apperrors.From(http.StatusNotFound, errInviteNotFound)
+	}
+	if err := wc.ir.Delete(invite.ID); err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.InviteRevoked, "invite", invite.ID, newInviteResponse(invite), nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Accept makes the user a member of the workspace they were invited
+// to, with the role of the invite. The invite must have been sent to
+// their email address.
+// POST /invites/:token/accept
+func (wc *WorkspaceController) Accept(ctx echo.Context) error {
+	now := time.Now()
+	invite := wc.ir.ByTokenHash(auth.HashToken(ctx.Param("token")))
+	if invite == nil || !invite.IsPending(now) {
+		return apperrors.From(http.StatusNotFound, errInviteNotFound)
+	}
+
+	user := auth.User(ctx)
+	if !strings.EqualFold(invite.Email, user.Email) {
+		return apperrors.From(http.StatusForbidden, auth.ErrForbidden)
+	}
+	err := wc.ir.Accept(invite, user.ID, now)
+	if err == repositories.ErrInviteNotPending {
+		return apperrors.From(http.StatusNotFound, errInviteNotFound)
+	}
+	if err != nil {
+		return apperrors.From(http.StatusInternalServerError, err)
+	}
+	audit.Log(ctx, audit.InviteAccepted, "invite", invite.ID, nil, map[string]uint{"user_id": user.ID})
+
+	membership := wc.wr.Membership(invite.WorkspaceID, user.ID)
+	if membership == nil {
+		return apperrors.From(http.StatusNotFound, auth.ErrWorkspaceNotFound)
+	}
+	return ctx.JSON(http.StatusOK, NewResponseData(newWorkspaceResponse(&invite.Workspace, membership)))
+}
+
+// findMember looks up the member from the :user param in the
+// workspace of the request
+func (wc *WorkspaceController) findMember(ctx echo.Context) *models.Membership {
+	id, _ := strconv.ParseUint(ctx.Param("user"), 10, 64)
+	return wc.wr.Membership(auth.WorkspaceID(ctx), uint(id))
+}
+
+// isLastOwner determines if the member is the only owner of the workspace
+func (wc *WorkspaceController) isLastOwner(member *models.Membership) bool {
+	for _, m := range wc.wr.Members(member.WorkspaceID) {
+		if m.Role == models.WorkspaceOwner && m.UserID != member.UserID {
+			return false
+		}
+	}
+	return true
+}
+
+// todosOf scopes the todo repository to the workspace of the request
+func todosOf(ctx echo.Context, tr repositories.TodoRepository) repositories.TodoRepository {
+	return tr.Workspace(auth.WorkspaceID(ctx))
+}
+
+// listsOf scopes the list repository to the workspace of the request
+func listsOf(ctx echo.Context, lr repositories.ListRepository) repositories.ListRepository {
+	return lr.Workspace(auth.WorkspaceID(ctx))
+}
+
+// webhooksOf scopes the webhook repository to the workspace of the request
+func webhooksOf(ctx echo.Context, wr repositories.WebhookRepository) repositories.WebhookRepository {
+	return wr.Workspace(auth.WorkspaceID(ctx))
+}
+
+// journalOf scopes the journal repository to the workspace of the request
+func journalOf(ctx echo.Context, jr repositories.JournalRepository) repositories.JournalRepository {
+	return jr.Workspace(auth.WorkspaceID(ctx))
+}
+
+// newWorkspaceResponse is a private function for creating *workspaceResponse
+func newWorkspaceResponse(w *models.Workspace, m *models.Membership) *workspaceResponse {
+	return &workspaceResponse{
+		ID:        w.ID,
+		Name:      w.Name,
+		Personal:  w.Personal,
+		Role:      m.Role,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// newWorkspaceMemberResponse is a private function for creating *workspaceMemberResponse
+func newWorkspaceMemberResponse(m *models.Membership) *workspaceMemberResponse {
+	return &workspaceMemberResponse{
+		ID:       m.UserID,
+		Username: m.User.Username,
+		Name:     m.User.Name,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+}
+
+// newInviteResponse is a private function for creating *inviteResponse
+func newInviteResponse(i *models.Invite) *inviteResponse {
+	return &inviteResponse{
+		ID:        i.ID,
+		Email:     i.Email,
+		Role:      i.Role,
+		ExpiresAt: i.ExpiresAt,
+		CreatedAt: i.CreatedAt,
+	}
+}
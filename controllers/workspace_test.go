@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type WorkspaceControllerTestSuite struct {
+	suite.Suite
+	spaces    *mocks.WorkspaceRepository
+	invites   *mocks.InviteRepository
+	users     *mocks.UserRepository
+	workspace *WorkspaceController
+	server    *echo.Echo
+	members   map[uint]*models.Membership
+}
+
+func (suite *WorkspaceControllerTestSuite) SetupTest() {
+	suite.spaces = &mocks.WorkspaceRepository{}
+	suite.invites = &mocks.InviteRepository{}
+	suite.users = &mocks.UserRepository{}
+	suite.workspace = NewWorkspace(suite.spaces, suite.invites, suite.users)
+	suite.server = echo.New()
+
+	// alice owns the workspace 5, bob is one of its admins and carol
+	// one of its members
+	suite.members = map[uint]*models.Membership{
+		1: {WorkspaceID: 5, UserID: 1, Role: models.WorkspaceOwner, User: models.User{Username: "alice"}},
+		2: {WorkspaceID: 5, UserID: 2, Role: models.WorkspaceAdmin, User: models.User{Username: "bob"}},
+		3: {WorkspaceID: 5, UserID: 3, Role: models.WorkspaceMember, User: models.User{Username: "carol"}},
+	}
+	members := []models.Membership{}
+	for id := uint(1); id <= 3; id++ {
+		suite.spaces.On("Membership", uint(5), id).Return(suite.members[id])
+		members = append(members, *suite.members[id])
+	}
+	// dave joins the workspace 5 by accepting an invite
+	suite.spaces.On("Membership", uint(5), uint(4)).Return(&models.Membership{WorkspaceID: 5, UserID: 4, Role: models.WorkspaceMember})
+	suite.spaces.On("Membership", uint(9), uint(3)).Return(&models.Membership{WorkspaceID: 9, UserID: 3, Role: models.WorkspaceOwner})
+	suite.spaces.On("Membership", mock.Anything, mock.Anything).Return(nil)
+	suite.spaces.On("Members", uint(5)).Return(members)
+	suite.spaces.On("ByID", uint(5)).Return(&models.Workspace{Model: gorm.Model{ID: 5}, Name: "Acme"})
+	suite.spaces.On("ByID", uint(9)).Return(&models.Workspace{Model: gorm.Model{ID: 9}, Name: "Carol", Personal: true})
+}
+
+// newContext creates a context with the params authenticated as the
+// user, in the workspace 5 when they are one of its members
+func (suite *WorkspaceControllerTestSuite) newContext(userID uint, method string, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "/", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	context := suite.server.NewContext(request, response)
+	var names, values []string
+	for i := 0; i < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	context.SetParamNames(names...)
+	context.SetParamValues(values...)
+	auth.SetUser(context, &models.User{Model: gorm.Model{ID: userID}, Email: fmt.Sprintf("user%d@example.com", userID)})
+	if m, ok := suite.members[userID]; ok {
+		auth.SetMembership(context, m)
+	}
+
+	return context, response
+}
+
+func (suite *WorkspaceControllerTestSuite) TestMiddlewareResolvesTheWorkspace() {
+	assert := assert.New(suite.T())
+
+	carol := &models.User{Model: gorm.Model{ID: 3}}
+	suite.spaces.On("Personal", carol).Return(&models.Workspace{Model: gorm.Model{ID: 9}, Personal: true}, nil)
+	var workspaceID uint
+	handler := auth.Workspace(suite.spaces)(func(ctx echo.Context) error {
+		workspaceID = auth.WorkspaceID(ctx)
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		header string
+		param  string
+		code   int
+		id     uint
+	}{
+		{"", "", http.StatusNoContent, 9},
+		{"5", "", http.StatusNoContent, 5},
+		{"9", "5", http.StatusNoContent, 5},
+		{"6", "", http.StatusNotFound, 0},
+		{"", "6", http.StatusNotFound, 0},
+		{"abc", "", http.StatusNotFound, 0},
+	}
+	for _, c := range cases {
+		workspaceID = 0
+		request := httptest.NewRequest(echo.GET, "/todos", nil)
+		if c.header != "" {
+			request.Header.Set(auth.WorkspaceHeader, c.header)
+		}
+		response := httptest.NewRecorder()
+		context := suite.server.NewContext(request, response)
+		if c.param != "" {
+			context.SetParamNames("workspace")
+			context.SetParamValues(c.param)
+		}
+		auth.SetUser(context, carol)
+
+		assert.NoError(test.Serve(context, handler))
+		assert.Equal(c.code, response.Code, c)
+		assert.Equal(c.id, workspaceID, c)
+	}
+}
+
+func (suite *WorkspaceControllerTestSuite) TestScopesTheRepositories() {
+	assert := assert.New(suite.T())
+
+	// the todo 4 belongs to the workspace 6, the scoped repository
+	// of the workspace 5 can't find it
+	todos := &mocks.TodoRepository{}
+	scoped := &mocks.TodoRepository{}
+	todos.On("Workspace", uint(5)).Return(scoped)
+	scoped.On("ByID", uint(4)).Return(nil)
+	controller := NewTodo(todos, &mocks.ReminderRepository{}, &mocks.ListRepository{}, &mocks.ListMemberRepository{}, &mocks.JournalRepository{}, &mocks.CommentRepository{})
+
+	context, response := suite.newContext(1, echo.GET, "", "id", "4")
+	assert.NoError(test.Serve(context, controller.Show))
+
+	assert.Equal(http.StatusNotFound, response.Code)
+	todos.AssertNotCalled(suite.T(), "ByID", mock.Anything)
+}
+
+func (suite *WorkspaceControllerTestSuite) TestUpdateByAdmin() {
+	assert := assert.New(suite.T())
+
+	suite.spaces.On("Update", mock.AnythingOfType("*models.Workspace")).Return(nil)
+
+	context, response := suite.newContext(2, echo.PUT, `{"name":"Acme Inc"}`, "workspace", "5")
+	assert.NoError(test.Serve(context, suite.workspace.Update))
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal("Acme Inc", test.GetResponseData(response)["name"])
+	}
+
+	context, response = suite.newContext(3, echo.PUT, `{"name":"Mine"}`, "workspace", "5")
+	assert.NoError(test.Serve(context, suite.workspace.Update))
+	assert.Equal(http.StatusForbidden, response.Code)
+}
+
+func (suite *WorkspaceControllerTestSuite) TestUpdateMemberKeepsAnOwner() {
+	assert := assert.New(suite.T())
+
+	suite.spaces.On("UpdateMember", mock.AnythingOfType("*models.Membership")).Return(nil)
+
+	context, response := suite.newContext(1, echo.PUT, `{"role":"admin"}`, "workspace", "5", "user", "1")
+	assert.NoError(test.Serve(context, suite.workspace.UpdateMember))
+	assert.Equal(http.StatusUnprocessableEntity, response.Code)
+
+	context, response = suite.newContext(2, echo.PUT, `{"role":"owner"}`, "workspace", "5", "user", "2")
+	assert.NoError(test.Serve(context, suite.workspace.UpdateMember))
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.spaces.AssertNotCalled(suite.T(), "UpdateMember", mock.Anything)
+
+	context, response = suite.newContext(1, echo.PUT, `{"role":"admin"}`, "workspace", "5", "user", "3")
+	assert.NoError(test.Serve(context, suite.workspace.UpdateMember))
+	if assert.Equal(http.StatusOK, response.Code) {
+		assert.Equal("admin", test.GetResponseData(response)["role"])
+	}
+}
+
+func (suite *WorkspaceControllerTestSuite) TestDestroyMember() {
+	assert := assert.New(suite.T())
+
+	suite.spaces.On("RemoveMember", uint(5), mock.Anything).Return(nil)
+
+	// an admin can't remove an owner, a member can't remove anyone
+	// and the last owner can't leave
+	for _, c := range [][2]uint{{2, 1}, {3, 2}, {1, 1}} {
+		context, response := suite.newContext(c[0], echo.DELETE, "", "workspace", "5", "user", fmt.Sprint(c[1]))
+		assert.NoError(test.Serve(context, suite.workspace.DestroyMember))
+		assert.NotEqual(http.StatusNoContent, response.Code, c)
+	}
+	suite.spaces.AssertNotCalled(suite.T(), "RemoveMember", mock.Anything, mock.Anything)
+
+	context, response := suite.newContext(2, echo.DELETE, "", "workspace", "5", "user", "3")
+	assert.NoError(test.Serve(context, suite.workspace.DestroyMember))
+	assert.Equal(http.StatusNoContent, response.Code)
+
+	context, response = suite.newContext(3, echo.DELETE, "", "workspace", "5", "user", "3")
+	assert.NoError(test.Serve(context, suite.workspace.DestroyMember))
+	assert.Equal(http.StatusNoContent, response.Code)
+	suite.spaces.AssertNumberOfCalls(suite.T(), "RemoveMember", 2)
+}
+
+func (suite *WorkspaceControllerTestSuite) TestStoreInviteShowsTheTokenOnce() {
+	assert := assert.New(suite.T())
+
+	suite.users.On("ByEmail", mock.Anything).Return(nil)
+	suite.invites.On("Create", mock.AnythingOfType("*models.Invite")).Return(nil)
+
+	context, response := suite.newContext(2, echo.POST, `{"email":"Dave@Example.com"}`, "workspace", "5")
+	assert.NoError(test.Serve(context, suite.workspace.StoreInvite))
+
+	if assert.Equal(http.StatusCreated, response.Code) {
+		token := test.GetResponseData(response)["token"].(string)
+		assert.True(strings.HasPrefix(token, inviteTokenPrefix))
+
+		invite := suite.invites.Calls[0].Arguments.Get(0).(*models.Invite)
+		assert.Equal(auth.HashToken(token), invite.TokenHash)
+		assert.Equal("dave@example.com", invite.Email)
+		assert.Equal(models.WorkspaceMember, invite.Role)
+		assert.Equal(uint(5), invite.WorkspaceID)
+	}
+
+	context, response = suite.newContext(3, echo.POST, `{"email":"erin@example.com"}`, "workspace", "5")
+	assert.NoError(test.Serve(context, suite.workspace.StoreInvite))
+	assert.Equal(http.StatusForbidden, response.Code)
+}
+
+func (suite *WorkspaceControllerTestSuite) TestStoreInviteToPersonalWorkspace() {
+	assert := assert.New(suite.T())
+
+	context, response := suite.newContext(4, echo.POST, `{"email":"erin@example.com"}`)
+	auth.SetMembership(context, &models.Membership{WorkspaceID: 9, UserID: 4, Role: models.WorkspaceOwner})
+	assert.NoError(test.Serve(context, suite.workspace.StoreInvite))
+
+	assert.Equal(http.StatusForbidden, response.Code)
+	suite.invites.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *WorkspaceControllerTestSuite) TestAccept() {
+	assert := assert.New(suite.T())
+
+	invite := &models.Invite{
+		Model:       gorm.Model{ID: 1},
+		WorkspaceID: 5,
+		Workspace:   models.Workspace{Model: gorm.Model{ID: 5}, Name: "Acme"},
+		Email:       "User4@example.com",
+		Role:        models.WorkspaceMember,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	expired := &models.Invite{Model: gorm.Model{ID: 2}, WorkspaceID: 5, Email: "user4@example.com", ExpiresAt: time.Now().Add(-time.Hour)}
+	suite.invites.On("ByTokenHash", auth.HashToken("inv_valid")).Return(invite)
+	taken := &models.Invite{Model: gorm.Model{ID: 3}, WorkspaceID: 5, Email: "user4@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.invites.On("ByTokenHash", auth.HashToken("inv_expired")).Return(expired)
+	suite.invites.On("ByTokenHash", auth.HashToken("inv_taken")).Return(taken)
+	suite.invites.On("ByTokenHash", mock.Anything).Return(nil)
+	suite.invites.On("Accept", invite, uint(4), mock.AnythingOfType("time.Time")).Return(nil)
+	suite.invites.On("Accept", taken, uint(4), mock.AnythingOfType("time.Time")).Return(repositories.ErrInviteNotPending)
+
+	context, response := suite.newContext(6, echo.POST, "", "token", "inv_valid")
+	assert.NoError(test.Serve(context, suite.workspace.Accept))
+	assert.Equal(http.StatusForbidden, response.Code)
+
+	context, response = suite.newContext(4, echo.POST, "", "token", "inv_expired")
+	assert.NoError(test.Serve(context, suite.workspace.Accept))
+	assert.Equal(http.StatusNotFound, response.Code)
+	suite.invites.AssertNotCalled(suite.T(), "Accept", mock.Anything, mock.Anything, mock.Anything)
+
+	context, response = suite.newContext(4, echo.POST, "", "token", "inv_valid")
+	assert.NoError(test.Serve(context, suite.workspace.Accept))
+	if assert.Equal(http.StatusOK, response.Code) {
+		data := test.GetResponseData(response)
+		assert.Equal("Acme", data["name"])
+		assert.Equal("member", data["role"])
+	}
+
+	// another request accepted it in the meantime
+
+	context, response = suite.newContext(4, echo.POST, "", "token", "inv_taken")
+	assert.NoError(test.Serve(context, suite.workspace.Accept))
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWorkspaceControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkspaceControllerTestSuite))
+}
@@ -55,12 +55,70 @@ func DropTestDB() error {
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&models.User{},
+		&models.List{},
+		&models.Todo{},
+		&models.Reminder{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.CalendarFeed{},
+		&models.Operation{},
+		&models.AuditEvent{},
+		&models.Activity{},
+		&models.Comment{},
+		&models.Attachment{},
+		&models.ListMember{},
+		&models.TodoAssignee{},
+		&models.TodoDependency{},
+		&models.TodoTag{},
+		&models.TimeEntry{},
+		&models.Column{},
+		&models.Workspace{},
+		&models.Membership{},
+		&models.Invite{},
+		&models.Template{},
+		&models.TemplateTodo{},
+		&models.TemplateTodoTag{},
+		&models.IdempotencyKey{},
+		&models.Change{},
+		&models.ClientRecord{},
 	)
 }
 
 // Refresh drops all tables and rebuilds them
 func Refresh(db *gorm.DB) error {
-	err := db.Migrator().DropTable(&models.User{})
+	err := db.Migrator().DropTable(
+		&models.User{},
+		&models.List{},
+		&models.Todo{},
+		&models.Reminder{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.CalendarFeed{},
+		&models.Operation{},
+		&models.AuditEvent{},
+		&models.Activity{},
+		&models.Comment{},
+		&models.Attachment{},
+		&models.ListMember{},
+		&models.TodoAssignee{},
+		&models.TodoDependency{},
+		&models.TodoTag{},
+		&models.TimeEntry{},
+		&models.Column{},
+		&models.Workspace{},
+		&models.Membership{},
+		&models.Invite{},
+		&models.Template{},
+		&models.TemplateTodo{},
+		&models.TemplateTodoTag{},
+		&models.IdempotencyKey{},
+		&models.Change{},
+		&models.ClientRecord{},
+	)
 	if err != nil {
 		return err
 	}
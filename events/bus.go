@@ -0,0 +1,63 @@
+package events
+
+// Publisher is used by the repositories to announce their writes
+type Publisher interface {
+	Publish(e Event) error
+}
+
+// Bus delivers published events to the subscribed users. The
+// in-process MemoryBus serves a single instance, a deployment
+// running several instances needs a Bus backed by a shared broker.
+type Bus interface {
+	Publisher
+
+	// Subscribe streams the events of the user. Events published
+	// after lastEventID are replayed first, if they're still known.
+	Subscribe(userID uint, lastEventID uint64) *Subscription
+}
+
+// Subscription is a stream of events for a single user
+type Subscription struct {
+	// C receives the events. It's closed when the subscription was
+	// closed or when the subscriber fell too far behind to keep up.
+	C <-chan Event
+
+	// Missed is true when some of the events after the requested
+	// last event ID are no longer known and couldn't be replayed.
+	Missed bool
+
+	close func()
+}
+
+// Close stops receiving events
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// Discard is a Publisher which drops every event
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+// Publish drops the event
+func (discard) Publish(e Event) error {
+	return nil
+}
+
+// Multi is a Publisher which publishes to all the given publishers
+func Multi(pubs ...Publisher) Publisher {
+	return multi(pubs)
+}
+
+type multi []Publisher
+
+// Publish hands the event to every publisher, the first error is returned
+func (m multi) Publish(e Event) error {
+	var first error
+	for _, p := range m {
+		if err := p.Publish(e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,59 @@
+package events
+
+import "time"
+
+// Event types published after successful writes
+const (
+	TodoCreated         = "todo.created"
+	TodoUpdated         = "todo.updated"
+	TodoDeleted         = "todo.deleted"
+	TodoRestored        = "todo.restored"
+	ListCreated         = "list.created"
+	ListUpdated         = "list.updated"
+	ListDeleted         = "list.deleted"
+	ListRestored        = "list.restored"
+	ReminderCreated     = "reminder.created"
+	ReminderDeleted     = "reminder.deleted"
+	NotificationCreated = "notification.created"
+)
+
+// Types lists every event type which can be subscribed to
+var Types = []string{
+	TodoCreated,
+	TodoUpdated,
+	TodoDeleted,
+	TodoRestored,
+	ListCreated,
+	ListUpdated,
+	ListDeleted,
+	ListRestored,
+	ReminderCreated,
+	ReminderDeleted,
+	NotificationCreated,
+}
+
+// Event describes a change to a resource. It is only
+// delivered to the users listed in UserIDs.
+//
+// Previous holds the fields an update changed, with the
+// values they had before it.
+type Event struct {
+	ID         uint64                 `json:"id"`
+	Type       string                 `json:"type"`
+	Resource   string                 `json:"resource"`
+	ResourceID uint                   `json:"resource_id"`
+	Data       interface{}            `json:"data,omitempty"`
+	Previous   map[string]interface{} `json:"previous,omitempty"`
+	UserIDs    []uint                 `json:"-"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// IsFor determines if the event should be delivered to the user
+func (e *Event) IsFor(userID uint) bool {
+	for _, id := range e.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryBus is an in-process Bus. It keeps the latest events in
+// memory so reconnecting clients can resume from their last event.
+type MemoryBus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event
+	size        int
+	buffer      int
+	subscribers map[uint]map[*subscriber]struct{}
+}
+
+// subscriber is the sending side of a Subscription
+type subscriber struct {
+	ch     chan Event
+	closed bool
+}
+
+var _ Bus = &MemoryBus{}
+
+// NewMemoryBus creates MemoryBus instance which remembers the last
+// size events and buffers up to buffer events for each subscriber.
+func NewMemoryBus(size int, buffer int) *MemoryBus {
+	return &MemoryBus{
+		size:        size,
+		buffer:      buffer,
+		subscribers: make(map[uint]map[*subscriber]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and hands it to the subscribers of
+// its users. It never blocks, a subscriber whose buffer is full gets
+// disconnected and is expected to resume with its last event ID.
+func (b *MemoryBus) Publish(e Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for _, userID := range e.UserIDs {
+		for s := range b.subscribers[userID] {
+			select {
+			case s.ch <- e:
+			default:
+				b.unsubscribe(userID, s)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Subscribe streams the events of the user, starting with the
+// remembered events published after lastEventID.
+func (b *MemoryBus) Subscribe(userID uint, lastEventID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	missed := false
+	switch {
+	case lastEventID > b.lastID:
+		// the IDs were reset, e.g. the server restarted
+		missed = true
+	case lastEventID > 0 && lastEventID < b.lastID:
+		missed = len(b.history) == 0 || b.history[0].ID > lastEventID+1
+		for _, e := range b.history {
+			if e.ID > lastEventID && e.IsFor(userID) {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	s := &subscriber{ch: make(chan Event, b.buffer+len(replay))}
+	for _, e := range replay {
+		s.ch <- e
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[*subscriber]struct{})
+	}
+	b.subscribers[userID][s] = struct{}{}
+
+	return &Subscription{
+		C:      s.ch,
+		Missed: missed,
+		close: func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.unsubscribe(userID, s)
+		},
+	}
+}
+
+// unsubscribe removes the subscriber, the lock must be held
+func (b *MemoryBus) unsubscribe(userID uint, s *subscriber) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+
+	delete(b.subscribers[userID], s)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
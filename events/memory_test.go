@@ -0,0 +1,79 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(sub *Subscription) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestMemoryBusDeliversToTheEventUsers(t *testing.T) {
+	bus := NewMemoryBus(10, 10)
+	alice := bus.Subscribe(1, 0)
+	bob := bus.Subscribe(2, 0)
+
+	bus.Publish(Event{Type: TodoCreated, UserIDs: []uint{1}})
+	bus.Publish(Event{Type: TodoCreated, UserIDs: []uint{1, 2}})
+
+	assert.Equal(t, []uint64{1, 2}, receive(alice))
+	assert.Equal(t, []uint64{2}, receive(bob))
+}
+
+func TestMemoryBusResumesFromLastEventID(t *testing.T) {
+	bus := NewMemoryBus(10, 10)
+	for i := 0; i < 4; i++ {
+		bus.Publish(Event{Type: TodoUpdated, UserIDs: []uint{1}})
+	}
+	bus.Publish(Event{Type: TodoUpdated, UserIDs: []uint{2}})
+
+	sub := bus.Subscribe(1, 2)
+	bus.Publish(Event{Type: TodoUpdated, UserIDs: []uint{1}})
+
+	assert.False(t, sub.Missed)
+	assert.Equal(t, []uint64{3, 4, 6}, receive(sub))
+}
+
+func TestMemoryBusReportsMissedEvents(t *testing.T) {
+	bus := NewMemoryBus(2, 10)
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: TodoUpdated, UserIDs: []uint{1}})
+	}
+
+	sub := bus.Subscribe(1, 1)
+	assert.True(t, sub.Missed)
+	assert.Equal(t, []uint64{4, 5}, receive(sub))
+
+	// IDs from before a restart
+	assert.True(t, bus.Subscribe(1, 99).Missed)
+	assert.False(t, bus.Subscribe(1, 3).Missed)
+}
+
+func TestMemoryBusDisconnectsSlowSubscribers(t *testing.T) {
+	bus := NewMemoryBus(10, 2)
+	slow := bus.Subscribe(1, 0)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, bus.Publish(Event{Type: TodoUpdated, UserIDs: []uint{1}}))
+	}
+
+	assert.Equal(t, []uint64{1, 2}, receive(slow))
+	_, open := <-slow.C
+	assert.False(t, open)
+
+	// closing it afterwards is harmless
+	slow.Close()
+}
@@ -0,0 +1,190 @@
+// Package i18n translates the messages of the API. The catalogs of
+// the supported languages are JSON files embedded in the binary, one
+// per locale, keyed by the name of the message. The language of a
+// request is the one the user prefers, or else the one its
+// Accept-Language header prefers.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultLocale is the language of the messages when the reader
+// accepts none of the supported ones
+const DefaultLocale = "en"
+
+// contextKey is where the language the user prefers is kept
+const contextKey = "locale"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// catalogs are the messages of every supported language, the
+// placeholders of a message are named like {field}
+var catalogs = load()
+
+// Args are the values of the placeholders of a message
+type Args map[string]interface{}
+
+// Message is a message of the catalogs to translate later on
+type Message struct {
+	Key  string
+	Args Args
+}
+
+// load reads the catalogs of the embedded files, a broken catalog is
+// a bug of the build so it panics
+func load() map[string]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	catalogs := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		b, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := make(map[string]string)
+		if err := json.Unmarshal(b, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+	return catalogs
+}
+
+// Locales returns the supported languages
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supported tells if the language has a catalog
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Has tells if the message is in the catalogs
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLocale][key]
+	return ok
+}
+
+// Preferred returns the languages of an Accept-Language header, e.g.
+// "es-MX,es;q=0.9,en;q=0.8", the most preferred first. The regions
+// are left out and the languages which are not acceptable too.
+func Preferred(acceptLanguage string) []string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		t := tag{lang: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		for _, p := range params[1:] {
+			if v := strings.TrimSpace(p); strings.HasPrefix(v, "q=") {
+				t.q, _ = strconv.ParseFloat(v[2:], 64)
+			}
+		}
+		if i := strings.Index(t.lang, "-"); i >= 0 {
+			t.lang = t.lang[:i]
+		}
+		if t.lang != "" && t.q > 0 {
+			tags = append(tags, t)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	langs := make([]string, len(tags))
+	for i, t := range tags {
+		langs[i] = t.lang
+	}
+	return langs
+}
+
+// Negotiate picks the supported language the reader prefers the most
+// from an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	for _, lang := range Preferred(acceptLanguage) {
+		if Supported(lang) {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale makes the language the user prefers the one of the
+// request, unless it isn't supported
+func SetLocale(ctx echo.Context, locale string) {
+	if Supported(locale) {
+		ctx.Set(contextKey, locale)
+	}
+}
+
+// Locale returns the language of the request
+func Locale(ctx echo.Context) string {
+	if locale, ok := ctx.Get(contextKey).(string); ok {
+		return locale
+	}
+	return Negotiate(ctx.Request().Header.Get("Accept-Language"))
+}
+
+// T translates the message in the language of the locale, falling
+// back to the default one, and then to the key itself
+func T(locale string, key string, args Args) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+
+	pairs := make([]string, 0, 2*len(args))
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// In translates the message in the language of the locale
+func (m Message) In(locale string) string {
+	return T(locale, m.Key, m.Args)
+}
+
+// Field returns the name of a field of a request shown in the
+// messages, e.g. "due date" for due_at. The fields of the items of
+// a list, like todos.0.title, are left as they are.
+func Field(locale string, field string) string {
+	if strings.Contains(field, ".") {
+		return field
+	}
+	if name, ok := catalogs[locale]["field."+field]; ok {
+		return name
+	}
+	if name, ok := catalogs[DefaultLocale]["field."+field]; ok {
+		return name
+	}
+	return strings.ReplaceAll(field, "_", " ")
+}
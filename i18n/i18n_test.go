@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// placeholders returns the placeholders of a message, sorted
+func placeholders(message string) []string {
+	names := regexp.MustCompile(`\{[a-z_]+\}`).FindAllString(message, -1)
+	sort.Strings(names)
+	return names
+}
+
+func TestEveryMessageIsInEveryLocale(t *testing.T) {
+	assert.Equal(t, []string{"en", "es"}, Locales())
+
+	for locale, catalog := range catalogs {
+		for key, message := range catalogs[DefaultLocale] {
+			if assert.Contains(t, catalog, key, "%s is missing from %s", key, locale) {
+				assert.Equal(t, placeholders(message), placeholders(catalog[key]), "%s of %s", key, locale)
+			}
+		}
+		for key := range catalog {
+			assert.Contains(t, catalogs[DefaultLocale], key, "%s of %s is unknown", key, locale)
+		}
+	}
+}
+
+func TestNegotiatePicksThePreferredSupportedLanguage(t *testing.T) {
+	assert.Equal(t, "es", Negotiate("es-MX,es;q=0.9,en;q=0.8"))
+	assert.Equal(t, "es", Negotiate("fr;q=0.9, en;q=0.5, es"))
+	assert.Equal(t, "en", Negotiate("fr-CA"))
+	assert.Equal(t, "en", Negotiate("es;q=0"))
+	assert.Equal(t, DefaultLocale, Negotiate(""))
+}
+
+func TestLocalePrefersTheUserPreference(t *testing.T) {
+	req := httptest.NewRequest(echo.GET, "/", nil)
+	req.Header.Set("Accept-Language", "es")
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	assert.Equal(t, "es", Locale(ctx))
+
+	SetLocale(ctx, "xx")
+	assert.Equal(t, "es", Locale(ctx))
+
+	SetLocale(ctx, "en")
+	assert.Equal(t, "en", Locale(ctx))
+}
+
+func TestT(t *testing.T) {
+	args := Args{"field": Field("es", "due_at"), "min": 3}
+	assert.Equal(t, "El campo fecha de vencimiento debe tener al menos 3 caracteres", T("es", "validation.min", args))
+	assert.Equal(t, "The title field is required", T("fr", "validation.required", Args{"field": "title"}))
+	assert.Equal(t, "validation.unknown", T("es", "validation.unknown", nil))
+
+	assert.Equal(t, "due date", Field("en", "due_at"))
+	assert.Equal(t, "starts at", Field("en", "starts_at"))
+	assert.Equal(t, "todos.0.title", Field("es", "todos.0.title"))
+}
@@ -0,0 +1,223 @@
+// Package idempotency makes retrying a request safe: the response to
+// a request made with an Idempotency-Key header is kept for a while,
+// and replayed when the request is retried with the same key.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// Header is the header the key of a request is sent with
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on the responses which are replayed
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength is the longest key, the size of its column
+const MaxKeyLength = 255
+
+// keptHeaders are the response headers which are replayed along with
+// the body
+var keptHeaders = []string{echo.HeaderLocation, "ETag", echo.HeaderLastModified}
+
+var (
+	// errKeyTooLong is returned when the key does not fit its column
+	errKeyTooLong = apperrors.New(http.StatusBadRequest, "idempotency_key_too_long")
+
+	// errKeyReused is returned when retrying a request with the key of
+	// another one
+	errKeyReused = apperrors.New(http.StatusUnprocessableEntity, "idempotency_key_reused")
+
+	// errInProgress is returned when retrying a request which is still
+	// being handled
+	errInProgress = apperrors.New(http.StatusConflict, "idempotency_key_in_progress")
+)
+
+// Keys keeps the responses to the requests made with a key
+type Keys struct {
+	kr  repositories.IdempotencyRepository
+	ttl time.Duration
+}
+
+// recorder is a private struct keeping a copy of the response body
+// written through it
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// New creates Keys instance which replays the responses for the ttl
+func New(kr repositories.IdempotencyRepository, ttl time.Duration) *Keys {
+	return &Keys{kr, ttl}
+}
+
+// Middleware handles the POST requests with a key once per user and
+// key. Retries get the response to the first request, while it is
+// still being handled they get 409 and reusing the key for another
+// request gets 422. The errors of the handler are responded with here,
+// so they are kept like any response, but failed requests, with a 5xx
+// status, are not so they can be retried. Use it after authenticating,
+// as the keys of the requests made before signing in can't be told
+// apart by their user, they are scoped to the request instead.
+func (k *Keys) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		header := req.Header.Get(Header)
+		if req.Method != echo.POST || header == "" {
+			return next(ctx)
+		}
+		if len(header) > MaxKeyLength {
+			return apperrors.From(http.StatusBadRequest, errKeyTooLong)
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return requests.ErrInvalidPayload
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		key := &models.IdempotencyKey{
+			Key:         header,
+			Fingerprint: fingerprint(req, body),
+			ExpiresAt:   time.Now().Add(k.ttl),
+		}
+		if user := auth.User(ctx); user != nil {
+			key.UserID = user.ID
+		} else {
+			key.Key = anonymousKey(header, key.Fingerprint)
+		}
+		existing, err := k.kr.Lock(key)
+		if err != nil {
+			return apperrors.From(http.StatusInternalServerError, err)
+		}
+		if existing != nil {
+			return replay(ctx, key, existing)
+		}
+
+		rec := &recorder{ResponseWriter: ctx.Response().Writer}
+		ctx.Response().Writer = rec
+		err = next(ctx)
+		if err != nil {
+			err = apperrors.Respond(ctx, err)
+		}
+		ctx.Response().Writer = rec.ResponseWriter
+
+		res := ctx.Response()
+		if err != nil || !res.Committed || res.Status >= http.StatusInternalServerError {
+			if err := k.kr.Release(key.ID); err != nil {
+				ctx.Logger().Errorf("idempotency: releasing %s: %v", key.Key, err)
+			}
+			return err
+		}
+
+		key.Status = res.Status
+		key.ContentType = res.Header().Get(echo.HeaderContentType)
+		key.Body = rec.body.Bytes()
+		key.Headers = keepHeaders(res.Header())
+		if err := k.kr.Complete(key); err != nil {
+			ctx.Logger().Errorf("idempotency: saving the response of %s: %v", key.Key, err)
+		}
+		return nil
+	}
+}
+
+// Prune deletes the expired keys. It has the signature of a scheduler
+// job.
+func (k *Keys) Prune(ctx context.Context, now time.Time) {
+	n, err := k.kr.Prune(now)
+	if err != nil {
+		log.Errorf("idempotency: pruning keys: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("idempotency: pruned %d expired keys", n)
+	}
+}
+
+// replay responds to the retry of a request with the response to the
+// first one, when it is the same request and it has one yet
+func replay(ctx echo.Context, key *models.IdempotencyKey, existing *models.IdempotencyKey) error {
+	if existing.Fingerprint != key.Fingerprint {
+		return apperrors.From(http.StatusUnprocessableEntity, errKeyReused)
+	}
+	if existing.IsPending() {
+		ctx.Response().Header().Set("Retry-After", "1")
+		return apperrors.From(http.StatusConflict, errInProgress)
+	}
+
+	restoreHeaders(ctx.Response().Header(), existing.Headers)
+	ctx.Response().Header().Set(ReplayedHeader, "true")
+	if existing.ContentType == "" {
+		return ctx.NoContent(existing.Status)
+	}
+	return ctx.Blob(existing.Status, existing.ContentType, existing.Body)
+}
+
+// fingerprint hashes what makes a request, its method, path, workspace
+// and body
+func fingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write([]byte(req.Header.Get(auth.WorkspaceHeader) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// anonymousKey scopes the key of a request made before signing in to
+// the request, so anyone else using the same key for another request
+// neither gets its response nor is refused
+func anonymousKey(header string, fingerprint string) string {
+	h := sha256.Sum256([]byte(fingerprint + "\n" + header))
+	return hex.EncodeToString(h[:])
+}
+
+// keepHeaders encodes the kept headers of the response, empty when it
+// has none of them
+func keepHeaders(header http.Header) string {
+	kept := map[string]string{}
+	for _, name := range keptHeaders {
+		if value := header.Get(name); value != "" {
+			kept[name] = value
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(kept)
+	return string(b)
+}
+
+// restoreHeaders sets the headers kept by keepHeaders on the response
+func restoreHeaders(header http.Header, kept string) {
+	if kept == "" {
+		return
+	}
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(kept), &headers); err != nil {
+		return
+	}
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+}
+
+// Write copies the bytes to the body before writing them
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
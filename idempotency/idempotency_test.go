@@ -0,0 +1,208 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/auth"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// newContext creates a context of a POST with the key, authenticated
+// as the user 7
+func newContext(key string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(echo.POST, "/todos", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	response := httptest.NewRecorder()
+	ctx := echo.New().NewContext(req, response)
+	auth.SetUser(ctx, &models.User{Model: gorm.Model{ID: 7}})
+
+	return ctx, response
+}
+
+// create is a handler creating a todo, it counts how often it was called
+func create(calls *int) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		*calls++
+		return ctx.JSON(http.StatusCreated, map[string]int{"id": 3})
+	}
+}
+
+func TestMiddlewareKeepsTheResponse(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.AnythingOfType("*models.IdempotencyKey")).Return(nil, nil)
+	repo.On("Complete", mock.AnythingOfType("*models.IdempotencyKey")).Return(nil)
+
+	var calls int
+	ctx, response := newContext("abc", `{"title": "Ship release"}`)
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, 1, calls)
+	key := repo.Calls[1].Arguments.Get(0).(*models.IdempotencyKey)
+	assert.Equal(t, uint(7), key.UserID)
+	assert.Equal(t, "abc", key.Key)
+	assert.Equal(t, http.StatusCreated, key.Status)
+	assert.JSONEq(t, `{"id": 3}`, string(key.Body))
+	assert.True(t, key.ExpiresAt.After(time.Now().Add(59*time.Minute)))
+}
+
+func TestMiddlewareReplaysTheResponse(t *testing.T) {
+	ctx, response := newContext("abc", `{"title": "Ship release"}`)
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(&models.IdempotencyKey{
+		UserID:      7,
+		Key:         "abc",
+		Fingerprint: fingerprint(ctx.Request(), []byte(`{"title": "Ship release"}`)),
+		Status:      http.StatusCreated,
+		ContentType: echo.MIMEApplicationJSONCharsetUTF8,
+		Body:        []byte(`{"id": 3}`),
+	}, nil)
+
+	var calls int
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Zero(t, calls)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "true", response.Header().Get(ReplayedHeader))
+	assert.JSONEq(t, `{"id": 3}`, response.Body.String())
+
+	// the key can't be reused for another request
+	ctx, response = newContext("abc", `{"title": "Another"}`)
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Zero(t, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+func TestMiddlewareReplaysTheHeadersOfTheResponse(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(nil, nil)
+	repo.On("Complete", mock.Anything).Return(nil)
+
+	ctx, _ := newContext("abc", `{}`)
+	handler := func(ctx echo.Context) error {
+		ctx.Response().Header().Set(echo.HeaderLocation, "/todos/3")
+		ctx.Response().Header().Set("ETag", `"v1"`)
+		ctx.Response().Header().Set("X-Other", "not kept")
+		return ctx.JSON(http.StatusCreated, map[string]int{"id": 3})
+	}
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(handler)))
+	key := repo.Calls[1].Arguments.Get(0).(*models.IdempotencyKey)
+	assert.NotContains(t, key.Headers, "X-Other")
+
+	key.UserID = 7
+	repo = &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(key, nil)
+
+	var calls int
+	ctx, response := newContext("abc", `{}`)
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Zero(t, calls)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "/todos/3", response.Header().Get(echo.HeaderLocation))
+	assert.Equal(t, `"v1"`, response.Header().Get("ETag"))
+	assert.Empty(t, response.Header().Get("X-Other"))
+}
+
+func TestMiddlewareScopesAnonymousKeysToTheRequest(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(nil, nil)
+	repo.On("Complete", mock.Anything).Return(nil)
+
+	anonymous := func(body string) *models.IdempotencyKey {
+		ctx, _ := newContext("abc", body)
+		auth.SetUser(ctx, nil)
+		var calls int
+		assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+		return repo.Calls[len(repo.Calls)-1].Arguments.Get(0).(*models.IdempotencyKey)
+	}
+	first := anonymous(`{"email": "jane@example.com"}`)
+	retry := anonymous(`{"email": "jane@example.com"}`)
+	other := anonymous(`{"email": "john@example.com"}`)
+
+	assert.Zero(t, first.UserID)
+	assert.NotEqual(t, "abc", first.Key)
+	assert.Equal(t, first.Key, retry.Key)
+	assert.NotEqual(t, first.Key, other.Key)
+}
+
+func TestMiddlewareLocksConcurrentRequests(t *testing.T) {
+	ctx, response := newContext("abc", `{}`)
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(&models.IdempotencyKey{Key: "abc", Fingerprint: fingerprint(ctx.Request(), []byte(`{}`))}, nil)
+
+	var calls int
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Zero(t, calls)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "1", response.Header().Get("Retry-After"))
+}
+
+func TestMiddlewareKeepsTheProblemOfAnError(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(nil, nil)
+	repo.On("Complete", mock.Anything).Return(nil)
+
+	ctx, response := newContext("abc", `{}`)
+	handler := func(ctx echo.Context) error {
+		return apperrors.New(http.StatusNotFound, "list_not_found")
+	}
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(handler)))
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	key := repo.Calls[1].Arguments.Get(0).(*models.IdempotencyKey)
+	assert.Equal(t, http.StatusNotFound, key.Status)
+	assert.Equal(t, apperrors.MIMEProblem, key.ContentType)
+	assert.Contains(t, string(key.Body), `"code":"list_not_found"`)
+}
+
+func TestMiddlewareReleasesTheKeyOfFailedRequests(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	repo.On("Lock", mock.Anything).Return(nil, nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.IdempotencyKey).ID = 4
+	})
+	repo.On("Release", uint(4)).Return(nil)
+
+	ctx, response := newContext("abc", `{}`)
+	handler := func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusServiceUnavailable, map[string]string{"message": "try again"})
+	}
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(handler)))
+
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	repo.AssertCalled(t, "Release", uint(4))
+	repo.AssertNotCalled(t, "Complete", mock.Anything)
+}
+
+func TestMiddlewareSkipsOtherRequests(t *testing.T) {
+	repo := &mocks.IdempotencyRepository{}
+	var calls int
+
+	// requests without a key
+	ctx, _ := newContext("", `{}`)
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	// and requests which are not POST
+	ctx, _ = newContext("abc", `{}`)
+	ctx.Request().Method = echo.PUT
+	assert.NoError(t, test.Serve(ctx, New(repo, time.Hour).Middleware(create(&calls))))
+
+	assert.Equal(t, 2, calls)
+	repo.AssertNotCalled(t, "Lock", mock.Anything)
+}
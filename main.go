@@ -1,14 +1,31 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"os"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/ksungcaya/todo-echo/activity"
+	"github.com/ksungcaya/todo-echo/audit"
+	"github.com/ksungcaya/todo-echo/auth"
+	"github.com/ksungcaya/todo-echo/changelog"
+	"github.com/ksungcaya/todo-echo/cli"
 	"github.com/ksungcaya/todo-echo/configs"
 	"github.com/ksungcaya/todo-echo/controllers"
 	"github.com/ksungcaya/todo-echo/database"
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/idempotency"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
 	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/requests"
 	"github.com/ksungcaya/todo-echo/router"
+	"github.com/ksungcaya/todo-echo/scheduler"
+	"github.com/ksungcaya/todo-echo/storage"
+	"github.com/ksungcaya/todo-echo/transfer"
+	"github.com/ksungcaya/todo-echo/webhooks"
 	"github.com/labstack/echo/v4"
 )
 
@@ -22,17 +39,162 @@ func main() {
 	database.AutoMigrate(db)
 	// database.Refresh(db)
 
+	bus := events.NewMemoryBus(config.Events.History, config.Events.Buffer)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	dispatcher := webhooks.NewDispatcher(webhookRepo)
+	activityRepo := repositories.NewActivityRepository(db)
+	// the recorder only looks up list names, it needs no publisher
+	recorder := activity.NewRecorder(activityRepo, repositories.NewListRepository(db, events.Discard), config.Activity.GroupWindow)
+	changeRepo := repositories.NewChangeRepository(db)
+	publisher := events.Multi(bus, dispatcher, recorder, changelog.NewRecorder(changeRepo))
+
 	userRepo := repositories.NewUserRepository(db)
-	authController := controllers.NewAuth(userRepo)
+	requests.RegisterUnique("users", userRepo)
+	listRepo := repositories.NewListRepository(db, publisher)
+	todoRepo := repositories.NewTodoRepository(db, publisher)
+	reminderRepo := repositories.NewReminderRepository(db, publisher)
+	notificationRepo := repositories.NewNotificationRepository(db, publisher)
+	calendarRepo := repositories.NewCalendarFeedRepository(db)
+	journalRepo := repositories.NewJournalRepository(db, publisher, config.Journal.History)
+	auditRepo := repositories.NewAuditRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	memberRepo := repositories.NewListMemberRepository(db)
+	timeEntryRepo := repositories.NewTimeEntryRepository(db)
+	columnRepo := repositories.NewColumnRepository(db)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	inviteRepo := repositories.NewInviteRepository(db)
+	templateRepo := repositories.NewTemplateRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+
+	importer := transfer.NewImporter(todoRepo, config.Import)
+	exporter := transfer.NewExporter(todoRepo)
+
+	// subcommands, e.g. "todo-echo import", run instead of the server
+	if len(os.Args) > 1 {
+		if err := cli.New(userRepo, workspaceRepo, importer, exporter, os.Stdout).Run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	inbox := notifiers.NewInbox(notificationRepo, config.Notification.Retention)
+	attachments := newAttachmentStorage(config.Attachment)
+	signer := storage.NewURLSigner(config.Auth.Secret, config.Attachment.URLTTL)
+	keys := idempotency.New(idempotencyRepo, config.Idempotency.TTL)
+
+	jwt := auth.NewJWT(config.Auth)
+	authController := controllers.NewAuth(userRepo, jwt)
+	userController := controllers.NewUser(userRepo)
+	todoController := controllers.NewTodo(todoRepo, reminderRepo, listRepo, memberRepo, journalRepo, commentRepo)
+	listController := controllers.NewList(listRepo, todoRepo, journalRepo, commentRepo, memberRepo)
+	journalController := controllers.NewJournal(journalRepo)
+	trashController := controllers.NewTrash(todoRepo, listRepo, config.Trash.Retention)
+	notificationController := controllers.NewNotification(notificationRepo)
+	eventController := controllers.NewEvents(bus, config.Events.Heartbeat)
+	webhookController := controllers.NewWebhook(webhookRepo, dispatcher)
+	transferController := controllers.NewTransfer(importer, exporter)
+	calendarController := controllers.NewCalendar(calendarRepo, workspaceRepo, exporter)
+	auditController := controllers.NewAudit(auditRepo)
+	activityController := controllers.NewActivity(activityRepo, listRepo)
+	commentController := controllers.NewComment(commentRepo, todoRepo, listRepo, memberRepo, userRepo, inbox)
+	memberController := controllers.NewMember(memberRepo, listRepo, userRepo, workspaceRepo, inbox)
+	assigneeController := controllers.NewAssignee(todoRepo, listRepo, memberRepo, userRepo, commentRepo, inbox)
+	tagController := controllers.NewTag(todoRepo, listRepo, memberRepo, commentRepo)
+	dependencyController := controllers.NewDependency(todoRepo, commentRepo)
+	timeController := controllers.NewTime(timeEntryRepo, todoRepo, listRepo)
+	columnController := controllers.NewColumn(columnRepo, listRepo, memberRepo, todoRepo, commentRepo)
+	workspaceController := controllers.NewWorkspace(workspaceRepo, inviteRepo, userRepo)
+	bulkController := controllers.NewBulk(todoRepo, listRepo, journalRepo)
+	syncController := controllers.NewSync(changeRepo, todoRepo, listRepo, journalRepo)
+	templateController := controllers.NewTemplate(templateRepo, listRepo, todoRepo, memberRepo)
+	attachmentController := controllers.NewAttachment(attachmentRepo, todoRepo, listRepo, memberRepo, attachments, signer,
+		config.Attachment.MaxBytes, config.Attachment.QuotaBytes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if config.Scheduler.Enabled {
+		s := scheduler.New(config.Scheduler, scheduler.SystemClock, reminderRepo, todoRepo, userRepo)
+		s.Register(models.ReminderEmail, notifiers.NewEmail(notifiers.NewSMTPMailer(config.Mail)))
+		s.Register(models.ReminderWebhook, notifiers.NewWebhook(nil))
+		s.Register(models.ReminderInApp, notifiers.NewInApp(inbox))
+		go s.Start(ctx)
+		go scheduler.Every(ctx, scheduler.SystemClock, config.Notification.PruneInterval, inbox.Prune)
+		purger := scheduler.NewTrashPurger(todoRepo, listRepo, config.Trash.Retention)
+		go scheduler.Every(ctx, scheduler.SystemClock, config.Trash.PurgeInterval, purger.Purge)
+		cleaner := scheduler.NewAttachmentCleaner(attachmentRepo, attachments, config.Scheduler.BatchSize)
+		go scheduler.Every(ctx, scheduler.SystemClock, config.Attachment.CleanupInterval, cleaner.Clean)
+		go webhooks.NewWorker(config.Webhook, scheduler.SystemClock, webhookRepo).Start(ctx)
+		go scheduler.Every(ctx, scheduler.SystemClock, config.Idempotency.PruneInterval, keys.Prune)
+	}
+
+	// the routes of the resources of a workspace are made in one, and
+	// the retries of the requests with an Idempotency-Key are replayed
+	signedIn := jwt.Middleware(userRepo)
+	user := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return signedIn(keys.Middleware(next))
+	}
+	inWorkspace := auth.Workspace(workspaceRepo)
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return user(inWorkspace(next))
+	}
 
 	r := router.New()
+	// the audit log keeps the address of the clients, the headers
+	// telling it are only believed when they come from the proxy
+	r.IPExtractor = echo.ExtractIPDirect()
+	if config.TrustProxy {
+		r.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
+	r.Use(audit.Middleware(auditRepo))
 	r.GET("/", hello)
-	r.POST("/auth/register", authController.Register)
+	r.SetAuthRoutes(authController, user, keys.Middleware)
+	r.SetUserRoutes(userController, user)
+	r.SetWorkspaceRoutes(workspaceController, user, authenticate)
+	r.SetTodoRoutes(todoController, authenticate)
+	r.SetBulkRoutes(bulkController, authenticate)
+	r.SetSyncRoutes(syncController, authenticate)
+	r.SetAssigneeRoutes(assigneeController, authenticate)
+	r.SetTagRoutes(tagController, authenticate)
+	r.SetDependencyRoutes(dependencyController, authenticate)
+	r.SetTimeRoutes(timeController, authenticate)
+	r.SetCommentRoutes(commentController, authenticate)
+	r.SetAttachmentRoutes(attachmentController, authenticate)
+	r.SetListRoutes(listController, authenticate)
+	r.SetMemberRoutes(memberController, authenticate)
+	r.SetColumnRoutes(columnController, authenticate)
+	r.SetTemplateRoutes(templateController, authenticate)
+	r.SetTrashRoutes(trashController, authenticate)
+	r.SetJournalRoutes(journalController, authenticate)
+	r.SetActivityRoutes(activityController, authenticate)
+	r.SetTransferRoutes(transferController, authenticate)
+	r.SetCalendarRoutes(calendarController, authenticate)
+	r.SetNotificationRoutes(notificationController, user)
+	r.SetEventRoutes(eventController, user)
+	r.SetWebhookRoutes(webhookController, authenticate)
+	r.SetAdminRoutes(auditController, user, auth.Admin(config.Auth.AdminIDs))
 
 	// Start server
 	// r.Logger.Fatal(r.Start(fmt.Sprintf(":%d", config.Port)))
 }
 
+// newAttachmentStorage creates the storage of the driver configured for attachments
+func newAttachmentStorage(config configs.AttachmentConfig) storage.Storage {
+	if config.Driver == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  config.S3.Endpoint,
+			Region:    config.S3.Region,
+			Bucket:    config.S3.Bucket,
+			AccessKey: config.S3.AccessKey,
+			SecretKey: config.S3.SecretKey,
+			PathStyle: config.S3.PathStyle,
+		}, nil)
+	}
+	return storage.NewLocal(config.Dir)
+}
+
 // Handler
 func hello(c echo.Context) error {
 	return c.String(http.StatusOK, "Hello, World!")
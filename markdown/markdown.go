@@ -0,0 +1,167 @@
+// Package markdown renders the Markdown users write, e.g. in comments,
+// into HTML which is safe to show. The source is HTML escaped before
+// anything else, so only the markup added here ever reaches the page.
+//
+// It supports paragraphs, line breaks, bullet lists, quotes, fenced
+// code blocks, `code`, **bold**, *emphasis*, [links](https://...) to
+// http, https and mailto URLs, and @username mentions.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	codeSpan = regexp.MustCompile("`([^`\n]+)`")
+	bold     = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	emphasis = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\n]+)[*_]`)
+	link     = regexp.MustCompile(`\[([^\]\n]+)\]\(((?:https?://|mailto:)[^\s)]+)\)`)
+	mention  = regexp.MustCompile(`(^|[^\w@])@(\w{1,30})`)
+)
+
+// Render converts the Markdown source into safe HTML
+func Render(src string) string {
+	lines := strings.Split(strings.ReplaceAll(html.EscapeString(src), "\r\n", "\n"), "\n")
+
+	var (
+		out       strings.Builder
+		paragraph []string
+		list      []string
+		quote     []string
+		code      []string
+		fenced    bool
+	)
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + inline(strings.Join(paragraph, "\n")) + "</p>")
+			paragraph = nil
+		}
+		if len(list) > 0 {
+			out.WriteString("<ul>")
+			for _, item := range list {
+				out.WriteString("<li>" + inline(item) + "</li>")
+			}
+			out.WriteString("</ul>")
+			list = nil
+		}
+		if len(quote) > 0 {
+			out.WriteString("<blockquote><p>" + inline(strings.Join(quote, "\n")) + "</p></blockquote>")
+			quote = nil
+		}
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if fenced {
+				out.WriteString("<pre><code>" + strings.Join(code, "\n") + "</code></pre>")
+				code = nil
+			} else {
+				flush()
+			}
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			code = append(code, line)
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			if len(list) == 0 {
+				flush()
+			}
+			list = append(list, trimmed[2:])
+		case strings.HasPrefix(trimmed, "&gt; ") || trimmed == "&gt;":
+			if len(quote) == 0 {
+				flush()
+			}
+			quote = append(quote, strings.TrimSpace(strings.TrimPrefix(trimmed, "&gt;")))
+		default:
+			if len(list) > 0 || len(quote) > 0 {
+				flush()
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	// an unclosed fence still ends the code block
+	if fenced {
+		out.WriteString("<pre><code>" + strings.Join(code, "\n") + "</code></pre>")
+	}
+	flush()
+
+	return out.String()
+}
+
+// Mentions are the usernames mentioned in the Markdown source, in
+// the order they are first mentioned
+func Mentions(src string) []string {
+	seen := make(map[string]bool)
+	usernames := []string{}
+	for _, m := range mention.FindAllStringSubmatch(withoutCode(src), -1) {
+		if username := m[2]; !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// inline renders the spans of escaped text, leaving code spans as written
+func inline(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpan.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(spans(text[last:loc[0]]))
+		out.WriteString("<code>" + text[loc[2]:loc[3]] + "</code>")
+		last = loc[1]
+	}
+	out.WriteString(spans(text[last:]))
+
+	return strings.ReplaceAll(out.String(), "\n", "<br>")
+}
+
+// spans renders the links, emphasis and mentions of escaped text.
+// The URLs of the links are left as written.
+func spans(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range link.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(emphasize(text[last:loc[0]]))
+		out.WriteString(`<a href="` + text[loc[4]:loc[5]] + `" rel="nofollow noopener">`)
+		out.WriteString(emphasize(text[loc[2]:loc[3]]) + "</a>")
+		last = loc[1]
+	}
+	out.WriteString(emphasize(text[last:]))
+
+	return out.String()
+}
+
+// emphasize renders the emphasis and mentions of escaped text
+func emphasize(text string) string {
+	text = bold.ReplaceAllString(text, "<strong>$1</strong>")
+	text = emphasis.ReplaceAllString(text, "$1<em>$2</em>")
+	return mention.ReplaceAllString(text, `$1<span class="mention">@$2</span>`)
+}
+
+// withoutCode blanks out the code of the source, where nobody is mentioned
+func withoutCode(src string) string {
+	var out []string
+	fenced := false
+	for _, line := range strings.Split(src, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced {
+			out = append(out, codeSpan.ReplaceAllString(line, ""))
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,38 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	src := "Ship **it** with *care*, see [the notes](https://example.com/a_b_c?x=1&y=2).\n" +
+		"Thanks @bob\n\n" +
+		"- one\n- `two`\n\n" +
+		"> quoted\n\n" +
+		"```\nif a < b {\n```"
+
+	assert.Equal(t, "<p>Ship <strong>it</strong> with <em>care</em>, see "+
+		`<a href="https://example.com/a_b_c?x=1&amp;y=2" rel="nofollow noopener">the notes</a>.<br>`+
+		`Thanks <span class="mention">@bob</span></p>`+
+		"<ul><li>one</li><li><code>two</code></li></ul>"+
+		"<blockquote><p>quoted</p></blockquote>"+
+		"<pre><code>if a &lt; b {</code></pre>", Render(src))
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	assert.Equal(t, "<p>&lt;script&gt;alert(1)&lt;/script&gt; &lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>",
+		Render(`<script>alert(1)</script> <img src=x onerror="alert(1)">`))
+	assert.Equal(t, "<p>[click](javascript:alert(1))</p>", Render("[click](javascript:alert(1))"))
+	// quotes stay escaped, so they can't end the href
+	assert.Equal(t, `<p><a href="https://a.com/&#34;onclick=&#34;alert(1" rel="nofollow noopener">x</a>)</p>`,
+		Render(`[x](https://a.com/"onclick="alert(1))`))
+}
+
+func TestMentions(t *testing.T) {
+	src := "@alice and @bob, ping @alice again. Not me@example.com,\n`@carol` or\n```\n@dave\n```"
+
+	assert.Equal(t, []string{"alice", "bob"}, Mentions(src))
+	assert.Empty(t, Mentions("nobody"))
+}
@@ -88,6 +88,20 @@ func (_m *UserRepository) Delete(id uint) error {
 	return r0
 }
 
+// Taken provides a mock function with given fields: column, value, exceptID
+func (_m *UserRepository) Taken(column string, value string, exceptID uint) bool {
+	ret := _m.Called(column, value, exceptID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, uint) bool); ok {
+		r0 = rf(column, value, exceptID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: user
 func (_m *UserRepository) Update(user *models.User) error {
 	ret := _m.Called(user)
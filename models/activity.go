@@ -0,0 +1,76 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Verbs of the activities in the feeds
+const (
+	ActivityCreated   = "created"
+	ActivityUpdated   = "updated"
+	ActivityCompleted = "completed"
+	ActivityReopened  = "reopened"
+	ActivityMoved     = "moved"
+	ActivityRenamed   = "renamed"
+	ActivityDeleted   = "deleted"
+	ActivityRestored  = "restored"
+)
+
+// Subjects of the activities in the feeds
+const (
+	ActivityTodo = "todo"
+	ActivityList = "list"
+)
+
+// Activity model definition
+//
+// It is a human-readable entry of a user's feed, the message is
+// rendered from it when read. Title and ListName are kept as they
+// were at the time, Detail holds what the message needs besides,
+// e.g. the name of a renamed list before it. Fields are the names
+// of the fields an update changed, comma separated, and Count how
+// many consecutive updates were grouped in the activity.
+type Activity struct {
+	gorm.Model
+	UserID    uint   `gorm:"index;not null"`
+	ActorID   uint   `gorm:"not null"`
+	Actor     User   `gorm:"foreignKey:ActorID"`
+	ListID    *uint  `gorm:"index"`
+	Verb      string `gorm:"type:varchar(20);not null"`
+	Subject   string `gorm:"type:varchar(20);not null"`
+	SubjectID uint   `gorm:"not null"`
+	Title     string `gorm:"type:varchar(255);not null"`
+	ListName  string `gorm:"type:varchar(100)"`
+	Detail    string `gorm:"type:varchar(255)"`
+	Fields    string `gorm:"type:varchar(255)"`
+	Count     int    `gorm:"not null;default:1"`
+}
+
+// FieldNames are the names of the fields the activity changed
+func (a *Activity) FieldNames() []string {
+	if a.Fields == "" {
+		return []string{}
+	}
+	return strings.Split(a.Fields, ",")
+}
+
+// AddFields adds the names of the fields to the ones the activity
+// changed, keeping the order they were first changed in
+func (a *Activity) AddFields(names ...string) {
+	fields := a.FieldNames()
+	for _, name := range names {
+		found := false
+		for _, f := range fields {
+			if f == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fields = append(fields, name)
+		}
+	}
+	a.Fields = strings.Join(fields, ",")
+}
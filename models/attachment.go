@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// Attachment model definition
+//
+// The file itself is kept in the storage under StorageKey, Checksum
+// is the hex encoded SHA-256 of its content. ContentType is sniffed
+// from the content rather than trusted from the upload.
+type Attachment struct {
+	gorm.Model
+	TodoID      uint   `gorm:"index;not null"`
+	UserID      uint   `gorm:"index;not null"`
+	Filename    string `gorm:"type:varchar(255);not null"`
+	ContentType string `gorm:"type:varchar(100);not null"`
+	Size        int64  `gorm:"not null"`
+	Checksum    string `gorm:"type:varchar(64);not null"`
+	StorageKey  string `gorm:"type:varchar(255);uniqueIndex;not null"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AuditEvent model definition
+//
+// Audit events are only ever appended, so unlike the other models
+// they can't be updated or deleted. Each one is chained to the one
+// before through PrevHash, changing or removing an entry breaks
+// the hashes of every entry after it. PrevHash is unique, so no two
+// entries can be chained to the same one.
+type AuditEvent struct {
+	ID         uint      `gorm:"primarykey"`
+	CreatedAt  time.Time `gorm:"index;not null"`
+	ActorID    *uint     `gorm:"index"`
+	Action     string    `gorm:"type:varchar(50);index;not null"`
+	TargetType string    `gorm:"type:varchar(30);index"`
+	TargetID   *uint
+	IP         string `gorm:"type:varchar(45)"`
+	UserAgent  string `gorm:"type:varchar(255)"`
+	RequestID  string `gorm:"type:varchar(64)"`
+	Diff       string `gorm:"type:text"`
+	PrevHash   string `gorm:"type:char(64);uniqueIndex;not null"`
+	Hash       string `gorm:"type:char(64);uniqueIndex;not null"`
+}
+
+// ComputeHash hashes the entry along with the hash of the one before it
+func (e *AuditEvent) ComputeHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s",
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		optionalID(e.ActorID),
+		e.Action,
+		e.TargetType,
+		optionalID(e.TargetID),
+		e.IP,
+		e.UserAgent,
+		e.RequestID,
+		e.Diff,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// optionalID formats an optional ID, empty when there's none
+func optionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprint(*id)
+}
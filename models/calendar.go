@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Components a calendar feed is made of
+const (
+	CalendarTodos  = "VTODO"
+	CalendarEvents = "VEVENT"
+)
+
+// CalendarFeed model definition
+//
+// A feed grants read access to the user's todos in a workspace
+// through a secret URL that calendar apps subscribe to. Only the hash of its token
+// is stored, and deleting the feed revokes the URL.
+type CalendarFeed struct {
+	gorm.Model
+	WorkspaceID uint   `gorm:"index;not null;default:0"`
+	UserID      uint   `gorm:"index;not null"`
+	Name        string `gorm:"type:varchar(100)"`
+	Component   string `gorm:"type:varchar(10);not null"`
+	TokenHash   string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	LastUsedAt  *time.Time
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Resources of the changes in the change logs
+const (
+	ChangeTodo = "todo"
+	ChangeList = "list"
+)
+
+// ChangeDeleted is the field of the clock of a tombstone, the time
+// its record was deleted at
+const ChangeDeleted = "deleted"
+
+// Change model definition
+//
+// It is an entry of a user's change log, which offline clients sync
+// from. Seq increases with every change logged for the user, the sync
+// token of a client is the last one it has seen. Data is a JSON
+// snapshot of the record after the change, a Deleted change being the
+// tombstone of a deleted record. Clock is a JSON object of the time
+// every field the change wrote was changed at, the latest change to
+// a field wins over the ones made before it.
+type Change struct {
+	ID          uint   `gorm:"primarykey"`
+	UserID      uint   `gorm:"uniqueIndex:idx_change_seq;not null"`
+	Seq         uint64 `gorm:"uniqueIndex:idx_change_seq;not null"`
+	WorkspaceID uint   `gorm:"index;not null;default:0"`
+	Resource    string `gorm:"type:varchar(20);index:idx_change_record;not null"`
+	ResourceID  uint   `gorm:"index:idx_change_record;not null"`
+	Deleted     bool   `gorm:"not null;default:false"`
+	Data        string `gorm:"type:text"`
+	Clock       string `gorm:"type:text"`
+	CreatedAt   time.Time
+}
+
+// Times decodes the clock of the change
+func (c *Change) Times() map[string]time.Time {
+	times := make(map[string]time.Time)
+	json.Unmarshal([]byte(c.Clock), &times)
+	return times
+}
+
+// SetTimes encodes the times the fields were changed at as the clock
+// of the change
+func (c *Change) SetTimes(times map[string]time.Time) {
+	b, _ := json.Marshal(times)
+	c.Clock = string(b)
+}
+
+// ClientRecord model definition
+//
+// It maps the ID a client gave a record it created offline to the ID
+// of the record, so the client can keep referring to it by its own ID
+// and creating it again is not duplicating it.
+type ClientRecord struct {
+	ID         uint   `gorm:"primarykey"`
+	UserID     uint   `gorm:"uniqueIndex:idx_client_record;not null"`
+	Resource   string `gorm:"type:varchar(20);uniqueIndex:idx_client_record;not null"`
+	ClientID   string `gorm:"type:varchar(64);uniqueIndex:idx_client_record;not null"`
+	ResourceID uint   `gorm:"not null"`
+	CreatedAt  time.Time
+}
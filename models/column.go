@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// Column model definition
+//
+// Columns are the lanes of a list's board, ordered by Position.
+// WIPLimit caps how many todos the column holds when set. The todos
+// in the Done column of a list, there is at most one, are completed.
+type Column struct {
+	gorm.Model
+	ListID   uint   `gorm:"index;not null"`
+	Name     string `gorm:"type:varchar(50);not null"`
+	Position int    `gorm:"not null;default:0"`
+	WIPLimit *int
+	Done     bool `gorm:"not null;default:false"`
+}
+
+// IsFull determines if the column holds as many todos as it may
+func (c *Column) IsFull(count int64) bool {
+	return c.WIPLimit != nil && count >= int64(*c.WIPLimit)
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment model definition
+//
+// Replies point to the comment they answer with ParentID. The body
+// is kept as the Markdown its author wrote, it's rendered when read.
+// Version is bumped by every edit of the comment, see Todo.
+type Comment struct {
+	gorm.Model
+	TodoID   uint   `gorm:"index;not null"`
+	UserID   uint   `gorm:"not null"`
+	User     User   `gorm:"foreignKey:UserID"`
+	ParentID *uint  `gorm:"index"`
+	Body     string `gorm:"type:text;not null"`
+	EditedAt *time.Time
+	Version  uint `gorm:"not null;default:1"`
+}
+
+// IsEdited determines if the comment was edited after it was posted
+func (c *Comment) IsEdited() bool {
+	return c.EditedAt != nil
+}
+
+// IsDeleted determines if the comment was deleted. Deleted comments
+// are only loaded to keep the replies to them in their thread.
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt.Valid
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// TodoDependency model definition
+//
+// The todo is blocked by the blocker until the blocker is completed.
+type TodoDependency struct {
+	gorm.Model
+	TodoID    uint `gorm:"uniqueIndex:idx_todo_dependency;not null"`
+	BlockerID uint `gorm:"uniqueIndex:idx_todo_dependency;index;not null"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// IdempotencyKey model definition
+//
+// It keeps the response to a request made with an Idempotency-Key
+// header, which is replayed when the request is retried with the same
+// key. UserID is 0 for the requests made before signing in, their Key
+// is hashed along with the Fingerprint to scope it to the request. The
+// Fingerprint is a hash of the request, as the key can't be reused for
+// another one, and Status stays 0 while the request is being handled.
+// Headers keeps the response headers which are replayed, as JSON.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primarykey"`
+	UserID      uint      `gorm:"uniqueIndex:idx_idempotency_key;not null"`
+	Key         string    `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key;not null"`
+	Fingerprint string    `gorm:"type:varchar(64);not null"`
+	Status      int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"type:varchar(255)"`
+	Body        []byte    `gorm:"type:mediumblob"`
+	Headers     string    `gorm:"type:text"`
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	CreatedAt   time.Time
+}
+
+// IsPending determines if the request of the key is still being handled
+func (k *IdempotencyKey) IsPending() bool {
+	return k.Status == 0
+}
@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// List model definition
+//
+// Version is bumped by every write to its fields, see Todo.
+type List struct {
+	gorm.Model
+	WorkspaceID uint   `gorm:"index;not null;default:0"`
+	UserID      uint   `gorm:"index;not null"`
+	Name        string `gorm:"type:varchar(100);not null"`
+	Version     uint   `gorm:"not null;default:1"`
+	Todos       []Todo
+}
@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// ListMember model definition
+//
+// Members are the users a list is shared with, besides its owner.
+// They can see the list and have its todos assigned to them.
+type ListMember struct {
+	gorm.Model
+	ListID uint `gorm:"uniqueIndex:idx_list_member;not null"`
+	UserID uint `gorm:"uniqueIndex:idx_list_member;index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+}
+
+// TodoAssignee model definition
+//
+// The users a todo is assigned to, they are members of its list.
+type TodoAssignee struct {
+	gorm.Model
+	TodoID uint `gorm:"uniqueIndex:idx_todo_assignee;not null"`
+	UserID uint `gorm:"uniqueIndex:idx_todo_assignee;index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+}
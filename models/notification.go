@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification types
+const (
+	NotificationShared    = "shared"
+	NotificationAssigned  = "assigned"
+	NotificationDueSoon   = "due_soon"
+	NotificationCommented = "commented"
+	NotificationMentioned = "mentioned"
+)
+
+// NotificationTypes lists every notification type a user can opt out of
+var NotificationTypes = []string{
+	NotificationShared,
+	NotificationAssigned,
+	NotificationDueSoon,
+	NotificationCommented,
+	NotificationMentioned,
+}
+
+// Notification model definition
+type Notification struct {
+	gorm.Model
+	UserID  uint   `gorm:"index;not null"`
+	Type    string `gorm:"type:varchar(30);not null"`
+	Title   string `gorm:"type:varchar(255);not null"`
+	Body    string `gorm:"type:text"`
+	ActorID *uint
+	TodoID  *uint
+	ReadAt  *time.Time `gorm:"index"`
+}
+
+// IsRead determines if the user has read the notification
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference model definition
+//
+// A missing preference means the notification type is enabled.
+type NotificationPreference struct {
+	gorm.Model
+	UserID  uint   `gorm:"uniqueIndex:idx_notification_preference;not null"`
+	Type    string `gorm:"type:varchar(30);uniqueIndex:idx_notification_preference;not null"`
+	Enabled bool   `gorm:"not null"`
+}
+
+// IsNotificationType determines if t is a known notification type
+func IsNotificationType(t string) bool {
+	for _, nt := range NotificationTypes {
+		if nt == t {
+			return true
+		}
+	}
+	return false
+}
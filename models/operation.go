@@ -0,0 +1,166 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of operations recorded in the journal
+const (
+	OperationCreate   = "create"
+	OperationUpdate   = "update"
+	OperationMove     = "move"
+	OperationComplete = "complete"
+	OperationDelete   = "delete"
+)
+
+// Subjects of the operations in the journal
+const (
+	OperationTodo = "todo"
+	OperationList = "list"
+)
+
+// Operation model definition
+//
+// Before and After are JSON snapshots of the subject around the
+// operation, empty when it did not exist (or was in the trash).
+// Undoing the operation brings back Before, redoing it After.
+type Operation struct {
+	gorm.Model
+	WorkspaceID uint       `gorm:"index;not null;default:0"`
+	UserID      uint       `gorm:"index;not null"`
+	Kind        string     `gorm:"type:varchar(20);not null"`
+	Subject     string     `gorm:"type:varchar(20);not null"`
+	SubjectID   uint       `gorm:"not null"`
+	Before      string     `gorm:"type:text"`
+	After       string     `gorm:"type:text"`
+	UndoneAt    *time.Time `gorm:"index"`
+}
+
+// IsUndone determines if the operation was undone and can be redone
+func (o *Operation) IsUndone() bool {
+	return o.UndoneAt != nil
+}
+
+// TodoSnapshot is the state of a todo an operation can bring back
+type TodoSnapshot struct {
+	ListID      *uint      `json:"list_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueAt       *time.Time `json:"due_at"`
+	Timezone    string     `json:"timezone"`
+	Recurrence  string     `json:"recurrence"`
+	Priority    int        `json:"priority"`
+	Estimate    *int       `json:"estimate"`
+}
+
+// NewTodoSnapshot takes the snapshot of the todo. The due date is
+// kept in UTC at the precision every database stores, so snapshots
+// taken before and after a round trip compare equal.
+func NewTodoSnapshot(t *Todo) *TodoSnapshot {
+	s := &TodoSnapshot{
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		Timezone:    t.Timezone,
+		Recurrence:  t.Recurrence,
+		Priority:    t.Priority,
+	}
+	if t.ListID != nil {
+		id := *t.ListID
+		s.ListID = &id
+	}
+	if t.Estimate != nil {
+		estimate := *t.Estimate
+		s.Estimate = &estimate
+	}
+	if t.DueAt != nil {
+		due := t.DueAt.UTC().Truncate(time.Millisecond)
+		s.DueAt = &due
+	}
+	return s
+}
+
+// Fill sets the fields of the todo from the snapshot
+func (s *TodoSnapshot) Fill(t *Todo) {
+	t.ListID = s.ListID
+	t.Title = s.Title
+	t.Description = s.Description
+	t.Completed = s.Completed
+	t.DueAt = s.DueAt
+	t.Timezone = s.Timezone
+	t.Recurrence = s.Recurrence
+	t.Priority = s.Priority
+	t.Estimate = s.Estimate
+}
+
+// Equal determines if the snapshots are of the same state
+func (s *TodoSnapshot) Equal(other *TodoSnapshot) bool {
+	return EncodeSnapshot(s) == EncodeSnapshot(other)
+}
+
+// ListSnapshot is the state of a list an operation can bring back
+type ListSnapshot struct {
+	Name string `json:"name"`
+}
+
+// NewListSnapshot takes the snapshot of the list
+func NewListSnapshot(l *List) *ListSnapshot {
+	return &ListSnapshot{Name: l.Name}
+}
+
+// Fill sets the fields of the list from the snapshot
+func (s *ListSnapshot) Fill(l *List) {
+	l.Name = s.Name
+}
+
+// NewTodoOperation creates the operation which changed the todo
+// from before to after, either of them nil when it did not exist.
+func NewTodoOperation(kind string, t *Todo, before, after *TodoSnapshot) *Operation {
+	return &Operation{
+		WorkspaceID: t.WorkspaceID,
+		UserID:      t.UserID,
+		Kind:        kind,
+		Subject:     OperationTodo,
+		SubjectID:   t.ID,
+		Before:      EncodeSnapshot(before),
+		After:       EncodeSnapshot(after),
+	}
+}
+
+// NewListOperation creates the operation which changed the list
+// from before to after, either of them nil when it did not exist.
+func NewListOperation(kind string, l *List, before, after *ListSnapshot) *Operation {
+	return &Operation{
+		WorkspaceID: l.WorkspaceID,
+		UserID:      l.UserID,
+		Kind:        kind,
+		Subject:     OperationList,
+		SubjectID:   l.ID,
+		Before:      EncodeSnapshot(before),
+		After:       EncodeSnapshot(after),
+	}
+}
+
+// EncodeSnapshot encodes a *TodoSnapshot or *ListSnapshot as it is
+// stored in an operation, nil snapshots are stored empty.
+func EncodeSnapshot(s interface{}) string {
+	switch v := s.(type) {
+	case *TodoSnapshot:
+		if v == nil {
+			return ""
+		}
+	case *ListSnapshot:
+		if v == nil {
+			return ""
+		}
+	case nil:
+		return ""
+	}
+
+	b, _ := json.Marshal(s)
+	return string(b)
+}
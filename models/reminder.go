@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reminder delivery channels
+const (
+	ReminderEmail   = "email"
+	ReminderWebhook = "webhook"
+	ReminderInApp   = "in_app"
+)
+
+// Reminder model definition
+//
+// A reminder either fires at an absolute time (RemindAt) or a
+// number of minutes before the todo's due date (OffsetMinutes).
+// FireAt is the resolved time the scheduler polls on, and the
+// Lease columns guarantee only one instance delivers it. LeaseToken
+// tells apart the claims of the same owner.
+type Reminder struct {
+	gorm.Model
+	TodoID        uint   `gorm:"index;not null"`
+	Channel       string `gorm:"type:varchar(20);not null"`
+	Target        string `gorm:"type:varchar(255)"`
+	RemindAt      *time.Time
+	OffsetMinutes *int
+	FireAt        *time.Time `gorm:"index"`
+	SentAt        *time.Time `gorm:"index"`
+	FailedAt      *time.Time
+	LeaseOwner    string `gorm:"type:varchar(100)"`
+	LeaseToken    string `gorm:"type:varchar(32)"`
+	LeaseUntil    *time.Time
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string `gorm:"type:varchar(255)"`
+}
+
+// IsRelative determines if the reminder is relative to the due date
+func (r *Reminder) IsRelative() bool {
+	return r.OffsetMinutes != nil
+}
+
+// Schedule resolves FireAt using the given todo. A relative reminder
+// on a todo without a due date will not fire until one is set.
+func (r *Reminder) Schedule(t *Todo) {
+	switch {
+	case r.IsRelative() && t.DueAt != nil:
+		at := t.DueAt.Add(-time.Duration(*r.OffsetMinutes) * time.Minute).UTC()
+		r.FireAt = &at
+	case r.IsRelative():
+		r.FireAt = nil
+	case r.RemindAt != nil:
+		at := r.RemindAt.UTC()
+		r.FireAt = &at
+	}
+}
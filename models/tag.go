@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// TodoTag model definition
+//
+// The tags label todos across their lists, e.g. "billable". Their
+// names are kept trimmed and lowercase.
+type TodoTag struct {
+	gorm.Model
+	TodoID uint   `gorm:"uniqueIndex:idx_todo_tag;not null"`
+	Name   string `gorm:"type:varchar(50);uniqueIndex:idx_todo_tag;index;not null"`
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// templateVariable matches the {{variables}} of a template's texts
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// Template model definition
+//
+// A reusable list of todos, e.g. an onboarding checklist. ListName is
+// the name of the list it creates. The texts may hold {{variables}}
+// which are filled in when the template is instantiated. Shared
+// templates can be used by every member of the workspace, while only
+// their owner can change them.
+type Template struct {
+	gorm.Model
+	WorkspaceID uint   `gorm:"index;not null"`
+	UserID      uint   `gorm:"index;not null"`
+	Name        string `gorm:"type:varchar(100);not null"`
+	ListName    string `gorm:"type:varchar(100);not null"`
+	Shared      bool   `gorm:"not null;default:false"`
+	Todos       []TemplateTodo
+}
+
+// TemplateTodo model definition
+//
+// A todo of a template. DueOffset is the number of days after the
+// start date of the instantiation it is due, nil when it has no due
+// date. Estimate is the expected effort in minutes. The todos it
+// creates get its tags.
+type TemplateTodo struct {
+	ID          uint   `gorm:"primarykey"`
+	TemplateID  uint   `gorm:"index;not null"`
+	Position    int    `gorm:"not null;default:0"`
+	Title       string `gorm:"type:varchar(255);not null"`
+	Description string `gorm:"type:text"`
+	Priority    int    `gorm:"not null;default:0"`
+	Estimate    *int
+	DueOffset   *int
+	Tags        []TemplateTodoTag
+}
+
+// TemplateTodoTag model definition
+//
+// A tag of a todo of a template, see TodoTag.
+type TemplateTodoTag struct {
+	ID             uint   `gorm:"primarykey"`
+	TemplateTodoID uint   `gorm:"index;not null"`
+	Name           string `gorm:"type:varchar(50);not null"`
+}
+
+// TagNames returns the names of the tags of the template todo
+func (tt *TemplateTodo) TagNames() []string {
+	names := make([]string, 0, len(tt.Tags))
+	for _, tag := range tt.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// Variables returns the names of the variables used by the template,
+// sorted and without duplicates
+func (t *Template) Variables() []string {
+	texts := []string{t.ListName}
+	for _, todo := range t.Todos {
+		texts = append(texts, todo.Title, todo.Description)
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, text := range texts {
+		for _, match := range templateVariable.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Instantiate creates the list and todos of the template for the
+// user, with the variables filled in and the due dates counted from
+// the start. The todos are in the order of the template, with their
+// tags.
+func (t *Template) Instantiate(userID uint, start time.Time, timezone string, vars map[string]string) (*List, []*Todo) {
+	list := &List{UserID: userID, Name: Substitute(t.ListName, vars)}
+
+	todos := make([]*Todo, 0, len(t.Todos))
+	for _, tt := range t.Todos {
+		todo := &Todo{
+			UserID:      userID,
+			Title:       Substitute(tt.Title, vars),
+			Description: Substitute(tt.Description, vars),
+			Priority:    tt.Priority,
+			Estimate:    tt.Estimate,
+			Timezone:    timezone,
+		}
+		for _, tag := range tt.Tags {
+			todo.Tags = append(todo.Tags, TodoTag{Name: tag.Name})
+		}
+		if tt.DueOffset != nil {
+			due := start.AddDate(0, 0, *tt.DueOffset).UTC()
+			todo.DueAt = &due
+		}
+		todos = append(todos, todo)
+	}
+	return list, todos
+}
+
+// Substitute replaces the {{variables}} of the text with their
+// values, the unknown ones are left as they are
+func Substitute(text string, vars map[string]string) string {
+	return templateVariable.ReplaceAllStringFunc(text, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-2])
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
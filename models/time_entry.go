@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimeEntry model definition
+//
+// An entry without StoppedAt is the running timer of the user,
+// every user has at most one.
+type TimeEntry struct {
+	gorm.Model
+	UserID    uint      `gorm:"index;not null"`
+	TodoID    uint      `gorm:"index;not null"`
+	Todo      Todo      `gorm:"foreignKey:TodoID"`
+	StartedAt time.Time `gorm:"index;not null"`
+	StoppedAt *time.Time
+}
+
+// IsRunning determines if the entry is a timer which is still running
+func (e *TimeEntry) IsRunning() bool {
+	return e.StoppedAt == nil
+}
+
+// Duration is the time tracked by the entry, a running timer up to now
+func (e *TimeEntry) Duration(now time.Time) time.Duration {
+	return e.Between(e.StartedAt, now, now)
+}
+
+// Between is the time tracked by the entry between from and to,
+// a running timer up to now
+func (e *TimeEntry) Between(from, to, now time.Time) time.Duration {
+	start, stop := e.StartedAt, now
+	if e.StoppedAt != nil {
+		stop = *e.StoppedAt
+	}
+	if start.Before(from) {
+		start = from
+	}
+	if stop.After(to) {
+		stop = to
+	}
+	if !stop.After(start) {
+		return 0
+	}
+	return stop.Sub(start)
+}
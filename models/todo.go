@@ -0,0 +1,129 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Priority levels of the todos, the higher the more urgent
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// PriorityNames are the names of the priority levels by value
+var PriorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// Todo model definition
+//
+// Recurrence is an RFC 5545 recurrence rule without the "RRULE:"
+// prefix, e.g. "FREQ=WEEKLY;BYDAY=MO", for todos which repeat.
+// Estimate is the expected effort in minutes. Blockers are the
+// dependencies on the todos which have to be completed first.
+// ColumnID is the column of its list's board the todo is in, nil
+// while it is not on the board, and Position its place in there.
+// Version is bumped by every write to its fields, so concurrent
+// editors can't silently overwrite each other's changes. Tags are
+// sorted by name.
+type Todo struct {
+	gorm.Model
+	WorkspaceID uint       `gorm:"index;not null;default:0"`
+	UserID      uint       `gorm:"index;not null"`
+	ListID      *uint      `gorm:"index"`
+	Title       string     `gorm:"type:varchar(255);not null"`
+	Description string     `gorm:"type:text"`
+	Completed   bool       `gorm:"not null;default:false"`
+	DueAt       *time.Time `gorm:"index"`
+	Timezone    string     `gorm:"type:varchar(64)"`
+	Recurrence  string     `gorm:"type:varchar(255)"`
+	Priority    int        `gorm:"index;not null;default:0"`
+	Estimate    *int
+	ColumnID    *uint `gorm:"index"`
+	Position    int   `gorm:"not null;default:0"`
+	Version     uint  `gorm:"not null;default:1"`
+	Reminders   []Reminder
+	Assignees   []TodoAssignee
+	Blockers    []TodoDependency `gorm:"foreignKey:TodoID"`
+	Tags        []TodoTag
+}
+
+// Location returns the todo's timezone, falling back to UTC
+// when none was set or the stored name is no longer valid.
+func (t *Todo) Location() *time.Location {
+	if t.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// LocalDueAt returns the due date in the todo's own timezone
+func (t *Todo) LocalDueAt() *time.Time {
+	if t.DueAt == nil {
+		return nil
+	}
+	due := t.DueAt.In(t.Location())
+	return &due
+}
+
+// AssigneeIDs returns the IDs of the users the todo is assigned to
+func (t *Todo) AssigneeIDs() []uint {
+	ids := make([]uint, 0, len(t.Assignees))
+	for _, a := range t.Assignees {
+		ids = append(ids, a.UserID)
+	}
+	return ids
+}
+
+// PriorityName returns the name of the todo's priority level
+func (t *Todo) PriorityName() string {
+	if t.Priority < 0 || t.Priority >= len(PriorityNames) {
+		return PriorityNames[PriorityNone]
+	}
+	return PriorityNames[t.Priority]
+}
+
+// BlockerIDs returns the IDs of the todos which block the todo
+func (t *Todo) BlockerIDs() []uint {
+	ids := make([]uint, 0, len(t.Blockers))
+	for _, b := range t.Blockers {
+		ids = append(ids, b.BlockerID)
+	}
+	return ids
+}
+
+// TagNames returns the names of the tags of the todo
+func (t *Todo) TagNames() []string {
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// HasTag determines if the todo is tagged with the name
+func (t *Todo) HasTag(name string) bool {
+	for _, tag := range t.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePriority returns the value of the named priority level
+func ParsePriority(name string) (int, bool) {
+	for value, n := range PriorityNames {
+		if n == name {
+			return value, true
+		}
+	}
+	return PriorityNone, false
+}
@@ -8,12 +8,22 @@ import (
 )
 
 // User model definition
+//
+// Users belong to many workspaces through their Memberships.
+// Version is bumped by every update of the user, see Todo. Locale is
+// the language the user prefers, the one of the requests when empty.
+// Timezone is the IANA name of the user's timezone, their reports are
+// in UTC when it is empty.
 type User struct {
 	gorm.Model
-	Username string `gorm:"type:varchar(30);unique_index;not null"`
-	Email    string `gorm:"type:varchar(100);unique_index;not null"`
-	Name     string `gorm:"type:varchar(100);not null"`
-	Password string `gorm:"type:varchar(100);"`
+	Username    string       `gorm:"type:varchar(30);unique_index;not null"`
+	Email       string       `gorm:"type:varchar(100);unique_index;not null"`
+	Name        string       `gorm:"type:varchar(100);not null"`
+	Password    string       `gorm:"type:varchar(100);"`
+	Locale      string       `gorm:"type:varchar(10);not null;default:''"`
+	Timezone    string       `gorm:"type:varchar(64);not null;default:''"`
+	Version     uint         `gorm:"not null;default:1"`
+	Memberships []Membership `gorm:"foreignKey:UserID"`
 }
 
 // BeforeCreate is called before saving the new user to the database
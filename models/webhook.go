@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook model definition
+//
+// Events is a comma separated list of the event types the
+// endpoint subscribes to, "*" subscribes to all of them. It gets
+// the events of the workspace it was created in which its user
+// can see, whoever made the change.
+type Webhook struct {
+	gorm.Model
+	UserID       uint   `gorm:"index;not null"`
+	WorkspaceID  uint   `gorm:"index;not null;default:0"`
+	URL          string `gorm:"type:varchar(255);not null"`
+	Secret       string `gorm:"type:varchar(100);not null"`
+	Events       string `gorm:"type:varchar(255);not null"`
+	Description  string `gorm:"type:varchar(255)"`
+	Active       bool   `gorm:"not null;default:true"`
+	FailureCount int    `gorm:"not null;default:0"`
+	DisabledAt   *time.Time
+}
+
+// EventTypes returns the event types the webhook subscribes to
+func (w *Webhook) EventTypes() []string {
+	if w.Events == "" {
+		return []string{}
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribes determines if the webhook wants the event type
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes() {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery model definition
+//
+// Deliveries are the persistent queue of the webhooks, each one
+// keeps the payload it sends so retries send the same body.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint       `gorm:"index;not null"`
+	EventType      string     `gorm:"type:varchar(50);not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"type:varchar(20);index;not null"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	ResponseBody   string `gorm:"type:text"`
+	LastError      string `gorm:"type:varchar(255)"`
+	DeliveredAt    *time.Time
+	LeaseOwner     string `gorm:"type:varchar(100)"`
+	LeaseUntil     *time.Time
+}
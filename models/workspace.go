@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Roles of the members of a workspace
+const (
+	WorkspaceOwner  = "owner"
+	WorkspaceAdmin  = "admin"
+	WorkspaceMember = "member"
+)
+
+// WorkspaceRoles are the roles a member of a workspace can have
+var WorkspaceRoles = []string{WorkspaceOwner, WorkspaceAdmin, WorkspaceMember}
+
+// Workspace model definition
+//
+// Workspaces are the tenants of the deployment, every list and todo
+// belongs to one and is only seen from within it. Every user has a
+// Personal workspace of their own besides the ones they join. OwnerID
+// is only set on the personal ones, so a user can't end up with two.
+type Workspace struct {
+	gorm.Model
+	Name     string `gorm:"type:varchar(100);not null"`
+	OwnerID  *uint  `gorm:"uniqueIndex:idx_personal_workspace"`
+	Personal bool   `gorm:"uniqueIndex:idx_personal_workspace;not null;default:false"`
+	Members  []Membership
+}
+
+// Membership model definition
+//
+// The users of a workspace and their Role in it.
+type Membership struct {
+	gorm.Model
+	WorkspaceID uint      `gorm:"uniqueIndex:idx_membership;not null"`
+	UserID      uint      `gorm:"uniqueIndex:idx_membership;index;not null"`
+	Role        string    `gorm:"type:varchar(20);not null"`
+	User        User      `gorm:"foreignKey:UserID"`
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID"`
+}
+
+// CanManage determines if the member can invite and remove members
+func (m *Membership) CanManage() bool {
+	return m.Role == WorkspaceOwner || m.Role == WorkspaceAdmin
+}
+
+// Invite model definition
+//
+// An invitation to join a workspace with a role, sent to an email
+// address. Only the hash of its token is stored.
+type Invite struct {
+	gorm.Model
+	WorkspaceID uint      `gorm:"index;not null"`
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID"`
+	InviterID   uint      `gorm:"not null"`
+	Email       string    `gorm:"type:varchar(100);not null"`
+	Role        string    `gorm:"type:varchar(20);not null"`
+	TokenHash   string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	AcceptedAt  *time.Time
+}
+
+// IsPending determines if the invite can still be accepted
+func (i *Invite) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
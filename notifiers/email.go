@@ -0,0 +1,25 @@
+package notifiers
+
+import "context"
+
+// EmailNotifier delivers reminders by email
+type EmailNotifier struct {
+	mailer Mailer
+}
+
+var _ Notifier = &EmailNotifier{}
+
+// NewEmail creates EmailNotifier instance
+func NewEmail(mailer Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer}
+}
+
+// Notify sends the message to the reminder's target, or
+// to the todo owner's email when no target was given.
+func (n *EmailNotifier) Notify(ctx context.Context, m Message) error {
+	to := m.Reminder.Target
+	if to == "" {
+		to = m.User.Email
+	}
+	return n.mailer.Send(to, m.Subject(), m.Body())
+}
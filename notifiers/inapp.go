@@ -0,0 +1,35 @@
+package notifiers
+
+import (
+	"context"
+
+	"github.com/ksungcaya/todo-echo/models"
+)
+
+// InAppStore keeps notifications the user will see inside the app
+type InAppStore interface {
+	Push(n *models.Notification) error
+}
+
+// InAppNotifier delivers reminders to the user's in-app inbox
+type InAppNotifier struct {
+	store InAppStore
+}
+
+var _ Notifier = &InAppNotifier{}
+
+// NewInApp creates InAppNotifier instance
+func NewInApp(store InAppStore) *InAppNotifier {
+	return &InAppNotifier{store}
+}
+
+// Notify pushes the message to the todo owner's inbox
+func (n *InAppNotifier) Notify(ctx context.Context, m Message) error {
+	return n.store.Push(&models.Notification{
+		UserID: m.User.ID,
+		Type:   models.NotificationDueSoon,
+		Title:  m.Subject(),
+		Body:   m.Body(),
+		TodoID: &m.Todo.ID,
+	})
+}
@@ -0,0 +1,103 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/gommon/log"
+)
+
+// Inbox creates the in-app notifications of the users
+// while honouring their notification preferences.
+type Inbox struct {
+	nr        repositories.NotificationRepository
+	retention time.Duration
+}
+
+var _ InAppStore = &Inbox{}
+
+// NewInbox creates Inbox instance. Read notifications
+// older than the retention are removed by Prune.
+func NewInbox(nr repositories.NotificationRepository, retention time.Duration) *Inbox {
+	return &Inbox{nr, retention}
+}
+
+// Push stores the notification unless the user opted out of its type
+func (i *Inbox) Push(n *models.Notification) error {
+	if !i.nr.IsEnabled(n.UserID, n.Type) {
+		return nil
+	}
+	return i.nr.Create(n)
+}
+
+// SharedWithYou notifies the user that a list was shared with them
+func (i *Inbox) SharedWithYou(to *models.User, by *models.User, list *models.List) error {
+	return i.fromActor(to, by, nil, models.NotificationShared,
+		fmt.Sprintf("%s shared %q with you", by.Name, list.Name))
+}
+
+// Assigned notifies the user that a todo was assigned to them
+func (i *Inbox) Assigned(to *models.User, by *models.User, todo *models.Todo) error {
+	return i.fromActor(to, by, &todo.ID, models.NotificationAssigned,
+		fmt.Sprintf("%s assigned %q to you", by.Name, todo.Title))
+}
+
+// Unassigned notifies the user that a todo is not assigned to them anymore
+func (i *Inbox) Unassigned(to *models.User, by *models.User, todo *models.Todo) error {
+	return i.fromActor(to, by, &todo.ID, models.NotificationAssigned,
+		fmt.Sprintf("%s unassigned you from %q", by.Name, todo.Title))
+}
+
+// Commented notifies the user that someone commented on a todo
+func (i *Inbox) Commented(to *models.User, by *models.User, todo *models.Todo) error {
+	return i.fromActor(to, by, &todo.ID, models.NotificationCommented,
+		fmt.Sprintf("%s commented on %q", by.Name, todo.Title))
+}
+
+// Mentioned notifies the user that someone mentioned them in a comment on a todo
+func (i *Inbox) Mentioned(to *models.User, by *models.User, todo *models.Todo) error {
+	return i.fromActor(to, by, &todo.ID, models.NotificationMentioned,
+		fmt.Sprintf("%s mentioned you on %q", by.Name, todo.Title))
+}
+
+// DueSoon notifies the user that a todo is about to be due
+func (i *Inbox) DueSoon(to *models.User, todo *models.Todo) error {
+	return i.Push(&models.Notification{
+		UserID: to.ID,
+		Type:   models.NotificationDueSoon,
+		Title:  fmt.Sprintf("%q is due soon", todo.Title),
+		TodoID: &todo.ID,
+	})
+}
+
+// Prune permanently removes the read notifications past the retention.
+// It has the signature of a scheduler job.
+func (i *Inbox) Prune(ctx context.Context, now time.Time) {
+	n, err := i.nr.PruneRead(now.Add(-i.retention))
+	if err != nil {
+		log.Errorf("inbox: pruning notifications: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("inbox: pruned %d read notifications", n)
+	}
+}
+
+// fromActor pushes a notification caused by another user, about the
+// todo of todoID if it is given. Users are never notified about their
+// own actions.
+func (i *Inbox) fromActor(to *models.User, by *models.User, todoID *uint, notificationType string, title string) error {
+	if to.ID == by.ID {
+		return nil
+	}
+	return i.Push(&models.Notification{
+		UserID:  to.ID,
+		Type:    notificationType,
+		Title:   title,
+		ActorID: &by.ID,
+		TodoID:  todoID,
+	})
+}
@@ -0,0 +1,50 @@
+package notifiers
+
+import (
+	"testing"
+
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var (
+	alice = &models.User{Model: gorm.Model{ID: 1}, Name: "Alice"}
+	bob   = &models.User{Model: gorm.Model{ID: 2}, Name: "Bob"}
+	todo  = &models.Todo{Model: gorm.Model{ID: 3}, UserID: 1, Title: "Ship release"}
+)
+
+func TestInboxNotifiesAboutOthersActions(t *testing.T) {
+	repo := &mocks.NotificationRepository{}
+	repo.On("IsEnabled", uint(2), models.NotificationShared).Return(true)
+	repo.On("Create", mock.AnythingOfType("*models.Notification")).Return(nil)
+
+	list := &models.List{Model: gorm.Model{ID: 7}, UserID: 1, Name: "Release"}
+	assert.NoError(t, NewInbox(repo, 0).SharedWithYou(bob, alice, list))
+
+	n := repo.Calls[1].Arguments.Get(0).(*models.Notification)
+	assert.Equal(t, uint(2), n.UserID)
+	assert.Equal(t, models.NotificationShared, n.Type)
+	assert.Equal(t, `Alice shared "Release" with you`, n.Title)
+	assert.Equal(t, uint(1), *n.ActorID)
+	assert.Nil(t, n.TodoID)
+}
+
+func TestInboxIgnoresOwnActions(t *testing.T) {
+	repo := &mocks.NotificationRepository{}
+
+	assert.NoError(t, NewInbox(repo, 0).Assigned(alice, alice, todo))
+
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestInboxHonoursPreferences(t *testing.T) {
+	repo := &mocks.NotificationRepository{}
+	repo.On("IsEnabled", uint(1), models.NotificationDueSoon).Return(false)
+
+	assert.NoError(t, NewInbox(repo, 0).DueSoon(alice, todo))
+
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
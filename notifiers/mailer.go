@@ -0,0 +1,53 @@
+package notifiers
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/configs"
+)
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// SMTPMailer is a Mailer backed by an SMTP server
+type SMTPMailer struct {
+	config configs.MailConfig
+}
+
+var _ Mailer = &SMTPMailer{}
+
+// NewSMTPMailer creates SMTPMailer instance
+func NewSMTPMailer(config configs.MailConfig) *SMTPMailer {
+	return &SMTPMailer{config}
+}
+
+// Send will send the email through the configured SMTP server
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+	return smtp.SendMail(addr, auth, m.config.From, []string{to}, m.message(to, subject, body))
+}
+
+// message builds the email. The subject is encoded as an RFC 2047
+// word when needed, so one taken from e.g. the title of a todo can't
+// break the headers.
+func (m *SMTPMailer) message(to string, subject string, body string) []byte {
+	return []byte(strings.Join([]string{
+		"From: " + m.config.From,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n"))
+}
@@ -0,0 +1,25 @@
+package notifiers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailerEncodesTheSubject(t *testing.T) {
+	m := NewSMTPMailer(configs.MailConfig{From: "todo@example.com"})
+
+	msg := string(m.message("bob@example.com", "Due soon\r\nBcc: eve@example.com", "body"))
+
+	assert.NotContains(t, msg, "\r\nBcc:")
+	assert.Contains(t, msg, "Subject: =?utf-8?q?Due_soon")
+	assert.True(t, strings.HasPrefix(msg, "From: todo@example.com\r\nTo: bob@example.com\r\n"))
+}
+
+func TestMailerKeepsPlainSubjects(t *testing.T) {
+	m := NewSMTPMailer(configs.MailConfig{From: "todo@example.com"})
+
+	assert.Contains(t, string(m.message("bob@example.com", "Ship release", "body")), "\r\nSubject: Ship release\r\n")
+}
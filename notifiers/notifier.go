@@ -0,0 +1,34 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ksungcaya/todo-echo/models"
+)
+
+// Notifier delivers a reminder through a single channel
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// Message is what gets delivered when a reminder fires
+type Message struct {
+	User     *models.User
+	Todo     *models.Todo
+	Reminder *models.Reminder
+}
+
+// Subject is the short title of the message
+func (m Message) Subject() string {
+	return fmt.Sprintf("Reminder: %s", m.Todo.Title)
+}
+
+// Body is the human readable content of the message
+func (m Message) Body() string {
+	due := m.Todo.LocalDueAt()
+	if due == nil {
+		return fmt.Sprintf("Don't forget to %q.", m.Todo.Title)
+	}
+	return fmt.Sprintf("%q is due on %s.", m.Todo.Title, due.Format("Mon, 02 Jan 2006 15:04 MST"))
+}
@@ -0,0 +1,77 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookNotifier delivers reminders by POSTing JSON to the reminder's target URL
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+var _ Notifier = &WebhookNotifier{}
+
+// webhookPayload is a private struct for the webhook request body
+type webhookPayload struct {
+	Event      string     `json:"event"`
+	TodoID     uint       `json:"todo_id"`
+	ReminderID uint       `json:"reminder_id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	DueAt      *time.Time `json:"due_at"`
+	Timezone   string     `json:"timezone,omitempty"`
+}
+
+// NewWebhook creates WebhookNotifier instance
+func NewWebhook(client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{client}
+}
+
+// Notify posts the message, any non 2xx response is treated as a failure
+func (n *WebhookNotifier) Notify(ctx context.Context, m Message) error {
+	if m.Reminder.Target == "" {
+		return errors.New("webhook reminder has no target url")
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:      "reminder.due",
+		TodoID:     m.Todo.ID,
+		ReminderID: m.Reminder.ID,
+		Title:      m.Todo.Title,
+		Message:    m.Body(),
+		DueAt:      m.Todo.DueAt,
+		Timezone:   m.Todo.Timezone,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.Reminder.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+// Package patch applies the patches of PATCH requests to JSON documents,
+// JSON Merge Patches (RFC 7396) and JSON Patches (RFC 6902).
+//
+// A merge patch is a document of the fields to change, null removing
+// them. A JSON patch is a list of add, remove, replace, move, copy and
+// test operations on the values its JSON Pointers (RFC 6901) point to.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a test operation of a JSON patch
+// finds another value than the one it expects
+var ErrTestFailed = errors.New("The test operation of the patch failed")
+
+// Operation is an operation of a JSON patch, From is only used by
+// move and copy and Value by add, replace and test
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Merge applies a JSON merge patch to the document
+func Merge(doc []byte, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// Apply applies a JSON patch to the document. The operations are
+// applied in order, and none of them when one fails.
+func Apply(doc []byte, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		if target, err = apply(target, op); err != nil {
+			if err == ErrTestFailed {
+				return nil, err
+			}
+			return nil, fmt.Errorf("operation %d: %v", i, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// merge merges the patch into the target as RFC 7396 describes
+func merge(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+			continue
+		}
+		t[name] = merge(t[name], value)
+	}
+	return t
+}
+
+// apply applies an operation to the document and returns the result
+func apply(doc interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		// a null value is given, unlike a missing one
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("the %s operation requires a value", op.Op)
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, op.Path, value)
+		case "replace":
+			return replace(doc, op.Path, value)
+		default:
+			current, err := get(doc, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, op.Path)
+	case "move", "copy":
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, fmt.Errorf("%q can't be moved into itself", op.From)
+			}
+			if doc, err = remove(doc, op.From); err != nil {
+				return nil, err
+			}
+		} else {
+			value = clone(value)
+		}
+		return add(doc, op.Path, value)
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// get returns the value the pointer points to
+func get(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	value := doc
+	for _, token := range tokens {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%q does not exist", pointer)
+			}
+			value = child
+		case []interface{}:
+			i, err := index(token, len(v)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%q does not exist", pointer)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("%q does not exist", pointer)
+		}
+	}
+	return value, nil
+}
+
+// add puts the value where the pointer points to, its parent must
+// exist. A member of an object is replaced while an element of an
+// array is inserted, "-" appending it.
+func add(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return update(doc, tokens, pointer, func(parent interface{}, last string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[last] = value
+			return p, nil
+		case []interface{}:
+			i := len(p)
+			if last != "-" {
+				if i, err = index(last, len(p)); err != nil {
+					return nil, fmt.Errorf("%q is out of range", pointer)
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("the parent of %q does not exist", pointer)
+	})
+}
+
+// replace changes the value the pointer points to, which must exist
+func replace(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	if _, err := get(doc, pointer); err != nil {
+		return nil, err
+	}
+	tokens, _ := parsePointer(pointer)
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return update(doc, tokens, pointer, func(parent interface{}, last string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[last] = value
+			return p, nil
+		case []interface{}:
+			i, _ := index(last, len(p)-1)
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("%q does not exist", pointer)
+	})
+}
+
+// remove takes out the value the pointer points to, which must exist
+func remove(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("the whole document can't be removed")
+	}
+
+	return update(doc, tokens, pointer, func(parent interface{}, last string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[last]; !ok {
+				return nil, fmt.Errorf("%q does not exist", pointer)
+			}
+			delete(p, last)
+			return p, nil
+		case []interface{}:
+			i, err := index(last, len(p)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%q does not exist", pointer)
+			}
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%q does not exist", pointer)
+	})
+}
+
+// update replaces the parent of the last token with what fn makes of
+// it, arrays change when they grow or shrink so their parents are
+// updated on the way back
+func update(doc interface{}, tokens []string, pointer string, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("the parent of %q does not exist", pointer)
+		}
+		child, err := update(child, tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = child
+		return v, nil
+	case []interface{}:
+		i, err := index(tokens[0], len(v)-1)
+		if err != nil {
+			return nil, fmt.Errorf("the parent of %q does not exist", pointer)
+		}
+		child, err := update(v[i], tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = child
+		return v, nil
+	}
+	return nil, fmt.Errorf("the parent of %q does not exist", pointer)
+}
+
+// parsePointer splits a JSON pointer into its unescaped tokens, the
+// empty pointer being the whole document
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%q is not a JSON pointer", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// index parses an array index which can't be over max, leading zeros
+// are not allowed
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, fmt.Errorf("invalid index %q", token)
+	}
+	return i, nil
+}
+
+// clone deep copies a decoded JSON value, so a copy does not share
+// its objects and arrays with the original
+func clone(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for name, child := range v {
+			c[name] = clone(child)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, child := range v {
+			c[i] = clone(child)
+		}
+		return c
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	// the examples of RFC 7396, appendix A
+	tests := []struct{ doc, patch, result string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		result, err := Merge([]byte(test.doc), []byte(test.patch))
+		if assert.NoError(t, err, test.patch) {
+			assert.JSONEq(t, test.result, string(result), test.patch)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	// the examples of RFC 6902, appendix A
+	tests := []struct{ doc, patch, result string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		// a null value clears the field, unlike a missing one
+		{`{"due_at":"2026-01-02"}`, `[{"op":"replace","path":"/due_at","value":null}]`, `{"due_at":null}`},
+		{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+	}
+
+	for _, test := range tests {
+		result, err := Apply([]byte(test.doc), []byte(test.patch))
+		if assert.NoError(t, err, test.patch) {
+			assert.JSONEq(t, test.result, string(result), test.patch)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	doc := []byte(`{"baz":"qux","foo":["bar"]}`)
+
+	_, err := Apply(doc, []byte(`[{"op":"test","path":"/baz","value":"bar"}]`))
+	assert.Equal(t, ErrTestFailed, err)
+
+	for _, patch := range []string{
+		`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		`[{"op":"add","path":"/foo/2","value":"qux"}]`,
+		`[{"op":"add","path":"/missing/child","value":1}]`,
+		`[{"op":"add","path":"/foo/01","value":1}]`,
+		`[{"op":"replace","path":"/missing","value":1}]`,
+		`[{"op":"remove","path":"/foo/1"}]`,
+		`[{"op":"move","from":"/foo","path":"/foo/0"}]`,
+		`[{"op":"add","path":"/baz"}]`,
+		`[{"op":"add","path":"baz","value":1}]`,
+		`[{"op":"frobnicate","path":"/baz"}]`,
+		`{"op":"add","path":"/baz","value":1}`,
+	} {
+		_, err := Apply(doc, []byte(patch))
+		assert.Error(t, err, patch)
+	}
+}
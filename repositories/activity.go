@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository will interact to the activities table
+type ActivityRepository interface {
+	// Methods for querying activities
+	ByUser(userID uint, offset int, limit int) ([]models.Activity, int64)
+	ByList(listID uint, offset int, limit int) ([]models.Activity, int64)
+
+	// Methods for altering activities
+	Record(activity *models.Activity, window time.Duration) error
+}
+
+type activityRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ ActivityRepository = &activityRepoGorm{}
+
+// NewActivityRepository creates instance of ActivityRepository
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepoGorm{db}
+}
+
+// ByUser will return a page of the user's feed, the latest first,
+// along with how many activities it has
+func (ar *activityRepoGorm) ByUser(userID uint, offset int, limit int) ([]models.Activity, int64) {
+	return ar.page("user_id = ?", userID, offset, limit)
+}
+
+// ByList will return a page of the feed of the list, the latest
+// first, along with how many activities it has
+func (ar *activityRepoGorm) ByList(listID uint, offset int, limit int) ([]models.Activity, int64) {
+	return ar.page("list_id = ?", listID, offset, limit)
+}
+
+// Record will add the activity to the user's feed. An update made
+// by the same actor to the same subject within the window of the
+// last update of the feed is grouped into it instead.
+func (ar *activityRepoGorm) Record(activity *models.Activity, window time.Duration) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		if activity.Verb != models.ActivityUpdated {
+			return tx.Create(activity).Error
+		}
+
+		var last models.Activity
+		err := tx.Where("user_id = ?", activity.UserID).Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		grouped := last.ID != 0 &&
+			last.Verb == models.ActivityUpdated &&
+			last.Subject == activity.Subject &&
+			last.SubjectID == activity.SubjectID &&
+			last.ActorID == activity.ActorID &&
+			time.Since(last.UpdatedAt) <= window
+		if !grouped {
+			return tx.Create(activity).Error
+		}
+
+		last.AddFields(activity.FieldNames()...)
+		last.Title = activity.Title
+		last.ListID = activity.ListID
+		last.ListName = activity.ListName
+		last.Count++
+		if err := tx.Save(&last).Error; err != nil {
+			return err
+		}
+		*activity = last
+		return nil
+	})
+}
+
+// page is a private function returning a page of the activities
+// matching the condition, the latest first, along with how many
+// there are
+func (ar *activityRepoGorm) page(where string, id uint, offset int, limit int) ([]models.Activity, int64) {
+	var total int64
+	ar.db.Model(&models.Activity{}).Where(where, id).Count(&total)
+
+	var activities []models.Activity
+	ar.db.Where(where, id).
+		Preload("Actor").
+		Order("updated_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&activities)
+
+	return activities, total
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ActivityRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo ActivityRepository
+	user *models.User
+}
+
+func (suite *ActivityRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Activity{})
+	db.Unscoped().Where("1 = 1").Delete(&models.User{})
+
+	suite.db = db
+	suite.repo = NewActivityRepository(db)
+	suite.user = &models.User{Username: "bob", Email: "bob@example.com", Name: "Bob", Password: "secret"}
+	db.Create(suite.user)
+}
+
+// updated creates an update of the todo's fields made by the suite's user
+func (suite *ActivityRepositoryTestSuite) updated(todoID uint, fields string) *models.Activity {
+	listID := uint(3)
+	return &models.Activity{
+		UserID:    suite.user.ID,
+		ActorID:   suite.user.ID,
+		ListID:    &listID,
+		Verb:      models.ActivityUpdated,
+		Subject:   models.ActivityTodo,
+		SubjectID: todoID,
+		Title:     "Ship release",
+		Fields:    fields,
+	}
+}
+
+func (suite *ActivityRepositoryTestSuite) TestRecordGroupsConsecutiveUpdates() {
+	assert := assert.New(suite.T())
+
+	assert.NoError(suite.repo.Record(suite.updated(1, "title"), time.Minute))
+	assert.NoError(suite.repo.Record(suite.updated(1, "description,title"), time.Minute))
+
+	activities, total := suite.repo.ByUser(suite.user.ID, 0, 10)
+	assert.Equal(int64(1), total)
+	if assert.Len(activities, 1) {
+		assert.Equal(2, activities[0].Count)
+		assert.Equal([]string{"title", "description"}, activities[0].FieldNames())
+		assert.Equal("Bob", activities[0].Actor.Name)
+	}
+}
+
+func (suite *ActivityRepositoryTestSuite) TestRecordDoesNotGroupOtherChanges() {
+	assert := assert.New(suite.T())
+
+	assert.NoError(suite.repo.Record(suite.updated(1, "title"), time.Minute))
+	assert.NoError(suite.repo.Record(suite.updated(2, "title"), time.Minute))
+	assert.NoError(suite.repo.Record(suite.updated(2, "title"), 0))
+
+	completed := suite.updated(2, "")
+	completed.Verb = models.ActivityCompleted
+	assert.NoError(suite.repo.Record(completed, time.Minute))
+	assert.NoError(suite.repo.Record(suite.updated(2, "title"), time.Minute))
+
+	activities, total := suite.repo.ByList(3, 0, 2)
+	assert.Equal(int64(5), total)
+	if assert.Len(activities, 2) {
+		assert.Equal(models.ActivityUpdated, activities[0].Verb)
+		assert.Equal(models.ActivityCompleted, activities[1].Verb)
+	}
+
+	activities, _ = suite.repo.ByList(4, 0, 10)
+	assert.Empty(activities)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestActivityRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ActivityRepositoryTestSuite))
+}
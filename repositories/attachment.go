@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository will interact to the attachments table.
+// The files themselves are kept in a storage.Storage.
+type AttachmentRepository interface {
+	// Methods for querying attachments
+	ByID(id uint) *models.Attachment
+	ByTodo(todoID uint) []models.Attachment
+	Usage(userID uint) int64
+	Orphaned(limit int) []models.Attachment
+
+	// Methods for altering attachments
+	Create(attachment *models.Attachment) error
+	Delete(id uint) error
+}
+
+type attachmentRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ AttachmentRepository = &attachmentRepoGorm{}
+
+// NewAttachmentRepository creates instance of AttachmentRepository
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepoGorm{db}
+}
+
+// ByID will look up an attachment by ID
+// If no record was found, the method will return nil
+func (ar *attachmentRepoGorm) ByID(id uint) *models.Attachment {
+	var a models.Attachment
+	err := ar.db.First(&a, id).Error
+	if err == nil {
+		return &a
+	}
+
+	return nil
+}
+
+// ByTodo will return the attachments of the todo, the oldest first
+func (ar *attachmentRepoGorm) ByTodo(todoID uint) []models.Attachment {
+	var attachments []models.Attachment
+	ar.db.Where("todo_id = ?", todoID).Order("id").Find(&attachments)
+
+	return attachments
+}
+
+// Usage will return how many bytes the attachments of the user take,
+// including the ones of their todos in the trash
+func (ar *attachmentRepoGorm) Usage(userID uint) int64 {
+	var usage int64
+	ar.db.Model(&models.Attachment{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ?", userID).
+		Scan(&usage)
+
+	return usage
+}
+
+// Orphaned will return up to limit attachments of todos which were
+// permanently deleted, whose files are left to clean up
+func (ar *attachmentRepoGorm) Orphaned(limit int) []models.Attachment {
+	var attachments []models.Attachment
+	ar.db.Where("todo_id NOT IN (?)", ar.db.Unscoped().Model(&models.Todo{}).Select("id")).
+		Order("id").
+		Limit(limit).
+		Find(&attachments)
+
+	return attachments
+}
+
+// Create will create a new attachment
+func (ar *attachmentRepoGorm) Create(attachment *models.Attachment) error {
+	return ar.db.Create(attachment).Error
+}
+
+// Delete will permanently delete the attachment, the caller is
+// expected to have deleted its file
+func (ar *attachmentRepoGorm) Delete(id uint) error {
+	return ar.db.Unscoped().Delete(&models.Attachment{}, id).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  AttachmentRepository
+	todos TodoRepository
+	user  *models.User
+	todo  *models.Todo
+	other *models.Todo
+}
+
+func (suite *AttachmentRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Attachment{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.User{})
+
+	suite.db = db
+	suite.repo = NewAttachmentRepository(db)
+	suite.user = &models.User{Username: "alice", Email: "alice@example.com", Name: "Alice", Password: "secret"}
+	db.Create(suite.user)
+
+	suite.todos = NewTodoRepository(db, events.Discard)
+	suite.todo = &models.Todo{UserID: suite.user.ID, Title: "Ship release"}
+	suite.other = &models.Todo{UserID: suite.user.ID, Title: "Pay rent"}
+	suite.todos.Create(suite.todo)
+	suite.todos.Create(suite.other)
+}
+
+// attach attaches a file of the size to the todo
+func (suite *AttachmentRepositoryTestSuite) attach(todo *models.Todo, key string, size int64) *models.Attachment {
+	a := &models.Attachment{
+		TodoID:      todo.ID,
+		UserID:      suite.user.ID,
+		Filename:    key + ".txt",
+		ContentType: "text/plain; charset=utf-8",
+		Size:        size,
+		StorageKey:  key,
+	}
+	suite.repo.Create(a)
+	return a
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestUsage() {
+	assert := assert.New(suite.T())
+
+	suite.attach(suite.todo, "a", 100)
+	suite.attach(suite.other, "b", 50)
+	deleted := suite.attach(suite.other, "c", 25)
+	assert.NoError(suite.repo.Delete(deleted.ID))
+
+	assert.Equal(int64(150), suite.repo.Usage(suite.user.ID))
+	assert.Equal(int64(0), suite.repo.Usage(suite.user.ID+1))
+}
+
+func (suite *AttachmentRepositoryTestSuite) TestOrphanedOnceTheTodoIsPurged() {
+	assert := assert.New(suite.T())
+
+	kept := suite.attach(suite.todo, "a", 100)
+	orphan := suite.attach(suite.other, "b", 50)
+
+	// todos in the trash keep their attachments
+	assert.NoError(suite.todos.Delete(suite.other.ID))
+	assert.Empty(suite.repo.Orphaned(10))
+
+	assert.NoError(suite.todos.ForceDelete(suite.other.ID))
+	orphaned := suite.repo.Orphaned(10)
+	if assert.Len(orphaned, 1) {
+		assert.Equal(orphan.ID, orphaned[0].ID)
+	}
+	assert.Len(suite.repo.ByTodo(suite.todo.ID), 1)
+	assert.Equal(kept.ID, suite.repo.ByTodo(suite.todo.ID)[0].ID)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAttachmentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AttachmentRepositoryTestSuite))
+}
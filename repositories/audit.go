@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// genesisHash is what the first audit event is chained to
+var genesisHash = strings.Repeat("0", 64)
+
+// errChainBroken stops walking the audit events at the first tampered one
+var errChainBroken = errors.New("audit chain broken")
+
+// maxAppendAttempts is how many times an event is appended, when other
+// processes keep chaining their events to the last one first
+const maxAppendAttempts = 5
+
+// AuditFilter narrows down the audit events, zero fields match everything
+type AuditFilter struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   *uint
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditRepository will interact to the audit_events table.
+// It can only append to it.
+type AuditRepository interface {
+	// Methods for querying audit events
+	Query(filter AuditFilter, offset int, limit int) ([]models.AuditEvent, int64)
+	Each(filter AuditFilter, fn func(*models.AuditEvent) error) error
+	Verify() (*models.AuditEvent, error)
+
+	// Methods for altering audit events
+	Append(event *models.AuditEvent) error
+}
+
+type auditRepoGorm struct {
+	db *gorm.DB
+
+	// the appends of the process wait for each other rather than
+	// retrying, see Append
+	mu sync.Mutex
+}
+
+var _ AuditRepository = &auditRepoGorm{}
+
+// NewAuditRepository creates instance of AuditRepository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepoGorm{db: db}
+}
+
+// Query will return a page of the audit events matching the filter,
+// the latest first, along with how many of them match
+func (ar *auditRepoGorm) Query(filter AuditFilter, offset int, limit int) ([]models.AuditEvent, int64) {
+	var total int64
+	ar.filtered(filter).Model(&models.AuditEvent{}).Count(&total)
+
+	var audits []models.AuditEvent
+	ar.filtered(filter).
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&audits)
+
+	return audits, total
+}
+
+// Each will call fn with every audit event matching the filter, the
+// oldest first. They are loaded in batches, so exports of the whole
+// log don't have to fit in memory.
+func (ar *auditRepoGorm) Each(filter AuditFilter, fn func(*models.AuditEvent) error) error {
+	var audits []models.AuditEvent
+	return ar.filtered(filter).FindInBatches(&audits, 500, func(tx *gorm.DB, batch int) error {
+		for i := range audits {
+			if err := fn(&audits[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// Verify will walk the hash chain from the first audit event and
+// return the first one which was tampered with, or nil when the
+// whole chain is intact.
+func (ar *auditRepoGorm) Verify() (*models.AuditEvent, error) {
+	var (
+		audits []models.AuditEvent
+		broken *models.AuditEvent
+	)
+	prev := genesisHash
+
+	err := ar.db.FindInBatches(&audits, 500, func(tx *gorm.DB, batch int) error {
+		for i := range audits {
+			if audits[i].PrevHash != prev || audits[i].ComputeHash() != audits[i].Hash {
+				broken = &audits[i]
+				return errChainBroken
+			}
+			prev = audits[i].Hash
+		}
+		return nil
+	}).Error
+	if err != nil && err != errChainBroken {
+		return nil, err
+	}
+
+	return broken, nil
+}
+
+// Append will add the event to the end of the audit log, chained to
+// the last event. The time it happened is set here, at the precision
+// every database stores, so its hash can be computed again. When
+// another process chained an event to the same last one first, the
+// unique previous hash refuses it and it is chained to that one.
+func (ar *auditRepoGorm) Append(event *models.AuditEvent) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		if err = ar.append(event); err == nil {
+			return nil
+		}
+
+		var chained int64
+		if ar.db.Model(&models.AuditEvent{}).Where("prev_hash = ?", event.PrevHash).Count(&chained); chained == 0 {
+			return err
+		}
+		event.ID = 0
+	}
+	return err
+}
+
+// append adds the event after the last one in a transaction
+func (ar *auditRepoGorm) append(event *models.AuditEvent) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		var last models.AuditEvent
+		event.PrevHash = genesisHash
+		if err := tx.Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		if last.ID != 0 {
+			event.PrevHash = last.Hash
+		}
+
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		event.Hash = event.ComputeHash()
+		return tx.Create(event).Error
+	})
+}
+
+// filtered is a private function returning the query of the audit
+// events matching the filter
+func (ar *auditRepoGorm) filtered(filter AuditFilter) *gorm.DB {
+	q := ar.db
+	if filter.ActorID != nil {
+		q = q.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		q = q.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		q = q.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.From != nil {
+		q = q.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("created_at < ?", *filter.To)
+	}
+	return q
+}
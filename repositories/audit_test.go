@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo AuditRepository
+}
+
+func (suite *AuditRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Where("1 = 1").Delete(&models.AuditEvent{})
+
+	suite.db = db
+	suite.repo = NewAuditRepository(db)
+}
+
+// appendEvent appends an event of the action by the actor
+func (suite *AuditRepositoryTestSuite) appendEvent(actorID uint, action string) *models.AuditEvent {
+	e := &models.AuditEvent{ActorID: &actorID, Action: action, TargetType: "todo", Diff: `{"title":{"from":null,"to":"x"}}`}
+	suite.NoError(suite.repo.Append(e))
+	return e
+}
+
+func (suite *AuditRepositoryTestSuite) TestAppendChainsEvents() {
+	assert := assert.New(suite.T())
+
+	first := suite.appendEvent(1, "todo.created")
+	second := suite.appendEvent(1, "todo.updated")
+
+	assert.Equal(genesisHash, first.PrevHash)
+	assert.Equal(first.Hash, second.PrevHash)
+	assert.Len(second.Hash, 64)
+
+	broken, err := suite.repo.Verify()
+	assert.NoError(err)
+	assert.Nil(broken)
+}
+
+func (suite *AuditRepositoryTestSuite) TestEventsCanNotBeChainedToTheSameOne() {
+	assert := assert.New(suite.T())
+
+	first := suite.appendEvent(1, "todo.created")
+	suite.appendEvent(1, "todo.updated")
+
+	// what another process would append after reading the same last event
+	fork := &models.AuditEvent{Action: "todo.deleted", PrevHash: first.Hash, CreatedAt: time.Now().UTC()}
+	fork.Hash = fork.ComputeHash()
+	assert.Error(suite.db.Create(fork).Error)
+
+	third := suite.appendEvent(2, "todo.deleted")
+	broken, err := suite.repo.Verify()
+	assert.NoError(err)
+	assert.Nil(broken)
+	assert.NotEqual(first.Hash, third.PrevHash)
+}
+
+func (suite *AuditRepositoryTestSuite) TestVerifyFindsTamperedEvents() {
+	assert := assert.New(suite.T())
+
+	suite.appendEvent(1, "todo.created")
+	tampered := suite.appendEvent(2, "todo.deleted")
+	suite.appendEvent(1, "todo.updated")
+
+	suite.db.Model(&models.AuditEvent{}).Where("id = ?", tampered.ID).Update("actor_id", 1)
+
+	broken, err := suite.repo.Verify()
+	assert.NoError(err)
+	if assert.NotNil(broken) {
+		assert.Equal(tampered.ID, broken.ID)
+	}
+}
+
+func (suite *AuditRepositoryTestSuite) TestVerifyFindsRemovedEvents() {
+	assert := assert.New(suite.T())
+
+	suite.appendEvent(1, "todo.created")
+	removed := suite.appendEvent(2, "todo.deleted")
+	next := suite.appendEvent(1, "todo.updated")
+
+	suite.db.Delete(removed)
+
+	broken, _ := suite.repo.Verify()
+	if assert.NotNil(broken) {
+		assert.Equal(next.ID, broken.ID)
+	}
+}
+
+func (suite *AuditRepositoryTestSuite) TestQuery() {
+	assert := assert.New(suite.T())
+
+	suite.appendEvent(1, "todo.created")
+	suite.appendEvent(2, "todo.created")
+	last := suite.appendEvent(1, "todo.deleted")
+
+	actorID := uint(1)
+	audits, total := suite.repo.Query(AuditFilter{ActorID: &actorID}, 0, 1)
+	assert.Equal(int64(2), total)
+	if assert.Len(audits, 1) {
+		assert.Equal(last.ID, audits[0].ID)
+	}
+
+	var actions []string
+	suite.repo.Each(AuditFilter{Action: "todo.created"}, func(e *models.AuditEvent) error {
+		actions = append(actions, e.Action)
+		return nil
+	})
+	assert.Equal([]string{"todo.created", "todo.created"}, actions)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAuditRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
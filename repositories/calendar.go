@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedRepository will interact to the calendar_feeds table.
+type CalendarFeedRepository interface {
+	// Methods for querying calendar feeds
+	ByID(id uint) *models.CalendarFeed
+	ByUser(userID uint) []models.CalendarFeed
+	ByTokenHash(hash string) *models.CalendarFeed
+
+	// Methods for altering calendar feeds
+	Create(feed *models.CalendarFeed) error
+	Touch(id uint, at time.Time) error
+	Delete(id uint) error
+}
+
+type calendarFeedRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ CalendarFeedRepository = &calendarFeedRepoGorm{}
+
+// NewCalendarFeedRepository creates instance of CalendarFeedRepository
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepoGorm{db}
+}
+
+// ByID will look up a calendar feed by ID
+// If no record was found, the method will return nil
+func (cr *calendarFeedRepoGorm) ByID(id uint) *models.CalendarFeed {
+	var f models.CalendarFeed
+	err := cr.db.First(&f, id).Error
+	if err == nil {
+		return &f
+	}
+
+	return nil
+}
+
+// ByUser will return all the calendar feeds of the user
+func (cr *calendarFeedRepoGorm) ByUser(userID uint) []models.CalendarFeed {
+	var feeds []models.CalendarFeed
+	cr.db.Where("user_id = ?", userID).Order("id").Find(&feeds)
+
+	return feeds
+}
+
+// ByTokenHash will look up a calendar feed by the hash of its token
+// If no record was found or it was revoked, the method will return nil
+func (cr *calendarFeedRepoGorm) ByTokenHash(hash string) *models.CalendarFeed {
+	var f models.CalendarFeed
+	err := cr.db.Where("token_hash = ?", hash).First(&f).Error
+	if err == nil {
+		return &f
+	}
+
+	return nil
+}
+
+// Create will create a new calendar feed
+func (cr *calendarFeedRepoGorm) Create(feed *models.CalendarFeed) error {
+	return cr.db.Create(feed).Error
+}
+
+// Touch will record when the feed was last fetched
+func (cr *calendarFeedRepoGorm) Touch(id uint, at time.Time) error {
+	return cr.db.Model(&models.CalendarFeed{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+// Delete will revoke the calendar feed by ID
+func (cr *calendarFeedRepoGorm) Delete(id uint) error {
+	return cr.db.Delete(&models.CalendarFeed{Model: gorm.Model{ID: id}}).Error
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CalendarFeedRepositoryTestSuite struct {
+	suite.Suite
+	repo CalendarFeedRepository
+}
+
+func (suite *CalendarFeedRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.CalendarFeed{})
+
+	suite.repo = NewCalendarFeedRepository(db)
+}
+
+func (suite *CalendarFeedRepositoryTestSuite) TestRevokedFeedsAreNotFound() {
+	assert := assert.New(suite.T())
+
+	feed := &models.CalendarFeed{UserID: 1, Component: models.CalendarTodos, TokenHash: "hash"}
+	assert.NoError(suite.repo.Create(feed))
+
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	assert.NoError(suite.repo.Touch(feed.ID, at))
+	found := suite.repo.ByTokenHash("hash")
+	if assert.NotNil(found) {
+		assert.True(at.Equal(*found.LastUsedAt))
+	}
+
+	assert.NoError(suite.repo.Delete(feed.ID))
+	assert.Nil(suite.repo.ByTokenHash("hash"))
+	assert.Empty(suite.repo.ByUser(1))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestCalendarFeedRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CalendarFeedRepositoryTestSuite))
+}
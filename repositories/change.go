@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ErrClientIDClaimed is returned when creating a record with the client
+// ID the client gave to another one
+var ErrClientIDClaimed = errors.New("The client ID was given to another record")
+
+// appendAttempts is how many times a change is tried to be appended
+// while concurrent ones for the same user take its seq
+const appendAttempts = 3
+
+// ChangeRepository will interact to the changes and the
+// client_records tables
+type ChangeRepository interface {
+	// Methods for querying the change logs
+	Since(userID uint, workspaceID uint, seq uint64, limit int) []models.Change
+	Clock(userID uint, resource string, id uint) map[string]time.Time
+	LastID() uint
+
+	// Methods for altering the change logs
+	Append(change *models.Change) error
+	Stamp(resource string, id uint, after uint, times map[string]time.Time) error
+
+	// Methods for the IDs clients gave their records, they are
+	// claimed along with the records by the repositories creating them
+	ClientRecord(userID uint, resource string, clientID string) *models.ClientRecord
+}
+
+type changeRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ ChangeRepository = &changeRepoGorm{}
+
+// NewChangeRepository creates instance of ChangeRepository
+func NewChangeRepository(db *gorm.DB) ChangeRepository {
+	return &changeRepoGorm{db}
+}
+
+// Since will return the changes logged for the user in the workspace
+// after the seq, in the order they were logged, up to the limit
+func (cr *changeRepoGorm) Since(userID uint, workspaceID uint, seq uint64, limit int) []models.Change {
+	var changes []models.Change
+	cr.db.Where("user_id = ? AND workspace_id = ? AND seq > ?", userID, workspaceID, seq).
+		Order("seq").
+		Limit(limit).
+		Find(&changes)
+
+	return changes
+}
+
+// Clock will return the time every field of the record was last
+// changed at, along with the time it was deleted at if it was, as
+// the change log of the user has it
+func (cr *changeRepoGorm) Clock(userID uint, resource string, id uint) map[string]time.Time {
+	var changes []models.Change
+	cr.db.Where("user_id = ? AND resource = ? AND resource_id = ?", userID, resource, id).Order("id").Find(&changes)
+
+	clock := make(map[string]time.Time)
+	for i := range changes {
+		for field, at := range changes[i].Times() {
+			if at.After(clock[field]) {
+				clock[field] = at
+			}
+		}
+	}
+	return clock
+}
+
+// LastID will return the ID of the last change logged for anyone,
+// the changes logged after it can be stamped
+func (cr *changeRepoGorm) LastID() uint {
+	var id uint
+	cr.db.Model(&models.Change{}).Select("COALESCE(MAX(id), 0)").Scan(&id)
+
+	return id
+}
+
+// Append will log the change with the next seq of its user. The
+// unique index of the user and the seq makes concurrent changes
+// for the same user take turns.
+func (cr *changeRepoGorm) Append(change *models.Change) error {
+	var err error
+	for attempt := 0; attempt < appendAttempts; attempt++ {
+		err = cr.db.Transaction(func(tx *gorm.DB) error {
+			var last uint64
+			err := tx.Model(&models.Change{}).
+				Where("user_id = ?", change.UserID).
+				Select("COALESCE(MAX(seq), 0)").
+				Scan(&last).Error
+			if err != nil {
+				return err
+			}
+
+			change.ID = 0
+			change.Seq = last + 1
+			return tx.Create(change).Error
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Stamp will set the time the fields were changed at on the changes
+// of the record logged after the change of ID after. It's for the
+// changes made on behalf of a client, which are as recent as when
+// they were made on the client rather than when it synced them.
+func (cr *changeRepoGorm) Stamp(resource string, id uint, after uint, times map[string]time.Time) error {
+	var changes []models.Change
+	err := cr.db.Where("resource = ? AND resource_id = ? AND id > ?", resource, id, after).Find(&changes).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range changes {
+		clock := changes[i].Times()
+		for field := range clock {
+			if at, ok := times[field]; ok {
+				clock[field] = at
+			}
+		}
+		changes[i].SetTimes(clock)
+		if err := cr.db.Model(&changes[i]).Update("clock", changes[i].Clock).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientRecord will look up the record the client of the user gave
+// the ID to. If no record was found, the method will return nil
+func (cr *changeRepoGorm) ClientRecord(userID uint, resource string, clientID string) *models.ClientRecord {
+	var r models.ClientRecord
+	err := cr.db.Where("user_id = ? AND resource = ? AND client_id = ?", userID, resource, clientID).First(&r).Error
+	if err == nil {
+		return &r
+	}
+
+	return nil
+}
+
+// claimClientID is a private function mapping the ID the client gave
+// a record to the record of ID, in the transaction creating it. It
+// fails with ErrClientIDClaimed when the client gave it to another
+// record, and the unique index fails the concurrent claims of it.
+func claimClientID(tx *gorm.DB, record *models.ClientRecord, id uint) error {
+	var count int64
+	err := tx.Model(&models.ClientRecord{}).
+		Where("user_id = ? AND resource = ? AND client_id = ?", record.UserID, record.Resource, record.ClientID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrClientIDClaimed
+	}
+
+	record.ResourceID = id
+	return tx.Create(record).Error
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ChangeRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo ChangeRepository
+}
+
+func (suite *ChangeRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Where("1 = 1").Delete(&models.Change{})
+	db.Where("1 = 1").Delete(&models.ClientRecord{})
+
+	suite.db = db
+	suite.repo = NewChangeRepository(db)
+}
+
+// newChange creates a change of the todo of ID whose fields were
+// changed at the time
+func newChange(userID uint, workspaceID uint, id uint, at time.Time, fields ...string) *models.Change {
+	c := &models.Change{UserID: userID, WorkspaceID: workspaceID, Resource: models.ChangeTodo, ResourceID: id}
+	times := make(map[string]time.Time)
+	for _, field := range fields {
+		times[field] = at
+	}
+	c.SetTimes(times)
+	return c
+}
+
+func (suite *ChangeRepositoryTestSuite) TestAppend() {
+	assert := assert.New(suite.T())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, c := range []*models.Change{
+		newChange(1, 0, 2, now, "title"),
+		newChange(2, 0, 2, now, "title"),
+		newChange(1, 7, 3, now, "title"),
+		newChange(1, 0, 2, now, "completed"),
+	} {
+		assert.NoError(suite.repo.Append(c))
+	}
+
+	// the seqs are per user, and the changes per workspace
+	changes := suite.repo.Since(1, 0, 0, 10)
+	if assert.Len(changes, 2) {
+		assert.Equal(uint64(1), changes[0].Seq)
+		assert.Equal(uint64(3), changes[1].Seq)
+	}
+	assert.Len(suite.repo.Since(1, 0, 1, 10), 1)
+	assert.Len(suite.repo.Since(1, 0, 0, 1), 1)
+	if changes := suite.repo.Since(2, 0, 0, 10); assert.Len(changes, 1) {
+		assert.Equal(uint64(1), changes[0].Seq)
+	}
+}
+
+func (suite *ChangeRepositoryTestSuite) TestClockAndStamp() {
+	assert := assert.New(suite.T())
+
+	earlier := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	suite.repo.Append(newChange(1, 0, 2, earlier, "title", "completed"))
+	mark := suite.repo.LastID()
+	suite.repo.Append(newChange(1, 0, 2, later, "title"))
+	suite.repo.Append(newChange(4, 0, 2, later, "title"))
+
+	assert.Equal(map[string]time.Time{"title": later, "completed": earlier}, suite.repo.Clock(1, models.ChangeTodo, 2))
+
+	// the changes after the mark get the times of the client, for the
+	// fields they changed only
+	client := later.Add(-30 * time.Minute)
+	assert.NoError(suite.repo.Stamp(models.ChangeTodo, 2, mark, map[string]time.Time{"title": client, "completed": client}))
+	assert.Equal(map[string]time.Time{"title": client, "completed": earlier}, suite.repo.Clock(1, models.ChangeTodo, 2))
+	assert.Equal(map[string]time.Time{"title": client}, suite.repo.Clock(4, models.ChangeTodo, 2))
+}
+
+func (suite *ChangeRepositoryTestSuite) TestClaim() {
+	assert := assert.New(suite.T())
+
+	assert.NoError(claimClientID(suite.db, &models.ClientRecord{UserID: 1, Resource: models.ChangeList, ClientID: "a1"}, 3))
+	// a client ID is given once per user and resource
+	err := claimClientID(suite.db, &models.ClientRecord{UserID: 1, Resource: models.ChangeList, ClientID: "a1"}, 4)
+	assert.Equal(ErrClientIDClaimed, err)
+
+	if r := suite.repo.ClientRecord(1, models.ChangeList, "a1"); assert.NotNil(r) {
+		assert.Equal(uint(3), r.ResourceID)
+	}
+	assert.Nil(suite.repo.ClientRecord(2, models.ChangeList, "a1"))
+	assert.Nil(suite.repo.ClientRecord(1, models.ChangeTodo, "a1"))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestChangeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ChangeRepositoryTestSuite))
+}
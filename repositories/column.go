@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ColumnRepository will interact to the columns table.
+type ColumnRepository interface {
+	// Methods for querying columns
+	ByID(id uint) *models.Column
+	ByList(listID uint) []models.Column
+
+	// Methods for altering columns
+	Create(column *models.Column) error
+	Update(column *models.Column) error
+	Delete(id uint) error
+	Move(column *models.Column, position int) error
+}
+
+type columnRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ ColumnRepository = &columnRepoGorm{}
+
+// NewColumnRepository creates instance of ColumnRepository
+func NewColumnRepository(db *gorm.DB) ColumnRepository {
+	return &columnRepoGorm{db}
+}
+
+// ByID will look up a column by ID
+// If no record was found, the method will return nil
+func (cr *columnRepoGorm) ByID(id uint) *models.Column {
+	var c models.Column
+	err := cr.db.First(&c, id).Error
+	if err == nil {
+		return &c
+	}
+
+	return nil
+}
+
+// ByList will return the columns of the list from left to right
+func (cr *columnRepoGorm) ByList(listID uint) []models.Column {
+	var columns []models.Column
+	cr.db.Where("list_id = ?", listID).
+		Order("position, id").
+		Find(&columns)
+
+	return columns
+}
+
+// Create will add the column after the last one of its list, when it
+// is the Done column the list's former one stops being it
+func (cr *columnRepoGorm) Create(column *models.Column) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Column{}).Where("list_id = ?", column.ListID).Count(&count).Error; err != nil {
+			return err
+		}
+		column.Position = int(count)
+
+		if err := tx.Create(column).Error; err != nil {
+			return err
+		}
+		return clearDoneColumns(tx, column)
+	})
+}
+
+// Update will update the column's name, WIP limit and whether it is
+// the Done column, the todos already in it are left as they are
+func (cr *columnRepoGorm) Update(column *models.Column) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(column).Updates(map[string]interface{}{
+			"name":      column.Name,
+			"wip_limit": column.WIPLimit,
+			"done":      column.Done,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return clearDoneColumns(tx, column)
+	})
+}
+
+// Delete will permanently delete the column by ID, the todos in it,
+// the trashed ones included, are taken off the board
+func (cr *columnRepoGorm) Delete(id uint) error {
+	column := cr.ByID(id)
+	if column == nil {
+		return nil
+	}
+
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Todo{}).
+			Where("column_id = ?", id).
+			Updates(map[string]interface{}{"column_id": nil, "position": 0, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Column{}).
+			Where("list_id = ? AND position > ? AND deleted_at IS NULL", column.ListID, column.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Column{}, id).Error
+	})
+}
+
+// Move will put the column at the position of its list's board, the
+// columns from there on shift right. Out of range positions put the
+// column last.
+func (cr *columnRepoGorm) Move(column *models.Column, position int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Column
+		if err := tx.First(&current, column.ID).Error; err != nil {
+			return err
+		}
+
+		others := func() *gorm.DB {
+			return tx.Model(&models.Column{}).Where("list_id = ? AND id <> ? AND deleted_at IS NULL", current.ListID, current.ID)
+		}
+		err := others().
+			Where("position > ?", current.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := others().Count(&count).Error; err != nil {
+			return err
+		}
+		if position < 0 || position > int(count) {
+			position = int(count)
+		}
+
+		err = others().
+			Where("position >= ?", position).
+			Update("position", gorm.Expr("position + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		column.Position = position
+		return tx.Model(column).Update("position", position).Error
+	})
+}
+
+// clearDoneColumns makes the column the only Done column of its list
+// when it is one
+func clearDoneColumns(tx *gorm.DB, column *models.Column) error {
+	if !column.Done {
+		return nil
+	}
+	return tx.Model(&models.Column{}).
+		Where("list_id = ? AND id <> ? AND deleted_at IS NULL", column.ListID, column.ID).
+		Update("done", false).Error
+}
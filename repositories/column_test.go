@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ColumnRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo ColumnRepository
+	list *models.List
+}
+
+func (suite *ColumnRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Column{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+
+	suite.db = db
+	suite.repo = NewColumnRepository(db)
+	suite.list = &models.List{UserID: 1, Name: "Release"}
+	db.Create(suite.list)
+}
+
+// names returns the names of the list's columns from left to right
+func (suite *ColumnRepositoryTestSuite) names() []string {
+	var names []string
+	for _, c := range suite.repo.ByList(suite.list.ID) {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func (suite *ColumnRepositoryTestSuite) TestCreateKeepsOneDoneColumn() {
+	assert := assert.New(suite.T())
+
+	shipped := &models.Column{ListID: suite.list.ID, Name: "Shipped", Done: true}
+	assert.NoError(suite.repo.Create(&models.Column{ListID: suite.list.ID, Name: "To do"}))
+	assert.NoError(suite.repo.Create(shipped))
+	assert.NoError(suite.repo.Create(&models.Column{ListID: suite.list.ID, Name: "Done", Done: true}))
+
+	columns := suite.repo.ByList(suite.list.ID)
+	if assert.Len(columns, 3) {
+		assert.Equal([]int{0, 1, 2}, []int{columns[0].Position, columns[1].Position, columns[2].Position})
+		assert.False(columns[1].Done)
+		assert.True(columns[2].Done)
+	}
+}
+
+func (suite *ColumnRepositoryTestSuite) TestMove() {
+	assert := assert.New(suite.T())
+
+	var columns []*models.Column
+	for _, name := range []string{"To do", "Doing", "Review", "Done"} {
+		column := &models.Column{ListID: suite.list.ID, Name: name}
+		suite.repo.Create(column)
+		columns = append(columns, column)
+	}
+
+	assert.NoError(suite.repo.Move(columns[3], 0))
+	assert.Equal([]string{"Done", "To do", "Doing", "Review"}, suite.names())
+
+	assert.NoError(suite.repo.Move(columns[0], 2))
+	assert.Equal([]string{"Done", "Doing", "To do", "Review"}, suite.names())
+
+	assert.NoError(suite.repo.Move(columns[3], 99))
+	assert.Equal([]string{"Doing", "To do", "Review", "Done"}, suite.names())
+	assert.Equal(3, columns[3].Position)
+}
+
+func (suite *ColumnRepositoryTestSuite) TestDeleteSendsTodosToTheBacklog() {
+	assert := assert.New(suite.T())
+
+	todo, doing := &models.Column{ListID: suite.list.ID, Name: "To do"}, &models.Column{ListID: suite.list.ID, Name: "Doing"}
+	suite.repo.Create(todo)
+	suite.repo.Create(doing)
+	card := &models.Todo{UserID: 1, ListID: &suite.list.ID, Title: "Ship release", ColumnID: &todo.ID, Position: 1}
+	suite.db.Create(card)
+
+	assert.NoError(suite.repo.Delete(todo.ID))
+
+	assert.Nil(suite.repo.ByID(todo.ID))
+	assert.Equal(0, suite.repo.ByID(doing.ID).Position)
+	var reloaded models.Todo
+	suite.db.First(&reloaded, card.ID)
+	assert.Nil(reloaded.ColumnID)
+	assert.Equal(0, reloaded.Position)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestColumnRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ColumnRepositoryTestSuite))
+}
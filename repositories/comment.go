@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// CommentRepository will interact to the comments table.
+type CommentRepository interface {
+	// Methods for querying comments
+	ByID(id uint) *models.Comment
+	ByTodo(todoID uint) []models.Comment
+	Counts(todoIDs []uint) map[uint]int64
+
+	// Methods for altering comments
+	Create(comment *models.Comment) error
+	Update(comment *models.Comment) error
+	Delete(id uint) error
+}
+
+type commentRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ CommentRepository = &commentRepoGorm{}
+
+// NewCommentRepository creates instance of CommentRepository
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepoGorm{db}
+}
+
+// ByID will look up a comment by ID along with its author
+// If no record was found, the method will return nil
+func (cr *commentRepoGorm) ByID(id uint) *models.Comment {
+	var c models.Comment
+	err := cr.db.Preload("User").First(&c, id).Error
+	if err == nil {
+		return &c
+	}
+
+	return nil
+}
+
+// ByTodo will return the comments on the todo along with their
+// authors, the oldest first. The deleted comments are included
+// since replies to them are still shown in their thread.
+func (cr *commentRepoGorm) ByTodo(todoID uint) []models.Comment {
+	var comments []models.Comment
+	cr.db.Unscoped().
+		Preload("User").
+		Where("todo_id = ?", todoID).
+		Order("id").
+		Find(&comments)
+
+	return comments
+}
+
+// Counts will return how many comments each of the todos has,
+// todos without comments are left out
+func (cr *commentRepoGorm) Counts(todoIDs []uint) map[uint]int64 {
+	counts := make(map[uint]int64, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		TodoID uint
+		Total  int64
+	}
+	cr.db.Model(&models.Comment{}).
+		Select("todo_id, COUNT(*) AS total").
+		Where("todo_id IN ?", todoIDs).
+		Group("todo_id").
+		Scan(&rows)
+
+	for _, row := range rows {
+		counts[row.TodoID] = row.Total
+	}
+	return counts
+}
+
+// Create will create a new comment
+func (cr *commentRepoGorm) Create(comment *models.Comment) error {
+	return cr.db.Create(comment).Error
+}
+
+// Update will save the body of the comment and when it was edited,
+// unless it was changed since it was read
+func (cr *commentRepoGorm) Update(comment *models.Comment) error {
+	return updateVersioned(cr.db, comment, &comment.Version, map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt})
+}
+
+// Delete will delete the comment, its replies stay in the thread
+func (cr *commentRepoGorm) Delete(id uint) error {
+	return cr.db.Delete(&models.Comment{}, id).Error
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type CommentRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  CommentRepository
+	user  *models.User
+	todo  *models.Todo
+	other *models.Todo
+}
+
+func (suite *CommentRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Comment{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.User{})
+
+	suite.db = db
+	suite.repo = NewCommentRepository(db)
+	suite.user = &models.User{Username: "alice", Email: "alice@example.com", Name: "Alice", Password: "secret"}
+	db.Create(suite.user)
+
+	todos := NewTodoRepository(db, events.Discard)
+	suite.todo = &models.Todo{UserID: suite.user.ID, Title: "Ship release"}
+	suite.other = &models.Todo{UserID: suite.user.ID, Title: "Pay rent"}
+	todos.Create(suite.todo)
+	todos.Create(suite.other)
+}
+
+// post posts a comment by the suite's user on the todo
+func (suite *CommentRepositoryTestSuite) post(todo *models.Todo, body string, parent *models.Comment) *models.Comment {
+	c := &models.Comment{TodoID: todo.ID, UserID: suite.user.ID, Body: body}
+	if parent != nil {
+		c.ParentID = &parent.ID
+	}
+	suite.repo.Create(c)
+	return c
+}
+
+func (suite *CommentRepositoryTestSuite) TestByTodoKeepsDeletedComments() {
+	assert := assert.New(suite.T())
+
+	root := suite.post(suite.todo, "Ready?", nil)
+	suite.post(suite.todo, "Yes", root)
+	suite.post(suite.other, "Paid", nil)
+	assert.NoError(suite.repo.Delete(root.ID))
+
+	comments := suite.repo.ByTodo(suite.todo.ID)
+	if assert.Len(comments, 2) {
+		assert.True(comments[0].IsDeleted())
+		assert.Equal(root.ID, *comments[1].ParentID)
+		assert.Equal("Alice", comments[1].User.Name)
+	}
+	assert.Nil(suite.repo.ByID(root.ID))
+}
+
+func (suite *CommentRepositoryTestSuite) TestCounts() {
+	assert := assert.New(suite.T())
+
+	suite.post(suite.todo, "One", nil)
+	suite.post(suite.todo, "Two", nil)
+	suite.repo.Delete(suite.post(suite.todo, "Three", nil).ID)
+
+	counts := suite.repo.Counts([]uint{suite.todo.ID, suite.other.ID})
+	assert.Equal(map[uint]int64{suite.todo.ID: 2}, counts)
+	assert.Empty(suite.repo.Counts(nil))
+}
+
+func (suite *CommentRepositoryTestSuite) TestUpdate() {
+	assert := assert.New(suite.T())
+
+	c := suite.post(suite.todo, "Draft", nil)
+	now := time.Now()
+	c.Body = "Final"
+	c.EditedAt = &now
+	assert.NoError(suite.repo.Update(c))
+
+	updated := suite.repo.ByID(c.ID)
+	assert.Equal("Final", updated.Body)
+	assert.True(updated.IsEdited())
+}
+
+func (suite *CommentRepositoryTestSuite) TestForceDeletingTheTodoDeletesItsComments() {
+	assert := assert.New(suite.T())
+
+	suite.post(suite.todo, "Ready?", nil)
+	assert.NoError(NewTodoRepository(suite.db, events.Discard).ForceDelete(suite.todo.ID))
+
+	assert.Empty(suite.repo.ByTodo(suite.todo.ID))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestCommentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentRepositoryTestSuite))
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// IdempotencyRepository will interact to the idempotency_keys table
+type IdempotencyRepository interface {
+	// Lock claims the key of the user for a request. When another
+	// request claimed it first, and it has not expired, the method
+	// returns that request's record instead.
+	Lock(key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+
+	// Complete saves the response to the request of the key
+	Complete(key *models.IdempotencyKey) error
+
+	// Release frees the key for a retry, as the request failed
+	Release(id uint) error
+
+	// Prune deletes the keys which expired before the time
+	Prune(before time.Time) (int64, error)
+}
+
+type idempotencyRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ IdempotencyRepository = &idempotencyRepoGorm{}
+
+// NewIdempotencyRepository creates instance of IdempotencyRepository
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepoGorm{db}
+}
+
+// Lock relies on the unique index of the user and the key, so only one
+// of concurrent requests with the same key gets to claim it
+func (ir *idempotencyRepoGorm) Lock(key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	of := map[string]interface{}{"user_id": key.UserID, "key": key.Key}
+
+	// an expired key can be claimed again
+	err := ir.db.Where(of).Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := ir.db.Create(key).Error; err != nil {
+		var existing models.IdempotencyKey
+		if ir.db.Where(of).First(&existing).Error != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	return nil, nil
+}
+
+// Complete will save the response to the request of the key
+func (ir *idempotencyRepoGorm) Complete(key *models.IdempotencyKey) error {
+	return ir.db.Model(key).Updates(map[string]interface{}{
+		"status":       key.Status,
+		"content_type": key.ContentType,
+		"body":         key.Body,
+		"headers":      key.Headers,
+	}).Error
+}
+
+// Release will delete the key by ID
+func (ir *idempotencyRepoGorm) Release(id uint) error {
+	return ir.db.Delete(&models.IdempotencyKey{}, id).Error
+}
+
+// Prune will delete the keys which expired before the time, and
+// returns how many it deleted
+func (ir *idempotencyRepoGorm) Prune(before time.Time) (int64, error) {
+	result := ir.db.Where("expires_at <= ?", before).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
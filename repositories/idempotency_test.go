@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo IdempotencyRepository
+}
+
+func (suite *IdempotencyRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Where("1 = 1").Delete(&models.IdempotencyKey{})
+
+	suite.db = db
+	suite.repo = NewIdempotencyRepository(db)
+}
+
+// newKey creates a key of the user which expires in the duration
+func newKey(userID uint, key string, expires time.Duration) *models.IdempotencyKey {
+	return &models.IdempotencyKey{UserID: userID, Key: key, Fingerprint: "f", ExpiresAt: time.Now().Add(expires)}
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestLock() {
+	assert := assert.New(suite.T())
+
+	first := newKey(1, "abc", time.Hour)
+	existing, err := suite.repo.Lock(first)
+	assert.NoError(err)
+	assert.Nil(existing)
+	assert.NotZero(first.ID)
+
+	// the second request gets the first one's key
+	existing, err = suite.repo.Lock(newKey(1, "abc", time.Hour))
+	assert.NoError(err)
+	if assert.NotNil(existing) {
+		assert.Equal(first.ID, existing.ID)
+		assert.True(existing.IsPending())
+	}
+
+	// keys are per user
+	existing, err = suite.repo.Lock(newKey(2, "abc", time.Hour))
+	assert.NoError(err)
+	assert.Nil(existing)
+
+	first.Status = 201
+	first.Body = []byte(`{"id": 3}`)
+	first.Headers = `{"Location":"/todos/3"}`
+	assert.NoError(suite.repo.Complete(first))
+	existing, _ = suite.repo.Lock(newKey(1, "abc", time.Hour))
+	assert.Equal(201, existing.Status)
+	assert.Equal(`{"id": 3}`, string(existing.Body))
+	assert.Equal(`{"Location":"/todos/3"}`, existing.Headers)
+
+	// a released key is free again
+	assert.NoError(suite.repo.Release(first.ID))
+	existing, _ = suite.repo.Lock(newKey(1, "abc", time.Hour))
+	assert.Nil(existing)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestExpiredKeys() {
+	assert := assert.New(suite.T())
+
+	suite.repo.Lock(newKey(1, "old", -time.Minute))
+	suite.repo.Lock(newKey(1, "new", time.Hour))
+
+	// an expired key can be claimed again
+	existing, err := suite.repo.Lock(newKey(1, "old", time.Hour))
+	assert.NoError(err)
+	assert.Nil(existing)
+
+	n, err := suite.repo.Prune(time.Now().Add(2 * time.Hour))
+	assert.NoError(err)
+	assert.Equal(int64(2), n)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestIdempotencyRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepositoryTestSuite))
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ErrInviteNotPending is returned when accepting an invite which was
+// accepted, revoked or expired in the meantime
+var ErrInviteNotPending = errors.New("The invite can no longer be accepted")
+
+// InviteRepository will interact to the invites table.
+type InviteRepository interface {
+	// Methods for querying invites
+	ByID(id uint) *models.Invite
+	ByTokenHash(hash string) *models.Invite
+	Pending(workspaceID uint, now time.Time) []models.Invite
+
+	// Methods for altering invites
+	Create(invite *models.Invite) error
+	Accept(invite *models.Invite, userID uint, at time.Time) error
+	Delete(id uint) error
+}
+
+type inviteRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ InviteRepository = &inviteRepoGorm{}
+
+// NewInviteRepository creates instance of InviteRepository
+func NewInviteRepository(db *gorm.DB) InviteRepository {
+	return &inviteRepoGorm{db}
+}
+
+// ByID will look up an invite by ID
+// If no record was found, the method will return nil
+func (ir *inviteRepoGorm) ByID(id uint) *models.Invite {
+	var i models.Invite
+	err := ir.db.First(&i, id).Error
+	if err == nil {
+		return &i
+	}
+
+	return nil
+}
+
+// ByTokenHash will look up an invite along with its workspace by the
+// hash of its token
+// If no record was found, the method will return nil
+func (ir *inviteRepoGorm) ByTokenHash(hash string) *models.Invite {
+	var i models.Invite
+	err := ir.db.Preload("Workspace").Where("token_hash = ?", hash).First(&i).Error
+	if err == nil {
+		return &i
+	}
+
+	return nil
+}
+
+// Pending will return the invites to the workspace which can still
+// be accepted, the most recent first
+func (ir *inviteRepoGorm) Pending(workspaceID uint, now time.Time) []models.Invite {
+	var invites []models.Invite
+	ir.db.Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, now).
+		Order("id DESC").
+		Find(&invites)
+
+	return invites
+}
+
+// Create will create a new invite
+func (ir *inviteRepoGorm) Create(invite *models.Invite) error {
+	return ir.db.Omit("Workspace").Create(invite).Error
+}
+
+// Accept will make the user a member of the invite's workspace with
+// its role, unless they are one already. Only one of concurrent
+// requests gets to accept it, the others get ErrInviteNotPending, and
+// a revoked invite can't be accepted either.
+func (ir *inviteRepoGorm) Accept(invite *models.Invite, userID uint, at time.Time) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Invite{}).
+			Where("id = ? AND accepted_at IS NULL AND deleted_at IS NULL AND expires_at > ?", invite.ID, at).
+			Update("accepted_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInviteNotPending
+		}
+		invite.AcceptedAt = &at
+
+		var count int64
+		tx.Model(&models.Membership{}).Where("workspace_id = ? AND user_id = ?", invite.WorkspaceID, userID).Count(&count)
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&models.Membership{WorkspaceID: invite.WorkspaceID, UserID: userID, Role: invite.Role}).Error
+	})
+}
+
+// Delete will revoke the invite by ID
+func (ir *inviteRepoGorm) Delete(id uint) error {
+	return ir.db.Delete(&models.Invite{}, id).Error
+}
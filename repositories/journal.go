@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNothingToUndo is returned when the user has no operation left to undo
+	ErrNothingToUndo = errors.New("Nothing to undo")
+
+	// ErrNothingToRedo is returned when the user has not undone any operation
+	// since their last change
+	ErrNothingToRedo = errors.New("Nothing to redo")
+
+	// ErrOperationConflict is returned when the subject of an operation was
+	// changed by a later edit, so undoing it would overwrite that edit
+	ErrOperationConflict = errors.New("It was changed since, so the operation can no longer be reverted")
+)
+
+// JournalRepository will interact to the operations table, the
+// journal of the changes users can undo and redo.
+type JournalRepository interface {
+	// Methods for querying the journal
+	History(userID uint) []models.Operation
+
+	// Methods for altering the journal
+	Record(op *models.Operation) error
+	Undo(userID uint) (*models.Operation, error)
+	Redo(userID uint) (*models.Operation, error)
+
+	// Workspace scopes the repository to the operations in the workspace
+	Workspace(id uint) JournalRepository
+}
+
+type journalRepoGorm struct {
+	db        *gorm.DB
+	pub       events.Publisher
+	history   int
+	workspace *uint
+}
+
+var _ JournalRepository = &journalRepoGorm{}
+
+// NewJournalRepository creates instance of JournalRepository which
+// keeps the last history operations of every user and publishes
+// the changes undoing and redoing them make to pub. Every workspace
+// has a journal of its own.
+func NewJournalRepository(db *gorm.DB, pub events.Publisher, history int) JournalRepository {
+	return &journalRepoGorm{db: db, pub: pub, history: history}
+}
+
+// Workspace returns a copy of the repository which only undoes and
+// redoes the operations in the workspace
+func (jr *journalRepoGorm) Workspace(id uint) JournalRepository {
+	return &journalRepoGorm{db: jr.db, pub: jr.pub, history: jr.history, workspace: &id}
+}
+
+// History will return the operations of the user, the latest first
+func (jr *journalRepoGorm) History(userID uint) []models.Operation {
+	var ops []models.Operation
+	jr.query().Where("user_id = ?", userID).Order("id DESC").Find(&ops)
+
+	return ops
+}
+
+// Record will add the operation to the user's journal of its workspace.
+// The operations they have undone can't be redone after a new change,
+// so they are dropped along with the ones past the history.
+func (jr *journalRepoGorm) Record(op *models.Operation) error {
+	return jr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("user_id = ? AND workspace_id = ? AND undone_at IS NOT NULL", op.UserID, op.WorkspaceID).
+			Delete(&models.Operation{}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(op).Error; err != nil {
+			return err
+		}
+
+		var ids []uint
+		err = tx.Model(&models.Operation{}).
+			Where("user_id = ? AND workspace_id = ?", op.UserID, op.WorkspaceID).
+			Order("id DESC").
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) <= jr.history {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids[jr.history:]).Delete(&models.Operation{}).Error
+	})
+}
+
+// Undo will revert the user's latest operation which wasn't undone yet.
+// An operation whose subject was changed since can never be reverted,
+// so it is dropped from the journal and ErrOperationConflict returned.
+func (jr *journalRepoGorm) Undo(userID uint) (*models.Operation, error) {
+	var op models.Operation
+	err := jr.query().Where("user_id = ? AND undone_at IS NULL", userID).Order("id DESC").First(&op).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNothingToUndo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := jr.revert(&op, op.After, op.Before, &now); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// Redo will apply again the operation the user undid last.
+// An operation whose subject was changed since can never be applied
+// again, so it is dropped from the journal and ErrOperationConflict returned.
+func (jr *journalRepoGorm) Redo(userID uint) (*models.Operation, error) {
+	var op models.Operation
+	err := jr.query().Where("user_id = ? AND undone_at IS NOT NULL", userID).Order("id").First(&op).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNothingToRedo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := jr.revert(&op, op.Before, op.After, nil); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// query starts a query for the operations in the repository's workspace
+func (jr *journalRepoGorm) query() *gorm.DB {
+	if jr.workspace == nil {
+		return jr.db
+	}
+	return jr.db.Where("workspace_id = ?", *jr.workspace)
+}
+
+// revert brings the subject of the operation from the state from back
+// to the state to and marks the operation undone at the time, all in
+// one transaction.
+func (jr *journalRepoGorm) revert(op *models.Operation, from string, to string, undoneAt *time.Time) error {
+	var e events.Event
+	restored := &pendingEvents{}
+	err := jr.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch op.Subject {
+		case models.OperationTodo:
+			e, err = revertTodo(tx, op, from, to)
+		case models.OperationList:
+			e, err = revertList(tx, op, from, to, restored)
+		default:
+			err = ErrOperationConflict
+		}
+		if err != nil {
+			return err
+		}
+
+		op.UndoneAt = undoneAt
+		return tx.Model(op).Update("undone_at", undoneAt).Error
+	})
+
+	if errors.Is(err, ErrOperationConflict) {
+		jr.db.Unscoped().Delete(op)
+	}
+	if err != nil {
+		return err
+	}
+
+	publish(jr.pub, e)
+	for _, e := range restored.events {
+		publish(jr.pub, e)
+	}
+	return nil
+}
+
+// revertTodo brings the todo of the operation from a state to another,
+// an empty state being the todo in the trash
+func revertTodo(tx *gorm.DB, op *models.Operation, from string, to string) (events.Event, error) {
+	var todo models.Todo
+	if err := tx.Unscoped().First(&todo, op.SubjectID).Error; err != nil {
+		return events.Event{}, ErrOperationConflict
+	}
+
+	trashed := todo.DeletedAt.Valid
+	if from == "" && !trashed {
+		return events.Event{}, ErrOperationConflict
+	}
+	if from != "" && (trashed || models.EncodeSnapshot(models.NewTodoSnapshot(&todo)) != from) {
+		return events.Event{}, ErrOperationConflict
+	}
+
+	if to == "" {
+		if err := trashTodos(tx, []uint{todo.ID}, trashedAt()); err != nil {
+			return events.Event{}, err
+		}
+		return todoEvent(tx, events.TodoDeleted, &todo), nil
+	}
+
+	var snapshot models.TodoSnapshot
+	if err := json.Unmarshal([]byte(to), &snapshot); err != nil {
+		return events.Event{}, err
+	}
+	before := todo
+	snapshot.Fill(&todo)
+
+	// the todo can't go back to a list which is gone
+	if todo.ListID != nil {
+		var lists int64
+		tx.Model(&models.List{}).Where("id = ? AND user_id = ?", *todo.ListID, todo.UserID).Count(&lists)
+		if lists == 0 {
+			return events.Event{}, ErrOperationConflict
+		}
+	}
+
+	if trashed {
+		if err := restoreTodo(tx, &todo); err != nil {
+			return events.Event{}, err
+		}
+	}
+	if err := updateTodo(tx, &todo); err != nil {
+		return events.Event{}, err
+	}
+
+	if trashed {
+		return todoEvent(tx, events.TodoRestored, &todo), nil
+	}
+	return withPrevious(todoEvent(tx, events.TodoUpdated, &todo), todoEvent(tx, events.TodoUpdated, &before)), nil
+}
+
+// revertList brings the list of the operation from a state to another,
+// an empty state being the list in the trash. The todos it brings back
+// with the list are published to restored.
+func revertList(tx *gorm.DB, op *models.Operation, from string, to string, restored *pendingEvents) (events.Event, error) {
+	var list models.List
+	if err := tx.Unscoped().First(&list, op.SubjectID).Error; err != nil {
+		return events.Event{}, ErrOperationConflict
+	}
+
+	trashed := list.DeletedAt.Valid
+	if from == "" && !trashed {
+		return events.Event{}, ErrOperationConflict
+	}
+	if from != "" && (trashed || models.EncodeSnapshot(models.NewListSnapshot(&list)) != from) {
+		return events.Event{}, ErrOperationConflict
+	}
+
+	if to == "" {
+		if err := trashList(tx, &list, trashedAt()); err != nil {
+			return events.Event{}, err
+		}
+		return listEvent(tx, events.ListDeleted, &list), nil
+	}
+
+	var snapshot models.ListSnapshot
+	if err := json.Unmarshal([]byte(to), &snapshot); err != nil {
+		return events.Event{}, err
+	}
+	before := list
+	snapshot.Fill(&list)
+
+	if trashed {
+		if err := restoreList(tx, &list, restored); err != nil {
+			return events.Event{}, err
+		}
+	}
+	if err := updateVersioned(tx, &list, &list.Version, map[string]interface{}{"name": list.Name}); err != nil {
+		return events.Event{}, err
+	}
+
+	if trashed {
+		return listEvent(tx, events.ListRestored, &list), nil
+	}
+	return withPrevious(listEvent(tx, events.ListUpdated, &list), listEvent(tx, events.ListUpdated, &before)), nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type JournalRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  JournalRepository
+	todos TodoRepository
+	lists ListRepository
+	todo  *models.Todo
+}
+
+func (suite *JournalRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Operation{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Reminder{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+
+	suite.db = db
+	suite.repo = NewJournalRepository(db, events.Discard, 3)
+	suite.todos = NewTodoRepository(db, events.Discard)
+	suite.lists = NewListRepository(db, events.Discard)
+
+	suite.todo = &models.Todo{UserID: 1, Title: "Ship release"}
+	suite.todos.Create(suite.todo)
+	suite.record(models.OperationCreate, nil, models.NewTodoSnapshot(suite.todo))
+}
+
+// record records the change of the suite's todo
+func (suite *JournalRepositoryTestSuite) record(kind string, before, after *models.TodoSnapshot) {
+	suite.repo.Record(models.NewTodoOperation(kind, suite.todo, before, after))
+}
+
+// rename renames the suite's todo and records it
+func (suite *JournalRepositoryTestSuite) rename(title string) {
+	before := models.NewTodoSnapshot(suite.todo)
+	suite.todo.Title = title
+	suite.todos.Update(suite.todo)
+	suite.record(models.OperationUpdate, before, models.NewTodoSnapshot(suite.todo))
+}
+
+func (suite *JournalRepositoryTestSuite) TestUndoAndRedo() {
+	assert := assert.New(suite.T())
+
+	suite.rename("Ship the release")
+
+	op, err := suite.repo.Undo(1)
+	if assert.NoError(err) {
+		assert.Equal(models.OperationUpdate, op.Kind)
+		assert.True(op.IsUndone())
+	}
+	assert.Equal("Ship release", suite.todos.ByID(suite.todo.ID).Title)
+
+	op, err = suite.repo.Redo(1)
+	if assert.NoError(err) {
+		assert.False(op.IsUndone())
+	}
+	assert.Equal("Ship the release", suite.todos.ByID(suite.todo.ID).Title)
+
+	_, err = suite.repo.Redo(1)
+	assert.Equal(ErrNothingToRedo, err)
+}
+
+func (suite *JournalRepositoryTestSuite) TestUndoDeleteRestoresFromTheTrash() {
+	assert := assert.New(suite.T())
+
+	suite.todos.Delete(suite.todo.ID)
+	suite.record(models.OperationDelete, models.NewTodoSnapshot(suite.todo), nil)
+
+	_, err := suite.repo.Undo(1)
+	assert.NoError(err)
+	assert.NotNil(suite.todos.ByID(suite.todo.ID))
+
+	// undoing the creation moves it to the trash again
+	_, err = suite.repo.Undo(1)
+	assert.NoError(err)
+	assert.Nil(suite.todos.ByID(suite.todo.ID))
+	assert.NotNil(suite.todos.TrashedByID(suite.todo.ID))
+
+	_, err = suite.repo.Undo(1)
+	assert.Equal(ErrNothingToUndo, err)
+}
+
+func (suite *JournalRepositoryTestSuite) TestUndoConflictsWithLaterEdits() {
+	assert := assert.New(suite.T())
+
+	suite.rename("Ship the release")
+
+	// edited elsewhere without being journaled, e.g. by an import
+	suite.todo.Title = "Release shipped"
+	suite.todos.Update(suite.todo)
+
+	_, err := suite.repo.Undo(1)
+	assert.True(errors.Is(err, ErrOperationConflict))
+	assert.Equal("Release shipped", suite.todos.ByID(suite.todo.ID).Title)
+
+	// the conflicting operation is dropped, the creation is up next
+	assert.Len(suite.repo.History(1), 1)
+}
+
+func (suite *JournalRepositoryTestSuite) TestUndoListDelete() {
+	assert := assert.New(suite.T())
+
+	list := &models.List{UserID: 1, Name: "Work"}
+	suite.lists.Create(list)
+	before := models.NewTodoSnapshot(suite.todo)
+	suite.todo.ListID = &list.ID
+	suite.todos.Update(suite.todo)
+	suite.record(models.OperationMove, before, models.NewTodoSnapshot(suite.todo))
+
+	suite.lists.Delete(list.ID)
+	suite.repo.Record(models.NewListOperation(models.OperationDelete, list, models.NewListSnapshot(list), nil))
+
+	_, err := suite.repo.Undo(1)
+	assert.NoError(err)
+	assert.NotNil(suite.lists.ByID(list.ID))
+	assert.Len(suite.todos.ByList(list.ID), 1)
+
+	_, err = suite.repo.Undo(1)
+	assert.NoError(err)
+	assert.Nil(suite.todos.ByID(suite.todo.ID).ListID)
+}
+
+func (suite *JournalRepositoryTestSuite) TestRecordForgetsUndoneAndOldOperations() {
+	assert := assert.New(suite.T())
+
+	suite.rename("One")
+	suite.repo.Undo(1)
+	suite.rename("Two")
+
+	_, err := suite.repo.Redo(1)
+	assert.Equal(ErrNothingToRedo, err)
+
+	suite.rename("Three")
+	suite.rename("Four")
+
+	history := suite.repo.History(1)
+	if assert.Len(history, 3) {
+		assert.Contains(history[0].After, "Four")
+		assert.Contains(history[2].After, "Two")
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestJournalRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(JournalRepositoryTestSuite))
+}
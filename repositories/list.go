@@ -0,0 +1,321 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ListRepository will interact to the lists table.
+type ListRepository interface {
+	// Methods for querying lists
+	ByID(id uint) *models.List
+	ByUser(userID uint) []models.List
+
+	// Methods for altering lists
+	Create(list *models.List) error
+	CreateClaimed(list *models.List, record *models.ClientRecord) error
+	Update(list *models.List) error
+	Delete(id uint) error
+
+	// Methods for the lists in the trash
+	Trashed(userID uint) []models.List
+	TrashedByID(id uint) *models.List
+	Restore(list *models.List) error
+	ForceDelete(id uint) error
+	Purge(before time.Time) (int64, error)
+
+	// Workspace scopes the repository to the lists of the workspace
+	Workspace(id uint) ListRepository
+}
+
+type listRepoGorm struct {
+	db        *gorm.DB
+	pub       events.Publisher
+	workspace *uint
+}
+
+var _ ListRepository = &listRepoGorm{}
+
+// NewListRepository creates instance of ListRepository
+// which publishes its writes to pub. It sees the lists of
+// every workspace until it is scoped to one.
+func NewListRepository(db *gorm.DB, pub events.Publisher) ListRepository {
+	return &listRepoGorm{db: db, pub: pub}
+}
+
+// Workspace returns a copy of the repository which only sees the
+// lists of the workspace, and creates the new ones in there
+func (lr *listRepoGorm) Workspace(id uint) ListRepository {
+	return &listRepoGorm{db: lr.db, pub: lr.pub, workspace: &id}
+}
+
+// ByID will look up a list by ID
+// If no record was found, the method will return nil
+func (lr *listRepoGorm) ByID(id uint) *models.List {
+	var l models.List
+	err := lr.query().First(&l, id).Error
+	if err == nil {
+		return &l
+	}
+
+	return nil
+}
+
+// ByUser will return all the lists owned by the user
+func (lr *listRepoGorm) ByUser(userID uint) []models.List {
+	var lists []models.List
+	lr.query().Where("user_id = ?", userID).Order("id").Find(&lists)
+
+	return lists
+}
+
+// Create will create a new list
+func (lr *listRepoGorm) Create(list *models.List) error {
+	if lr.workspace != nil {
+		list.WorkspaceID = *lr.workspace
+	}
+	if err := lr.db.Create(list).Error; err != nil {
+		return err
+	}
+
+	publish(lr.pub, listEvent(lr.db, events.ListCreated, list))
+	return nil
+}
+
+// CreateClaimed will create the list like Create, and give it the ID
+// the client gave it in the same transaction. Nothing is created when
+// the client ID was given to another record.
+func (lr *listRepoGorm) CreateClaimed(list *models.List, record *models.ClientRecord) error {
+	if lr.workspace != nil {
+		list.WorkspaceID = *lr.workspace
+	}
+	err := lr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(list).Error; err != nil {
+			return err
+		}
+		return claimClientID(tx, record, list.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(lr.pub, listEvent(lr.db, events.ListCreated, list))
+	return nil
+}
+
+// Update will update the list's fields, unless it was changed since
+// it was read
+func (lr *listRepoGorm) Update(list *models.List) error {
+	var before models.List
+	err := lr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&before, list.ID).Error; err != nil {
+			return err
+		}
+		return updateVersioned(tx, list, &list.Version, map[string]interface{}{"name": list.Name})
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(lr.pub, withPrevious(listEvent(lr.db, events.ListUpdated, list), listEvent(lr.db, events.ListUpdated, &before)))
+	return nil
+}
+
+// Delete will move the list to the trash by ID, along with its todos
+// and their reminders. They share the time they were deleted at, so
+// restoring the list brings back what was deleted with it.
+func (lr *listRepoGorm) Delete(id uint) error {
+	list := lr.ByID(id)
+	if list == nil {
+		return nil
+	}
+
+	err := lr.db.Transaction(func(tx *gorm.DB) error {
+		return trashList(tx, list, trashedAt())
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(lr.pub, listEvent(lr.db, events.ListDeleted, list))
+	return nil
+}
+
+// Trashed will return the lists of the user in the trash, the most
+// recently deleted first
+func (lr *listRepoGorm) Trashed(userID uint) []models.List {
+	var lists []models.List
+	lr.query().Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id").
+		Find(&lists)
+
+	return lists
+}
+
+// TrashedByID will look up a list in the trash by ID
+// If no record was found, the method will return nil
+func (lr *listRepoGorm) TrashedByID(id uint) *models.List {
+	var l models.List
+	err := lr.query().Unscoped().Where("deleted_at IS NOT NULL").First(&l, id).Error
+	if err == nil {
+		return &l
+	}
+
+	return nil
+}
+
+// Restore will take the list out of the trash along with the todos
+// and reminders which were deleted with it. Todos deleted before
+// the list stay in the trash. The todos are published as restored
+// after the list.
+func (lr *listRepoGorm) Restore(list *models.List) error {
+	restored := &pendingEvents{}
+	err := lr.db.Transaction(func(tx *gorm.DB) error {
+		return restoreList(tx, list, restored)
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(lr.pub, listEvent(lr.db, events.ListRestored, list))
+	for _, e := range restored.events {
+		publish(lr.pub, e)
+	}
+	return nil
+}
+
+// ForceDelete will permanently delete the list by ID along with its
+// todos in the trash.
+func (lr *listRepoGorm) ForceDelete(id uint) error {
+	return lr.db.Transaction(func(tx *gorm.DB) error {
+		return destroyLists(tx, []uint{id})
+	})
+}
+
+// Purge will permanently delete the lists which were moved to the
+// trash before the given time, and returns how many were deleted.
+func (lr *listRepoGorm) Purge(before time.Time) (int64, error) {
+	var ids []uint
+	err := lr.query().Unscoped().Model(&models.List{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = lr.db.Transaction(func(tx *gorm.DB) error {
+		return destroyLists(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// trashList soft deletes the list along with its todos and their
+// reminders at the time
+func trashList(tx *gorm.DB, list *models.List, at time.Time) error {
+	var ids []uint
+	if err := tx.Model(&models.Todo{}).Where("list_id = ?", list.ID).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if err := trashTodos(tx, ids, at); err != nil {
+		return err
+	}
+
+	return tx.Model(list).Update("deleted_at", at).Error
+}
+
+// restoreList takes the list out of the trash along with the todos
+// and reminders which were deleted with it. The restored todos are
+// published to pub, which holds them until the transaction commits.
+func restoreList(tx *gorm.DB, list *models.List, pub *pendingEvents) error {
+	at := list.DeletedAt.Time
+
+	var ids []uint
+	err := tx.Unscoped().Model(&models.Todo{}).
+		Where("list_id = ? AND deleted_at = ?", list.ID, at).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		err = tx.Unscoped().Model(&models.Reminder{}).
+			Where("todo_id IN ? AND deleted_at = ?", ids, at).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&models.Todo{}).
+			Where("id IN ?", ids).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		var todos []models.Todo
+		err = tx.Preload("Reminders").Preload("Assignees.User").Preload("Blockers").
+			Order("id").
+			Find(&todos, ids).Error
+		if err != nil {
+			return err
+		}
+		for i := range todos {
+			pub.Publish(todoEvent(tx, events.TodoRestored, &todos[i]))
+		}
+	}
+
+	if err := tx.Unscoped().Model(list).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	list.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// query starts a query for the lists of the repository's workspace
+func (lr *listRepoGorm) query() *gorm.DB {
+	if lr.workspace == nil {
+		return lr.db
+	}
+	return lr.db.Where("lists.workspace_id = ?", *lr.workspace)
+}
+
+// destroyLists permanently deletes the lists, their members, their
+// columns and their todos in the trash
+func destroyLists(tx *gorm.DB, ids []uint) error {
+	var todoIDs []uint
+	err := tx.Unscoped().Model(&models.Todo{}).
+		Where("list_id IN ? AND deleted_at IS NOT NULL", ids).
+		Pluck("id", &todoIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(todoIDs) > 0 {
+		if err := destroyTodos(tx, todoIDs); err != nil {
+			return err
+		}
+	}
+
+	// nothing may reference the lists once they are gone
+	err = tx.Unscoped().Model(&models.Todo{}).
+		Where("list_id IN ?", ids).
+		Update("list_id", nil).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("list_id IN ?", ids).Delete(&models.ListMember{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("list_id IN ?", ids).Delete(&models.Column{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.List{}).Error
+}
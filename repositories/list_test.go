@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ListRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  ListRepository
+	todos TodoRepository
+	list  *models.List
+}
+
+func (suite *ListRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Reminder{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+	db.Unscoped().Where("1 = 1").Delete(&models.ListMember{})
+
+	suite.db = db
+	suite.repo = NewListRepository(db, events.Discard)
+	suite.todos = NewTodoRepository(db, events.Discard)
+
+	suite.list = &models.List{UserID: 1, Name: "Groceries"}
+	suite.repo.Create(suite.list)
+}
+
+// addTodo creates a todo with a reminder in the suite's list
+func (suite *ListRepositoryTestSuite) addTodo(title string) *models.Todo {
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	todo := &models.Todo{
+		UserID:    1,
+		ListID:    &suite.list.ID,
+		Title:     title,
+		Reminders: []models.Reminder{{Channel: models.ReminderInApp, RemindAt: &at}},
+	}
+	suite.todos.Create(todo)
+	return todo
+}
+
+func (suite *ListRepositoryTestSuite) TestDeleteMovesTodosToTheTrash() {
+	assert := assert.New(suite.T())
+
+	milk := suite.addTodo("Milk")
+	suite.addTodo("Bread")
+
+	assert.NoError(suite.repo.Delete(suite.list.ID))
+
+	assert.Nil(suite.repo.ByID(suite.list.ID))
+	assert.Empty(suite.todos.ByList(suite.list.ID))
+	assert.Len(suite.repo.Trashed(1), 1)
+
+	trashed := suite.todos.Trashed(1)
+	if assert.Len(trashed, 2) {
+		list := suite.repo.TrashedByID(suite.list.ID)
+		assert.True(list.DeletedAt.Time.Equal(trashed[0].DeletedAt.Time))
+	}
+
+	var reminders int64
+	suite.db.Model(&models.Reminder{}).Where("todo_id = ?", milk.ID).Count(&reminders)
+	assert.Zero(reminders)
+}
+
+func (suite *ListRepositoryTestSuite) TestRestoreBringsBackWhatWasDeletedWithIt() {
+	assert := assert.New(suite.T())
+
+	milk := suite.addTodo("Milk")
+	bread := suite.addTodo("Bread")
+
+	// bread was deleted on its own before the list
+	suite.todos.Delete(bread.ID)
+	suite.db.Unscoped().Model(&models.Todo{}).Where("id = ?", bread.ID).
+		Update("deleted_at", time.Now().Add(-time.Hour))
+	suite.repo.Delete(suite.list.ID)
+
+	list := suite.repo.TrashedByID(suite.list.ID)
+	if !assert.NotNil(list) {
+		return
+	}
+	assert.NoError(suite.repo.Restore(list))
+
+	assert.NotNil(suite.repo.ByID(suite.list.ID))
+	todos := suite.todos.ByList(suite.list.ID)
+	if assert.Len(todos, 1) {
+		assert.Equal(milk.ID, todos[0].ID)
+		assert.Len(todos[0].Reminders, 1)
+	}
+	assert.NotNil(suite.todos.TrashedByID(bread.ID))
+}
+
+func (suite *ListRepositoryTestSuite) TestPurge() {
+	assert := assert.New(suite.T())
+
+	milk := suite.addTodo("Milk")
+	suite.repo.Delete(suite.list.ID)
+
+	kept := &models.List{UserID: 1, Name: "Work"}
+	suite.repo.Create(kept)
+
+	n, err := suite.repo.Purge(time.Now().Add(time.Second))
+	assert.NoError(err)
+	assert.Equal(int64(1), n)
+
+	assert.Nil(suite.repo.TrashedByID(suite.list.ID))
+	assert.Nil(suite.todos.TrashedByID(milk.ID))
+	assert.NotNil(suite.repo.ByID(kept.ID))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func (suite *ListRepositoryTestSuite) TestPublishesToTheMembers() {
+	assert := assert.New(suite.T())
+
+	suite.db.Create(&models.ListMember{ListID: suite.list.ID, UserID: 2})
+	bus := events.NewMemoryBus(10, 10)
+	sub := bus.Subscribe(2, 0)
+	defer sub.Close()
+
+	suite.list.Name = "Groceries for the week"
+	assert.NoError(NewListRepository(suite.db, bus).Update(suite.list))
+	e := <-sub.C
+	assert.Equal(events.ListUpdated, e.Type)
+	assert.Equal([]uint{1, 2}, e.UserIDs)
+
+	todo := &models.Todo{UserID: 1, ListID: &suite.list.ID, Title: "Milk"}
+	assert.NoError(NewTodoRepository(suite.db, bus).Create(todo))
+	e = <-sub.C
+	assert.Equal(events.TodoCreated, e.Type)
+	assert.Equal(todo.ID, e.ResourceID)
+}
+
+func TestListRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ListRepositoryTestSuite))
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// ListMemberRepository will interact to the list_members table.
+// The owner of a list is not one of its members.
+type ListMemberRepository interface {
+	// Methods for querying list members
+	ByList(listID uint) []models.ListMember
+	IsMember(listID uint, userID uint) bool
+
+	// Methods for altering list members
+	Create(member *models.ListMember) error
+	Delete(listID uint, userID uint) error
+}
+
+type listMemberRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ ListMemberRepository = &listMemberRepoGorm{}
+
+// NewListMemberRepository creates instance of ListMemberRepository
+func NewListMemberRepository(db *gorm.DB) ListMemberRepository {
+	return &listMemberRepoGorm{db}
+}
+
+// ByList will return the members of the list along with their
+// users, the first to join first
+func (mr *listMemberRepoGorm) ByList(listID uint) []models.ListMember {
+	var members []models.ListMember
+	mr.db.Preload("User").
+		Where("list_id = ?", listID).
+		Order("id").
+		Find(&members)
+
+	return members
+}
+
+// IsMember determines if the user is a member of the list
+func (mr *listMemberRepoGorm) IsMember(listID uint, userID uint) bool {
+	var count int64
+	mr.db.Model(&models.ListMember{}).
+		Where("list_id = ? AND user_id = ?", listID, userID).
+		Count(&count)
+
+	return count > 0
+}
+
+// Create will add a member to the list
+func (mr *listMemberRepoGorm) Create(member *models.ListMember) error {
+	return mr.db.Create(member).Error
+}
+
+// Delete will remove the user from the members of the list, the
+// todos of the list assigned to them are unassigned
+func (mr *listMemberRepoGorm) Delete(listID uint, userID uint) error {
+	return mr.db.Transaction(func(tx *gorm.DB) error {
+		todos := tx.Unscoped().Model(&models.Todo{}).Select("id").Where("list_id = ?", listID)
+		err := tx.Unscoped().
+			Where("user_id = ? AND todo_id IN (?)", userID, todos).
+			Delete(&models.TodoAssignee{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().
+			Where("list_id = ? AND user_id = ?", listID, userID).
+			Delete(&models.ListMember{}).Error
+	})
+}
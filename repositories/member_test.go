@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ListMemberRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  ListMemberRepository
+	todos TodoRepository
+	team  *models.List
+	home  *models.List
+}
+
+func (suite *ListMemberRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.ListMember{})
+	db.Unscoped().Where("1 = 1").Delete(&models.TodoAssignee{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+	db.Unscoped().Where("1 = 1").Delete(&models.User{})
+
+	suite.db = db
+	suite.repo = NewListMemberRepository(db)
+	suite.todos = NewTodoRepository(db, events.Discard)
+	suite.team = &models.List{UserID: 1, Name: "Team"}
+	suite.home = &models.List{UserID: 1, Name: "Home"}
+	db.Create(suite.team)
+	db.Create(suite.home)
+}
+
+func (suite *ListMemberRepositoryTestSuite) TestByList() {
+	assert := assert.New(suite.T())
+
+	bob := &models.User{Username: "bob", Email: "bob@example.com", Name: "Bob", Password: "secret"}
+	suite.db.Create(bob)
+	assert.NoError(suite.repo.Create(&models.ListMember{ListID: suite.team.ID, UserID: bob.ID}))
+	assert.Error(suite.repo.Create(&models.ListMember{ListID: suite.team.ID, UserID: bob.ID}))
+
+	members := suite.repo.ByList(suite.team.ID)
+	if assert.Len(members, 1) {
+		assert.Equal("bob", members[0].User.Username)
+	}
+	assert.True(suite.repo.IsMember(suite.team.ID, bob.ID))
+	assert.False(suite.repo.IsMember(suite.home.ID, bob.ID))
+	assert.Empty(suite.repo.ByList(suite.home.ID))
+}
+
+func (suite *ListMemberRepositoryTestSuite) TestDeleteUnassignsTheListsTodos() {
+	assert := assert.New(suite.T())
+
+	suite.repo.Create(&models.ListMember{ListID: suite.team.ID, UserID: 2})
+	suite.repo.Create(&models.ListMember{ListID: suite.home.ID, UserID: 2})
+
+	ship := &models.Todo{UserID: 1, ListID: &suite.team.ID, Title: "Ship release"}
+	trashed := &models.Todo{UserID: 1, ListID: &suite.team.ID, Title: "Write notes"}
+	rent := &models.Todo{UserID: 1, ListID: &suite.home.ID, Title: "Pay rent"}
+	for _, todo := range []*models.Todo{ship, trashed, rent} {
+		suite.todos.Create(todo)
+		suite.todos.Assign(todo, []uint{1, 2})
+	}
+	suite.todos.Delete(trashed.ID)
+
+	assert.NoError(suite.repo.Delete(suite.team.ID, 2))
+
+	assert.False(suite.repo.IsMember(suite.team.ID, 2))
+	assert.True(suite.repo.IsMember(suite.home.ID, 2))
+	assert.Equal([]uint{1}, suite.todos.ByID(ship.ID).AssigneeIDs())
+	assert.Equal([]uint{1, 2}, suite.todos.ByID(rent.ID).AssigneeIDs())
+
+	// the todos in the trash come back without the member
+	suite.todos.Restore(suite.todos.TrashedByID(trashed.ID))
+	assert.Equal([]uint{1}, suite.todos.ByID(trashed.ID).AssigneeIDs())
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestListMemberRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ListMemberRepositoryTestSuite))
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository will interact to the notifications
+// and notification_preferences tables.
+type NotificationRepository interface {
+	// Methods for querying notifications
+	ByID(id uint) *models.Notification
+	ByUser(userID uint, unreadOnly bool, offset int, limit int) ([]models.Notification, int64)
+	UnreadCount(userID uint) int64
+
+	// Methods for altering notifications
+	Create(notification *models.Notification) error
+	MarkRead(id uint, at time.Time) error
+	MarkAllRead(userID uint, at time.Time) (int64, error)
+	PruneRead(before time.Time) (int64, error)
+
+	// Methods for notification preferences
+	Preferences(userID uint) []models.NotificationPreference
+	IsEnabled(userID uint, notificationType string) bool
+	SetPreference(userID uint, notificationType string, enabled bool) error
+}
+
+type notificationRepoGorm struct {
+	db  *gorm.DB
+	pub events.Publisher
+}
+
+var _ NotificationRepository = &notificationRepoGorm{}
+
+// NewNotificationRepository creates instance of NotificationRepository
+// which publishes new notifications to pub.
+func NewNotificationRepository(db *gorm.DB, pub events.Publisher) NotificationRepository {
+	return &notificationRepoGorm{db, pub}
+}
+
+// ByID will look up a notification by ID
+// If no record was found, the method will return nil
+func (nr *notificationRepoGorm) ByID(id uint) *models.Notification {
+	var n models.Notification
+	err := nr.db.First(&n, id).Error
+	if err == nil {
+		return &n
+	}
+
+	return nil
+}
+
+// ByUser will return a page of the user's notifications, newest
+// first, along with the total number of notifications matched.
+func (nr *notificationRepoGorm) ByUser(userID uint, unreadOnly bool, offset int, limit int) ([]models.Notification, int64) {
+	query := nr.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var notifications []models.Notification
+	query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&notifications)
+
+	return notifications, total
+}
+
+// UnreadCount will count the user's unread notifications
+func (nr *notificationRepoGorm) UnreadCount(userID uint) int64 {
+	var count int64
+	nr.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count)
+
+	return count
+}
+
+// Create will create a new notification
+func (nr *notificationRepoGorm) Create(notification *models.Notification) error {
+	if err := nr.db.Create(notification).Error; err != nil {
+		return err
+	}
+
+	publish(nr.pub, events.Event{
+		Type:       events.NotificationCreated,
+		Resource:   "notification",
+		ResourceID: notification.ID,
+		UserIDs:    []uint{notification.UserID},
+		Data: map[string]interface{}{
+			"id":      notification.ID,
+			"type":    notification.Type,
+			"title":   notification.Title,
+			"todo_id": notification.TodoID,
+		},
+	})
+	return nil
+}
+
+// MarkRead will mark the notification as read, notifications
+// which were already read keep their original read time.
+func (nr *notificationRepoGorm) MarkRead(id uint, at time.Time) error {
+	return nr.db.Model(&models.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", at.UTC()).Error
+}
+
+// MarkAllRead will mark all the unread notifications of the user
+// as read and return how many of them were updated.
+func (nr *notificationRepoGorm) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	res := nr.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at.UTC())
+
+	return res.RowsAffected, res.Error
+}
+
+// PruneRead will permanently delete the notifications
+// which were read before the given time.
+func (nr *notificationRepoGorm) PruneRead(before time.Time) (int64, error) {
+	res := nr.db.Unscoped().
+		Where("read_at IS NOT NULL AND read_at < ?", before.UTC()).
+		Delete(&models.Notification{})
+
+	return res.RowsAffected, res.Error
+}
+
+// Preferences will return the preferences the user has saved
+func (nr *notificationRepoGorm) Preferences(userID uint) []models.NotificationPreference {
+	var prefs []models.NotificationPreference
+	nr.db.Where("user_id = ?", userID).Order("type").Find(&prefs)
+
+	return prefs
+}
+
+// IsEnabled determines if the user wants to receive the notification
+// type. Types the user never changed are enabled by default.
+func (nr *notificationRepoGorm) IsEnabled(userID uint, notificationType string) bool {
+	var pref models.NotificationPreference
+	err := nr.db.Where("user_id = ? AND type = ?", userID, notificationType).First(&pref).Error
+	if err != nil {
+		return true
+	}
+
+	return pref.Enabled
+}
+
+// SetPreference will save whether the user wants to receive the notification type
+func (nr *notificationRepoGorm) SetPreference(userID uint, notificationType string, enabled bool) error {
+	var pref models.NotificationPreference
+	return nr.db.
+		Where(models.NotificationPreference{UserID: userID, Type: notificationType}).
+		Assign(map[string]interface{}{"enabled": enabled}).
+		FirstOrCreate(&pref).Error
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type NotificationRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo NotificationRepository
+	now  time.Time
+}
+
+func (suite *NotificationRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Notification{})
+	db.Unscoped().Where("1 = 1").Delete(&models.NotificationPreference{})
+
+	suite.db = db
+	suite.repo = NewNotificationRepository(db, events.Discard)
+	suite.now = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	for _, title := range []string{"first", "second", "third"} {
+		suite.repo.Create(&models.Notification{UserID: 1, Type: models.NotificationShared, Title: title})
+	}
+	suite.repo.Create(&models.Notification{UserID: 2, Type: models.NotificationShared, Title: "other"})
+}
+
+func (suite *NotificationRepositoryTestSuite) TestByUserPaginatesNewestFirst() {
+	assert := assert.New(suite.T())
+
+	notifications, total := suite.repo.ByUser(1, false, 0, 2)
+
+	assert.Equal(int64(3), total)
+	if assert.Len(notifications, 2) {
+		assert.Equal("third", notifications[0].Title)
+		assert.Equal("second", notifications[1].Title)
+	}
+
+	notifications, _ = suite.repo.ByUser(1, false, 2, 2)
+	if assert.Len(notifications, 1) {
+		assert.Equal("first", notifications[0].Title)
+	}
+}
+
+func (suite *NotificationRepositoryTestSuite) TestMarkRead() {
+	assert := assert.New(suite.T())
+
+	notifications, _ := suite.repo.ByUser(1, false, 0, 1)
+	assert.NoError(suite.repo.MarkRead(notifications[0].ID, suite.now))
+
+	read := suite.repo.ByID(notifications[0].ID)
+	assert.True(read.IsRead())
+	assert.Equal(int64(2), suite.repo.UnreadCount(1))
+
+	unread, total := suite.repo.ByUser(1, true, 0, 10)
+	assert.Equal(int64(2), total)
+	assert.Len(unread, 2)
+}
+
+func (suite *NotificationRepositoryTestSuite) TestMarkAllRead() {
+	assert := assert.New(suite.T())
+
+	count, err := suite.repo.MarkAllRead(1, suite.now)
+
+	assert.NoError(err)
+	assert.Equal(int64(3), count)
+	assert.Zero(suite.repo.UnreadCount(1))
+	assert.Equal(int64(1), suite.repo.UnreadCount(2))
+}
+
+func (suite *NotificationRepositoryTestSuite) TestPruneRead() {
+	assert := assert.New(suite.T())
+
+	suite.repo.MarkAllRead(1, suite.now)
+	notifications, _ := suite.repo.ByUser(1, false, 0, 1)
+	suite.repo.MarkRead(notifications[0].ID, suite.now) // already read, keeps read_at
+	suite.repo.Create(&models.Notification{UserID: 1, Type: models.NotificationShared, Title: "unread"})
+
+	count, err := suite.repo.PruneRead(suite.now)
+	assert.NoError(err)
+	assert.Zero(count)
+
+	count, err = suite.repo.PruneRead(suite.now.Add(time.Minute))
+	assert.NoError(err)
+	assert.Equal(int64(3), count)
+
+	remaining, total := suite.repo.ByUser(1, false, 0, 10)
+	assert.Equal(int64(1), total)
+	assert.Equal("unread", remaining[0].Title)
+}
+
+func (suite *NotificationRepositoryTestSuite) TestPreferences() {
+	assert := assert.New(suite.T())
+
+	assert.True(suite.repo.IsEnabled(1, models.NotificationShared))
+
+	assert.NoError(suite.repo.SetPreference(1, models.NotificationShared, false))
+	assert.False(suite.repo.IsEnabled(1, models.NotificationShared))
+	assert.True(suite.repo.IsEnabled(2, models.NotificationShared))
+
+	assert.NoError(suite.repo.SetPreference(1, models.NotificationShared, true))
+	assert.True(suite.repo.IsEnabled(1, models.NotificationShared))
+	assert.Len(suite.repo.Preferences(1), 1)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestNotificationRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationRepositoryTestSuite))
+}
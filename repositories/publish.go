@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+// publish announces a successful write. The write already happened,
+// so a bus failure is only logged instead of being returned.
+func publish(pub events.Publisher, e events.Event) {
+	if err := pub.Publish(e); err != nil {
+		log.Errorf("repositories: publishing %s: %v", e.Type, err)
+	}
+}
+
+// pendingEvents holds the events of the writes of a transaction
+// until it is committed
+type pendingEvents struct {
+	events []events.Event
+}
+
+// Publish holds the event back
+func (p *pendingEvents) Publish(e events.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+// todoEvent creates the event of a todo change for the users who can
+// see it, its owner first and then the users it is assigned to and
+// the members of its list
+func todoEvent(db *gorm.DB, eventType string, t *models.Todo) events.Event {
+	userIDs := []uint{t.UserID}
+	for _, a := range t.Assignees {
+		userIDs = appendUserID(userIDs, a.UserID)
+	}
+	if t.ListID != nil {
+		userIDs = appendListMembers(db, userIDs, *t.ListID)
+	}
+
+	return events.Event{
+		Type:       eventType,
+		Resource:   "todo",
+		ResourceID: t.ID,
+		UserIDs:    userIDs,
+		Data: map[string]interface{}{
+			"id":           t.ID,
+			"workspace_id": t.WorkspaceID,
+			"list_id":      t.ListID,
+			"title":        t.Title,
+			"description":  t.Description,
+			"completed":    t.Completed,
+			"due_at":       t.DueAt,
+			"timezone":     t.Timezone,
+			"recurrence":   t.Recurrence,
+			"priority":     t.Priority,
+			"estimate":     t.Estimate,
+			"column_id":    t.ColumnID,
+			"position":     t.Position,
+		},
+	}
+}
+
+// listEvent creates the event of a list change for its owner and its
+// members
+func listEvent(db *gorm.DB, eventType string, l *models.List) events.Event {
+	return events.Event{
+		Type:       eventType,
+		Resource:   "list",
+		ResourceID: l.ID,
+		UserIDs:    appendListMembers(db, []uint{l.UserID}, l.ID),
+		Data: map[string]interface{}{
+			"id":           l.ID,
+			"workspace_id": l.WorkspaceID,
+			"name":         l.Name,
+		},
+	}
+}
+
+// appendListMembers appends the IDs of the members of the list to the
+// IDs of the users, but for the ones they have already
+func appendListMembers(db *gorm.DB, userIDs []uint, listID uint) []uint {
+	var members []uint
+	db.Model(&models.ListMember{}).Where("list_id = ?", listID).Order("id").Pluck("user_id", &members)
+	for _, id := range members {
+		userIDs = appendUserID(userIDs, id)
+	}
+	return userIDs
+}
+
+// appendUserID appends the ID to the IDs of the users, unless they
+// have it already
+func appendUserID(userIDs []uint, id uint) []uint {
+	for _, userID := range userIDs {
+		if userID == id {
+			return userIDs
+		}
+	}
+	return append(userIDs, id)
+}
+
+// withPrevious adds to the event of an update the values the fields
+// it changed had in the event of the state before it. The users who
+// could see the state before hear about it too, e.g. the members of
+// the list a todo was moved out of.
+func withPrevious(e events.Event, before events.Event) events.Event {
+	for _, id := range before.UserIDs {
+		e.UserIDs = appendUserID(e.UserIDs, id)
+	}
+
+	data, _ := e.Data.(map[string]interface{})
+	previous, _ := before.Data.(map[string]interface{})
+
+	for field, value := range data {
+		old := previous[field]
+		a, _ := json.Marshal(value)
+		b, _ := json.Marshal(old)
+		if string(a) == string(b) {
+			continue
+		}
+		if e.Previous == nil {
+			e.Previous = make(map[string]interface{})
+		}
+		e.Previous[field] = old
+	}
+	return e
+}
@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/text"
+	"gorm.io/gorm"
+)
+
+// ErrLeaseLost is returned when releasing the lease of a reminder which
+// expired and was claimed again in the meantime
+var ErrLeaseLost = errors.New("The lease of the reminder was lost")
+
+// ReminderRepository will interact to the reminders table.
+type ReminderRepository interface {
+	// Methods for querying reminders
+	ByID(id uint) *models.Reminder
+
+	// Methods for altering reminders
+	Create(reminder *models.Reminder) error
+	Delete(id uint) error
+
+	// Methods used by the reminder scheduler
+	Claim(owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error)
+	MarkSent(reminder *models.Reminder, at time.Time) error
+	Retry(reminder *models.Reminder, reason string, at time.Time) error
+	MarkFailed(reminder *models.Reminder, reason string, at time.Time) error
+}
+
+type reminderRepoGorm struct {
+	db  *gorm.DB
+	pub events.Publisher
+}
+
+var _ ReminderRepository = &reminderRepoGorm{}
+
+// NewReminderRepository creates instance of ReminderRepository
+// which publishes its writes to pub.
+func NewReminderRepository(db *gorm.DB, pub events.Publisher) ReminderRepository {
+	return &reminderRepoGorm{db, pub}
+}
+
+// ByID will look up a reminder by ID
+// If no record was found, the method will return nil
+func (rr *reminderRepoGorm) ByID(id uint) *models.Reminder {
+	var r models.Reminder
+	err := rr.db.First(&r, id).Error
+	if err == nil {
+		return &r
+	}
+
+	return nil
+}
+
+// Create will create a new reminder. The caller is expected
+// to have resolved FireAt through models.Reminder.Schedule.
+func (rr *reminderRepoGorm) Create(reminder *models.Reminder) error {
+	if err := rr.db.Create(reminder).Error; err != nil {
+		return err
+	}
+
+	publish(rr.pub, rr.event(events.ReminderCreated, reminder))
+	return nil
+}
+
+// Delete will delete a reminder by ID
+func (rr *reminderRepoGorm) Delete(id uint) error {
+	reminder := rr.ByID(id)
+	if reminder == nil {
+		return nil
+	}
+	if err := rr.db.Delete(reminder).Error; err != nil {
+		return err
+	}
+
+	publish(rr.pub, rr.event(events.ReminderDeleted, reminder))
+	return nil
+}
+
+// Claim leases up to limit due reminders to the owner. Each candidate
+// is taken with a conditional update so when several instances poll
+// at the same time, only the one whose update matched will get it.
+// Every lease gets a token of its own, so a lease which expired and
+// was claimed again, even by the same owner, is told apart.
+func (rr *reminderRepoGorm) Claim(owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	now = now.UTC()
+	until := now.Add(lease)
+
+	var candidates []models.Reminder
+	err := rr.db.
+		Where("fire_at <= ? AND sent_at IS NULL AND failed_at IS NULL", now).
+		Where("lease_until IS NULL OR lease_until <= ?", now).
+		Order("fire_at").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.Reminder, 0, len(candidates))
+	for _, r := range candidates {
+		token := newLeaseToken()
+		res := rr.db.Model(&models.Reminder{}).
+			Where("id = ? AND (lease_until IS NULL OR lease_until <= ?)", r.ID, now).
+			Updates(map[string]interface{}{"lease_owner": owner, "lease_token": token, "lease_until": until})
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 1 {
+			r.LeaseOwner = owner
+			r.LeaseToken = token
+			r.LeaseUntil = &until
+			claimed = append(claimed, r)
+		}
+	}
+
+	return claimed, nil
+}
+
+// MarkSent flags the reminder as delivered and releases its lease
+func (rr *reminderRepoGorm) MarkSent(reminder *models.Reminder, at time.Time) error {
+	return rr.release(reminder, map[string]interface{}{
+		"sent_at":    at.UTC(),
+		"last_error": "",
+	})
+}
+
+// Retry releases the lease of a failed delivery so the
+// reminder will be picked up again once at has passed.
+func (rr *reminderRepoGorm) Retry(reminder *models.Reminder, reason string, at time.Time) error {
+	return rr.release(reminder, map[string]interface{}{
+		"lease_until": at.UTC(),
+		"last_error":  text.Truncate(reason, 255),
+	})
+}
+
+// MarkFailed gives up on delivering the reminder
+func (rr *reminderRepoGorm) MarkFailed(reminder *models.Reminder, reason string, at time.Time) error {
+	return rr.release(reminder, map[string]interface{}{
+		"failed_at":  at.UTC(),
+		"last_error": text.Truncate(reason, 255),
+	})
+}
+
+// release clears the lease, counts the attempt and applies the changes
+// as long as the reminder is still leased as it was claimed. Otherwise
+// the lease expired and another claim of it is delivering it now, so
+// it is left to that one and ErrLeaseLost is returned.
+func (rr *reminderRepoGorm) release(reminder *models.Reminder, changes map[string]interface{}) error {
+	values := map[string]interface{}{
+		"lease_owner": "",
+		"lease_token": "",
+		"lease_until": nil,
+		"attempts":    gorm.Expr("attempts + 1"),
+	}
+	for k, v := range changes {
+		values[k] = v
+	}
+
+	res := rr.db.Model(&models.Reminder{}).
+		Where("id = ? AND lease_owner = ? AND lease_token = ?", reminder.ID, reminder.LeaseOwner, reminder.LeaseToken).
+		Updates(values)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// newLeaseToken generates the random token of a lease
+func newLeaseToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// event creates the event of a reminder change for the owner of its todo
+func (rr *reminderRepoGorm) event(eventType string, r *models.Reminder) events.Event {
+	var todo models.Todo
+	rr.db.Unscoped().Select("id", "user_id").First(&todo, r.TodoID)
+
+	return events.Event{
+		Type:       eventType,
+		Resource:   "reminder",
+		ResourceID: r.ID,
+		UserIDs:    []uint{todo.UserID},
+		Data: map[string]interface{}{
+			"id":      r.ID,
+			"todo_id": r.TodoID,
+			"channel": r.Channel,
+			"fire_at": r.FireAt,
+		},
+	}
+}
+
+// rescheduleReminders resolves FireAt again for the pending
+// relative reminders of the todo after its due date changed.
+func rescheduleReminders(tx *gorm.DB, todo *models.Todo) error {
+	var reminders []models.Reminder
+	err := tx.Where("todo_id = ? AND sent_at IS NULL AND offset_minutes IS NOT NULL", todo.ID).
+		Find(&reminders).Error
+	if err != nil {
+		return err
+	}
+
+	for _, r := range reminders {
+		r.Schedule(todo)
+		err := tx.Model(&models.Reminder{Model: gorm.Model{ID: r.ID}}).
+			Updates(map[string]interface{}{"fire_at": r.FireAt, "failed_at": nil, "attempts": 0}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReminderRepositoryTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	repo     ReminderRepository
+	now      time.Time
+	todo     *models.Todo
+	reminder *models.Reminder
+}
+
+func (suite *ReminderRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Reminder{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+
+	suite.db = db
+	suite.repo = NewReminderRepository(db, events.Discard)
+	suite.now = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	suite.todo = &models.Todo{UserID: 1, Title: "Ship release"}
+	suite.db.Create(suite.todo)
+
+	at := suite.now.Add(-time.Minute)
+	suite.reminder = &models.Reminder{TodoID: suite.todo.ID, Channel: models.ReminderEmail, RemindAt: &at}
+	suite.reminder.Schedule(suite.todo)
+	suite.db.Create(suite.reminder)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestClaimLeasesDueReminders() {
+	assert := assert.New(suite.T())
+
+	claimed, err := suite.repo.Claim("instance-a", suite.now, time.Minute, 10)
+
+	assert.NoError(err)
+	if assert.Len(claimed, 1) {
+		assert.Equal(suite.reminder.ID, claimed[0].ID)
+		assert.Equal("instance-a", claimed[0].LeaseOwner)
+	}
+
+	// another instance polling at the same time gets nothing
+	claimed, err = suite.repo.Claim("instance-b", suite.now, time.Minute, 10)
+	assert.NoError(err)
+	assert.Empty(claimed)
+
+	// until the lease of the first one expired
+	claimed, _ = suite.repo.Claim("instance-b", suite.now.Add(2*time.Minute), time.Minute, 10)
+	assert.Len(claimed, 1)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestClaimIgnoresFutureReminders() {
+	assert := assert.New(suite.T())
+
+	claimed, err := suite.repo.Claim("instance-a", suite.now.Add(-time.Hour), time.Minute, 10)
+
+	assert.NoError(err)
+	assert.Empty(claimed)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestMarkSent() {
+	assert := assert.New(suite.T())
+
+	claimed, _ := suite.repo.Claim("instance-a", suite.now, time.Minute, 10)
+	assert.NoError(suite.repo.MarkSent(&claimed[0], suite.now))
+
+	sent := suite.repo.ByID(suite.reminder.ID)
+	assert.NotNil(sent.SentAt)
+	assert.Nil(sent.LeaseUntil)
+	assert.Equal(1, sent.Attempts)
+
+	claimed, _ = suite.repo.Claim("instance-a", suite.now.Add(time.Hour), time.Minute, 10)
+	assert.Empty(claimed)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestReleaseNeedsTheLease() {
+	assert := assert.New(suite.T())
+
+	// the lease of the first claim expires and the same owner claims it again
+	first, _ := suite.repo.Claim("instance-a", suite.now, time.Minute, 10)
+	second, _ := suite.repo.Claim("instance-a", suite.now.Add(2*time.Minute), time.Minute, 10)
+	if !assert.Len(first, 1) || !assert.Len(second, 1) {
+		return
+	}
+
+	assert.Equal(ErrLeaseLost, suite.repo.MarkSent(&first[0], suite.now))
+	assert.Equal(ErrLeaseLost, suite.repo.Retry(&first[0], "smtp is down", suite.now))
+	assert.Equal(ErrLeaseLost, suite.repo.MarkFailed(&first[0], "gone", suite.now))
+	leased := suite.repo.ByID(suite.reminder.ID)
+	assert.Nil(leased.SentAt)
+	assert.Zero(leased.Attempts)
+
+	assert.NoError(suite.repo.MarkSent(&second[0], suite.now))
+	assert.NotNil(suite.repo.ByID(suite.reminder.ID).SentAt)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestRetry() {
+	assert := assert.New(suite.T())
+
+	claimed, _ := suite.repo.Claim("instance-a", suite.now, time.Minute, 10)
+	assert.NoError(suite.repo.Retry(&claimed[0], "smtp is down", suite.now.Add(10*time.Minute)))
+
+	retried := suite.repo.ByID(suite.reminder.ID)
+	assert.Equal("smtp is down", retried.LastError)
+	assert.Equal(1, retried.Attempts)
+
+	claimed, _ = suite.repo.Claim("instance-a", suite.now.Add(5*time.Minute), time.Minute, 10)
+	assert.Empty(claimed)
+	claimed, _ = suite.repo.Claim("instance-a", suite.now.Add(10*time.Minute), time.Minute, 10)
+	assert.Len(claimed, 1)
+}
+
+func (suite *ReminderRepositoryTestSuite) TestMarkFailed() {
+	assert := assert.New(suite.T())
+
+	claimed, _ := suite.repo.Claim("instance-a", suite.now, time.Minute, 10)
+	assert.NoError(suite.repo.MarkFailed(&claimed[0], "gone", suite.now))
+
+	claimed, _ = suite.repo.Claim("instance-a", suite.now.Add(time.Hour), time.Minute, 10)
+	assert.Empty(claimed)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestReminderRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReminderRepositoryTestSuite))
+}
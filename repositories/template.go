@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// TemplateRepository will interact to the templates, template_todos
+// and template_todo_tags tables.
+type TemplateRepository interface {
+	// Methods for querying templates
+	ByID(id uint) *models.Template
+	Available(workspaceID uint, userID uint) []models.Template
+
+	// Methods for altering templates
+	Create(template *models.Template) error
+	Update(template *models.Template) error
+	Delete(id uint) error
+}
+
+type templateRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ TemplateRepository = &templateRepoGorm{}
+
+// NewTemplateRepository creates instance of TemplateRepository
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepoGorm{db}
+}
+
+// ByID will look up a template by ID along with its todos in order
+// and their tags
+// If no record was found, the method will return nil
+func (tr *templateRepoGorm) ByID(id uint) *models.Template {
+	var t models.Template
+	err := tr.preloaded().First(&t, id).Error
+	if err == nil {
+		return &t
+	}
+
+	return nil
+}
+
+// Available will return the templates of the workspace the user can
+// use, their own and the shared ones, by name
+func (tr *templateRepoGorm) Available(workspaceID uint, userID uint) []models.Template {
+	var templates []models.Template
+	tr.preloaded().
+		Where("workspace_id = ? AND (user_id = ? OR shared = ?)", workspaceID, userID, true).
+		Order("name, id").
+		Find(&templates)
+
+	return templates
+}
+
+// Create will create the template along with its todos and their tags
+func (tr *templateRepoGorm) Create(template *models.Template) error {
+	numberTodos(template)
+	return tr.db.Create(template).Error
+}
+
+// Update will update the template and replace its todos and their tags
+func (tr *templateRepoGorm) Update(template *models.Template) error {
+	numberTodos(template)
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(template).Omit("Todos").Updates(map[string]interface{}{
+			"name":      template.Name,
+			"list_name": template.ListName,
+			"shared":    template.Shared,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := deleteTemplateTodos(tx, template.ID); err != nil {
+			return err
+		}
+		for i := range template.Todos {
+			template.Todos[i].ID = 0
+			template.Todos[i].TemplateID = template.ID
+		}
+		if len(template.Todos) == 0 {
+			return nil
+		}
+		return tx.Create(&template.Todos).Error
+	})
+}
+
+// Delete will delete the template along with its todos and their tags
+func (tr *templateRepoGorm) Delete(id uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteTemplateTodos(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Template{}, id).Error
+	})
+}
+
+// preloaded queries the templates along with their todos in order
+// and their tags
+func (tr *templateRepoGorm) preloaded() *gorm.DB {
+	return tr.db.Preload("Todos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Preload("Todos.Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+// deleteTemplateTodos deletes the todos of the template and their tags
+func deleteTemplateTodos(tx *gorm.DB, templateID uint) error {
+	todos := tx.Model(&models.TemplateTodo{}).Select("id").Where("template_id = ?", templateID)
+	if err := tx.Where("template_todo_id IN (?)", todos).Delete(&models.TemplateTodoTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("template_id = ?", templateID).Delete(&models.TemplateTodo{}).Error
+}
+
+// numberTodos sets the positions of the template's todos in the
+// order they are given
+func numberTodos(template *models.Template) {
+	for i := range template.Todos {
+		template.Todos[i].Position = i
+	}
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TemplateRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo TemplateRepository
+}
+
+func (suite *TemplateRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.TemplateTodoTag{})
+	db.Unscoped().Where("1 = 1").Delete(&models.TemplateTodo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Template{})
+
+	suite.db = db
+	suite.repo = NewTemplateRepository(db)
+}
+
+// titles returns the titles of the template's todos in order
+func (suite *TemplateRepositoryTestSuite) titles(id uint) []string {
+	var titles []string
+	for _, t := range suite.repo.ByID(id).Todos {
+		titles = append(titles, t.Title)
+	}
+	return titles
+}
+
+func (suite *TemplateRepositoryTestSuite) TestCreateAndUpdateKeepTheOrder() {
+	assert := assert.New(suite.T())
+
+	template := &models.Template{WorkspaceID: 1, UserID: 1, Name: "Onboarding", ListName: "Onboarding {{name}}", Todos: []models.TemplateTodo{
+		{Title: "Laptop"}, {Title: "Accounts"}, {Title: "Intro call"},
+	}}
+	assert.NoError(suite.repo.Create(template))
+	assert.Equal([]string{"Laptop", "Accounts", "Intro call"}, suite.titles(template.ID))
+
+	template.Name = "New hire"
+	template.Todos = []models.TemplateTodo{{Title: "Intro call"}, {Title: "Laptop"}}
+	assert.NoError(suite.repo.Update(template))
+	assert.Equal("New hire", suite.repo.ByID(template.ID).Name)
+	assert.Equal([]string{"Intro call", "Laptop"}, suite.titles(template.ID))
+
+	assert.NoError(suite.repo.Delete(template.ID))
+	assert.Nil(suite.repo.ByID(template.ID))
+	var count int64
+	suite.db.Model(&models.TemplateTodo{}).Count(&count)
+	assert.Zero(count)
+}
+
+func (suite *TemplateRepositoryTestSuite) TestTagsAreReplacedWithTheTodos() {
+	assert := assert.New(suite.T())
+
+	template := &models.Template{WorkspaceID: 1, UserID: 1, Name: "Onboarding", ListName: "Onboarding", Todos: []models.TemplateTodo{
+		{Title: "Laptop", Tags: []models.TemplateTodoTag{{Name: "it"}, {Name: "day one"}}}, {Title: "Intro call"},
+	}}
+	assert.NoError(suite.repo.Create(template))
+	todos := suite.repo.ByID(template.ID).Todos
+	assert.Equal([]string{"it", "day one"}, todos[0].TagNames())
+	assert.Empty(todos[1].Tags)
+
+	template.Todos = []models.TemplateTodo{{Title: "Intro call", Tags: []models.TemplateTodoTag{{Name: "people"}}}}
+	assert.NoError(suite.repo.Update(template))
+	assert.Equal([]string{"people"}, suite.repo.ByID(template.ID).Todos[0].TagNames())
+
+	var count int64
+	suite.db.Model(&models.TemplateTodoTag{}).Count(&count)
+	assert.Equal(int64(1), count)
+	assert.NoError(suite.repo.Delete(template.ID))
+	suite.db.Model(&models.TemplateTodoTag{}).Count(&count)
+	assert.Zero(count)
+}
+
+func (suite *TemplateRepositoryTestSuite) TestAvailable() {
+	assert := assert.New(suite.T())
+
+	suite.repo.Create(&models.Template{WorkspaceID: 1, UserID: 1, Name: "Mine", ListName: "Mine"})
+	suite.repo.Create(&models.Template{WorkspaceID: 1, UserID: 2, Name: "Shared", ListName: "Shared", Shared: true})
+	suite.repo.Create(&models.Template{WorkspaceID: 1, UserID: 2, Name: "Private", ListName: "Private"})
+	suite.repo.Create(&models.Template{WorkspaceID: 2, UserID: 1, Name: "Elsewhere", ListName: "Elsewhere", Shared: true})
+
+	var names []string
+	for _, t := range suite.repo.Available(1, 1) {
+		names = append(names, t.Name)
+	}
+	assert.Equal([]string{"Mine", "Shared"}, names)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTemplateRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TemplateRepositoryTestSuite))
+}
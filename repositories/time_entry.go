@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// TimeEntryRepository will interact to the time_entries table
+type TimeEntryRepository interface {
+	// Methods for querying time entries
+	ByTodo(todoID uint) []models.TimeEntry
+	Running(userID uint) *models.TimeEntry
+	Between(userID uint, from, to time.Time) []models.TimeEntry
+
+	// Methods for altering time entries
+	Start(entry *models.TimeEntry) (stopped *models.TimeEntry, err error)
+	Stop(entry *models.TimeEntry, at time.Time) error
+}
+
+type timeEntryRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ TimeEntryRepository = &timeEntryRepoGorm{}
+
+// NewTimeEntryRepository creates instance of TimeEntryRepository
+func NewTimeEntryRepository(db *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepoGorm{db}
+}
+
+// ByTodo will return the time entries of the todo, the latest first
+func (tr *timeEntryRepoGorm) ByTodo(todoID uint) []models.TimeEntry {
+	var entries []models.TimeEntry
+	tr.db.Where("todo_id = ?", todoID).
+		Order("started_at DESC, id DESC").
+		Find(&entries)
+
+	return entries
+}
+
+// Running will look up the running timer of the user
+// If the user has none, the method will return nil
+func (tr *timeEntryRepoGorm) Running(userID uint) *models.TimeEntry {
+	var e models.TimeEntry
+	err := tr.db.Where("user_id = ? AND stopped_at IS NULL", userID).First(&e).Error
+	if err == nil {
+		return &e
+	}
+
+	return nil
+}
+
+// Between will return the time entries of the user which overlap the
+// time range, along with their todos even when they are in the trash
+// and the tags of the todos
+func (tr *timeEntryRepoGorm) Between(userID uint, from, to time.Time) []models.TimeEntry {
+	var entries []models.TimeEntry
+	tr.db.Preload("Todo", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Todo.Tags").
+		Where("user_id = ? AND started_at < ?", userID, to).
+		Where("stopped_at IS NULL OR stopped_at > ?", from).
+		Order("started_at, id").
+		Find(&entries)
+
+	return entries
+}
+
+// Start will start the timer of the entry, the running timer of the
+// user is stopped when it starts and returned
+func (tr *timeEntryRepoGorm) Start(entry *models.TimeEntry) (*models.TimeEntry, error) {
+	var stopped *models.TimeEntry
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		var running models.TimeEntry
+		err := tx.Where("user_id = ? AND stopped_at IS NULL", entry.UserID).First(&running).Error
+		if err == nil {
+			at := entry.StartedAt
+			if err := tx.Model(&running).Update("stopped_at", at).Error; err != nil {
+				return err
+			}
+			running.StoppedAt = &at
+			stopped = &running
+		}
+
+		entry.StoppedAt = nil
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stopped, nil
+}
+
+// Stop will stop the timer of the entry at the time
+func (tr *timeEntryRepoGorm) Stop(entry *models.TimeEntry, at time.Time) error {
+	if err := tr.db.Model(entry).Update("stopped_at", at).Error; err != nil {
+		return err
+	}
+	entry.StoppedAt = &at
+	return nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TimeEntryRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  TimeEntryRepository
+	todos TodoRepository
+	todo  *models.Todo
+	other *models.Todo
+	at    time.Time
+}
+
+func (suite *TimeEntryRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.TimeEntry{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+
+	suite.db = db
+	suite.repo = NewTimeEntryRepository(db)
+	suite.todos = NewTodoRepository(db, events.Discard)
+	suite.todo = &models.Todo{UserID: 1, Title: "Ship release"}
+	suite.other = &models.Todo{UserID: 1, Title: "Pay rent"}
+	suite.todos.Create(suite.todo)
+	suite.todos.Create(suite.other)
+	suite.at = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+}
+
+func (suite *TimeEntryRepositoryTestSuite) TestStartStopsTheRunningTimer() {
+	assert := assert.New(suite.T())
+
+	first := &models.TimeEntry{UserID: 1, TodoID: suite.todo.ID, StartedAt: suite.at}
+	stopped, err := suite.repo.Start(first)
+	assert.NoError(err)
+	assert.Nil(stopped)
+
+	// another user's timer keeps running
+	theirs := &models.TimeEntry{UserID: 2, TodoID: suite.todo.ID, StartedAt: suite.at}
+	suite.repo.Start(theirs)
+
+	second := &models.TimeEntry{UserID: 1, TodoID: suite.other.ID, StartedAt: suite.at.Add(time.Hour)}
+	stopped, err = suite.repo.Start(second)
+	assert.NoError(err)
+	if assert.NotNil(stopped) {
+		assert.Equal(first.ID, stopped.ID)
+		assert.True(suite.at.Add(time.Hour).Equal(*stopped.StoppedAt))
+	}
+
+	assert.Equal(second.ID, suite.repo.Running(1).ID)
+	assert.Equal(theirs.ID, suite.repo.Running(2).ID)
+
+	assert.NoError(suite.repo.Stop(second, suite.at.Add(2*time.Hour)))
+	assert.Nil(suite.repo.Running(1))
+	assert.Len(suite.repo.ByTodo(suite.todo.ID), 2)
+}
+
+func (suite *TimeEntryRepositoryTestSuite) TestBetween() {
+	assert := assert.New(suite.T())
+
+	stop := suite.at.Add(time.Hour)
+	before := suite.at.Add(-2 * time.Hour)
+	entries := []*models.TimeEntry{
+		{UserID: 1, TodoID: suite.todo.ID, StartedAt: before, StoppedAt: &suite.at},
+		{UserID: 1, TodoID: suite.other.ID, StartedAt: suite.at.Add(-time.Minute), StoppedAt: &stop},
+		{UserID: 1, TodoID: suite.todo.ID, StartedAt: stop},
+		{UserID: 2, TodoID: suite.todo.ID, StartedAt: suite.at},
+	}
+	for _, e := range entries {
+		suite.db.Create(e)
+	}
+	suite.todos.Delete(suite.other.ID)
+
+	found := suite.repo.Between(1, suite.at, suite.at.Add(24*time.Hour))
+	if assert.Len(found, 2) {
+		assert.Equal(entries[1].ID, found[0].ID)
+		assert.Equal("Pay rent", found[0].Todo.Title)
+		assert.Equal(entries[2].ID, found[1].ID)
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTimeEntryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeEntryRepositoryTestSuite))
+}
@@ -0,0 +1,784 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDependencyCycle is returned when a todo would end up blocked,
+	// directly or through other todos, by itself
+	ErrDependencyCycle = errors.New("The todo would end up blocking itself")
+
+	// ErrWIPLimit is returned when moving a todo to a column which
+	// holds as many todos as its WIP limit allows
+	ErrWIPLimit = errors.New("The column has reached its WIP limit")
+)
+
+// TodoRepository will interact to the todos table.
+type TodoRepository interface {
+	// Methods for querying todos
+	ByID(id uint) *models.Todo
+	ByUser(userID uint) []models.Todo
+	ByList(listID uint) []models.Todo
+	AssignedTo(userID uint) []models.Todo
+	Ready(userID uint) []models.Todo
+	OpenBlockers(todoID uint) []models.Todo
+
+	// Methods for altering todos
+	Create(todo *models.Todo) error
+	CreateClaimed(todo *models.Todo, record *models.ClientRecord) error
+	Update(todo *models.Todo) error
+	Delete(id uint) error
+	Assign(todo *models.Todo, userIDs []uint) (added []uint, removed []uint, err error)
+	Block(todo *models.Todo, blockerID uint) error
+	Unblock(todo *models.Todo, blockerID uint) error
+	Tag(todo *models.Todo, names []string) error
+	Move(todo *models.Todo, column *models.Column, position int) error
+
+	// Methods for the todos in the trash
+	Trashed(userID uint) []models.Todo
+	TrashedByID(id uint) *models.Todo
+	Restore(todo *models.Todo) error
+	ForceDelete(id uint) error
+	Purge(before time.Time) (int64, error)
+
+	// Workspace scopes the repository to the todos of the workspace
+	Workspace(id uint) TodoRepository
+
+	// Transaction runs fn with a repository writing in a transaction
+	Transaction(fn func(TodoRepository) error) error
+}
+
+type todoRepoGorm struct {
+	db        *gorm.DB
+	pub       events.Publisher
+	workspace *uint
+}
+
+var _ TodoRepository = &todoRepoGorm{}
+
+// NewTodoRepository creates instance of TodoRepository
+// which publishes its writes to pub. It sees the todos of
+// every workspace until it is scoped to one.
+func NewTodoRepository(db *gorm.DB, pub events.Publisher) TodoRepository {
+	return &todoRepoGorm{db: db, pub: pub}
+}
+
+// Workspace returns a copy of the repository which only sees the
+// todos of the workspace, and creates the new ones in there
+func (tr *todoRepoGorm) Workspace(id uint) TodoRepository {
+	return &todoRepoGorm{db: tr.db, pub: tr.pub, workspace: &id}
+}
+
+// Transaction runs fn with a copy of the repository whose writes are
+// made in a transaction, which is rolled back when fn returns an
+// error. The events of the writes are only published once they are
+// committed. Transactions within one run in a savepoint.
+func (tr *todoRepoGorm) Transaction(fn func(TodoRepository) error) error {
+	pending := &pendingEvents{}
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&todoRepoGorm{db: tx, pub: pending, workspace: tr.workspace})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range pending.events {
+		publish(tr.pub, e)
+	}
+	return nil
+}
+
+// ByID will look up a todo by ID along with its reminders, assignees,
+// blockers and tags
+// If no record was found, the method will return nil
+func (tr *todoRepoGorm) ByID(id uint) *models.Todo {
+	var t models.Todo
+	err := tr.preloaded().First(&t, id).Error
+	if err == nil {
+		return &t
+	}
+
+	return nil
+}
+
+// ByUser will return all the todos owned by the user
+func (tr *todoRepoGorm) ByUser(userID uint) []models.Todo {
+	var todos []models.Todo
+	tr.preloaded().
+		Where(&models.Todo{UserID: userID}).
+		Order("id").
+		Find(&todos)
+
+	return todos
+}
+
+// ByList will return all the todos of the list
+func (tr *todoRepoGorm) ByList(listID uint) []models.Todo {
+	var todos []models.Todo
+	tr.preloaded().
+		Where("list_id = ?", listID).
+		Order("id").
+		Find(&todos)
+
+	return todos
+}
+
+// AssignedTo will return the todos assigned to the user, whoever
+// owns them, the ones due first at the top
+func (tr *todoRepoGorm) AssignedTo(userID uint) []models.Todo {
+	var todos []models.Todo
+	tr.preloaded().
+		Where("id IN (?)", tr.db.Model(&models.TodoAssignee{}).Select("todo_id").Where("user_id = ?", userID)).
+		Order("due_at IS NULL, due_at, id").
+		Find(&todos)
+
+	return todos
+}
+
+// Ready will return the open todos of the user which no open todo
+// blocks, the most urgent first
+func (tr *todoRepoGorm) Ready(userID uint) []models.Todo {
+	var todos []models.Todo
+	tr.preloaded().
+		Where("user_id = ? AND completed = ?", userID, false).
+		Where("id NOT IN (?)", tr.db.Model(&models.TodoDependency{}).
+			Select("todo_dependencies.todo_id").
+			Joins("JOIN todos blockers ON blockers.id = todo_dependencies.blocker_id").
+			Where("blockers.completed = ? AND blockers.deleted_at IS NULL", false)).
+		Order("priority DESC, due_at IS NULL, due_at, id").
+		Find(&todos)
+
+	return todos
+}
+
+// OpenBlockers will return the todos blocking the todo which
+// aren't completed yet
+func (tr *todoRepoGorm) OpenBlockers(todoID uint) []models.Todo {
+	var todos []models.Todo
+	tr.query().Where("completed = ?", false).
+		Where("id IN (?)", tr.db.Model(&models.TodoDependency{}).Select("blocker_id").Where("todo_id = ?", todoID)).
+		Order("id").
+		Find(&todos)
+
+	return todos
+}
+
+// Create will create a new todo together with any
+// reminders and tags attached to it.
+func (tr *todoRepoGorm) Create(todo *models.Todo) error {
+	if tr.workspace != nil {
+		todo.WorkspaceID = *tr.workspace
+	}
+	if err := tr.db.Create(todo).Error; err != nil {
+		return err
+	}
+
+	publish(tr.pub, todoEvent(tr.db, events.TodoCreated, todo))
+	return nil
+}
+
+// CreateClaimed will create the todo like Create, and give it the ID
+// the client gave it in the same transaction. Nothing is created when
+// the client ID was given to another record.
+func (tr *todoRepoGorm) CreateClaimed(todo *models.Todo, record *models.ClientRecord) error {
+	if tr.workspace != nil {
+		todo.WorkspaceID = *tr.workspace
+	}
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(todo).Error; err != nil {
+			return err
+		}
+		return claimClientID(tx, record, todo.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(tr.pub, todoEvent(tr.db, events.TodoCreated, todo))
+	return nil
+}
+
+// Update will update the todo's fields, including the ones
+// being cleared, and re-schedule its relative reminders so
+// they follow the new due date. It fails with
+// ErrVersionConflict when the todo was changed since it was
+// read.
+func (tr *todoRepoGorm) Update(todo *models.Todo) error {
+	var before models.Todo
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&before, todo.ID).Error; err != nil {
+			return err
+		}
+		return updateTodo(tx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(tr.pub, withPrevious(todoEvent(tr.db, events.TodoUpdated, todo), todoEvent(tr.db, events.TodoUpdated, &before)))
+	return nil
+}
+
+// Delete will move the todo and its reminders to the trash by ID
+func (tr *todoRepoGorm) Delete(id uint) error {
+	todo := tr.ByID(id)
+	if todo == nil {
+		return nil
+	}
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		return trashTodos(tx, []uint{id}, trashedAt())
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(tr.pub, todoEvent(tr.db, events.TodoDeleted, todo))
+	return nil
+}
+
+// Assign will replace the users the todo is assigned to, and returns
+// the IDs of the users it added and removed
+func (tr *todoRepoGorm) Assign(todo *models.Todo, userIDs []uint) ([]uint, []uint, error) {
+	keep := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		keep[id] = true
+	}
+
+	var current, added, removed []uint
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TodoAssignee{}).Where("todo_id = ?", todo.ID).Order("id").Pluck("user_id", &current).Error; err != nil {
+			return err
+		}
+
+		assigned := make(map[uint]bool, len(current))
+		for _, id := range current {
+			assigned[id] = true
+			if !keep[id] {
+				removed = append(removed, id)
+			}
+		}
+		for _, id := range userIDs {
+			if !assigned[id] {
+				assigned[id] = true
+				added = append(added, id)
+			}
+		}
+
+		if len(removed) > 0 {
+			err := tx.Unscoped().Where("todo_id = ? AND user_id IN ?", todo.ID, removed).Delete(&models.TodoAssignee{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, id := range added {
+			if err := tx.Create(&models.TodoAssignee{TodoID: todo.ID, UserID: id}).Error; err != nil {
+				return err
+			}
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			return nil
+		}
+		return bumpVersion(tx, todo)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todo.Assignees = nil
+	tr.db.Preload("User").Where("todo_id = ?", todo.ID).Order("id").Find(&todo.Assignees)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil, nil
+	}
+
+	// the users who were unassigned hear about it too
+	e := todoEvent(tr.db, events.TodoUpdated, todo)
+	for _, id := range removed {
+		e.UserIDs = appendUserID(e.UserIDs, id)
+	}
+	e.Data.(map[string]interface{})["assignees"] = todo.AssigneeIDs()
+	e.Previous = map[string]interface{}{"assignees": current}
+	publish(tr.pub, e)
+	return added, removed, nil
+}
+
+// Block will make the todo blocked by another one, unless the other
+// todo is already blocked by it directly or through other todos
+func (tr *todoRepoGorm) Block(todo *models.Todo, blockerID uint) error {
+	before := todo.BlockerIDs()
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		// walk the todos blocking the blocker, reaching the todo is a cycle
+		seen := map[uint]bool{blockerID: true}
+		for next := []uint{blockerID}; len(next) > 0; {
+			if seen[todo.ID] {
+				return ErrDependencyCycle
+			}
+			var blockers []uint
+			if err := tx.Model(&models.TodoDependency{}).Where("todo_id IN ?", next).Pluck("blocker_id", &blockers).Error; err != nil {
+				return err
+			}
+			next = next[:0]
+			for _, id := range blockers {
+				if !seen[id] {
+					seen[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		if seen[todo.ID] {
+			return ErrDependencyCycle
+		}
+
+		var count int64
+		tx.Model(&models.TodoDependency{}).Where("todo_id = ? AND blocker_id = ?", todo.ID, blockerID).Count(&count)
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(&models.TodoDependency{TodoID: todo.ID, BlockerID: blockerID}).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	tr.reloadBlockers(todo, before)
+	return nil
+}
+
+// Unblock will remove the dependency of the todo on another one
+func (tr *todoRepoGorm) Unblock(todo *models.Todo, blockerID uint) error {
+	before := todo.BlockerIDs()
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().
+			Where("todo_id = ? AND blocker_id = ?", todo.ID, blockerID).
+			Delete(&models.TodoDependency{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return bumpVersion(tx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	tr.reloadBlockers(todo, before)
+	return nil
+}
+
+// Tag will replace the tags of the todo with the names
+func (tr *todoRepoGorm) Tag(todo *models.Todo, names []string) error {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+
+	var current []string
+	changed := false
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TodoTag{}).Where("todo_id = ?", todo.ID).Order("name").Pluck("name", &current).Error; err != nil {
+			return err
+		}
+
+		tagged := make(map[string]bool, len(current))
+		var removed []string
+		for _, name := range current {
+			tagged[name] = true
+			if !keep[name] {
+				removed = append(removed, name)
+			}
+		}
+		if len(removed) > 0 {
+			err := tx.Unscoped().Where("todo_id = ? AND name IN ?", todo.ID, removed).Delete(&models.TodoTag{}).Error
+			if err != nil {
+				return err
+			}
+			changed = true
+		}
+		for _, name := range names {
+			if tagged[name] {
+				continue
+			}
+			tagged[name] = true
+			if err := tx.Create(&models.TodoTag{TodoID: todo.ID, Name: name}).Error; err != nil {
+				return err
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return bumpVersion(tx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	todo.Tags = nil
+	tr.db.Where("todo_id = ?", todo.ID).Order("name").Find(&todo.Tags)
+	if !changed {
+		return nil
+	}
+
+	e := todoEvent(tr.db, events.TodoUpdated, todo)
+	e.Data.(map[string]interface{})["tags"] = todo.TagNames()
+	e.Previous = map[string]interface{}{"tags": current}
+	publish(tr.pub, e)
+	return nil
+}
+
+// Move will put the todo at the position of the column, the todos
+// from there on shift down. Out of range positions put the todo last.
+// Moving it to the Done column completes it and moving it out of
+// there reopens it.
+func (tr *todoRepoGorm) Move(todo *models.Todo, column *models.Column, position int) error {
+	// the todo gets its own copy, gorm writes the updates through it
+	columnID := column.ID
+	var before models.Todo
+	var completed bool
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&before, todo.ID).Error; err != nil {
+			return err
+		}
+		if err := leaveColumn(tx, &before); err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&models.Todo{}).
+			Where("column_id = ? AND id <> ? AND deleted_at IS NULL", column.ID, todo.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		moving := before.ColumnID == nil || *before.ColumnID != column.ID
+		if moving && column.IsFull(count) {
+			return ErrWIPLimit
+		}
+		if position < 0 || position > int(count) {
+			position = int(count)
+		}
+
+		err = tx.Model(&models.Todo{}).
+			Where("column_id = ? AND id <> ? AND position >= ? AND deleted_at IS NULL", column.ID, todo.ID, position).
+			Updates(map[string]interface{}{"position": gorm.Expr("position + 1"), "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+
+		completed = before.Completed
+		if column.Done {
+			completed = true
+		} else if moving && before.ColumnID != nil {
+			var from models.Column
+			if tx.First(&from, *before.ColumnID).Error == nil && from.Done {
+				completed = false
+			}
+		}
+		return tx.Model(todo).Updates(map[string]interface{}{
+			"column_id": columnID,
+			"position":  position,
+			"completed": completed,
+			"version":   before.Version + 1,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	todo.ColumnID = &columnID
+	todo.Position = position
+	todo.Completed = completed
+	todo.Version = before.Version + 1
+	before.Assignees = todo.Assignees
+	publish(tr.pub, withPrevious(todoEvent(tr.db, events.TodoUpdated, todo), todoEvent(tr.db, events.TodoUpdated, &before)))
+	return nil
+}
+
+// Trashed will return the todos of the user in the trash, the most
+// recently deleted first
+func (tr *todoRepoGorm) Trashed(userID uint) []models.Todo {
+	var todos []models.Todo
+	tr.query().Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id").
+		Find(&todos)
+
+	return todos
+}
+
+// TrashedByID will look up a todo in the trash by ID
+// If no record was found, the method will return nil
+func (tr *todoRepoGorm) TrashedByID(id uint) *models.Todo {
+	var t models.Todo
+	err := tr.query().Unscoped().Where("deleted_at IS NOT NULL").First(&t, id).Error
+	if err == nil {
+		return &t
+	}
+
+	return nil
+}
+
+// Restore will take the todo out of the trash along with the reminders
+// deleted with it. A todo whose list is still in the trash is moved
+// out of the list, so it doesn't stay hidden.
+func (tr *todoRepoGorm) Restore(todo *models.Todo) error {
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if todo.ListID != nil {
+			var lists int64
+			tx.Model(&models.List{}).Where("id = ?", *todo.ListID).Count(&lists)
+			if lists == 0 {
+				todo.ListID = nil
+			}
+		}
+
+		return restoreTodo(tx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	publish(tr.pub, todoEvent(tr.db, events.TodoRestored, todo))
+	return nil
+}
+
+// ForceDelete will permanently delete the todo and its reminders by ID
+func (tr *todoRepoGorm) ForceDelete(id uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		return destroyTodos(tx, []uint{id})
+	})
+}
+
+// Purge will permanently delete the todos which were moved to the
+// trash before the given time, and returns how many were deleted.
+func (tr *todoRepoGorm) Purge(before time.Time) (int64, error) {
+	var ids []uint
+	err := tr.query().Unscoped().Model(&models.Todo{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = tr.db.Transaction(func(tx *gorm.DB) error {
+		return destroyTodos(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// query starts a query for the todos of the repository's workspace
+func (tr *todoRepoGorm) query() *gorm.DB {
+	if tr.workspace == nil {
+		return tr.db
+	}
+	return tr.db.Where("todos.workspace_id = ?", *tr.workspace)
+}
+
+// preloaded starts a query for todos loading what they come with
+func (tr *todoRepoGorm) preloaded() *gorm.DB {
+	return tr.query().Preload("Reminders").Preload("Assignees.User").Preload("Blockers").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") })
+}
+
+// reloadBlockers loads the blockers of the todo again after changing
+// them, and publishes the change when there is one
+func (tr *todoRepoGorm) reloadBlockers(todo *models.Todo, before []uint) {
+	todo.Blockers = nil
+	tr.db.Where("todo_id = ?", todo.ID).Order("id").Find(&todo.Blockers)
+
+	after := todo.BlockerIDs()
+	if len(after) == len(before) {
+		return
+	}
+	e := todoEvent(tr.db, events.TodoUpdated, todo)
+	e.Data.(map[string]interface{})["blocked_by"] = after
+	e.Previous = map[string]interface{}{"blocked_by": before}
+	publish(tr.pub, e)
+}
+
+// trashedAt is the time items are moved to the trash. Items deleted
+// together share it, which is how restoring finds them again, so it
+// is truncated to what every database can store.
+func trashedAt() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// updateTodo writes the todo's fields, including the ones being
+// cleared, re-schedules its relative reminders and unassigns the
+// users who aren't members of the list it is in anymore
+func updateTodo(tx *gorm.DB, todo *models.Todo) error {
+	if err := syncColumn(tx, todo); err != nil {
+		return err
+	}
+
+	err := updateVersioned(tx, todo, &todo.Version, map[string]interface{}{
+		"list_id":     todo.ListID,
+		"title":       todo.Title,
+		"description": todo.Description,
+		"completed":   todo.Completed,
+		"due_at":      todo.DueAt,
+		"timezone":    todo.Timezone,
+		"recurrence":  todo.Recurrence,
+		"priority":    todo.Priority,
+		"estimate":    todo.Estimate,
+		"column_id":   todo.ColumnID,
+		"position":    todo.Position,
+	})
+	if err != nil {
+		return err
+	}
+	if err := unassignNonMembers(tx, todo); err != nil {
+		return err
+	}
+
+	return rescheduleReminders(tx, todo)
+}
+
+// syncColumn keeps the todo's place on the board in line with it. A
+// todo moved to another list is taken off the board, a completed one
+// goes last in the Done column and a reopened one leaves it for the
+// first column, when the list has such columns.
+func syncColumn(tx *gorm.DB, todo *models.Todo) error {
+	if todo.ColumnID == nil {
+		return nil
+	}
+
+	var column models.Column
+	err := tx.First(&column, *todo.ColumnID).Error
+	if err != nil || todo.ListID == nil || column.ListID != *todo.ListID {
+		if err := leaveColumn(tx, todo); err != nil {
+			return err
+		}
+		todo.ColumnID = nil
+		todo.Position = 0
+		return nil
+	}
+	if todo.Completed == column.Done {
+		return nil
+	}
+
+	var target models.Column
+	err = tx.Where("list_id = ? AND done = ?", column.ListID, todo.Completed).
+		Order("position, id").
+		First(&target).Error
+	if err != nil {
+		return nil
+	}
+	if err := leaveColumn(tx, todo); err != nil {
+		return err
+	}
+
+	var count int64
+	err = tx.Model(&models.Todo{}).
+		Where("column_id = ? AND id <> ? AND deleted_at IS NULL", target.ID, todo.ID).
+		Count(&count).Error
+	todo.ColumnID = &target.ID
+	todo.Position = int(count)
+	return err
+}
+
+// bumpVersion moves the todo to its next version, for the writes which
+// change what it is shown with but not its own fields, e.g. its
+// assignees. The todo gets the version it is at now.
+func bumpVersion(tx *gorm.DB, todo *models.Todo) error {
+	err := tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Update("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Todo{}).Select("version").Where("id = ?", todo.ID).Scan(&todo.Version).Error
+}
+
+// leaveColumn closes the gap the todo leaves in its column's positions
+func leaveColumn(tx *gorm.DB, todo *models.Todo) error {
+	if todo.ColumnID == nil {
+		return nil
+	}
+	return tx.Model(&models.Todo{}).
+		Where("column_id = ? AND id <> ? AND position > ? AND deleted_at IS NULL", *todo.ColumnID, todo.ID, todo.Position).
+		Updates(map[string]interface{}{"position": gorm.Expr("position - 1"), "version": gorm.Expr("version + 1")}).Error
+}
+
+// unassignNonMembers unassigns the todo from the users who are not
+// members of its list, only its owner can have a todo without a list
+func unassignNonMembers(tx *gorm.DB, todo *models.Todo) error {
+	query := tx.Unscoped().Where("todo_id = ? AND user_id <> ?", todo.ID, todo.UserID)
+	if todo.ListID != nil {
+		members := tx.Model(&models.ListMember{}).Select("user_id").Where("list_id = ?", *todo.ListID)
+		query = query.Where("user_id NOT IN (?)", members)
+	}
+	return query.Delete(&models.TodoAssignee{}).Error
+}
+
+// restoreTodo takes the todo out of the trash along with the reminders
+// deleted with it
+func restoreTodo(tx *gorm.DB, todo *models.Todo) error {
+	err := tx.Unscoped().Model(&models.Reminder{}).
+		Where("todo_id = ? AND deleted_at = ?", todo.ID, todo.DeletedAt.Time).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Unscoped().Model(todo).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"list_id":    todo.ListID,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	todo.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// trashTodos soft deletes the todos and their reminders at the time
+func trashTodos(tx *gorm.DB, ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := tx.Model(&models.Reminder{}).
+		Where("todo_id IN ? AND deleted_at IS NULL", ids).
+		Update("deleted_at", at).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Todo{}).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Update("deleted_at", at).Error
+}
+
+// destroyTodos permanently deletes the todos, their reminders,
+// assignees, dependencies, tags, time entries and comments
+func destroyTodos(tx *gorm.DB, ids []uint) error {
+	if err := tx.Unscoped().Where("todo_id IN ?", ids).Delete(&models.Reminder{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("todo_id IN ?", ids).Delete(&models.TodoAssignee{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("todo_id IN ? OR blocker_id IN ?", ids, ids).Delete(&models.TodoDependency{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("todo_id IN ?", ids).Delete(&models.TodoTag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("todo_id IN ?", ids).Delete(&models.TimeEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("todo_id IN ?", ids).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Todo{}).Error
+}
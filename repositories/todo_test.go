@@ -0,0 +1,537 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TodoRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo TodoRepository
+	todo *models.Todo
+	due  time.Time
+}
+
+func (suite *TodoRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.Reminder{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Todo{})
+	db.Unscoped().Where("1 = 1").Delete(&models.List{})
+	db.Unscoped().Where("1 = 1").Delete(&models.ListMember{})
+	db.Unscoped().Where("1 = 1").Delete(&models.TodoAssignee{})
+	db.Unscoped().Where("1 = 1").Delete(&models.TodoDependency{})
+	db.Unscoped().Where("1 = 1").Delete(&models.TodoTag{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Column{})
+
+	suite.db = db
+	suite.repo = NewTodoRepository(db, events.Discard)
+	suite.due = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	before := 30
+	suite.todo = &models.Todo{
+		UserID:    1,
+		Title:     "Ship release",
+		DueAt:     &suite.due,
+		Timezone:  "Asia/Manila",
+		Reminders: []models.Reminder{{Channel: models.ReminderEmail, OffsetMinutes: &before}},
+	}
+	suite.todo.Reminders[0].Schedule(suite.todo)
+	suite.repo.Create(suite.todo)
+}
+
+func (suite *TodoRepositoryTestSuite) TestByID() {
+	assert := assert.New(suite.T())
+
+	todo := suite.repo.ByID(suite.todo.ID)
+
+	assert.NotNil(todo)
+	assert.Equal("Ship release", todo.Title)
+	assert.Len(todo.Reminders, 1)
+	assert.Nil(suite.repo.ByID(suite.todo.ID + 1))
+}
+
+func (suite *TodoRepositoryTestSuite) TestByUser() {
+	assert := assert.New(suite.T())
+
+	suite.repo.Create(&models.Todo{UserID: 2, Title: "Someone else's"})
+
+	todos := suite.repo.ByUser(1)
+
+	assert.Len(todos, 1)
+	assert.Equal(suite.todo.ID, todos[0].ID)
+}
+
+func (suite *TodoRepositoryTestSuite) TestCreateClaimed() {
+	assert := assert.New(suite.T())
+	suite.db.Where("1 = 1").Delete(&models.ClientRecord{})
+
+	todo := &models.Todo{UserID: 1, Title: "Buy milk"}
+	assert.NoError(suite.repo.CreateClaimed(todo, &models.ClientRecord{UserID: 1, Resource: models.ChangeTodo, ClientID: "t-1"}))
+	assert.NotZero(todo.ID)
+	if r := NewChangeRepository(suite.db).ClientRecord(1, models.ChangeTodo, "t-1"); assert.NotNil(r) {
+		assert.Equal(todo.ID, r.ResourceID)
+	}
+
+	// a retry of the same client ID creates nothing
+	var count int64
+	retry := &models.Todo{UserID: 1, Title: "Buy milk"}
+	err := suite.repo.CreateClaimed(retry, &models.ClientRecord{UserID: 1, Resource: models.ChangeTodo, ClientID: "t-1"})
+	assert.Equal(ErrClientIDClaimed, err)
+	suite.db.Model(&models.Todo{}).Where("title = ?", "Buy milk").Count(&count)
+	assert.Equal(int64(1), count)
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdateReschedulesRelativeReminders() {
+	assert := assert.New(suite.T())
+
+	due := suite.due.Add(24 * time.Hour)
+	suite.todo.DueAt = &due
+	suite.todo.Completed = true
+	assert.NoError(suite.repo.Update(suite.todo))
+
+	updated := suite.repo.ByID(suite.todo.ID)
+	assert.True(updated.Completed)
+	assert.True(due.Equal(*updated.DueAt))
+	assert.True(due.Add(-30 * time.Minute).Equal(*updated.Reminders[0].FireAt))
+
+	// clearing the due date holds the reminder back
+	suite.todo.DueAt = nil
+	suite.todo.Completed = false
+	assert.NoError(suite.repo.Update(suite.todo))
+
+	updated = suite.repo.ByID(suite.todo.ID)
+	assert.False(updated.Completed)
+	assert.Nil(updated.DueAt)
+	assert.Nil(updated.Reminders[0].FireAt)
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdatePublishesWhatChanged() {
+	assert := assert.New(suite.T())
+
+	bus := events.NewMemoryBus(10, 10)
+	sub := bus.Subscribe(1, 0)
+	defer sub.Close()
+
+	suite.todo.Title = "Ship the release"
+	suite.todo.Completed = true
+	assert.NoError(NewTodoRepository(suite.db, bus).Update(suite.todo))
+
+	e := <-sub.C
+	assert.Equal(events.TodoUpdated, e.Type)
+	assert.Equal(map[string]interface{}{"title": "Ship release", "completed": false}, e.Previous)
+}
+
+func (suite *TodoRepositoryTestSuite) TestDelete() {
+	assert := assert.New(suite.T())
+
+	assert.NoError(suite.repo.Delete(suite.todo.ID))
+
+	assert.Nil(suite.repo.ByID(suite.todo.ID))
+	var count int64
+	suite.db.Model(&models.Reminder{}).Where("todo_id = ?", suite.todo.ID).Count(&count)
+	assert.Zero(count)
+
+	trashed := suite.repo.Trashed(1)
+	if assert.Len(trashed, 1) {
+		assert.Equal(suite.todo.ID, trashed[0].ID)
+	}
+}
+
+func (suite *TodoRepositoryTestSuite) TestRestore() {
+	assert := assert.New(suite.T())
+
+	// a reminder removed before the todo was deleted stays removed
+	removed := &models.Reminder{TodoID: suite.todo.ID, Channel: models.ReminderInApp, RemindAt: &suite.due}
+	suite.db.Create(removed)
+	suite.db.Delete(removed)
+
+	suite.repo.Delete(suite.todo.ID)
+	todo := suite.repo.TrashedByID(suite.todo.ID)
+	if !assert.NotNil(todo) {
+		return
+	}
+	assert.NoError(suite.repo.Restore(todo))
+
+	restored := suite.repo.ByID(suite.todo.ID)
+	if assert.NotNil(restored) {
+		assert.Len(restored.Reminders, 1)
+		assert.Equal(suite.todo.Reminders[0].ID, restored.Reminders[0].ID)
+	}
+	assert.Nil(suite.repo.TrashedByID(suite.todo.ID))
+	assert.Empty(suite.repo.Trashed(1))
+}
+
+func (suite *TodoRepositoryTestSuite) TestRestoreOutOfTrashedList() {
+	assert := assert.New(suite.T())
+
+	lists := NewListRepository(suite.db, events.Discard)
+	list := &models.List{UserID: 1, Name: "Work"}
+	lists.Create(list)
+	suite.todo.ListID = &list.ID
+	suite.repo.Update(suite.todo)
+
+	lists.Delete(list.ID)
+	assert.NoError(suite.repo.Restore(suite.repo.TrashedByID(suite.todo.ID)))
+
+	restored := suite.repo.ByID(suite.todo.ID)
+	if assert.NotNil(restored) {
+		assert.Nil(restored.ListID)
+	}
+}
+
+func (suite *TodoRepositoryTestSuite) TestPurge() {
+	assert := assert.New(suite.T())
+
+	kept := &models.Todo{UserID: 1, Title: "Kept"}
+	suite.repo.Create(kept)
+	suite.repo.Delete(suite.todo.ID)
+
+	n, err := suite.repo.Purge(time.Now().Add(-time.Hour))
+	assert.NoError(err)
+	assert.Zero(n)
+
+	n, err = suite.repo.Purge(time.Now().Add(time.Second))
+	assert.NoError(err)
+	assert.Equal(int64(1), n)
+
+	assert.Nil(suite.repo.TrashedByID(suite.todo.ID))
+	assert.NotNil(suite.repo.ByID(kept.ID))
+	var count int64
+	suite.db.Unscoped().Model(&models.Reminder{}).Where("todo_id = ?", suite.todo.ID).Count(&count)
+	assert.Zero(count)
+}
+
+func (suite *TodoRepositoryTestSuite) TestAssign() {
+	assert := assert.New(suite.T())
+
+	bus := events.NewMemoryBus(10, 10)
+	sub := bus.Subscribe(3, 0)
+	defer sub.Close()
+	repo := NewTodoRepository(suite.db, bus)
+
+	added, removed, err := repo.Assign(suite.todo, []uint{2, 3})
+	assert.NoError(err)
+	assert.Equal([]uint{2, 3}, added)
+	assert.Empty(removed)
+	assert.Equal([]uint{2, 3}, suite.todo.AssigneeIDs())
+
+	added, removed, err = repo.Assign(suite.todo, []uint{2, 4})
+	assert.NoError(err)
+	assert.Equal([]uint{4}, added)
+	assert.Equal([]uint{3}, removed)
+	assert.Equal([]uint{2, 4}, repo.ByID(suite.todo.ID).AssigneeIDs())
+
+	// the unassigned user hears about it too
+	<-sub.C
+	e := <-sub.C
+	assert.Equal(events.TodoUpdated, e.Type)
+	assert.Equal([]uint{2, 3}, e.Previous["assignees"])
+
+	added, removed, err = repo.Assign(suite.todo, []uint{2, 4})
+	assert.NoError(err)
+	assert.Empty(added)
+	assert.Empty(removed)
+}
+
+func (suite *TodoRepositoryTestSuite) TestAssignBlockAndUnblockChangeTheVersion() {
+	assert := assert.New(suite.T())
+
+	blocker := &models.Todo{UserID: 1, Title: "Write changelog"}
+	suite.repo.Create(blocker)
+	version := suite.repo.ByID(suite.todo.ID).Version
+
+	suite.repo.Assign(suite.todo, []uint{2})
+	assert.Equal(version+1, suite.todo.Version)
+	assert.NoError(suite.repo.Block(suite.todo, blocker.ID))
+	assert.Equal(version+2, suite.todo.Version)
+	assert.NoError(suite.repo.Unblock(suite.todo, blocker.ID))
+	assert.Equal(version+3, suite.todo.Version)
+	assert.Equal(version+3, suite.repo.ByID(suite.todo.ID).Version)
+
+	// writes which change nothing keep the version
+	suite.repo.Assign(suite.todo, []uint{2})
+	assert.NoError(suite.repo.Unblock(suite.todo, blocker.ID))
+	assert.Equal(version+3, suite.repo.ByID(suite.todo.ID).Version)
+}
+
+func (suite *TodoRepositoryTestSuite) TestTag() {
+	assert := assert.New(suite.T())
+
+	version := suite.repo.ByID(suite.todo.ID).Version
+	assert.NoError(suite.repo.Tag(suite.todo, []string{"release", "billable"}))
+	assert.Equal([]string{"billable", "release"}, suite.todo.TagNames())
+	assert.Equal(version+1, suite.todo.Version)
+
+	assert.NoError(suite.repo.Tag(suite.todo, []string{"release", "urgent"}))
+	todo := suite.repo.ByID(suite.todo.ID)
+	assert.Equal([]string{"release", "urgent"}, todo.TagNames())
+	assert.Equal(version+2, todo.Version)
+
+	// tagging it the same keeps the version
+	assert.NoError(suite.repo.Tag(suite.todo, []string{"urgent", "release"}))
+	assert.Equal(version+2, suite.repo.ByID(suite.todo.ID).Version)
+
+	// the todos of templates are created with their tags
+	laptop := &models.Todo{UserID: 1, Title: "Laptop", Tags: []models.TodoTag{{Name: "it"}}}
+	assert.NoError(suite.repo.Create(laptop))
+	assert.Equal([]string{"it"}, suite.repo.ByID(laptop.ID).TagNames())
+}
+
+func (suite *TodoRepositoryTestSuite) TestAssignedTo() {
+	assert := assert.New(suite.T())
+
+	later := suite.due.Add(time.Hour)
+	other := &models.Todo{UserID: 5, Title: "Pay rent", DueAt: &later}
+	undated := &models.Todo{UserID: 5, Title: "Read book"}
+	suite.repo.Create(other)
+	suite.repo.Create(undated)
+	suite.repo.Create(&models.Todo{UserID: 5, Title: "Not assigned"})
+
+	for _, todo := range []*models.Todo{undated, other, suite.todo} {
+		suite.repo.Assign(todo, []uint{2})
+	}
+
+	todos := suite.repo.AssignedTo(2)
+	if assert.Len(todos, 3) {
+		assert.Equal(suite.todo.ID, todos[0].ID)
+		assert.Equal(other.ID, todos[1].ID)
+		assert.Equal(undated.ID, todos[2].ID)
+	}
+	assert.Empty(suite.repo.AssignedTo(1))
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdateUnassignsNonMembers() {
+	assert := assert.New(suite.T())
+
+	team := &models.List{UserID: 1, Name: "Team"}
+	home := &models.List{UserID: 1, Name: "Home"}
+	suite.db.Create(team)
+	suite.db.Create(home)
+	suite.db.Create(&models.ListMember{ListID: team.ID, UserID: 2})
+	suite.db.Create(&models.ListMember{ListID: team.ID, UserID: 3})
+	suite.db.Create(&models.ListMember{ListID: home.ID, UserID: 3})
+
+	suite.todo.ListID = &team.ID
+	assert.NoError(suite.repo.Update(suite.todo))
+	suite.repo.Assign(suite.todo, []uint{1, 2, 3})
+
+	suite.todo.ListID = &home.ID
+	assert.NoError(suite.repo.Update(suite.todo))
+	assert.Equal([]uint{1, 3}, suite.repo.ByID(suite.todo.ID).AssigneeIDs())
+
+	// only the owner can have a todo without a list
+	suite.todo.ListID = nil
+	assert.NoError(suite.repo.Update(suite.todo))
+	assert.Equal([]uint{1}, suite.repo.ByID(suite.todo.ID).AssigneeIDs())
+}
+
+func (suite *TodoRepositoryTestSuite) TestBlockDetectsCycles() {
+	assert := assert.New(suite.T())
+
+	design := &models.Todo{UserID: 1, Title: "Design"}
+	build := &models.Todo{UserID: 1, Title: "Build"}
+	suite.repo.Create(design)
+	suite.repo.Create(build)
+
+	// design blocks build, which blocks shipping
+	assert.NoError(suite.repo.Block(build, design.ID))
+	assert.NoError(suite.repo.Block(suite.todo, build.ID))
+	assert.Equal([]uint{build.ID}, suite.repo.ByID(suite.todo.ID).BlockerIDs())
+
+	assert.Equal(ErrDependencyCycle, suite.repo.Block(design, suite.todo.ID))
+	assert.Equal(ErrDependencyCycle, suite.repo.Block(build, suite.todo.ID))
+	assert.Equal(ErrDependencyCycle, suite.repo.Block(design, design.ID))
+	assert.Empty(suite.repo.ByID(design.ID).Blockers)
+
+	// blocking twice changes nothing
+	assert.NoError(suite.repo.Block(build, design.ID))
+	assert.Len(suite.repo.ByID(build.ID).Blockers, 1)
+
+	assert.NoError(suite.repo.Unblock(build, design.ID))
+	assert.Empty(build.Blockers)
+	assert.NoError(suite.repo.Block(design, suite.todo.ID))
+}
+
+func (suite *TodoRepositoryTestSuite) TestReady() {
+	assert := assert.New(suite.T())
+
+	design := &models.Todo{UserID: 1, Title: "Design", Priority: models.PriorityHigh}
+	review := &models.Todo{UserID: 1, Title: "Review"}
+	suite.repo.Create(design)
+	suite.repo.Create(review)
+	suite.repo.Block(suite.todo, design.ID)
+	suite.repo.Block(suite.todo, review.ID)
+
+	ids := func(todos []models.Todo) []uint {
+		var ids []uint
+		for _, t := range todos {
+			ids = append(ids, t.ID)
+		}
+		return ids
+	}
+	assert.Equal([]uint{design.ID, review.ID}, ids(suite.repo.Ready(1)))
+	assert.Len(suite.repo.OpenBlockers(suite.todo.ID), 2)
+
+	// completed and trashed blockers don't block anymore
+	design.Completed = true
+	suite.repo.Update(design)
+	suite.repo.Delete(review.ID)
+	assert.Equal([]uint{suite.todo.ID}, ids(suite.repo.Ready(1)))
+	assert.Empty(suite.repo.OpenBlockers(suite.todo.ID))
+
+	// nor do the ones deleted for good
+	assert.NoError(suite.repo.ForceDelete(review.ID))
+	assert.Equal([]uint{design.ID}, suite.repo.ByID(suite.todo.ID).BlockerIDs())
+}
+
+func (suite *TodoRepositoryTestSuite) TestMove() {
+	assert := assert.New(suite.T())
+
+	list := &models.List{UserID: 1, Name: "Release"}
+	suite.db.Create(list)
+	limit := 2
+	doing := &models.Column{ListID: list.ID, Name: "Doing", WIPLimit: &limit}
+	done := &models.Column{ListID: list.ID, Name: "Done", Position: 1, Done: true}
+	suite.db.Create(doing)
+	suite.db.Create(done)
+
+	var cards []*models.Todo
+	for _, title := range []string{"Design", "Build", "Test"} {
+		card := &models.Todo{UserID: 1, ListID: &list.ID, Title: title}
+		suite.repo.Create(card)
+		cards = append(cards, card)
+	}
+	order := func(column *models.Column) []string {
+		var titles []string
+		suite.db.Model(&models.Todo{}).Where("column_id = ?", column.ID).Order("position").Pluck("title", &titles)
+		return titles
+	}
+
+	assert.NoError(suite.repo.Move(cards[0], doing, -1))
+	assert.NoError(suite.repo.Move(cards[1], doing, 0))
+	assert.Equal([]string{"Build", "Design"}, order(doing))
+	assert.Equal(ErrWIPLimit, suite.repo.Move(cards[2], doing, 0))
+
+	// reordering within a full column is fine
+	assert.NoError(suite.repo.Move(cards[1], doing, 1))
+	assert.Equal([]string{"Design", "Build"}, order(doing))
+
+	assert.NoError(suite.repo.Move(cards[0], done, 0))
+	assert.True(cards[0].Completed)
+	assert.True(suite.repo.ByID(cards[0].ID).Completed)
+	assert.Equal([]string{"Build"}, order(doing))
+	assert.Equal(0, suite.repo.ByID(cards[1].ID).Position)
+
+	assert.NoError(suite.repo.Move(cards[0], doing, 0))
+	assert.False(suite.repo.ByID(cards[0].ID).Completed)
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdateSyncsTheColumn() {
+	assert := assert.New(suite.T())
+
+	list := &models.List{UserID: 1, Name: "Release"}
+	other := &models.List{UserID: 1, Name: "Home"}
+	suite.db.Create(list)
+	suite.db.Create(other)
+	doing := &models.Column{ListID: list.ID, Name: "Doing"}
+	done := &models.Column{ListID: list.ID, Name: "Done", Position: 1, Done: true}
+	suite.db.Create(doing)
+	suite.db.Create(done)
+
+	suite.todo.ListID = &list.ID
+	suite.repo.Update(suite.todo)
+	suite.repo.Move(suite.todo, doing, 0)
+
+	// completing the todo moves it to the Done column and reopening it back
+	suite.todo.Completed = true
+	assert.NoError(suite.repo.Update(suite.todo))
+	assert.Equal(done.ID, *suite.repo.ByID(suite.todo.ID).ColumnID)
+
+	suite.todo.Completed = false
+	assert.NoError(suite.repo.Update(suite.todo))
+	assert.Equal(doing.ID, *suite.repo.ByID(suite.todo.ID).ColumnID)
+
+	// it leaves the board of a list it is moved out of
+	suite.todo.ListID = &other.ID
+	assert.NoError(suite.repo.Update(suite.todo))
+	assert.Nil(suite.repo.ByID(suite.todo.ID).ColumnID)
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdateChecksTheVersion() {
+	assert := assert.New(suite.T())
+
+	assert.Equal(uint(1), suite.todo.Version)
+	first := suite.repo.ByID(suite.todo.ID)
+	second := suite.repo.ByID(suite.todo.ID)
+
+	first.Title = "Ship it"
+	assert.NoError(suite.repo.Update(first))
+	assert.Equal(uint(2), first.Version)
+
+	// the second editor read the todo before the first one changed it
+	second.Title = "Ship release 2"
+	assert.Equal(ErrVersionConflict, suite.repo.Update(second))
+	assert.Equal(uint(1), second.Version)
+
+	todo := suite.repo.ByID(suite.todo.ID)
+	assert.Equal("Ship it", todo.Title)
+	assert.Equal(uint(2), todo.Version)
+}
+
+func (suite *TodoRepositoryTestSuite) TestTransaction() {
+	assert := assert.New(suite.T())
+
+	bus := events.NewMemoryBus(10, 10)
+	sub := bus.Subscribe(1, 0)
+	repo := NewTodoRepository(suite.db, bus)
+
+	// nothing is written nor published when it is rolled back
+	err := repo.Transaction(func(tx TodoRepository) error {
+		todo := tx.ByID(suite.todo.ID)
+		todo.Title = "Rolled back"
+		tx.Update(todo)
+		return errors.New("boom")
+	})
+	assert.EqualError(err, "boom")
+	assert.Equal("Ship release", repo.ByID(suite.todo.ID).Title)
+	select {
+	case e := <-sub.C:
+		suite.Fail("unexpected event", e.Type)
+	default:
+	}
+
+	// a failing transaction within one only undoes its own writes
+	kept := &models.Todo{UserID: 1, Title: "Kept"}
+	undone := &models.Todo{UserID: 1, Title: "Undone"}
+	err = repo.Transaction(func(tx TodoRepository) error {
+		tx.Transaction(func(item TodoRepository) error { return item.Create(kept) })
+		tx.Transaction(func(item TodoRepository) error {
+			item.Create(undone)
+			return errors.New("boom")
+		})
+		return nil
+	})
+	assert.NoError(err)
+	assert.NotNil(repo.ByID(kept.ID))
+	assert.Nil(repo.ByID(undone.ID))
+
+	// the events of the committed writes are published afterwards
+	e := <-sub.C
+	assert.Equal(events.TodoCreated, e.Type)
+	assert.Equal(kept.ID, e.ResourceID)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTodoRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TodoRepositoryTestSuite))
+}
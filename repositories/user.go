@@ -3,6 +3,7 @@ package repositories
 import (
 	"github.com/ksungcaya/todo-echo/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository will interact to the user table.
@@ -12,6 +13,10 @@ type UserRepository interface {
 	ByEmail(email string) *models.User
 	ByUsername(username string) *models.User
 
+	// Taken tells if another user has the value in the column, it
+	// backs the unique rule of the requests on users
+	Taken(column string, value string, exceptID uint) bool
+
 	// Methods for altering users
 	Create(user *models.User) error
 	Update(user *models.User) error
@@ -67,6 +72,18 @@ func (ur *userRepoGorm) ByEmail(email string) *models.User {
 	return nil
 }
 
+// Taken will tell if a user other than the one of exceptID,
+// if it is not zero, has the value in the column
+func (ur *userRepoGorm) Taken(column string, value string, exceptID uint) bool {
+	var count int64
+	err := ur.db.Model(&models.User{}).
+		Where(clause.Eq{Column: clause.Column{Name: column}, Value: value}).
+		Where("id <> ?", exceptID).
+		Count(&count).Error
+
+	return err == nil && count > 0
+}
+
 // Create will create a new record to the database
 // based on the provided User struct. The password will
 // be automatically Hashed here by gorm's BeforeCreate
@@ -79,9 +96,23 @@ func (ur *userRepoGorm) Create(user *models.User) error {
 // based on the provided User struct. The password "WILL NOT"
 // be automatically Hashed here, instead when another struct
 // consumes this method, we will check there if a new password
-// has been provided and perform the hasing there.
+// has been provided and perform the hasing there. The empty fields
+// are left as they are, but for the locale and the timezone which are
+// cleared by an empty one. It fails with ErrVersionConflict when the user was
+// changed since it was read.
 func (ur *userRepoGorm) Update(user *models.User) error {
-	return ur.db.Updates(user).Error
+	values := map[string]interface{}{"locale": user.Locale, "timezone": user.Timezone}
+	for column, value := range map[string]string{
+		"username": user.Username,
+		"email":    user.Email,
+		"name":     user.Name,
+		"password": user.Password,
+	} {
+		if value != "" {
+			values[column] = value
+		}
+	}
+	return updateVersioned(ur.db, user, &user.Version, values)
 }
 
 // Delete will delete a record from the database by ID
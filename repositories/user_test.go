@@ -67,6 +67,14 @@ func (suite *UserRepositoryTestSuite) TestByEmail() {
 	assert.Equal(suite.user.Username, user.Username)
 }
 
+func (suite *UserRepositoryTestSuite) TestTaken() {
+	assert := assert.New(suite.T())
+
+	assert.True(suite.repo.Taken("email", suite.user.Email, 0))
+	assert.False(suite.repo.Taken("email", suite.user.Email, suite.user.ID))
+	assert.False(suite.repo.Taken("username", "nobody", 0))
+}
+
 func (suite *UserRepositoryTestSuite) TestCreate() {
 	assert := assert.New(suite.T())
 
@@ -114,6 +122,32 @@ func (suite *UserRepositoryTestSuite) TestUpdate() {
 	assert.Greater(updated.UpdatedAt.UnixNano(), u.CreatedAt.UnixNano())
 }
 
+func (suite *UserRepositoryTestSuite) TestUpdateLocaleAndTimezone() {
+	assert := assert.New(suite.T())
+
+	var u models.User
+	suite.db.First(&u, suite.user.ID)
+
+	u.Locale = "es"
+	u.Timezone = "Asia/Manila"
+	assert.NoError(suite.repo.Update(&u))
+
+	var updated models.User
+	suite.db.First(&updated, suite.user.ID)
+	assert.Equal("es", updated.Locale)
+	assert.Equal("Asia/Manila", updated.Timezone)
+
+	// empty ones clear the ones the user had
+	updated.Locale = ""
+	updated.Timezone = ""
+	assert.NoError(suite.repo.Update(&updated))
+
+	var cleared models.User
+	suite.db.First(&cleared, suite.user.ID)
+	assert.Equal("", cleared.Locale)
+	assert.Equal("", cleared.Timezone)
+}
+
 func (suite *UserRepositoryTestSuite) TestDelete() {
 	assert := assert.New(suite.T())
 
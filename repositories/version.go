@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when writing a record which was
+// changed since it was read, as its version is no longer the same
+var ErrVersionConflict = errors.New("It was changed since it was read")
+
+// updateVersioned writes the values of the record when it is still at
+// the version it was read at, and moves it to the next version
+func updateVersioned(tx *gorm.DB, model interface{}, version *uint, values map[string]interface{}) error {
+	// gorm writes the values through the model, the version included
+	read := *version
+	values["version"] = read + 1
+	result := tx.Model(model).Where("version = ?", read).Updates(values)
+
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = ErrVersionConflict
+	}
+	if err != nil {
+		*version = read
+		return err
+	}
+	*version = read + 1
+	return nil
+}
@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository will interact to the webhooks
+// and webhook_deliveries tables.
+type WebhookRepository interface {
+	// Methods for querying webhooks
+	ByID(id uint) *models.Webhook
+	ByUser(userID uint) []models.Webhook
+	Subscribed(userID uint, eventType string) []models.Webhook
+
+	// Methods for altering webhooks
+	Create(webhook *models.Webhook) error
+	Update(webhook *models.Webhook) error
+	Delete(id uint) error
+	TrackFailure(id uint, failed bool, disableAfter int, at time.Time) (bool, error)
+
+	// Methods for the deliveries of the webhooks
+	DeliveryByID(id uint) *models.WebhookDelivery
+	Deliveries(webhookID uint, offset int, limit int) ([]models.WebhookDelivery, int64)
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	SaveDelivery(delivery *models.WebhookDelivery) error
+	ClaimDeliveries(owner string, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+
+	// Workspace scopes the repository to the webhooks of the workspace
+	Workspace(id uint) WebhookRepository
+}
+
+type webhookRepoGorm struct {
+	db        *gorm.DB
+	workspace *uint
+}
+
+var _ WebhookRepository = &webhookRepoGorm{}
+
+// NewWebhookRepository creates instance of WebhookRepository.
+// It sees the webhooks of every workspace until it is scoped
+// to one.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepoGorm{db: db}
+}
+
+// Workspace returns a copy of the repository which only sees the
+// webhooks of the workspace, and creates the new ones in there
+func (wr *webhookRepoGorm) Workspace(id uint) WebhookRepository {
+	return &webhookRepoGorm{db: wr.db, workspace: &id}
+}
+
+// ByID will look up a webhook by ID
+// If no record was found, the method will return nil
+func (wr *webhookRepoGorm) ByID(id uint) *models.Webhook {
+	var w models.Webhook
+	err := wr.query().First(&w, id).Error
+	if err == nil {
+		return &w
+	}
+
+	return nil
+}
+
+// ByUser will return all the webhooks of the user
+func (wr *webhookRepoGorm) ByUser(userID uint) []models.Webhook {
+	var webhooks []models.Webhook
+	wr.query().Where("user_id = ?", userID).Order("id").Find(&webhooks)
+
+	return webhooks
+}
+
+// Subscribed will return the active webhooks of the user which subscribe to the event type
+func (wr *webhookRepoGorm) Subscribed(userID uint, eventType string) []models.Webhook {
+	var candidates []models.Webhook
+	wr.query().Where("user_id = ? AND active = ?", userID, true).Order("id").Find(&candidates)
+
+	webhooks := make([]models.Webhook, 0, len(candidates))
+	for _, w := range candidates {
+		if w.Subscribes(eventType) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks
+}
+
+// Create will create a new webhook
+func (wr *webhookRepoGorm) Create(webhook *models.Webhook) error {
+	if wr.workspace != nil {
+		webhook.WorkspaceID = *wr.workspace
+	}
+	return wr.db.Create(webhook).Error
+}
+
+// Update will update the webhook's settings. Activating
+// it again starts over with a clean failure count.
+func (wr *webhookRepoGorm) Update(webhook *models.Webhook) error {
+	values := map[string]interface{}{
+		"url":         webhook.URL,
+		"events":      webhook.Events,
+		"description": webhook.Description,
+		"active":      webhook.Active,
+	}
+	if webhook.Active {
+		webhook.FailureCount = 0
+		webhook.DisabledAt = nil
+		values["failure_count"] = 0
+		values["disabled_at"] = nil
+	}
+
+	return wr.db.Model(webhook).Updates(values).Error
+}
+
+// Delete will delete the webhook and its delivery history
+func (wr *webhookRepoGorm) Delete(id uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{Model: gorm.Model{ID: id}}).Error
+	})
+}
+
+// TrackFailure counts the consecutive failed attempts of the webhook,
+// a successful one resets the count. The webhook gets disabled once
+// the count reaches disableAfter, which is reported back.
+func (wr *webhookRepoGorm) TrackFailure(id uint, failed bool, disableAfter int, at time.Time) (bool, error) {
+	if !failed {
+		err := wr.db.Model(&models.Webhook{}).
+			Where("id = ? AND failure_count > 0", id).
+			Update("failure_count", 0).Error
+		return false, err
+	}
+
+	disabled := false
+	err := wr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Webhook{}).
+			Where("id = ?", id).
+			Update("failure_count", gorm.Expr("failure_count + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.Webhook{}).
+			Where("id = ? AND active = ? AND failure_count >= ?", id, true, disableAfter).
+			Updates(map[string]interface{}{"active": false, "disabled_at": at.UTC()})
+		disabled = res.RowsAffected > 0
+		return res.Error
+	})
+
+	return disabled, err
+}
+
+// DeliveryByID will look up a delivery by ID
+// If no record was found, the method will return nil
+func (wr *webhookRepoGorm) DeliveryByID(id uint) *models.WebhookDelivery {
+	var d models.WebhookDelivery
+	err := wr.db.First(&d, id).Error
+	if err == nil {
+		return &d
+	}
+
+	return nil
+}
+
+// Deliveries will return a page of the webhook's deliveries, newest
+// first, along with the total number of deliveries it has.
+func (wr *webhookRepoGorm) Deliveries(webhookID uint, offset int, limit int) ([]models.WebhookDelivery, int64) {
+	query := wr.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	query.Count(&total)
+
+	var deliveries []models.WebhookDelivery
+	query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries)
+
+	return deliveries, total
+}
+
+// CreateDelivery will queue a new delivery
+func (wr *webhookRepoGorm) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return wr.db.Create(delivery).Error
+}
+
+// SaveDelivery will save the outcome of a delivery attempt and release its lease
+func (wr *webhookRepoGorm) SaveDelivery(delivery *models.WebhookDelivery) error {
+	delivery.LeaseOwner = ""
+	delivery.LeaseUntil = nil
+	return wr.db.Save(delivery).Error
+}
+
+// ClaimDeliveries leases up to limit pending deliveries which are due,
+// the same way reminders are claimed, so each attempt is made only once.
+func (wr *webhookRepoGorm) ClaimDeliveries(owner string, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	now = now.UTC()
+	until := now.Add(lease)
+
+	var candidates []models.WebhookDelivery
+	err := wr.db.
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Where("lease_until IS NULL OR lease_until <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.WebhookDelivery, 0, len(candidates))
+	for _, d := range candidates {
+		res := wr.db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND (lease_until IS NULL OR lease_until <= ?)", d.ID, now).
+			Updates(map[string]interface{}{"lease_owner": owner, "lease_until": until})
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 1 {
+			d.LeaseOwner = owner
+			d.LeaseUntil = &until
+			claimed = append(claimed, d)
+		}
+	}
+
+	return claimed, nil
+}
+
+// query is the query of the webhooks the repository sees
+func (wr *webhookRepoGorm) query() *gorm.DB {
+	if wr.workspace == nil {
+		return wr.db
+	}
+	return wr.db.Where("webhooks.workspace_id = ?", *wr.workspace)
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type WebhookRepositoryTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	repo    WebhookRepository
+	now     time.Time
+	webhook *models.Webhook
+}
+
+func (suite *WebhookRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	db.Unscoped().Where("1 = 1").Delete(&models.WebhookDelivery{})
+	db.Unscoped().Where("1 = 1").Delete(&models.Webhook{})
+
+	suite.db = db
+	suite.repo = NewWebhookRepository(db)
+	suite.now = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	suite.webhook = &models.Webhook{
+		UserID: 1,
+		URL:    "https://example.com/hook",
+		Secret: "whsec_test",
+		Events: "todo.created,todo.updated",
+		Active: true,
+	}
+	suite.repo.Create(suite.webhook)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestSubscribed() {
+	assert := assert.New(suite.T())
+
+	suite.repo.Create(&models.Webhook{UserID: 1, URL: "https://example.com/all", Secret: "s", Events: "*", Active: true})
+	inactive := &models.Webhook{UserID: 1, URL: "https://example.com/off", Secret: "s", Events: "*"}
+	suite.repo.Create(inactive)
+	suite.db.Model(inactive).Update("active", false)
+
+	assert.Len(suite.repo.Subscribed(1, "todo.created"), 2)
+	assert.Len(suite.repo.Subscribed(1, "todo.deleted"), 1)
+	assert.Empty(suite.repo.Subscribed(2, "todo.created"))
+}
+
+func (suite *WebhookRepositoryTestSuite) TestWorkspace() {
+	assert := assert.New(suite.T())
+
+	other := &models.Webhook{UserID: 1, URL: "https://example.com/team", Secret: "s", Events: "*", Active: true}
+	assert.NoError(suite.repo.Workspace(7).Create(other))
+	assert.Equal(uint(7), other.WorkspaceID)
+
+	team := suite.repo.Workspace(7)
+	assert.Len(team.Subscribed(1, "todo.created"), 1)
+	assert.Len(team.ByUser(1), 1)
+	assert.Nil(team.ByID(suite.webhook.ID))
+	// the unscoped repository sees the webhooks of every workspace
+	assert.Len(suite.repo.Subscribed(1, "todo.created"), 2)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestTrackFailureDisablesWebhook() {
+	assert := assert.New(suite.T())
+
+	disabled, err := suite.repo.TrackFailure(suite.webhook.ID, true, 2, suite.now)
+	assert.NoError(err)
+	assert.False(disabled)
+
+	// a success in between starts over
+	suite.repo.TrackFailure(suite.webhook.ID, false, 2, suite.now)
+	disabled, _ = suite.repo.TrackFailure(suite.webhook.ID, true, 2, suite.now)
+	assert.False(disabled)
+
+	disabled, _ = suite.repo.TrackFailure(suite.webhook.ID, true, 2, suite.now)
+	assert.True(disabled)
+
+	webhook := suite.repo.ByID(suite.webhook.ID)
+	assert.False(webhook.Active)
+	assert.Equal(2, webhook.FailureCount)
+	assert.NotNil(webhook.DisabledAt)
+
+	// activating it again resets the failures
+	webhook.Active = true
+	assert.NoError(suite.repo.Update(webhook))
+	webhook = suite.repo.ByID(suite.webhook.ID)
+	assert.True(webhook.Active)
+	assert.Zero(webhook.FailureCount)
+	assert.Nil(webhook.DisabledAt)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestClaimDeliveries() {
+	assert := assert.New(suite.T())
+
+	due := suite.now.Add(-time.Second)
+	later := suite.now.Add(time.Hour)
+	suite.repo.CreateDelivery(&models.WebhookDelivery{WebhookID: suite.webhook.ID, EventType: "todo.created", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: &due})
+	suite.repo.CreateDelivery(&models.WebhookDelivery{WebhookID: suite.webhook.ID, EventType: "todo.created", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: &later})
+	suite.repo.CreateDelivery(&models.WebhookDelivery{WebhookID: suite.webhook.ID, EventType: "todo.created", Payload: "{}", Status: models.DeliverySucceeded})
+
+	claimed, err := suite.repo.ClaimDeliveries("instance-a", suite.now, time.Minute, 10)
+	assert.NoError(err)
+	assert.Len(claimed, 1)
+
+	claimed, _ = suite.repo.ClaimDeliveries("instance-b", suite.now, time.Minute, 10)
+	assert.Empty(claimed)
+
+	deliveries, total := suite.repo.Deliveries(suite.webhook.ID, 0, 2)
+	assert.Equal(int64(3), total)
+	assert.Len(deliveries, 2)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWebhookRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookRepositoryTestSuite))
+}
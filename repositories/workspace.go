@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"github.com/ksungcaya/todo-echo/models"
+	"gorm.io/gorm"
+)
+
+// WorkspaceRepository will interact to the workspaces and memberships tables.
+type WorkspaceRepository interface {
+	// Methods for querying workspaces
+	ByID(id uint) *models.Workspace
+	ByUser(userID uint) []models.Membership
+	Membership(workspaceID uint, userID uint) *models.Membership
+	Members(workspaceID uint) []models.Membership
+
+	// Methods for altering workspaces
+	Personal(user *models.User) (*models.Workspace, error)
+	Create(workspace *models.Workspace, ownerID uint) error
+	Update(workspace *models.Workspace) error
+	AddMember(member *models.Membership) error
+	UpdateMember(member *models.Membership) error
+	RemoveMember(workspaceID uint, userID uint) error
+}
+
+type workspaceRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ WorkspaceRepository = &workspaceRepoGorm{}
+
+// NewWorkspaceRepository creates instance of WorkspaceRepository
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepoGorm{db}
+}
+
+// ByID will look up a workspace by ID
+// If no record was found, the method will return nil
+func (wr *workspaceRepoGorm) ByID(id uint) *models.Workspace {
+	var w models.Workspace
+	err := wr.db.First(&w, id).Error
+	if err == nil {
+		return &w
+	}
+
+	return nil
+}
+
+// ByUser will return the memberships of the user along with their
+// workspaces, the personal workspace first
+func (wr *workspaceRepoGorm) ByUser(userID uint) []models.Membership {
+	var memberships []models.Membership
+	wr.db.Preload("Workspace").
+		Joins("JOIN workspaces ON workspaces.id = memberships.workspace_id AND workspaces.deleted_at IS NULL").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.personal DESC, memberships.id").
+		Find(&memberships)
+
+	return memberships
+}
+
+// Membership will look up the membership of the user in the workspace
+// If no record was found, the method will return nil
+func (wr *workspaceRepoGorm) Membership(workspaceID uint, userID uint) *models.Membership {
+	var m models.Membership
+	err := wr.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&m).Error
+	if err == nil {
+		return &m
+	}
+
+	return nil
+}
+
+// Members will return the members of the workspace along with their
+// users, the first to join first
+func (wr *workspaceRepoGorm) Members(workspaceID uint) []models.Membership {
+	var members []models.Membership
+	wr.db.Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("id").
+		Find(&members)
+
+	return members
+}
+
+// Personal will return the personal workspace of the user, creating
+// it the first time. The lists, todos, operations and calendar feeds
+// of the user from before workspaces existed are moved into it. When
+// concurrent requests create it at once, the unique owner of personal
+// workspaces refuses all but one, and the others return that one.
+func (wr *workspaceRepoGorm) Personal(user *models.User) (*models.Workspace, error) {
+	if w := wr.personal(user.ID); w != nil {
+		return w, nil
+	}
+
+	w := models.Workspace{Name: user.Name, OwnerID: &user.ID, Personal: true}
+	err := wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := createWorkspace(tx, &w, user.ID); err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.List{}, &models.Todo{}, &models.Operation{}, &models.CalendarFeed{}} {
+			err := tx.Unscoped().Model(model).
+				Where("user_id = ? AND workspace_id = ?", user.ID, 0).
+				Update("workspace_id", w.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if created := wr.personal(user.ID); created != nil {
+			return created, nil
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Create will create the workspace with the user as its owner
+func (wr *workspaceRepoGorm) Create(workspace *models.Workspace, ownerID uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		return createWorkspace(tx, workspace, ownerID)
+	})
+}
+
+// Update will rename the workspace
+func (wr *workspaceRepoGorm) Update(workspace *models.Workspace) error {
+	return wr.db.Model(workspace).Update("name", workspace.Name).Error
+}
+
+// AddMember will add a user to the workspace
+func (wr *workspaceRepoGorm) AddMember(member *models.Membership) error {
+	return wr.db.Create(member).Error
+}
+
+// UpdateMember will change the role of a member
+func (wr *workspaceRepoGorm) UpdateMember(member *models.Membership) error {
+	return wr.db.Model(member).Update("role", member.Role).Error
+}
+
+// RemoveMember will remove the user from the workspace, the lists of
+// the workspace stop being shared with them and its todos assigned to
+// them are unassigned
+func (wr *workspaceRepoGorm) RemoveMember(workspaceID uint, userID uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		lists := tx.Unscoped().Model(&models.List{}).Select("id").Where("workspace_id = ?", workspaceID)
+		err := tx.Unscoped().
+			Where("user_id = ? AND list_id IN (?)", userID, lists).
+			Delete(&models.ListMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		todos := tx.Unscoped().Model(&models.Todo{}).Select("id").Where("workspace_id = ?", workspaceID)
+		err = tx.Unscoped().
+			Where("user_id = ? AND todo_id IN (?)", userID, todos).
+			Delete(&models.TodoAssignee{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().
+			Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+			Delete(&models.Membership{}).Error
+	})
+}
+
+// personal is a private method looking up the personal workspace the
+// user owns, nil when there's none yet
+func (wr *workspaceRepoGorm) personal(userID uint) *models.Workspace {
+	var w models.Workspace
+	err := wr.db.
+		Joins("JOIN memberships ON memberships.workspace_id = workspaces.id AND memberships.deleted_at IS NULL").
+		Where("workspaces.personal = ? AND memberships.user_id = ? AND memberships.role = ?", true, userID, models.WorkspaceOwner).
+		First(&w).Error
+	if err == nil {
+		return &w
+	}
+
+	return nil
+}
+
+// createWorkspace creates the workspace and the membership of its owner
+func createWorkspace(tx *gorm.DB, workspace *models.Workspace, ownerID uint) error {
+	if err := tx.Omit("Members").Create(workspace).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.Membership{WorkspaceID: workspace.ID, UserID: ownerID, Role: models.WorkspaceOwner}).Error
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepositoryTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	repo    WorkspaceRepository
+	invites InviteRepository
+	todos   TodoRepository
+	lists   ListRepository
+	acme    *models.Workspace
+	globex  *models.Workspace
+}
+
+func (suite *WorkspaceRepositoryTestSuite) SetupTest() {
+	db, _ := test.InitTestDB()
+	for _, model := range []interface{}{
+		&models.Invite{}, &models.Membership{}, &models.Workspace{}, &models.TodoAssignee{},
+		&models.ListMember{}, &models.Todo{}, &models.List{}, &models.Operation{},
+	} {
+		db.Unscoped().Where("1 = 1").Delete(model)
+	}
+
+	suite.db = db
+	suite.repo = NewWorkspaceRepository(db)
+	suite.invites = NewInviteRepository(db)
+	suite.todos = NewTodoRepository(db, events.Discard)
+	suite.lists = NewListRepository(db, events.Discard)
+
+	// the first user is a member of both workspaces
+	suite.acme = &models.Workspace{Name: "Acme"}
+	suite.globex = &models.Workspace{Name: "Globex"}
+	suite.repo.Create(suite.acme, 1)
+	suite.repo.Create(suite.globex, 2)
+	suite.repo.AddMember(&models.Membership{WorkspaceID: suite.globex.ID, UserID: 1, Role: models.WorkspaceMember})
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestScopedTodosAreIsolated() {
+	assert := assert.New(suite.T())
+
+	acme := suite.todos.Workspace(suite.acme.ID)
+	globex := suite.todos.Workspace(suite.globex.ID)
+	todo := &models.Todo{UserID: 1, Title: "Acme roadmap"}
+	assert.NoError(acme.Create(todo))
+	assert.Equal(suite.acme.ID, todo.WorkspaceID)
+
+	assert.NotNil(acme.ByID(todo.ID))
+	assert.Nil(globex.ByID(todo.ID))
+	assert.Len(acme.ByUser(1), 1)
+	assert.Empty(globex.ByUser(1))
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestScopedListsAreIsolated() {
+	assert := assert.New(suite.T())
+
+	acme := suite.lists.Workspace(suite.acme.ID)
+	globex := suite.lists.Workspace(suite.globex.ID)
+	list := &models.List{UserID: 1, Name: "Launch"}
+	assert.NoError(globex.Create(list))
+	assert.Equal(suite.globex.ID, list.WorkspaceID)
+
+	assert.NotNil(globex.ByID(list.ID))
+	assert.Nil(acme.ByID(list.ID))
+	assert.Len(globex.ByUser(1), 1)
+	assert.Empty(acme.ByUser(1))
+
+	// the todos of a list are only found within its workspace
+	suite.todos.Workspace(suite.globex.ID).Create(&models.Todo{UserID: 1, Title: "Press kit", ListID: &list.ID})
+	assert.Len(suite.todos.Workspace(suite.globex.ID).ByList(list.ID), 1)
+	assert.Empty(suite.todos.Workspace(suite.acme.ID).ByList(list.ID))
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestByUser() {
+	assert := assert.New(suite.T())
+
+	_, err := suite.repo.Personal(&models.User{Model: gorm.Model{ID: 1}, Name: "Alice"})
+	assert.NoError(err)
+
+	memberships := suite.repo.ByUser(1)
+	if assert.Len(memberships, 3) {
+		assert.True(memberships[0].Workspace.Personal)
+		assert.Equal("Acme", memberships[1].Workspace.Name)
+		assert.Equal(models.WorkspaceOwner, memberships[1].Role)
+		assert.Equal("Globex", memberships[2].Workspace.Name)
+		assert.Equal(models.WorkspaceMember, memberships[2].Role)
+	}
+	assert.Len(suite.repo.ByUser(2), 1)
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestPersonalAdoptsTheRowsFromBefore() {
+	assert := assert.New(suite.T())
+
+	todo := &models.Todo{UserID: 3, Title: "Old todo"}
+	list := &models.List{UserID: 3, Name: "Old list"}
+	suite.todos.Create(todo)
+	suite.lists.Create(list)
+	other := &models.Todo{UserID: 4, Title: "Someone else's"}
+	suite.todos.Create(other)
+
+	user := &models.User{Model: gorm.Model{ID: 3}, Name: "Carol"}
+	personal, err := suite.repo.Personal(user)
+	if assert.NoError(err) {
+		assert.True(personal.Personal)
+		assert.Equal("Carol", personal.Name)
+		assert.Equal(models.WorkspaceOwner, suite.repo.Membership(personal.ID, 3).Role)
+
+		assert.NotNil(suite.todos.Workspace(personal.ID).ByID(todo.ID))
+		assert.NotNil(suite.lists.Workspace(personal.ID).ByID(list.ID))
+		assert.Nil(suite.todos.Workspace(personal.ID).ByID(other.ID))
+	}
+
+	again, err := suite.repo.Personal(user)
+	if assert.NoError(err) {
+		assert.Equal(personal.ID, again.ID)
+	}
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestOnePersonalWorkspacePerUser() {
+	assert := assert.New(suite.T())
+
+	owner := uint(3)
+	assert.NoError(suite.repo.Create(&models.Workspace{Name: "Carol", OwnerID: &owner, Personal: true}, owner))
+	assert.Error(suite.repo.Create(&models.Workspace{Name: "Carol", OwnerID: &owner, Personal: true}, owner))
+
+	// the user's other workspaces have no owner to be unique
+	assert.NoError(suite.repo.Create(&models.Workspace{Name: "Initech"}, owner))
+	assert.NoError(suite.repo.Create(&models.Workspace{Name: "Hooli"}, owner))
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestRemoveMember() {
+	assert := assert.New(suite.T())
+
+	lists := suite.lists.Workspace(suite.globex.ID)
+	list := &models.List{UserID: 2, Name: "Sales"}
+	lists.Create(list)
+	suite.db.Create(&models.ListMember{ListID: list.ID, UserID: 1})
+	todo := &models.Todo{UserID: 2, Title: "Call", ListID: &list.ID}
+	suite.todos.Workspace(suite.globex.ID).Create(todo)
+	suite.db.Create(&models.TodoAssignee{TodoID: todo.ID, UserID: 1})
+
+	assert.NoError(suite.repo.RemoveMember(suite.globex.ID, 1))
+
+	assert.Nil(suite.repo.Membership(suite.globex.ID, 1))
+	assert.NotNil(suite.repo.Membership(suite.acme.ID, 1))
+	var count int64
+	suite.db.Model(&models.ListMember{}).Where("user_id = ?", 1).Count(&count)
+	assert.Zero(count)
+	suite.db.Model(&models.TodoAssignee{}).Where("user_id = ?", 1).Count(&count)
+	assert.Zero(count)
+}
+
+func (suite *WorkspaceRepositoryTestSuite) TestAcceptInvite() {
+	assert := assert.New(suite.T())
+
+	now := time.Now()
+	invite := &models.Invite{
+		WorkspaceID: suite.acme.ID,
+		InviterID:   1,
+		Email:       "dave@example.com",
+		Role:        models.WorkspaceAdmin,
+		TokenHash:   "hash",
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	assert.NoError(suite.invites.Create(invite))
+	assert.Len(suite.invites.Pending(suite.acme.ID, now), 1)
+	assert.Empty(suite.invites.Pending(suite.acme.ID, now.Add(2*time.Hour)))
+
+	found := suite.invites.ByTokenHash("hash")
+	if assert.NotNil(found) {
+		assert.Equal("Acme", found.Workspace.Name)
+	}
+
+	assert.NoError(suite.invites.Accept(invite, 4, now))
+	if member := suite.repo.Membership(suite.acme.ID, 4); assert.NotNil(member) {
+		assert.Equal(models.WorkspaceAdmin, member.Role)
+	}
+	assert.False(suite.invites.ByID(invite.ID).IsPending(now))
+	assert.Empty(suite.invites.Pending(suite.acme.ID, now))
+
+	// a concurrent request accepting it too is refused
+	stale := suite.invites.ByTokenHash("hash")
+	stale.AcceptedAt = nil
+	assert.Equal(ErrInviteNotPending, suite.invites.Accept(stale, 5, now))
+	assert.Nil(suite.repo.Membership(suite.acme.ID, 5))
+
+	// and so is one accepting a revoked invite
+	revoked := &models.Invite{WorkspaceID: suite.acme.ID, InviterID: 1, Email: "erin@example.com",
+		Role: models.WorkspaceMember, TokenHash: "revoked", ExpiresAt: now.Add(time.Hour)}
+	suite.invites.Create(revoked)
+	suite.invites.Delete(revoked.ID)
+	assert.Equal(ErrInviteNotPending, suite.invites.Accept(revoked, 5, now))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWorkspaceRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkspaceRepositoryTestSuite))
+}
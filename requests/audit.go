@@ -0,0 +1,33 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditQueryRequest is the struct for querying the audit log.
+// The times without an offset are read in UTC.
+type AuditQueryRequest struct {
+	ActorID    string `json:"actor_id" query:"actor_id" validate:"numeric"`
+	Action     string `json:"action" query:"action"`
+	TargetType string `json:"target_type" query:"target_type"`
+	TargetID   string `json:"target_id" query:"target_id" validate:"numeric"`
+	From       string `json:"from" query:"from" validate:"datetime"`
+	To         string `json:"to" query:"to" validate:"datetime|after:from"`
+	Format     string `json:"format" query:"format" validate:"in:json,csv"`
+}
+
+// make sure to implement Request interface
+var _ Request = &AuditQueryRequest{}
+
+// Validate will validate the request with the given context
+func (ar *AuditQueryRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(ar, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(ar); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
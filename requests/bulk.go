@@ -0,0 +1,108 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Operations of a bulk request
+const (
+	BulkComplete = "complete"
+	BulkReopen   = "reopen"
+	BulkMove     = "move"
+	BulkTag      = "tag"
+	BulkDelete   = "delete"
+	BulkUpdate   = "update"
+)
+
+// Modes of a bulk request. Atomic requests are applied all or nothing
+// while the operations of partial ones succeed or fail on their own.
+const (
+	BulkAtomic  = "atomic"
+	BulkPartial = "partial"
+)
+
+// BulkRequest is the struct for applying a batch of at most 500
+// operations to the todos, atomically unless the mode says otherwise
+type BulkRequest struct {
+	Mode       string          `json:"mode" form:"mode" validate:"in:atomic,partial"`
+	Operations []BulkOperation `json:"operations" form:"operations" validate:"required|max:500"`
+}
+
+// BulkOperation is an operation of a bulk request on the todo of ID.
+// Move puts it in the list of ListID, or takes it out of its list
+// when ListID is nil, tag adds the Tags to the ones it has and update
+// changes the Fields which are given.
+type BulkOperation struct {
+	Op             string      `json:"op" validate:"required|in:complete,reopen,move,tag,delete,update"`
+	ID             uint        `json:"id" validate:"required"`
+	ListID         *uint       `json:"list_id"`
+	IgnoreBlockers bool        `json:"ignore_blockers"`
+	Tags           []string    `json:"tags" validate:"required_if:op,tag|max:20"`
+	Fields         *BulkFields `json:"fields" validate:"required_if:op,update"`
+}
+
+// BulkFields are the fields of a todo a bulk update changes, the
+// ones which are nil are left as they are
+type BulkFields struct {
+	Title       *string `json:"title" validate:"filled|max:255"`
+	Description *string `json:"description" validate:"max:5000"`
+	DueAt       *string `json:"due_at" validate:"datetime"`
+	Timezone    *string `json:"timezone" validate:"timezone"`
+	Priority    *string `json:"priority" validate:"in:none,low,medium,high,urgent"`
+	Estimate    *int    `json:"estimate" validate:"min:0"`
+}
+
+// make sure to implement Request interface
+var _ Request = &BulkRequest{}
+
+// Validate will validate the request with the given context, the
+// errors of the operations are named after their index, e.g.
+// operations.0.op
+func (br *BulkRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(br, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(br); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	if br.Mode == "" {
+		br.Mode = BulkAtomic
+	}
+	return http.StatusOK, nil
+}
+
+// check reports the names of tags which are blank or too long
+func (bo *BulkOperation) check(report func(field string, key string, args i18n.Args)) {
+	checkTags(bo.Tags, report)
+}
+
+// Fill copies the fields which are given to the todo, an empty due
+// date clears it. The due date is read in the todo's timezone.
+func (f *BulkFields) Fill(t *models.Todo) {
+	if f.Title != nil {
+		t.Title = *f.Title
+	}
+	if f.Description != nil {
+		t.Description = *f.Description
+	}
+	if f.Timezone != nil {
+		t.Timezone = *f.Timezone
+	}
+	if f.Priority != nil {
+		t.Priority, _ = models.ParsePriority(*f.Priority)
+	}
+	if f.Estimate != nil {
+		t.Estimate = f.Estimate
+	}
+	if f.DueAt != nil {
+		t.DueAt = nil
+		if due, err := ParseDateTime(*f.DueAt, t.Timezone); err == nil {
+			due = due.UTC()
+			t.DueAt = &due
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// CalendarFeedRequest is the struct for creating a calendar feed.
+// Component is "todo" for VTODO components, the default, or
+// "event" for VEVENT components.
+type CalendarFeedRequest struct {
+	Name      string `json:"name" form:"name" validate:"max:100"`
+	Component string `json:"component" form:"component" validate:"in:todo,event"`
+}
+
+// make sure to implement Request interface
+var _ Request = &CalendarFeedRequest{}
+
+// Validate will validate the request with the given context
+func (cr *CalendarFeedRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(cr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(cr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// CalendarFeedModel creates a *models.CalendarFeed of the user using
+// request data, the hash of its token is stored instead of the token
+func (cr *CalendarFeedRequest) CalendarFeedModel(userID uint, tokenHash string) *models.CalendarFeed {
+	f := &models.CalendarFeed{
+		UserID:    userID,
+		Name:      cr.Name,
+		Component: models.CalendarTodos,
+		TokenHash: tokenHash,
+	}
+	if cr.Component == "event" {
+		f.Component = models.CalendarEvents
+	}
+	return f
+}
@@ -0,0 +1,65 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ColumnRequest is the struct for creating and updating a board column
+type ColumnRequest struct {
+	Name     string `json:"name" form:"name" validate:"required|max:50"`
+	WIPLimit *int   `json:"wip_limit" form:"wip_limit" validate:"min:1"`
+	Done     bool   `json:"done" form:"done"`
+}
+
+// make sure to implement Request interface
+var _ Request = &ColumnRequest{}
+
+// Validate will validate the request with the given context
+func (cr *ColumnRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(cr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(cr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Fill copies the request data to the column
+func (cr *ColumnRequest) Fill(c *models.Column) {
+	c.Name = cr.Name
+	c.WIPLimit = cr.WIPLimit
+	c.Done = cr.Done
+}
+
+// MoveRequest is the struct for moving a todo or a column on a board,
+// the position is counted from 0 and the last one when omitted
+type MoveRequest struct {
+	ColumnID uint `json:"column_id" form:"column_id"`
+	Position *int `json:"position" form:"position" validate:"min:0"`
+}
+
+// make sure to implement Request interface
+var _ Request = &MoveRequest{}
+
+// Validate will validate the request with the given context
+func (mr *MoveRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(mr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(mr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// At returns the requested position, -1 for the last one
+func (mr *MoveRequest) At() int {
+	if mr.Position == nil {
+		return -1
+	}
+	return *mr.Position
+}
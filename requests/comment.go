@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// CommentRequest is the struct for posting and editing a comment,
+// the parent can only be set when posting a reply. See TodoRequest
+// for the version.
+type CommentRequest struct {
+	Body     string `json:"body" form:"body" validate:"required|max:5000"`
+	ParentID *uint  `json:"parent_id" form:"parent_id"`
+	Version  *uint  `json:"version" form:"version"`
+}
+
+// make sure to implement Request interface
+var _ Request = &CommentRequest{}
+
+// Validate will validate the request with the given context
+func (cr *CommentRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(cr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(cr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// CommentModel creates a *models.Comment on the todo by the user using request data
+func (cr *CommentRequest) CommentModel(todoID uint, userID uint) *models.Comment {
+	return &models.Comment{TodoID: todoID, UserID: userID, ParentID: cr.ParentID, Body: cr.Body}
+}
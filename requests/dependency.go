@@ -0,0 +1,26 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BlockerRequest is the struct for making a todo blocked by another one
+type BlockerRequest struct {
+	BlockerID uint `json:"blocker_id" form:"blocker_id" validate:"required"`
+}
+
+// make sure to implement Request interface
+var _ Request = &BlockerRequest{}
+
+// Validate will validate the request with the given context
+func (br *BlockerRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(br, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(br); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
@@ -2,35 +2,29 @@ package requests
 
 import (
 	"encoding/json"
+
+	"github.com/ksungcaya/todo-echo/i18n"
 )
 
-// ValidationErrors struct
+// ValidationErrors struct. Errors are the messages in the default
+// language, the fields with messages of the catalogs can be
+// translated in another one.
 type ValidationErrors struct {
-	Errors map[string][]string `json:"errors"`
-}
-
-// ResponseError struct
-type ResponseError struct {
-	Errors interface{} `json:"errors"`
+	Errors   map[string][]string `json:"errors"`
+	messages map[string][]i18n.Message
 }
 
-// NewValidationError creates a ValidationError.
-func NewValidationError(field string, message string) ValidationErrors {
-	e := make(map[string][]string, 1)
-	e[field] = []string{message}
-	return ValidationErrors{Errors: e}
+// NewFieldError creates a ValidationError with a message of the
+// catalogs, its {field} is the name of the field
+func NewFieldError(field string, key string, args i18n.Args) ValidationErrors {
+	ve := newValidationErrors()
+	ve.add(field, i18n.Message{Key: key, Args: args})
+	return ve
 }
 
-// NewResponseError creates a ResponseError with "errors" as parent node.
-func NewResponseError(err error) ResponseError {
-	e := ResponseError{}
-	switch v := err.(type) {
-	case ValidationErrors:
-		e.Errors = v.Errors
-	default:
-		e.Errors = map[string]string{"message": v.Error()}
-	}
-	return e
+// newValidationErrors creates ValidationErrors without errors
+func newValidationErrors() ValidationErrors {
+	return ValidationErrors{Errors: make(map[string][]string), messages: make(map[string][]i18n.Message)}
 }
 
 // It allows ValidationErrors to subscribe to the Error interface.
@@ -39,3 +33,43 @@ func (ve ValidationErrors) Error() string {
 	data, _ := json.MarshalIndent(ve.Errors, "", "  ")
 	return string(data)
 }
+
+// FieldErrors returns the messages of the invalid fields in the
+// language of the locale
+func (ve ValidationErrors) FieldErrors(locale string) map[string][]string {
+	if len(ve.messages) == 0 {
+		return ve.Errors
+	}
+
+	errs := make(map[string][]string, len(ve.Errors))
+	for field, messages := range ve.Errors {
+		keyed, ok := ve.messages[field]
+		if !ok {
+			errs[field] = messages
+			continue
+		}
+		for _, m := range keyed {
+			errs[field] = append(errs[field], localize(locale, field, m))
+		}
+	}
+	return errs
+}
+
+// add appends a message of the catalogs to the errors of the field
+func (ve ValidationErrors) add(field string, m i18n.Message) {
+	ve.Errors[field] = append(ve.Errors[field], localize(i18n.DefaultLocale, field, m))
+	ve.messages[field] = append(ve.messages[field], m)
+}
+
+// localize translates the message of the field, naming the field and
+// the other fields the message names
+func localize(locale string, field string, m i18n.Message) string {
+	args := i18n.Args{"field": i18n.Field(locale, field)}
+	for name, value := range m.Args {
+		if other, ok := value.(fieldRef); ok {
+			value = i18n.Field(locale, string(other))
+		}
+		args[name] = value
+	}
+	return i18n.T(locale, m.Key, args)
+}
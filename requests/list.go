@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ListRequest is the struct for creating and updating a list, see
+// TodoRequest for the version
+type ListRequest struct {
+	Name    string `json:"name" form:"name" validate:"required|max:100"`
+	Version *uint  `json:"version" form:"version"`
+}
+
+// make sure to implement Request interface
+var _ Request = &ListRequest{}
+
+// Validate will validate the request with the given context
+func (lr *ListRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(lr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(lr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// ListModel creates a *models.List owned by the user using request data
+func (lr *ListRequest) ListModel(userID uint) *models.List {
+	return &models.List{UserID: userID, Name: lr.Name}
+}
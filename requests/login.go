@@ -4,13 +4,12 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
-	"gopkg.in/thedevsaddam/govalidator.v1"
 )
 
 // LoginRequest is the struct for registration request
 type LoginRequest struct {
-	Username string `json:"username" form:"username"`
-	Password string `json:"password" form:"password"`
+	Username string `json:"username" form:"username" validate:"required|min:3"`
+	Password string `json:"password" form:"password" validate:"required|min:3"`
 }
 
 // make sure to implement Request interface
@@ -26,11 +25,3 @@ func (lr *LoginRequest) Validate(ctx echo.Context) (int, error) {
 	}
 	return http.StatusOK, nil
 }
-
-// rules is a privated function called on request validation
-func (lr *LoginRequest) rules() govalidator.MapData {
-	return govalidator.MapData{
-		"username": []string{"required", "min:3"},
-		"password": []string{"required", "min:3"},
-	}
-}
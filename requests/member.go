@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MemberRequest is the struct for sharing a list with a user
+type MemberRequest struct {
+	Username string `json:"username" form:"username" validate:"required|between:3,30"`
+}
+
+// make sure to implement Request interface
+var _ Request = &MemberRequest{}
+
+// Validate will validate the request with the given context
+func (mr *MemberRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(mr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(mr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// AssigneeRequest is the struct for assigning a todo, it replaces
+// the users the todo is assigned to
+type AssigneeRequest struct {
+	UserIDs []uint `json:"user_ids" form:"user_ids" validate:"max:20"`
+}
+
+// make sure to implement Request interface
+var _ Request = &AssigneeRequest{}
+
+// Validate will validate the request with the given context
+func (ar *AssigneeRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(ar, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(ar); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
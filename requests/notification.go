@@ -0,0 +1,45 @@
+package requests
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// NotificationPreferencesRequest is the struct for updating
+// which notification types the user wants to receive
+type NotificationPreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences" form:"preferences" validate:"required"`
+}
+
+// make sure to implement Request interface
+var _ Request = &NotificationPreferencesRequest{}
+
+// Validate will validate the request with the given context
+func (nr *NotificationPreferencesRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(nr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(nr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// check checks the types of the preferences are known
+func (nr *NotificationPreferencesRequest) check(report func(field string, key string, args i18n.Args)) {
+	types := make([]string, 0, len(nr.Preferences))
+	for t := range nr.Preferences {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		if !models.IsNotificationType(t) {
+			report("preferences", "validation.unknown_notification", i18n.Args{"type": t})
+		}
+	}
+}
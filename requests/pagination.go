@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Pagination defaults and limits
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// Pagination is the page requested through the page and per_page query params
+type Pagination struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+}
+
+// NewPagination reads the pagination from the query string. Missing
+// or invalid values fall back to the first page of DefaultPerPage.
+func NewPagination(ctx echo.Context) Pagination {
+	p := Pagination{Page: 1, PerPage: DefaultPerPage}
+
+	if page, err := strconv.Atoi(ctx.QueryParam("page")); err == nil && page > 0 {
+		p.Page = page
+	}
+	if perPage, err := strconv.Atoi(ctx.QueryParam("per_page")); err == nil && perPage > 0 {
+		p.PerPage = perPage
+	}
+	if p.PerPage > MaxPerPage {
+		p.PerPage = MaxPerPage
+	}
+
+	return p
+}
+
+// Offset is the number of records before the requested page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
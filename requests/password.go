@@ -0,0 +1,27 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PasswordRequest is the struct for changing the password
+type PasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password" validate:"required"`
+	Password        string `json:"password" form:"password" validate:"required|password"`
+}
+
+// make sure to implement Request interface
+var _ Request = &PasswordRequest{}
+
+// Validate will validate the request with the given context
+func (pr *PasswordRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(pr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(pr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/patch"
+	"github.com/labstack/echo/v4"
+)
+
+// Media types of the patches PATCH requests are made with
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// errUnsupportedPatch is returned when a PATCH request is neither a
+// merge patch nor a JSON patch
+var errUnsupportedPatch = apperrors.New(http.StatusUnsupportedMediaType, "unsupported_patch")
+
+// BindPatch applies the patch of the request body to the current
+// fields of the record, and binds the result to the request. Fields
+// the patch removes, or sets to null, are left zero in the request.
+func BindPatch(request Request, ctx echo.Context, current interface{}) (int, error) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != MIMEMergePatch && mediaType != MIMEJSONPatch {
+		return http.StatusUnsupportedMediaType, errUnsupportedPatch
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil || !json.Valid(body) {
+		return http.StatusBadRequest, ErrInvalidPayload
+	}
+
+	var patched []byte
+	if mediaType == MIMEMergePatch {
+		patched, err = patch.Merge(doc, body)
+	} else {
+		patched, err = patch.Apply(doc, body)
+	}
+	switch {
+	case err == patch.ErrTestFailed:
+		return http.StatusConflict, err
+	case err != nil:
+		return http.StatusUnprocessableEntity, errors.New("The patch can't be applied, " + err.Error())
+	}
+
+	if err := json.Unmarshal(patched, request); err != nil {
+		return http.StatusUnprocessableEntity, errors.New("The patched fields are invalid")
+	}
+	return http.StatusOK, nil
+}
@@ -5,15 +5,14 @@ import (
 
 	"github.com/ksungcaya/todo-echo/models"
 	"github.com/labstack/echo/v4"
-	"gopkg.in/thedevsaddam/govalidator.v1"
 )
 
 // RegisterRequest is the struct for registration request
 type RegisterRequest struct {
-	Username string `json:"username" form:"username"`
-	Email    string `json:"email" form:"email"`
-	Name     string `json:"name" form:"name"`
-	Password string `json:"password" form:"password"`
+	Username string `json:"username" form:"username" validate:"required|between:3,30|unique:users,username"`
+	Email    string `json:"email" form:"email" validate:"required|email|max:100|unique:users,email"`
+	Name     string `json:"name" form:"name" validate:"required|max:100"`
+	Password string `json:"password" form:"password" validate:"required|password"`
 }
 
 // make sure to implement Request interface
@@ -39,13 +38,3 @@ func (rr *RegisterRequest) UserModel() *models.User {
 		Password: rr.Password,
 	}
 }
-
-// rules is a privated function called on request validation
-func (rr *RegisterRequest) rules() govalidator.MapData {
-	return govalidator.MapData{
-		"username": []string{"required", "between:3,100"},
-		"email":    []string{"required", "min:4", "max:20", "email"},
-		"name":     []string{"required", "min:4", "max:20"},
-		"password": []string{"required", "min:6"},
-	}
-}
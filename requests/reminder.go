@@ -0,0 +1,57 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ReminderRequest is the struct for adding a reminder to a todo.
+// Either At (absolute) or Before (minutes before due) must be given.
+type ReminderRequest struct {
+	Channel string `json:"channel" form:"channel" validate:"required|in:email,webhook,in_app"`
+	Target  string `json:"target" form:"target" validate:"required_if:channel,webhook|max:255"`
+	At      string `json:"at" form:"at" validate:"required_without:before|excluded_with:before|datetime"`
+	Before  *int   `json:"before" form:"before" validate:"min:0"`
+}
+
+// make sure to implement Request interface
+var _ Request = &ReminderRequest{}
+
+// Validate will validate the request with the given context
+func (rr *ReminderRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(rr, ctx); err != nil {
+		return code, err
+	}
+	if err := rr.Check(); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Check validates request data that is already bound, e.g. a
+// reminder which is read from an imported file.
+func (rr *ReminderRequest) Check() error {
+	return ValidateRequest(rr)
+}
+
+// ReminderModel creates a *models.Reminder for the todo using request data.
+// An absolute time without an offset is read in the todo's timezone.
+func (rr *ReminderRequest) ReminderModel(t *models.Todo) *models.Reminder {
+	r := &models.Reminder{
+		TodoID:        t.ID,
+		Channel:       rr.Channel,
+		Target:        rr.Target,
+		OffsetMinutes: rr.Before,
+	}
+	if rr.At != "" {
+		if at, err := ParseDateTime(rr.At, t.Timezone); err == nil {
+			at = at.UTC()
+			r.RemindAt = &at
+		}
+	}
+
+	r.Schedule(t)
+	return r
+}
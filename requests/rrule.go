@@ -0,0 +1,110 @@
+package requests
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	rruleFrequencies = map[string]bool{
+		"SECONDLY": true, "MINUTELY": true, "HOURLY": true,
+		"DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+	}
+	rruleWeekday = regexp.MustCompile(`^(MO|TU|WE|TH|FR|SA|SU)$`)
+	rruleByDay   = regexp.MustCompile(`^[+-]?([1-9]|[1-4][0-9]|5[0-3])?(MO|TU|WE|TH|FR|SA|SU)$`)
+)
+
+// rruleRanges are the bounds of the numeric BYxxx parts,
+// the negative ones count from the end of the period.
+var rruleRanges = map[string][2]int{
+	"BYSECOND":   {0, 60},
+	"BYMINUTE":   {0, 59},
+	"BYHOUR":     {0, 23},
+	"BYMONTHDAY": {-31, 31},
+	"BYYEARDAY":  {-366, 366},
+	"BYWEEKNO":   {-53, 53},
+	"BYMONTH":    {1, 12},
+	"BYSETPOS":   {-366, 366},
+}
+
+// NormalizeRRule upper cases a recurrence rule and takes off
+// the "RRULE:" prefix it has when copied from a calendar.
+func NormalizeRRule(rule string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+}
+
+// ValidateRRule checks an RFC 5545 recurrence rule,
+// e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+func ValidateRRule(rule string) error {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(NormalizeRRule(rule), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return fmt.Errorf("invalid part %q", part)
+		}
+		if _, ok := parts[kv[0]]; ok {
+			return fmt.Errorf("%s is given more than once", kv[0])
+		}
+		parts[kv[0]] = kv[1]
+	}
+
+	if !rruleFrequencies[parts["FREQ"]] {
+		return fmt.Errorf("invalid FREQ %q", parts["FREQ"])
+	}
+	if _, ok := parts["COUNT"]; ok {
+		if _, ok := parts["UNTIL"]; ok {
+			return fmt.Errorf("COUNT and UNTIL can't be given together")
+		}
+	}
+
+	for key, value := range parts {
+		if err := validateRRulePart(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRRulePart checks the value of a single part of a rule
+func validateRRulePart(key string, value string) error {
+	switch key {
+	case "FREQ":
+		return nil
+	case "INTERVAL", "COUNT":
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return fmt.Errorf("%s must be a positive number", key)
+		}
+	case "UNTIL":
+		if _, err := time.Parse("20060102", value); err == nil {
+			return nil
+		}
+		if _, err := time.Parse("20060102T150405Z", value); err != nil {
+			return fmt.Errorf("invalid UNTIL %q", value)
+		}
+	case "WKST":
+		if !rruleWeekday.MatchString(value) {
+			return fmt.Errorf("invalid WKST %q", value)
+		}
+	case "BYDAY":
+		for _, day := range strings.Split(value, ",") {
+			if !rruleByDay.MatchString(day) {
+				return fmt.Errorf("invalid BYDAY %q", day)
+			}
+		}
+	default:
+		bounds, ok := rruleRanges[key]
+		if !ok {
+			return fmt.Errorf("unknown part %s", key)
+		}
+		for _, v := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < bounds[0] || n > bounds[1] || (n == 0 && bounds[0] < 0) {
+				return fmt.Errorf("invalid %s %q", key, v)
+			}
+		}
+	}
+	return nil
+}
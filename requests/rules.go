@@ -0,0 +1,279 @@
+package requests
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+)
+
+// dateTimeLayouts are the accepted formats of date and time inputs.
+// Layouts without an offset are read in the request's timezone.
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// minPasswordLength is the fewest characters of a password
+const minPasswordLength = 8
+
+var (
+	numeric  = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+	hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// Uniqueness is implemented by the repositories of the tables the
+// unique rule looks up, see RegisterUnique
+type Uniqueness interface {
+	// Taken tells if a record other than the one of exceptID, none
+	// when it is zero, has the value in the column
+	Taken(column string, value string, exceptID uint) bool
+}
+
+var (
+	tablesMu sync.RWMutex
+	tables   = make(map[string]Uniqueness)
+)
+
+// the rules every request can use
+func init() {
+	RegisterRule("required", Rule{Check: given, Implicit: true})
+
+	// filled requires a value of the field unless it is nil
+	RegisterRule("filled", Rule{
+		Check:    func(f *Field) bool { return !f.Value.IsValid() || !f.Empty() },
+		Implicit: true,
+	})
+
+	// required_with:other requires the field when the other is given
+	RegisterRule("required_with", Rule{
+		Check:    func(f *Field) bool { return f.Other(f.Params[0]).Empty() || !f.Empty() },
+		Message:  otherMessage("validation.required_with"),
+		Implicit: true,
+	})
+
+	// required_without:other requires the field unless the other is given
+	RegisterRule("required_without", Rule{
+		Check:    func(f *Field) bool { return !f.Other(f.Params[0]).Empty() || !f.Empty() },
+		Message:  otherMessage("validation.required_without"),
+		Implicit: true,
+	})
+
+	// required_if:other,value requires the field when the other has the value
+	RegisterRule("required_if", Rule{
+		Check: func(f *Field) bool { return f.Other(f.Params[0]).String() != f.Params[1] || !f.Empty() },
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.required_if", i18n.Args{"other": fieldRef(f.Params[0]), "value": f.Params[1]}
+		},
+		Implicit: true,
+	})
+
+	// excluded_with:other forbids the field when the other is given
+	RegisterRule("excluded_with", Rule{
+		Check:   func(f *Field) bool { return f.Other(f.Params[0]).Empty() },
+		Message: otherMessage("validation.excluded_with"),
+	})
+
+	// after:other requires a date and time after the one of the other
+	RegisterRule("after", Rule{
+		Check: func(f *Field) bool {
+			other, err := ParseDateTime(f.Other(f.Params[0]).String(), "")
+			if err != nil {
+				return true
+			}
+			t, err := ParseDateTime(f.String(), "")
+			return err == nil && t.After(other)
+		},
+		Message: otherMessage("validation.after"),
+	})
+
+	RegisterRule("min", Rule{
+		Check: func(f *Field) bool {
+			size, ok := f.size()
+			return ok && size >= param(f, 0)
+		},
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.min" + kindSuffix(f), i18n.Args{"min": f.Params[0]}
+		},
+	})
+
+	RegisterRule("max", Rule{
+		Check: func(f *Field) bool {
+			size, ok := f.size()
+			return ok && size <= param(f, 0)
+		},
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.max" + kindSuffix(f), i18n.Args{"max": f.Params[0]}
+		},
+	})
+
+	RegisterRule("between", Rule{
+		Check: func(f *Field) bool {
+			size, ok := f.size()
+			return ok && size >= param(f, 0) && size <= param(f, 1)
+		},
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.between", i18n.Args{"min": f.Params[0], "max": f.Params[1]}
+		},
+	})
+
+	RegisterRule("in", Rule{
+		Check: func(f *Field) bool { return contains(f.Params, f.String()) },
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.in", i18n.Args{"values": strings.Join(f.Params, ", ")}
+		},
+	})
+
+	RegisterRule("numeric", Rule{Check: func(f *Field) bool {
+		if f.Value.Kind() == reflect.String {
+			return numeric.MatchString(f.String())
+		}
+		_, ok := f.size()
+		return ok
+	}})
+
+	RegisterRule("email", Rule{Check: func(f *Field) bool {
+		addr, err := mail.ParseAddress(f.String())
+		return err == nil && addr.Address == f.String()
+	}})
+
+	RegisterRule("url", Rule{Check: func(f *Field) bool {
+		u, err := url.ParseRequestURI(f.String())
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}})
+
+	RegisterRule("datetime", Rule{Check: func(f *Field) bool {
+		_, err := ParseDateTime(f.String(), "")
+		return err == nil
+	}})
+
+	RegisterRule("timezone", Rule{Check: func(f *Field) bool {
+		_, err := time.LoadLocation(f.String())
+		return err == nil
+	}})
+
+	RegisterRule("rrule", Rule{Check: func(f *Field) bool {
+		return ValidateRRule(f.String()) == nil
+	}})
+
+	// locale requires one of the languages of the catalogs
+	RegisterRule("locale", Rule{
+		Check: func(f *Field) bool { return i18n.Supported(f.String()) },
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.in", i18n.Args{"values": strings.Join(i18n.Locales(), ", ")}
+		},
+	})
+
+	// password requires a password of at least minPasswordLength
+	// characters, with both letters and numbers
+	RegisterRule("password", Rule{
+		Check: func(f *Field) bool {
+			s := f.String()
+			return len([]rune(s)) >= minPasswordLength && strings.IndexFunc(s, unicode.IsLetter) >= 0 && strings.IndexFunc(s, unicode.IsDigit) >= 0
+		},
+		Message: func(f *Field) (string, i18n.Args) {
+			return "validation.password", i18n.Args{"min": minPasswordLength}
+		},
+	})
+
+	// hexcolor requires a color like #f80 or #ff8800
+	RegisterRule("hexcolor", Rule{Check: func(f *Field) bool {
+		return hexColor.MatchString(f.String())
+	}})
+
+	// unique:table,column[,field] requires a value no record of the
+	// table has in the column, except the record whose ID is the
+	// value of the field, e.g. the one being updated
+	RegisterRule("unique", Rule{Check: func(f *Field) bool {
+		var exceptID uint
+		if len(f.Params) > 2 {
+			id, _ := strconv.ParseUint(f.Other(f.Params[2]).String(), 10, 64)
+			exceptID = uint(id)
+		}
+		return !uniqueness(f.Params[0]).Taken(f.Params[1], f.String(), exceptID)
+	}})
+}
+
+// RegisterUnique makes the unique rule look the values of the table
+// up in the repository
+func RegisterUnique(table string, repo Uniqueness) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	tables[table] = repo
+}
+
+// uniqueness returns the repository of the table, a table without one
+// is a bug of the setup so it panics
+func uniqueness(table string) Uniqueness {
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+	repo, ok := tables[table]
+	if !ok {
+		panic(fmt.Sprintf("requests: no repository is registered for the unique values of %s", table))
+	}
+	return repo
+}
+
+// ParseDateTime parses a date and time input. When the input does not
+// carry its own offset, it will be read in the given timezone.
+func ParseDateTime(value string, timezone string) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date and time %q", value)
+}
+
+// given tells if the field has a value
+func given(f *Field) bool {
+	return !f.Empty()
+}
+
+// otherMessage is the message of a rule naming another field
+func otherMessage(key string) func(f *Field) (string, i18n.Args) {
+	return func(f *Field) (string, i18n.Args) {
+		return key, i18n.Args{"other": fieldRef(f.Params[0])}
+	}
+}
+
+// param returns the numeric parameter of the rule at i, a parameter
+// which is not a number is a bug of the tag so it panics
+func param(f *Field, i int) float64 {
+	n, err := strconv.ParseFloat(f.Params[i], 64)
+	if err != nil {
+		panic(fmt.Sprintf("requests: %s has the invalid parameter %q", f.Name, f.Params[i]))
+	}
+	return n
+}
+
+// kindSuffix tells the kind of the value of the field for the min and
+// max rules, whose messages differ between strings, numbers and lists
+func kindSuffix(f *Field) string {
+	switch f.Value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "_size"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "_number"
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package requests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/patch"
+	"github.com/labstack/echo/v4"
+)
+
+// SyncFields are the fields of every resource a client can change
+var SyncFields = map[string][]string{
+	models.ChangeTodo: {"list_id", "title", "description", "completed", "due_at", "timezone", "recurrence", "priority", "estimate"},
+	models.ChangeList: {"name"},
+}
+
+// SyncRequest is the struct for syncing an offline client. Token is
+// the sync token of the last sync, empty for the first one, and the
+// changes, at most 500, are the ones the client made since.
+type SyncRequest struct {
+	Token   string       `json:"token" form:"token" validate:"numeric"`
+	Changes []SyncChange `json:"changes" form:"changes" validate:"max:500"`
+}
+
+// SyncChange is a change a client made to a record. The record is
+// named by its ID, or by the ID the client gave it when the client
+// created it, ClientID. A change deleting the record has DeletedAt,
+// the others have the Fields they changed, which are written as for
+// creating or updating the record, and the time every one of them
+// was changed at in UpdatedAt. The list_id of a todo can be the
+// client ID of a list as well.
+type SyncChange struct {
+	Resource  string                     `json:"resource" validate:"required|in:todo,list"`
+	ID        uint                       `json:"id" validate:"required_without:client_id"`
+	ClientID  string                     `json:"client_id" validate:"max:64"`
+	Fields    map[string]json.RawMessage `json:"fields" validate:"required_without:deleted_at"`
+	UpdatedAt map[string]time.Time       `json:"updated_at"`
+	DeletedAt *time.Time                 `json:"deleted_at"`
+}
+
+// make sure to implement Request interface
+var _ Request = &SyncRequest{}
+
+// Validate will validate the request with the given context, the
+// errors of the changes are named after their index, e.g.
+// changes.0.resource
+func (sr *SyncRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(sr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(sr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Seq is the seq of the last change the client synced
+func (sr *SyncRequest) Seq() uint64 {
+	seq, _ := strconv.ParseUint(sr.Token, 10, 64)
+	return seq
+}
+
+// check checks the names of the fields of the change, every one of
+// them must be synced and have the time it was changed at
+func (sc *SyncChange) check(report func(field string, key string, args i18n.Args)) {
+	fields, ok := SyncFields[sc.Resource]
+	for field := range sc.Fields {
+		if ok && !contains(fields, field) {
+			report("fields."+field, "validation.not_synced", i18n.Args{"name": field})
+		}
+		if _, ok := sc.UpdatedAt[field]; !ok {
+			report("updated_at."+field, "validation.changed_at", i18n.Args{"name": field})
+		}
+	}
+}
+
+// ListClientID returns the client ID the todo of the change is put
+// in the list of, if it is one
+func (sc *SyncChange) ListClientID() (string, bool) {
+	var clientID string
+	if err := json.Unmarshal(sc.Fields["list_id"], &clientID); err != nil || clientID == "" {
+		return "", false
+	}
+	return clientID, true
+}
+
+// SetListID puts the todo of the change in the list of ID instead
+func (sc *SyncChange) SetListID(id uint) {
+	sc.Fields["list_id"] = json.RawMessage(strconv.FormatUint(uint64(id), 10))
+}
+
+// Todo makes the request changing the fields of the todo, or creating
+// it when it is nil, and validates it. The other fields of the todo
+// are left as they are.
+func (sc *SyncChange) Todo(t *models.Todo, fields []string) (*TodoRequest, int, error) {
+	current := new(TodoRequest)
+	if t != nil {
+		current = NewTodoRequest(t)
+	}
+
+	tr := new(TodoRequest)
+	if code, err := sc.merge(tr, current, fields); err != nil {
+		return nil, code, err
+	}
+	if code, err := tr.validate(); err != nil {
+		return nil, code, err
+	}
+	return tr, http.StatusOK, nil
+}
+
+// List makes the request changing the fields of the list, or creating
+// it when it is nil, and validates it
+func (sc *SyncChange) List(l *models.List, fields []string) (*ListRequest, int, error) {
+	current := new(ListRequest)
+	if l != nil {
+		current.Name = l.Name
+	}
+
+	lr := new(ListRequest)
+	if code, err := sc.merge(lr, current, fields); err != nil {
+		return nil, code, err
+	}
+	if err := ValidateRequest(lr); err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	return lr, http.StatusOK, nil
+}
+
+// merge binds to the request the current fields of the record with
+// the fields of the change merged in, a null clearing the field
+func (sc *SyncChange) merge(request Request, current interface{}, fields []string) (int, error) {
+	changed := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		changed[field] = sc.Fields[field]
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	p, _ := json.Marshal(changed)
+	merged, err := patch.Merge(doc, p)
+	if err == nil {
+		err = json.Unmarshal(merged, request)
+	}
+	if err != nil {
+		return http.StatusUnprocessableEntity, errors.New("The fields are invalid")
+	}
+	return http.StatusOK, nil
+}
+
+// contains determines if the name is one of the names
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
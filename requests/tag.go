@@ -0,0 +1,77 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+// maxTagLength is the most characters of the name of a tag
+const maxTagLength = 50
+
+// MaxTags is the most tags a todo can have
+const MaxTags = 20
+
+// TagRequest is the struct for tagging a todo, it replaces the tags
+// the todo has
+type TagRequest struct {
+	Tags []string `json:"tags" form:"tags" validate:"max:20"`
+}
+
+// make sure to implement Request interface
+var _ Request = &TagRequest{}
+
+// Validate will validate the request with the given context
+func (tr *TagRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(tr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(tr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// check reports the names of tags which are blank or too long
+func (tr *TagRequest) check(report func(field string, key string, args i18n.Args)) {
+	checkTags(tr.Tags, report)
+}
+
+// Names returns the names of the tags normalized, without duplicates
+func (tr *TagRequest) Names() []string {
+	return NormalizeTags(tr.Tags)
+}
+
+// checkTags reports the names of the tags field which are blank or
+// too long, by their index
+func checkTags(tags []string, report func(field string, key string, args i18n.Args)) {
+	for i, name := range tags {
+		if name := NormalizeTag(name); name == "" || utf8.RuneCountInString(name) > maxTagLength {
+			report(fmt.Sprintf("tags.%d", i), "validation.tag", i18n.Args{"max": maxTagLength})
+		}
+	}
+}
+
+// NormalizeTag returns the name a tag is kept under, trimmed and
+// lowercase
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTags returns the names of the tags normalized, without
+// duplicates and the blank ones
+func NormalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = NormalizeTag(name); name != "" && !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
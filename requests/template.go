@@ -0,0 +1,122 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// TemplateRequest is the struct for creating and updating a template,
+// the todos replace the ones the template had and are at most 200
+type TemplateRequest struct {
+	Name     string                `json:"name" form:"name" validate:"required|max:100"`
+	ListName string                `json:"list_name" form:"list_name" validate:"max:100"`
+	Shared   bool                  `json:"shared" form:"shared"`
+	Todos    []TemplateTodoRequest `json:"todos" form:"todos" validate:"max:200"`
+}
+
+// TemplateTodoRequest is the struct for a todo of a template. The
+// due offset is in days after the start date and the estimate in
+// minutes. The tags are given to the todos it creates.
+type TemplateTodoRequest struct {
+	Title       string   `json:"title" validate:"required|max:255"`
+	Description string   `json:"description" validate:"max:5000"`
+	Priority    string   `json:"priority" validate:"in:none,low,medium,high,urgent"`
+	Estimate    *int     `json:"estimate" validate:"min:0"`
+	DueOffset   *int     `json:"due_offset" validate:"min:0"`
+	Tags        []string `json:"tags" validate:"max:20"`
+}
+
+// check reports the names of tags which are blank or too long
+func (tr *TemplateTodoRequest) check(report func(field string, key string, args i18n.Args)) {
+	checkTags(tr.Tags, report)
+}
+
+// make sure to implement Request interface
+var _ Request = &TemplateRequest{}
+
+// Validate will validate the request with the given context, the
+// errors of the todos are named after their index, e.g. todos.0.title
+func (tr *TemplateRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(tr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(tr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Fill copies the request data to the template, the list is named
+// after the template when no list name is given
+func (tr *TemplateRequest) Fill(t *models.Template) {
+	t.Name = tr.Name
+	t.ListName = tr.ListName
+	if t.ListName == "" {
+		t.ListName = tr.Name
+	}
+	t.Shared = tr.Shared
+
+	t.Todos = make([]models.TemplateTodo, 0, len(tr.Todos))
+	for _, todo := range tr.Todos {
+		priority, _ := models.ParsePriority(todo.Priority)
+		tt := models.TemplateTodo{
+			Title:       todo.Title,
+			Description: todo.Description,
+			Priority:    priority,
+			Estimate:    todo.Estimate,
+			DueOffset:   todo.DueOffset,
+		}
+		for _, name := range NormalizeTags(todo.Tags) {
+			tt.Tags = append(tt.Tags, models.TemplateTodoTag{Name: name})
+		}
+		t.Todos = append(t.Todos, tt)
+	}
+}
+
+// CaptureRequest is the struct for making a template of a list
+type CaptureRequest struct {
+	Name   string `json:"name" form:"name" validate:"required|max:100"`
+	Shared bool   `json:"shared" form:"shared"`
+}
+
+// make sure to implement Request interface
+var _ Request = &CaptureRequest{}
+
+// Validate will validate the request with the given context
+func (cr *CaptureRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(cr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(cr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// InstantiateRequest is the struct for creating a list from a
+// template. The todos are due counting from the start date, today
+// when omitted, in the timezone. They are added to the list of
+// ListID instead of a new one when it is given.
+type InstantiateRequest struct {
+	ListID    *uint             `json:"list_id" form:"list_id"`
+	Start     string            `json:"start" form:"start" validate:"datetime"`
+	Timezone  string            `json:"timezone" form:"timezone" validate:"timezone"`
+	Variables map[string]string `json:"variables" form:"variables"`
+}
+
+// make sure to implement Request interface
+var _ Request = &InstantiateRequest{}
+
+// Validate will validate the request with the given context
+func (ir *InstantiateRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(ir, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(ir); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
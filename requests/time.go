@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TimeReportRequest is the struct for querying the time tracked by
+// the user. The times without an offset are read in the timezone,
+// the user's own when it is not given.
+type TimeReportRequest struct {
+	From     string `json:"from" query:"from" validate:"datetime"`
+	To       string `json:"to" query:"to" validate:"datetime"`
+	Group    string `json:"group" query:"group" validate:"in:day,week"`
+	Timezone string `json:"timezone" query:"timezone" validate:"timezone"`
+	ListID   string `json:"list_id" query:"list_id" validate:"numeric"`
+	TodoID   string `json:"todo_id" query:"todo_id" validate:"numeric"`
+	Tag      string `json:"tag" query:"tag" validate:"max:50"`
+}
+
+// make sure to implement Request interface
+var _ Request = &TimeReportRequest{}
+
+// Validate will validate the request with the given context
+func (tr *TimeReportRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(tr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(tr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
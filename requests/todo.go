@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// TodoRequest is the struct for creating and updating a todo. The
+// estimate is in minutes, and a todo whose blockers are still open
+// can only be completed with IgnoreBlockers. Version is the version
+// of the todo an update is made to, unless If-Match gives it.
+type TodoRequest struct {
+	ListID         *uint  `json:"list_id" form:"list_id"`
+	Title          string `json:"title" form:"title" validate:"required|max:255"`
+	Description    string `json:"description" form:"description" validate:"max:5000"`
+	Completed      bool   `json:"completed" form:"completed"`
+	DueAt          string `json:"due_at" form:"due_at" validate:"datetime"`
+	Timezone       string `json:"timezone" form:"timezone" validate:"timezone"`
+	Recurrence     string `json:"recurrence" form:"recurrence" validate:"rrule"`
+	Priority       string `json:"priority" form:"priority" validate:"in:none,low,medium,high,urgent"`
+	Estimate       *int   `json:"estimate" form:"estimate" validate:"min:0"`
+	IgnoreBlockers bool   `json:"ignore_blockers" form:"ignore_blockers"`
+	Version        *uint  `json:"version" form:"version"`
+}
+
+// make sure to implement Request interface
+var _ Request = &TodoRequest{}
+
+// NewTodoRequest creates the request which would leave the todo as it
+// is, the document a patch of the todo is applied to. The due date is
+// given in the todo's own timezone.
+func NewTodoRequest(t *models.Todo) *TodoRequest {
+	tr := &TodoRequest{
+		ListID:      t.ListID,
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		Timezone:    t.Timezone,
+		Recurrence:  t.Recurrence,
+		Priority:    t.PriorityName(),
+		Estimate:    t.Estimate,
+	}
+	if due := t.LocalDueAt(); due != nil {
+		tr.DueAt = due.Format(time.RFC3339)
+	}
+	return tr
+}
+
+// Validate will validate the request with the given context
+func (tr *TodoRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(tr, ctx); err != nil {
+		return code, err
+	}
+	return tr.validate()
+}
+
+// Patch applies the patch of the request to the todo's fields and
+// validates the result. An explicit null clears the due date, the
+// estimate or the list of the todo.
+func (tr *TodoRequest) Patch(ctx echo.Context, t *models.Todo) (int, error) {
+	if code, err := BindPatch(tr, ctx, NewTodoRequest(t)); err != nil {
+		return code, err
+	}
+	return tr.validate()
+}
+
+// validate validates the fields of the request once they are bound
+func (tr *TodoRequest) validate() (int, error) {
+	if err := ValidateRequest(tr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// TodoModel creates a *models.Todo owned by the user using request data
+func (tr *TodoRequest) TodoModel(userID uint) *models.Todo {
+	t := &models.Todo{UserID: userID}
+	tr.Fill(t)
+	return t
+}
+
+// Fill copies the request data to the todo. The due date is stored
+// in UTC, while the timezone is kept for displaying it back.
+func (tr *TodoRequest) Fill(t *models.Todo) {
+	t.ListID = tr.ListID
+	t.Title = tr.Title
+	t.Description = tr.Description
+	t.Completed = tr.Completed
+	t.Timezone = tr.Timezone
+	t.Recurrence = NormalizeRRule(tr.Recurrence)
+	t.Priority, _ = models.ParsePriority(tr.Priority)
+	t.Estimate = tr.Estimate
+	t.DueAt = nil
+
+	if tr.DueAt != "" {
+		if due, err := ParseDateTime(tr.DueAt, tr.Timezone); err == nil {
+			due = due.UTC()
+			t.DueAt = &due
+		}
+	}
+}
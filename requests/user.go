@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// UserRequest is the struct for changing the profile of the user,
+// the password has its own request. Locale is the language the user
+// prefers, none leaves it to Accept-Language. Timezone is the one
+// their reports are in by default. Version is the version
+// of the user the change is made to, unless If-Match gives it. ID is
+// the user's own, whose username and email are not taken by them.
+type UserRequest struct {
+	ID       uint   `json:"-" form:"-"`
+	Username string `json:"username" form:"username" validate:"required|between:3,30|unique:users,username,ID"`
+	Email    string `json:"email" form:"email" validate:"required|email|max:100|unique:users,email,ID"`
+	Name     string `json:"name" form:"name" validate:"required|max:100"`
+	Locale   string `json:"locale" form:"locale" validate:"locale"`
+	Timezone string `json:"timezone" form:"timezone" validate:"timezone"`
+	Version  *uint  `json:"version" form:"version"`
+}
+
+// make sure to implement Request interface
+var _ Request = &UserRequest{}
+
+// NewUserRequest creates the request which would leave the profile of
+// the user as it is, the document a patch of the user is applied to
+func NewUserRequest(u *models.User) *UserRequest {
+	return &UserRequest{
+		ID:       u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+		Name:     u.Name,
+		Locale:   u.Locale,
+		Timezone: u.Timezone,
+	}
+}
+
+// Validate will validate the request with the given context
+func (ur *UserRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(ur, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(ur); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Patch applies the patch of the request to the profile of the user
+// and validates the result
+func (ur *UserRequest) Patch(ctx echo.Context, u *models.User) (int, error) {
+	if code, err := BindPatch(ur, ctx, NewUserRequest(u)); err != nil {
+		return code, err
+	}
+	ur.ID = u.ID
+	if err := ValidateRequest(ur); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// Fill copies the request data to the user
+func (ur *UserRequest) Fill(u *models.User) {
+	u.Username = ur.Username
+	u.Email = ur.Email
+	u.Name = ur.Name
+	u.Locale = ur.Locale
+	u.Timezone = ur.Timezone
+}
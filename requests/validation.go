@@ -0,0 +1,287 @@
+package requests
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ksungcaya/todo-echo/i18n"
+)
+
+// Rule is a rule the fields of the requests name in their validate
+// tag, the rules of a field are separated by | and the parameters of
+// a rule follow a colon, e.g. `validate:"required|between:3,30"`.
+// The fields of nested structs, and of the structs of slices, are
+// validated as well, their errors are named after the path to them,
+// e.g. todos.0.title.
+type Rule struct {
+	// Check tells if the field passes the rule
+	Check func(f *Field) bool
+	// Message returns the message of the catalogs the field fails the
+	// rule with, validation.<name> of the rule when it is nil
+	Message func(f *Field) (string, i18n.Args)
+	// Implicit rules are checked on empty fields as well, the other
+	// ones pass them, e.g. an empty due date is a valid datetime
+	Implicit bool
+}
+
+// Field is a field of a request a rule is checked on
+type Field struct {
+	// Name is the name of the field in the errors, e.g. todos.0.title
+	Name string
+	// Value is the value of the field, pointers are followed and nil
+	// ones are the invalid value
+	Value reflect.Value
+	// Params are the parameters of the rule, e.g. 3 and 30 of between:3,30
+	Params []string
+
+	pointer bool
+	parent  reflect.Value
+	prefix  string
+}
+
+// checker is implemented by the structs with rules the tags can't
+// tell, e.g. of the keys of a map. check reports the errors of the
+// fields named as in the struct.
+type checker interface {
+	check(report func(field string, key string, args i18n.Args))
+}
+
+// fieldRef is an argument of a message naming another field, it is
+// translated along with the message
+type fieldRef string
+
+var (
+	rulesMu sync.RWMutex
+	rules   = make(map[string]Rule)
+
+	// nested caches whether the fields of a type have rules
+	nested sync.Map
+)
+
+// RegisterRule makes the rule available to the validate tags under
+// the name, replacing the rule of the name if there is one
+func RegisterRule(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = rule
+}
+
+// lookupRule returns the rule registered under the name
+func lookupRule(name string) (Rule, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+// newField creates the field of the struct parent with the value,
+// named after the prefix
+func newField(name string, value reflect.Value, parent reflect.Value, prefix string) *Field {
+	return &Field{Name: prefix + name, Value: indirect(value), pointer: value.Kind() == reflect.Ptr, parent: parent, prefix: prefix}
+}
+
+// Empty tells if the field is nil, an empty string or list, or else
+// the zero value. A pointer to the zero value is not empty, e.g. 0
+// minutes before.
+func (f *Field) Empty() bool {
+	v := f.Value
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return !f.pointer && v.IsZero()
+}
+
+// String returns the value of the field as a string
+func (f *Field) String() string {
+	switch {
+	case !f.Value.IsValid():
+		return ""
+	case f.Value.Kind() == reflect.String:
+		return f.Value.String()
+	}
+	return fmt.Sprint(f.Value.Interface())
+}
+
+// Other returns the field of the same struct named name, by its JSON
+// name or else its Go name. Naming a field the struct doesn't have is
+// a bug of the tag so it panics.
+func (f *Field) Other(name string) *Field {
+	t := f.parent.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if jsonName(sf) == name || sf.Name == name {
+			return newField(name, f.parent.Field(i), f.parent, f.prefix)
+		}
+	}
+	panic(fmt.Sprintf("requests: %s names the unknown field %s", f.Name, name))
+}
+
+// size returns the length of a string, in characters, or of a list,
+// and the value of a number. The others have no size.
+func (f *Field) size() (float64, bool) {
+	v := f.Value
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// ValidateRequest validates the request with the rules of the tags of
+// its fields, it returns ValidationErrors when some of them fail
+func ValidateRequest(request interface{}) error {
+	v := indirect(reflect.ValueOf(request))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	ve := newValidationErrors()
+	validateStruct(v, "", ve)
+	if len(ve.Errors) == 0 {
+		return nil
+	}
+	return ve
+}
+
+// validateStruct checks the rules of the fields of the struct, and of
+// the structs nested in it, naming their errors after the prefix
+func validateStruct(v reflect.Value, prefix string, ve ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		f := newField(jsonName(sf), v.Field(i), v, prefix)
+		if sf.Anonymous && f.Value.Kind() == reflect.Struct {
+			validateStruct(f.Value, prefix, ve)
+			continue
+		}
+
+		if tag != "" {
+			checkRules(f, tag, ve)
+		}
+		if _, failed := ve.Errors[f.Name]; !failed {
+			validateNested(f.Value, f.Name, ve)
+		}
+	}
+
+	if c, ok := addr(v).(checker); ok {
+		c.check(func(field string, key string, args i18n.Args) {
+			ve.add(prefix+field, i18n.Message{Key: key, Args: args})
+		})
+	}
+}
+
+// validateNested validates the struct of a field, or the structs of
+// a list, the errors of the items of a list are named after their
+// index
+func validateNested(v reflect.Value, name string, ve ValidationErrors) {
+	if !v.IsValid() || !hasRules(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, name+".", ve)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(indirect(v.Index(i)), fmt.Sprintf("%s.%d", name, i), ve)
+		}
+	}
+}
+
+// checkRules checks the rules of the tag on the field in order, up to
+// the first one it fails, e.g. an invalid email is not looked up
+func checkRules(f *Field, tag string, ve ValidationErrors) {
+	for _, spec := range strings.Split(tag, "|") {
+		name, params := spec, []string(nil)
+		if i := strings.Index(spec, ":"); i >= 0 {
+			name, params = spec[:i], strings.Split(spec[i+1:], ",")
+		}
+		rule, ok := lookupRule(name)
+		if !ok {
+			panic(fmt.Sprintf("requests: %s has the unknown rule %s", f.Name, name))
+		}
+
+		f.Params = params
+		if (!rule.Implicit && f.Empty()) || rule.Check(f) {
+			continue
+		}
+
+		key, args := "validation."+name, i18n.Args(nil)
+		if rule.Message != nil {
+			key, args = rule.Message(f)
+		}
+		ve.add(f.Name, i18n.Message{Key: key, Args: args})
+		return
+	}
+}
+
+// hasRules tells if a type, or the types nested in it, has fields
+// with rules
+func hasRules(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if has, ok := nested.Load(t); ok {
+		return has.(bool)
+	}
+
+	// a type nested in itself has the rules of its other fields
+	nested.Store(t, false)
+	has := reflect.PtrTo(t).Implements(reflect.TypeOf((*checker)(nil)).Elem())
+	for i := 0; i < t.NumField() && !has; i++ {
+		sf := t.Field(i)
+		has = sf.PkgPath == "" && (sf.Tag.Get("validate") != "" || hasRules(sf.Type))
+	}
+	nested.Store(t, has)
+	return has
+}
+
+// jsonName is the name of the field in the JSON of the request
+func jsonName(sf reflect.StructField) string {
+	if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return sf.Name
+}
+
+// indirect follows the pointers of the value, a nil one is the
+// invalid value
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// addr returns the pointer to the value, if it has one
+func addr(v reflect.Value) interface{} {
+	if !v.CanAddr() {
+		return nil
+	}
+	return v.Addr().Interface()
+}
@@ -0,0 +1,140 @@
+package requests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// takenValues is a Uniqueness of the values every column has taken,
+// but for the record of ownerID
+type takenValues struct {
+	ownerID uint
+	values  map[string]bool
+}
+
+func (tv takenValues) Taken(column string, value string, exceptID uint) bool {
+	return tv.values[column+":"+value] && exceptID != tv.ownerID
+}
+
+// fieldErrors returns the fields with errors of the request and their
+// messages in English
+func fieldErrors(request interface{}) map[string][]string {
+	if err, ok := ValidateRequest(request).(ValidationErrors); ok {
+		return err.FieldErrors("en")
+	}
+	return nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestValidateRequestNamesTheErrorsOfNestedFields(t *testing.T) {
+	br := &BulkRequest{Operations: []BulkOperation{
+		{Op: BulkComplete, ID: 1},
+		{Op: "archive"},
+		{Op: BulkUpdate, ID: 2},
+		{Op: BulkUpdate, ID: 3, Fields: &BulkFields{Title: stringPtr(""), DueAt: stringPtr(""), Estimate: intPtr(-1)}},
+	}}
+
+	assert.Equal(t, map[string][]string{
+		"operations.1.op":              {"The operations.1.op field must be one of complete, reopen, move, tag, delete, update"},
+		"operations.1.id":              {"The operations.1.id field is required"},
+		"operations.2.fields":          {"The operations.2.fields field is required when op is update"},
+		"operations.3.fields.title":    {"The operations.3.fields.title field must not be empty"},
+		"operations.3.fields.estimate": {"The operations.3.fields.estimate field value can not be less than 0"},
+	}, fieldErrors(br))
+}
+
+func TestValidateRequestChecksTheItemsOfLists(t *testing.T) {
+	tr := &TemplateRequest{Name: "Onboarding", Todos: []TemplateTodoRequest{
+		{Title: "Say hello", DueOffset: intPtr(0)},
+		{Priority: "asap", DueOffset: intPtr(-2)},
+	}}
+
+	errs := fieldErrors(tr)
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, "todos.1.title")
+	assert.Contains(t, errs, "todos.1.priority")
+	assert.Contains(t, errs, "todos.1.due_offset")
+
+	tr.Todos = make([]TemplateTodoRequest, 201)
+	assert.Equal(t, map[string][]string{"todos": {"The todos field may not have more than 200 items"}}, fieldErrors(tr))
+}
+
+func TestValidateRequestChecksTheRulesAcrossFields(t *testing.T) {
+	assert.Equal(t, map[string][]string{
+		"at": {"The at field is required when before is not given"},
+	}, fieldErrors(&ReminderRequest{Channel: "email"}))
+
+	// 0 minutes before is given, even though it is zero
+	assert.Nil(t, fieldErrors(&ReminderRequest{Channel: "email", Before: intPtr(0)}))
+	assert.Equal(t, map[string][]string{
+		"at": {"The at field can't be given along with before"},
+	}, fieldErrors(&ReminderRequest{Channel: "email", At: "2026-01-02 09:00", Before: intPtr(0)}))
+
+	assert.Equal(t, map[string][]string{
+		"target": {"The target field is required when channel is webhook"},
+	}, fieldErrors(&ReminderRequest{Channel: "webhook", Before: intPtr(10)}))
+
+	assert.Equal(t, map[string][]string{
+		"to": {"The to field must be after from"},
+	}, fieldErrors(&AuditQueryRequest{From: "2026-01-02", To: "2026-01-01"}))
+}
+
+func TestValidateRequestLooksUpUniqueValues(t *testing.T) {
+	RegisterUnique("users", takenValues{ownerID: 1, values: map[string]bool{"email:alice@realworld.io": true}})
+
+	rr := &RegisterRequest{Username: "alice", Name: "Alice", Email: "alice@realworld.io", Password: "secret-42"}
+	assert.Equal(t, map[string][]string{"email": {"The email has already been taken"}}, fieldErrors(rr))
+
+	// the email is alice's own
+	ur := &UserRequest{ID: 1, Username: "alice", Name: "Alice", Email: "alice@realworld.io"}
+	assert.Nil(t, fieldErrors(ur))
+
+	// an email longer than 20 characters is fine
+	rr.Email = "alice.liddell@wonderland.example.org"
+	assert.Nil(t, fieldErrors(rr))
+}
+
+func TestValidateRequestChecksTheStrengthOfPasswords(t *testing.T) {
+	for _, password := range []string{"secret", "secretive", "12345678"} {
+		assert.Equal(t, map[string][]string{
+			"password": {"The password field must be at least 8 characters and contain both letters and numbers"},
+		}, fieldErrors(&PasswordRequest{CurrentPassword: "secret", Password: password}), password)
+	}
+	assert.Nil(t, fieldErrors(&PasswordRequest{CurrentPassword: "secret", Password: "secret-42"}))
+}
+
+func TestValidateRequestChecksTheCustomRules(t *testing.T) {
+	type colorRequest struct {
+		Color string `json:"color" validate:"hexcolor"`
+	}
+
+	for _, color := range []string{"", "#f80", "#FF8800"} {
+		assert.Nil(t, fieldErrors(&colorRequest{Color: color}), color)
+	}
+	for _, color := range []string{"f80", "#ff880", "#gg8800"} {
+		assert.Equal(t, map[string][]string{
+			"color": {"The color field must be a hex color, like #ff8800"},
+		}, fieldErrors(&colorRequest{Color: color}), color)
+	}
+
+	errs := fieldErrors(&TodoRequest{Title: "Ship it", Timezone: "Mars/Olympus", Recurrence: "FREQ=SOMETIMES", DueAt: "tomorrow"})
+	assert.Equal(t, []string{"The timezone field must be a valid timezone"}, errs["timezone"])
+	assert.Equal(t, []string{"The recurrence field must be a valid recurrence rule"}, errs["recurrence"])
+	assert.Equal(t, []string{"The due date field must be a valid date and time"}, errs["due_at"])
+}
+
+func TestValidateRequestTranslatesTheOtherFields(t *testing.T) {
+	err := ValidateRequest(&SyncRequest{Changes: []SyncChange{{Resource: "todo", DeletedAt: nil}}})
+	if assert.IsType(t, ValidationErrors{}, err) {
+		errs := err.(ValidationErrors).FieldErrors("es")
+		assert.Equal(t, []string{"El campo changes.0.id es obligatorio cuando no se indica ID de cliente"}, errs["changes.0.id"])
+	}
+}
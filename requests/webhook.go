@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/i18n"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookRequest is the struct for creating and updating a webhook
+type WebhookRequest struct {
+	URL         string   `json:"url" form:"url" validate:"required|url|max:255"`
+	Events      []string `json:"events" form:"events" validate:"required"`
+	Description string   `json:"description" form:"description" validate:"max:255"`
+	Active      *bool    `json:"active" form:"active"`
+}
+
+// make sure to implement Request interface
+var _ Request = &WebhookRequest{}
+
+// Validate will validate the request with the given context
+func (wr *WebhookRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(wr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(wr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// check checks the events are known types, or * for every one
+func (wr *WebhookRequest) check(report func(field string, key string, args i18n.Args)) {
+	for _, e := range wr.Events {
+		if e != "*" && !isEventType(e) {
+			report("events", "validation.unknown_event", i18n.Args{"type": e})
+		}
+	}
+}
+
+// Fill copies the request data to the webhook
+func (wr *WebhookRequest) Fill(w *models.Webhook) {
+	w.URL = wr.URL
+	w.Events = strings.Join(wr.Events, ",")
+	w.Description = wr.Description
+	if wr.Active != nil {
+		w.Active = *wr.Active
+	}
+}
+
+// WebhookModel creates an active *models.Webhook of the user using request data
+func (wr *WebhookRequest) WebhookModel(userID uint, secret string) *models.Webhook {
+	w := &models.Webhook{UserID: userID, Secret: secret, Active: true}
+	wr.Fill(w)
+	return w
+}
+
+// isEventType determines if t is a known event type
+func isEventType(t string) bool {
+	for _, et := range events.Types {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WorkspaceRequest is the struct for creating and renaming a workspace
+type WorkspaceRequest struct {
+	Name string `json:"name" form:"name" validate:"required|max:100"`
+}
+
+// make sure to implement Request interface
+var _ Request = &WorkspaceRequest{}
+
+// Validate will validate the request with the given context
+func (wr *WorkspaceRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(wr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(wr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// InviteRequest is the struct for inviting someone to a workspace by
+// email, they join it as a member unless the role says otherwise
+type InviteRequest struct {
+	Email string `json:"email" form:"email" validate:"required|email|max:100"`
+	Role  string `json:"role" form:"role" validate:"in:admin,member"`
+}
+
+// make sure to implement Request interface
+var _ Request = &InviteRequest{}
+
+// Validate will validate the request with the given context
+func (ir *InviteRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(ir, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(ir); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	ir.Email = strings.ToLower(ir.Email)
+	if ir.Role == "" {
+		ir.Role = "member"
+	}
+	return http.StatusOK, nil
+}
+
+// RoleRequest is the struct for changing the role of a workspace member
+type RoleRequest struct {
+	Role string `json:"role" form:"role" validate:"required|in:owner,admin,member"`
+}
+
+// make sure to implement Request interface
+var _ Request = &RoleRequest{}
+
+// Validate will validate the request with the given context
+func (rr *RoleRequest) Validate(ctx echo.Context) (int, error) {
+	if code, err := BindRequest(rr, ctx); err != nil {
+		return code, err
+	}
+	if err := ValidateRequest(rr); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
@@ -1,7 +1,13 @@
 package router
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/ksungcaya/todo-echo/auth"
 	"github.com/ksungcaya/todo-echo/controllers"
+	"github.com/ksungcaya/todo-echo/idempotency"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
@@ -16,18 +22,272 @@ type Router struct {
 func New() *Router {
 	e := echo.New()
 	e.Logger.SetLevel(log.DEBUG)
+	e.HTTPErrorHandler = apperrors.Handler
 	e.Pre(middleware.RemoveTrailingSlash())
+	e.Pre(workspacePath)
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-		AllowMethods: []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
+		AllowOrigins:  []string{"*"},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXRequestID, "Last-Event-ID", "If-None-Match", "If-Match", idempotency.Header, auth.WorkspaceHeader},
+		AllowMethods:  []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
+		ExposeHeaders: []string{"ETag", idempotency.ReplayedHeader},
 	}))
 
 	return &Router{e}
 }
 
-// SetAuthRoutes define auth routes
-func (r *Router) SetAuthRoutes(ac *controllers.AuthController) {
+// SetAuthRoutes define auth routes, changing the password requires
+// authentication and registering can be retried with an idempotency key
+func (r *Router) SetAuthRoutes(ac *controllers.AuthController, authenticate echo.MiddlewareFunc, idempotent echo.MiddlewareFunc) {
+	g := r.Group("/auth")
+	g.POST("/login", ac.Login)
+	g.POST("/register", ac.Register, idempotent)
+	g.PUT("/password", ac.Password, authenticate)
+}
+
+// SetUserRoutes define the routes of the profile of the user, all of
+// them requires authentication
+func (r *Router) SetUserRoutes(uc *controllers.UserController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/user", authenticate)
+	g.GET("", uc.Show)
+	g.PUT("", uc.Update)
+	g.PATCH("", uc.Patch)
+}
+
+// SetTodoRoutes define todo routes, all of them requires authentication
+func (r *Router) SetTodoRoutes(tc *controllers.TodoController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/todos", authenticate)
+	g.GET("", tc.Index)
+	g.POST("", tc.Store)
+	g.GET("/:id", tc.Show)
+	g.PUT("/:id", tc.Update)
+	g.PATCH("/:id", tc.Patch)
+	g.DELETE("/:id", tc.Destroy)
+	g.POST("/:id/reminders", tc.StoreReminder)
+	g.DELETE("/:id/reminders/:reminder", tc.DestroyReminder)
+}
+
+// SetBulkRoutes define bulk routes, all of them requires authentication
+func (r *Router) SetBulkRoutes(bc *controllers.BulkController, authenticate echo.MiddlewareFunc) {
+	r.POST("/todos/bulk", bc.Store, authenticate)
+}
+
+// SetSyncRoutes define the sync route of offline clients, it requires authentication
+func (r *Router) SetSyncRoutes(sc *controllers.SyncController, authenticate echo.MiddlewareFunc) {
+	r.POST("/sync", sc.Sync, authenticate)
+}
+
+// SetAssigneeRoutes define todo assignee routes, all of them requires authentication
+func (r *Router) SetAssigneeRoutes(ac *controllers.AssigneeController, authenticate echo.MiddlewareFunc) {
+	r.GET("/todos/assigned", ac.Index, authenticate)
+	r.PUT("/todos/:id/assignees", ac.Update, authenticate)
+}
+
+// SetTagRoutes define todo tag routes, all of them requires authentication
+func (r *Router) SetTagRoutes(tc *controllers.TagController, authenticate echo.MiddlewareFunc) {
+	r.PUT("/todos/:id/tags", tc.Update, authenticate)
+}
+
+// SetDependencyRoutes define todo dependency routes, all of them requires authentication
+func (r *Router) SetDependencyRoutes(dc *controllers.DependencyController, authenticate echo.MiddlewareFunc) {
+	r.GET("/todos/ready", dc.Ready, authenticate)
+	r.POST("/todos/:id/blockers", dc.Store, authenticate)
+	r.DELETE("/todos/:id/blockers/:blocker", dc.Destroy, authenticate)
+}
+
+// SetTimeRoutes define time tracking routes, all of them requires authentication
+func (r *Router) SetTimeRoutes(tc *controllers.TimeController, authenticate echo.MiddlewareFunc) {
+	r.GET("/timer", tc.Current, authenticate)
+	r.POST("/timer/stop", tc.Stop, authenticate)
+	r.POST("/todos/:id/timer", tc.Start, authenticate)
+	r.GET("/todos/:id/time-entries", tc.Index, authenticate)
+	r.GET("/time/report", tc.Report, authenticate)
+}
+
+// SetCommentRoutes define todo comment routes, all of them requires authentication
+func (r *Router) SetCommentRoutes(cc *controllers.CommentController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/todos/:id/comments", authenticate)
+	g.GET("", cc.Index)
+	g.POST("", cc.Store)
+	g.PUT("/:comment", cc.Update)
+	g.DELETE("/:comment", cc.Destroy)
+}
+
+// SetAttachmentRoutes define todo attachment routes. Downloads are
+// authorized by the signature of their URL instead of authentication.
+func (r *Router) SetAttachmentRoutes(ac *controllers.AttachmentController, authenticate echo.MiddlewareFunc) {
+	r.GET("/attachments/:id", ac.Download)
+
+	g := r.Group("/todos/:id/attachments", authenticate)
+	g.GET("", ac.Index)
+	g.POST("", ac.Store)
+	g.DELETE("/:attachment", ac.Destroy)
+}
+
+// SetListRoutes define todo list routes, all of them requires authentication
+func (r *Router) SetListRoutes(lc *controllers.ListController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/lists", authenticate)
+	g.GET("", lc.Index)
+	g.POST("", lc.Store)
+	g.GET("/:id", lc.Show)
+	g.PUT("/:id", lc.Update)
+	g.DELETE("/:id", lc.Destroy)
+}
+
+// SetMemberRoutes define list member routes, all of them requires authentication
+func (r *Router) SetMemberRoutes(mc *controllers.MemberController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/lists/:id/members", authenticate)
+	g.GET("", mc.Index)
+	g.POST("", mc.Store)
+	g.DELETE("/:user", mc.Destroy)
+}
+
+// SetColumnRoutes define board routes, all of them requires authentication
+func (r *Router) SetColumnRoutes(cc *controllers.ColumnController, authenticate echo.MiddlewareFunc) {
+	r.GET("/lists/:id/board", cc.Board, authenticate)
+	r.POST("/todos/:id/move", cc.MoveTodo, authenticate)
+
+	g := r.Group("/lists/:id/columns", authenticate)
+	g.POST("", cc.Store)
+	g.PUT("/:column", cc.Update)
+	g.DELETE("/:column", cc.Destroy)
+	g.POST("/:column/move", cc.Move)
+}
+
+// SetTemplateRoutes define template routes, all of them requires authentication
+func (r *Router) SetTemplateRoutes(tc *controllers.TemplateController, authenticate echo.MiddlewareFunc) {
+	r.POST("/lists/:id/template", tc.Capture, authenticate)
+
+	g := r.Group("/templates", authenticate)
+	g.GET("", tc.Index)
+	g.POST("", tc.Store)
+	g.GET("/:id", tc.Show)
+	g.PUT("/:id", tc.Update)
+	g.DELETE("/:id", tc.Destroy)
+	g.POST("/:id/instantiate", tc.Instantiate)
+}
+
+// SetTrashRoutes define trash routes, all of them requires authentication
+func (r *Router) SetTrashRoutes(tc *controllers.TrashController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/trash", authenticate)
+	g.GET("", tc.Index)
+	g.DELETE("", tc.Empty)
+	g.POST("/todos/:id/restore", tc.RestoreTodo)
+	g.DELETE("/todos/:id", tc.DestroyTodo)
+	g.POST("/lists/:id/restore", tc.RestoreList)
+	g.DELETE("/lists/:id", tc.DestroyList)
+}
+
+// SetJournalRoutes define undo and redo routes, all of them requires authentication
+func (r *Router) SetJournalRoutes(jc *controllers.JournalController, authenticate echo.MiddlewareFunc) {
+	r.GET("/journal", jc.Index, authenticate)
+	r.POST("/undo", jc.Undo, authenticate)
+	r.POST("/redo", jc.Redo, authenticate)
+}
+
+// SetActivityRoutes define activity feed routes, all of them requires authentication
+func (r *Router) SetActivityRoutes(ac *controllers.ActivityController, authenticate echo.MiddlewareFunc) {
+	r.GET("/activity", ac.Index, authenticate)
+	r.GET("/lists/:id/activity", ac.List, authenticate)
+}
+
+// SetTransferRoutes define todo import and export routes, all of them requires authentication
+func (r *Router) SetTransferRoutes(xc *controllers.TransferController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/todos", authenticate)
+	g.GET("/export", xc.Export)
+	g.POST("/import", xc.Import)
+}
+
+// SetCalendarRoutes define calendar feed routes. The feed itself is
+// fetched by calendar apps, the secret token in its URL authorizes it.
+func (r *Router) SetCalendarRoutes(cc *controllers.CalendarController, authenticate echo.MiddlewareFunc) {
+	r.GET("/calendar/:token", cc.Feed)
+
+	g := r.Group("/calendar/feeds", authenticate)
+	g.GET("", cc.Index)
+	g.POST("", cc.Store)
+	g.DELETE("/:id", cc.Destroy)
+}
+
+// SetNotificationRoutes define notification inbox routes, all of them requires authentication
+func (r *Router) SetNotificationRoutes(nc *controllers.NotificationController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/notifications", authenticate)
+	g.GET("", nc.Index)
+	g.POST("/read-all", nc.ReadAll)
+	g.POST("/:id/read", nc.Read)
+	g.GET("/preferences", nc.Preferences)
+	g.PUT("/preferences", nc.UpdatePreferences)
+}
+
+// SetEventRoutes define real-time event routes, all of them requires
+// authentication which may be given by the access_token query param
+func (r *Router) SetEventRoutes(ec *controllers.EventController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/events", auth.QueryToken, authenticate)
+	g.GET("", ec.Stream)
+	g.GET("/ws", ec.Socket)
+}
+
+// SetWebhookRoutes define webhook routes, all of them requires authentication
+func (r *Router) SetWebhookRoutes(wc *controllers.WebhookController, authenticate echo.MiddlewareFunc) {
+	g := r.Group("/webhooks", authenticate)
+	g.GET("", wc.Index)
+	g.POST("", wc.Store)
+	g.GET("/:id", wc.Show)
+	g.PUT("/:id", wc.Update)
+	g.DELETE("/:id", wc.Destroy)
+	g.GET("/:id/deliveries", wc.Deliveries)
+	g.POST("/:id/deliveries/:delivery/redeliver", wc.Redeliver)
+}
+
+// SetAdminRoutes define admin routes, all of them requires an authenticated admin
+func (r *Router) SetAdminRoutes(ac *controllers.AuditController, authenticate echo.MiddlewareFunc, admin echo.MiddlewareFunc) {
+	g := r.Group("/admin", authenticate, admin)
+	g.GET("/audit", ac.Index)
+	g.GET("/audit/verify", ac.Verify)
+}
+
+// SetWorkspaceRoutes define workspace routes, listing and creating
+// workspaces and accepting invites only requires an authenticated
+// user, the others are made in the workspace of their path
+func (r *Router) SetWorkspaceRoutes(wc *controllers.WorkspaceController, user echo.MiddlewareFunc, authenticate echo.MiddlewareFunc) {
+	r.GET("/workspaces", wc.Index, user)
+	r.POST("/workspaces", wc.Store, user)
+	r.POST("/invites/:token/accept", wc.Accept, user)
+
+	g := r.Group("/workspaces/:workspace", authenticate)
+	g.GET("", wc.Show)
+	g.PUT("", wc.Update)
+	g.GET("/members", wc.Members)
+	g.PUT("/members/:user", wc.UpdateMember)
+	g.DELETE("/members/:user", wc.DestroyMember)
+	g.GET("/invites", wc.Invites)
+	g.POST("/invites", wc.StoreInvite)
+	g.DELETE("/invites/:invite", wc.DestroyInvite)
+}
+
+// workspacePath lets every route be reached within a workspace, e.g.
+// /workspaces/2/todos is /todos in the workspace 2 as if it was named
+// by the X-Workspace-ID header. The routes of the workspace itself
+// are left alone.
+func workspacePath(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		if !strings.HasPrefix(req.URL.Path, "/workspaces/") {
+			return next(ctx)
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/workspaces/"), "/", 3)
+		if len(parts) < 2 || parts[1] == "members" || parts[1] == "invites" {
+			return next(ctx)
+		}
+		if _, err := strconv.ParseUint(parts[0], 10, 64); err != nil {
+			return next(ctx)
+		}
 
+		req.Header.Set(auth.WorkspaceHeader, parts[0])
+		req.URL.Path = "/" + strings.Join(parts[1:], "/")
+		req.URL.RawPath = ""
+		return next(ctx)
+	}
 }
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/storage"
+	"github.com/labstack/gommon/log"
+)
+
+// AttachmentCleaner deletes the files attached to todos which were
+// permanently deleted, e.g. when the trash was purged
+type AttachmentCleaner struct {
+	ar        repositories.AttachmentRepository
+	store     storage.Storage
+	batchSize int
+}
+
+// NewAttachmentCleaner creates AttachmentCleaner instance which
+// cleans up batchSize attachments at a time
+func NewAttachmentCleaner(ar repositories.AttachmentRepository, store storage.Storage, batchSize int) *AttachmentCleaner {
+	return &AttachmentCleaner{ar, store, batchSize}
+}
+
+// Clean deletes the files of the orphaned attachments along with
+// them. It has the signature of a scheduler job.
+func (c *AttachmentCleaner) Clean(ctx context.Context, now time.Time) {
+	cleaned := 0
+	for ctx.Err() == nil {
+		attachments := c.ar.Orphaned(c.batchSize)
+		for _, a := range attachments {
+			// the attachment is kept until its file is gone, so the
+			// next run tries again
+			if err := c.store.Delete(ctx, a.StorageKey); err != nil {
+				log.Errorf("attachments: deleting %s: %v", a.StorageKey, err)
+				return
+			}
+			if err := c.ar.Delete(a.ID); err != nil {
+				log.Errorf("attachments: deleting attachment %d: %v", a.ID, err)
+				return
+			}
+			cleaned++
+		}
+		if len(attachments) < c.batchSize {
+			break
+		}
+	}
+
+	if cleaned > 0 {
+		log.Infof("attachments: cleaned up %d orphaned files", cleaned)
+	}
+}
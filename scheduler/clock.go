@@ -0,0 +1,18 @@
+package scheduler
+
+import "time"
+
+// Clock tells the scheduler what time it is, so tests can control it
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a task which is run periodically
+type Job func(ctx context.Context, now time.Time)
+
+// Every runs the job right away and then on every interval until
+// ctx is done. It is meant to be run in its own goroutine.
+func Every(ctx context.Context, clock Clock, interval time.Duration, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job(ctx, clock.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/gommon/log"
+)
+
+// Scheduler polls for due reminders and delivers them through
+// the notifier registered for their channel.
+type Scheduler struct {
+	config    configs.SchedulerConfig
+	clock     Clock
+	owner     string
+	reminders repositories.ReminderRepository
+	todos     repositories.TodoRepository
+	users     repositories.UserRepository
+	notifiers map[string]notifiers.Notifier
+}
+
+// New creates Scheduler instance
+func New(
+	config configs.SchedulerConfig,
+	clock Clock,
+	rr repositories.ReminderRepository,
+	tr repositories.TodoRepository,
+	ur repositories.UserRepository,
+) *Scheduler {
+	host, _ := os.Hostname()
+
+	return &Scheduler{
+		config:    config,
+		clock:     clock,
+		owner:     fmt.Sprintf("%s-%d", host, os.Getpid()),
+		reminders: rr,
+		todos:     tr,
+		users:     ur,
+		notifiers: make(map[string]notifiers.Notifier),
+	}
+}
+
+// Register sets the notifier used for the reminder channel
+func (s *Scheduler) Register(channel string, n notifiers.Notifier) {
+	s.notifiers[channel] = n
+}
+
+// Start polls every configured interval until ctx is done.
+// It is meant to be run in its own goroutine.
+func (s *Scheduler) Start(ctx context.Context) {
+	Every(ctx, s.clock, s.config.Interval, func(ctx context.Context, now time.Time) {
+		s.Tick(ctx)
+	})
+}
+
+// Tick claims the reminders due at the clock's current time and
+// delivers them. It returns the number of reminders delivered.
+func (s *Scheduler) Tick(ctx context.Context) int {
+	now := s.clock.Now().UTC()
+
+	reminders, err := s.reminders.Claim(s.owner, now, s.config.Lease, s.config.BatchSize)
+	if err != nil {
+		log.Errorf("scheduler: claiming reminders: %v", err)
+	}
+
+	sent := 0
+	for i := range reminders {
+		r := &reminders[i]
+		if err := s.deliver(ctx, r); err != nil {
+			s.fail(r, now, err)
+			continue
+		}
+		if err := s.reminders.MarkSent(r, now); err != nil {
+			log.Errorf("scheduler: marking reminder %d as sent: %v", r.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent
+}
+
+// deliver sends the reminder. Reminders of todos which have
+// been completed or deleted are dropped without notifying.
+func (s *Scheduler) deliver(ctx context.Context, r *models.Reminder) error {
+	todo := s.todos.ByID(r.TodoID)
+	if todo == nil || todo.Completed {
+		return nil
+	}
+
+	user := s.users.ByID(todo.UserID)
+	if user == nil {
+		return nil
+	}
+
+	n, ok := s.notifiers[r.Channel]
+	if !ok {
+		return fmt.Errorf("no notifier registered for channel %q", r.Channel)
+	}
+
+	return n.Notify(ctx, notifiers.Message{User: user, Todo: todo, Reminder: r})
+}
+
+// fail schedules another attempt with an exponential backoff,
+// or gives up once the reminder ran out of attempts.
+func (s *Scheduler) fail(r *models.Reminder, now time.Time, cause error) {
+	attempts := r.Attempts + 1
+
+	var err error
+	if attempts >= s.config.MaxAttempts {
+		err = s.reminders.MarkFailed(r, cause.Error(), now)
+	} else {
+		retryAt := now.Add(s.config.Interval * time.Duration(1<<uint(attempts)))
+		err = s.reminders.Retry(r, cause.Error(), retryAt)
+	}
+
+	if err != nil {
+		log.Errorf("scheduler: releasing reminder %d: %v", r.ID, err)
+	}
+}
@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/notifiers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// fakeClock always tells the same time
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// fakeNotifier records the messages it was asked to deliver
+type fakeNotifier struct {
+	err  error
+	sent []notifiers.Message
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, m notifiers.Message) error {
+	n.sent = append(n.sent, m)
+	return n.err
+}
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	clock     *fakeClock
+	reminders *mocks.ReminderRepository
+	todos     *mocks.TodoRepository
+	users     *mocks.UserRepository
+	notifier  *fakeNotifier
+	scheduler *Scheduler
+	user      *models.User
+	todo      *models.Todo
+	reminder  models.Reminder
+}
+
+var config = configs.SchedulerConfig{
+	Interval:    time.Minute,
+	Lease:       2 * time.Minute,
+	BatchSize:   10,
+	MaxAttempts: 3,
+}
+
+func (suite *SchedulerTestSuite) SetupTest() {
+	suite.clock = &fakeClock{time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)}
+	suite.reminders = &mocks.ReminderRepository{}
+	suite.todos = &mocks.TodoRepository{}
+	suite.users = &mocks.UserRepository{}
+	suite.notifier = &fakeNotifier{}
+
+	suite.scheduler = New(config, suite.clock, suite.reminders, suite.todos, suite.users)
+	suite.scheduler.Register(models.ReminderEmail, suite.notifier)
+
+	suite.user = &models.User{Model: gorm.Model{ID: 1}, Email: "alice@realworld.io"}
+	suite.todo = &models.Todo{Model: gorm.Model{ID: 2}, UserID: 1, Title: "Ship release"}
+	suite.reminder = models.Reminder{Model: gorm.Model{ID: 3}, TodoID: 2, Channel: models.ReminderEmail}
+
+	suite.todos.On("ByID", uint(2)).Return(suite.todo)
+	suite.users.On("ByID", uint(1)).Return(suite.user)
+}
+
+func (suite *SchedulerTestSuite) claims(reminders ...models.Reminder) {
+	now := suite.clock.now
+	suite.reminders.On("Claim", suite.scheduler.owner, now, config.Lease, config.BatchSize).Return(reminders, nil)
+}
+
+func (suite *SchedulerTestSuite) TestTickDeliversDueReminders() {
+	assert := assert.New(suite.T())
+
+	suite.claims(suite.reminder)
+	suite.reminders.On("MarkSent", &suite.reminder, suite.clock.now).Return(nil)
+
+	assert.Equal(1, suite.scheduler.Tick(context.Background()))
+
+	suite.reminders.AssertCalled(suite.T(), "MarkSent", &suite.reminder, suite.clock.now)
+	if assert.Len(suite.notifier.sent, 1) {
+		msg := suite.notifier.sent[0]
+		assert.Equal(suite.user, msg.User)
+		assert.Equal(suite.todo, msg.Todo)
+		assert.Equal(uint(3), msg.Reminder.ID)
+	}
+}
+
+func (suite *SchedulerTestSuite) TestTickUsesTheClock() {
+	assert := assert.New(suite.T())
+
+	suite.clock.now = suite.clock.now.Add(time.Hour)
+	suite.claims()
+
+	assert.Equal(0, suite.scheduler.Tick(context.Background()))
+	suite.reminders.AssertCalled(suite.T(), "Claim", suite.scheduler.owner, suite.clock.now, config.Lease, config.BatchSize)
+}
+
+func (suite *SchedulerTestSuite) TestTickSkipsCompletedTodos() {
+	assert := assert.New(suite.T())
+
+	suite.todo.Completed = true
+	suite.claims(suite.reminder)
+	suite.reminders.On("MarkSent", &suite.reminder, suite.clock.now).Return(nil)
+
+	suite.scheduler.Tick(context.Background())
+
+	assert.Empty(suite.notifier.sent)
+	suite.reminders.AssertCalled(suite.T(), "MarkSent", &suite.reminder, suite.clock.now)
+}
+
+func (suite *SchedulerTestSuite) TestTickRetriesFailedDeliveriesWithBackoff() {
+	assert := assert.New(suite.T())
+
+	suite.notifier.err = errors.New("smtp is down")
+	suite.reminder.Attempts = 1
+	suite.claims(suite.reminder)
+	suite.reminders.On("Retry", &suite.reminder, "smtp is down", mock.Anything).Return(nil)
+
+	assert.Equal(0, suite.scheduler.Tick(context.Background()))
+
+	// second attempt, waits 2^2 intervals
+	suite.reminders.AssertCalled(suite.T(), "Retry", &suite.reminder, "smtp is down", suite.clock.now.Add(4*time.Minute))
+	suite.reminders.AssertNotCalled(suite.T(), "MarkSent", mock.Anything, mock.Anything)
+}
+
+func (suite *SchedulerTestSuite) TestTickGivesUpAfterMaxAttempts() {
+	suite.notifier.err = errors.New("smtp is down")
+	suite.reminder.Attempts = config.MaxAttempts - 1
+	suite.claims(suite.reminder)
+	suite.reminders.On("MarkFailed", &suite.reminder, "smtp is down", suite.clock.now).Return(nil)
+
+	suite.scheduler.Tick(context.Background())
+
+	suite.reminders.AssertCalled(suite.T(), "MarkFailed", &suite.reminder, "smtp is down", suite.clock.now)
+}
+
+func (suite *SchedulerTestSuite) TestTickWithoutNotifierForChannel() {
+	suite.reminder.Channel = models.ReminderWebhook
+	suite.claims(suite.reminder)
+	suite.reminders.On("Retry", &suite.reminder, mock.Anything, mock.Anything).Return(nil)
+
+	suite.scheduler.Tick(context.Background())
+
+	suite.reminders.AssertCalled(suite.T(), "Retry", &suite.reminder, `no notifier registered for channel "webhook"`, mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
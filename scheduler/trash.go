@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/labstack/gommon/log"
+)
+
+// TrashPurger permanently deletes the todos and lists which
+// have been in the trash for longer than the retention.
+type TrashPurger struct {
+	tr        repositories.TodoRepository
+	lr        repositories.ListRepository
+	retention time.Duration
+}
+
+// NewTrashPurger creates TrashPurger instance
+func NewTrashPurger(tr repositories.TodoRepository, lr repositories.ListRepository, retention time.Duration) *TrashPurger {
+	return &TrashPurger{tr, lr, retention}
+}
+
+// Purge permanently deletes the expired items in the trash.
+// It has the signature of a scheduler job.
+func (p *TrashPurger) Purge(ctx context.Context, now time.Time) {
+	before := now.Add(-p.retention)
+
+	lists, err := p.lr.Purge(before)
+	if err != nil {
+		log.Errorf("trash: purging lists: %v", err)
+	}
+	todos, err := p.tr.Purge(before)
+	if err != nil {
+		log.Errorf("trash: purging todos: %v", err)
+	}
+
+	if lists > 0 || todos > 0 {
+		log.Infof("trash: purged %d lists and %d todos", lists, todos)
+	}
+}
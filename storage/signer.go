@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// URLSigner signs the download URLs of blobs, so they can be fetched
+// without authentication until they expire
+type URLSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewURLSigner creates URLSigner instance whose signatures, made
+// with the key, are valid for the ttl
+func NewURLSigner(key string, ttl time.Duration) *URLSigner {
+	return &URLSigner{[]byte(key), ttl}
+}
+
+// Sign signs the download of the resource from now on,
+// returning when the signature expires along with it
+func (s *URLSigner) Sign(resource string, now time.Time) (time.Time, string) {
+	expires := now.Add(s.ttl).Truncate(time.Second)
+	return expires, s.signature(resource, expires.Unix())
+}
+
+// Verify determines if the signature of the resource was made by
+// the signer and didn't expire at now yet
+func (s *URLSigner) Verify(resource string, expires int64, signature string, now time.Time) bool {
+	if now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.signature(resource, expires)))
+}
+
+// signature is the signature of the resource until the expiration
+func (s *URLSigner) signature(resource string, expires int64) string {
+	return hex.EncodeToString(hmacSHA256(s.key, resource+"\n"+strconv.FormatInt(expires, 10)))
+}
@@ -0,0 +1,46 @@
+// Package storage keeps the blobs of the app, e.g. the files attached
+// to todos, behind the Storage interface. Local keeps them on the
+// filesystem, S3 in a bucket of any S3 compatible service.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when there's no blob with the key
+	ErrNotFound = errors.New("blob not found")
+
+	// ErrInvalidKey is returned for keys which could escape the storage,
+	// keys are relative slash separated paths
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Storage stores blobs by key
+type Storage interface {
+	// Put stores the size bytes of r under the key, replacing any blob it had
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the blob of the key, the caller has to close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob of the key, deleting a missing blob is no error
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey determines if the key is a relative path without
+// empty, "." or ".." segments
+func validKey(key string) bool {
+	if key == "" || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -3,8 +3,20 @@ package test
 import (
 	"encoding/json"
 	"net/http/httptest"
+
+	"github.com/ksungcaya/todo-echo/apperrors"
+	"github.com/labstack/echo/v4"
 )
 
+// Serve is a helper function to call the handler like the server does,
+// responding with the problem of the error it returns
+func Serve(ctx echo.Context, h echo.HandlerFunc) error {
+	if err := h(ctx); err != nil {
+		apperrors.Handler(err, ctx)
+	}
+	return nil
+}
+
 // GetResponseData is a helper function to get data from JSON response
 func GetResponseData(response *httptest.ResponseRecorder) map[string]interface{} {
 	return GetResponse(response, "data")
@@ -15,6 +27,15 @@ func GetResponseErrors(response *httptest.ResponseRecorder) map[string]interface
 	return GetResponse(response, "errors")
 }
 
+// GetResponseProblem is a helper function to get the problem of an
+// error response
+func GetResponseProblem(response *httptest.ResponseRecorder) map[string]interface{} {
+	var problem map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &problem)
+
+	return problem
+}
+
 // GetResponse is a helper function to get a key from JSON response
 func GetResponse(response *httptest.ResponseRecorder, key string) map[string]interface{} {
 	var data map[string]interface{}
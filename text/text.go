@@ -0,0 +1,23 @@
+// Package text holds the helpers of the strings which are stored,
+// e.g. in columns of a limited length.
+package text
+
+import "unicode/utf8"
+
+// Truncate cuts s down to at most n characters. It never splits a
+// character, so the result stays valid UTF-8 which strict databases
+// accept, and a varchar(n) column holds n characters of it.
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j]
+		}
+		i++
+	}
+	return s
+}
@@ -0,0 +1,19 @@
+package text
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "trunc", Truncate("truncated", 5))
+
+	// characters of several bytes are kept whole
+	cut := Truncate("año más 🎉 fin", 9)
+	assert.Equal(t, "año más 🎉", cut)
+	assert.True(t, utf8.ValidString(cut))
+	assert.Equal(t, "", Truncate("ñ", 0))
+}
@@ -0,0 +1,141 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns are the columns written to a CSV export
+var csvColumns = []string{"title", "description", "completed", "due_at", "timezone", "recurrence"}
+
+// csvAliases are other names tools give to the columns
+var csvAliases = map[string]string{
+	"name":  "title",
+	"task":  "title",
+	"notes": "description",
+	"done":  "completed",
+	"due":   "due_at",
+}
+
+// ErrCSVHeader is returned when a CSV file has no title column
+var ErrCSVHeader = errors.New("The first row of the CSV file must name its columns, including title")
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	e := &csvEncoder{csv.NewWriter(w)}
+	e.w.Write(csvColumns)
+	return e
+}
+
+// Encode writes the record as a row
+func (e *csvEncoder) Encode(r *Record) error {
+	return e.w.Write([]string{
+		r.Title,
+		r.Description,
+		strconv.FormatBool(r.Completed),
+		r.DueAt,
+		r.Timezone,
+		r.Recurrence,
+	})
+}
+
+// Close flushes the rows which are still buffered
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVDecoder(r io.Reader) *csvDecoder {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvDecoder{r: cr}
+}
+
+// Next reads the next row. The columns are looked up by the
+// names in the first row, so they may come in any order and
+// unknown ones are ignored.
+func (d *csvDecoder) Next() (*Record, error) {
+	if d.columns == nil {
+		if err := d.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := d.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	d.row++
+
+	value := func(column string) string {
+		if i, ok := d.columns[column]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	r := &Record{
+		Row:         d.row,
+		Title:       strings.TrimSpace(value("title")),
+		Description: value("description"),
+		DueAt:       strings.TrimSpace(value("due_at")),
+		Timezone:    strings.TrimSpace(value("timezone")),
+		Recurrence:  strings.TrimSpace(value("recurrence")),
+	}
+	completed, ok := parseBool(value("completed"))
+	if !ok {
+		r.invalid("completed", "The completed field must be true or false")
+	}
+	r.Completed = completed
+
+	return r, nil
+}
+
+// readHeader maps the column names of the first row
+func (d *csvDecoder) readHeader() error {
+	header, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	d.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := csvAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := d.columns[name]; !ok {
+			d.columns[name] = i
+		}
+	}
+	if _, ok := d.columns["title"]; !ok {
+		return ErrCSVHeader
+	}
+	return nil
+}
+
+// parseBool parses the ways spreadsheets tend to mark a checkbox
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "n":
+		return false, true
+	case "true", "1", "yes", "y", "x", "done":
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,42 @@
+package transfer
+
+import (
+	"io"
+
+	"github.com/ksungcaya/todo-echo/repositories"
+)
+
+// Exporter writes todos to files
+type Exporter struct {
+	tr repositories.TodoRepository
+}
+
+// NewExporter creates an Exporter
+func NewExporter(tr repositories.TodoRepository) *Exporter {
+	return &Exporter{tr}
+}
+
+// Workspace returns an Exporter of the todos in the workspace
+func (ex *Exporter) Workspace(id uint) *Exporter {
+	return &Exporter{ex.tr.Workspace(id)}
+}
+
+// Export writes the todos of the user to w in the format
+func (ex *Exporter) Export(userID uint, format Format, w io.Writer) error {
+	enc, err := NewEncoder(format, w)
+	if err != nil {
+		return err
+	}
+	return ex.Encode(userID, enc)
+}
+
+// Encode writes the todos of the user with the encoder
+func (ex *Exporter) Encode(userID uint, enc Encoder) error {
+	todos := ex.tr.ByUser(userID)
+	for i := range todos {
+		if err := enc.Encode(NewRecord(&todos[i])); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
@@ -0,0 +1,141 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a file format todos can be imported from and exported to
+type Format string
+
+// Supported formats
+const (
+	FormatCSV       Format = "csv"
+	FormatJSON      Format = "json"
+	FormatTodoTxt   Format = "todotxt"
+	FormatMarkdown  Format = "markdown"
+	FormatICalendar Format = "ics"
+)
+
+// ErrUnknownFormat is returned when a format is not supported
+var ErrUnknownFormat = errors.New("The format must be one of csv, json, todotxt, markdown or ics")
+
+// Encoder writes records to a file one at a time. Close must
+// be called once all of them were written.
+type Encoder interface {
+	Encode(r *Record) error
+	Close() error
+}
+
+// Decoder reads records from a file one at a time. It returns
+// io.EOF once there are no more records.
+type Decoder interface {
+	Next() (*Record, error)
+}
+
+// ParseFormat looks up a format by its name or a common alias
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "csv":
+		return FormatCSV, nil
+	case "json":
+		return FormatJSON, nil
+	case "todotxt", "todo.txt", "txt":
+		return FormatTodoTxt, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "ics", "ical", "icalendar":
+		return FormatICalendar, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FormatFromFilename guesses the format from a file's extension
+func FormatFromFilename(name string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// FormatFromContentType guesses the format from a media type
+func FormatFromContentType(contentType string) (Format, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/json":
+		return FormatJSON, nil
+	case "text/plain":
+		return FormatTodoTxt, nil
+	case "text/markdown":
+		return FormatMarkdown, nil
+	case "text/calendar":
+		return FormatICalendar, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=UTF-8"
+	case FormatJSON:
+		return "application/json; charset=UTF-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=UTF-8"
+	case FormatICalendar:
+		return "text/calendar; charset=UTF-8"
+	}
+	return "text/plain; charset=UTF-8"
+}
+
+// Filename returns the file name exports of the format are saved as
+func (f Format) Filename() string {
+	switch f {
+	case FormatCSV:
+		return "todos.csv"
+	case FormatJSON:
+		return "todos.json"
+	case FormatMarkdown:
+		return "todos.md"
+	case FormatICalendar:
+		return "todos.ics"
+	}
+	return "todo.txt"
+}
+
+// NewEncoder creates an Encoder of the format writing to w
+func NewEncoder(f Format, w io.Writer) (Encoder, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatJSON:
+		return newJSONEncoder(w), nil
+	case FormatTodoTxt:
+		return newTodoTxtEncoder(w), nil
+	case FormatMarkdown:
+		return newMarkdownEncoder(w), nil
+	case FormatICalendar:
+		return NewICalendarEncoder(w, ComponentTodo), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// NewDecoder creates a Decoder of the format reading from r
+func NewDecoder(f Format, r io.Reader) (Decoder, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVDecoder(r), nil
+	case FormatJSON:
+		return newJSONDecoder(r), nil
+	case FormatTodoTxt:
+		return newTodoTxtDecoder(r), nil
+	case FormatMarkdown:
+		return newMarkdownDecoder(r), nil
+	case FormatICalendar:
+		return newICalendarDecoder(r), nil
+	}
+	return nil, ErrUnknownFormat
+}
@@ -0,0 +1,220 @@
+package transfer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// exportTodos encodes the todos in the format
+func exportTodos(t *testing.T, format Format, todos ...*models.Todo) string {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(format, &buf)
+	require.NoError(t, err)
+	for _, todo := range todos {
+		require.NoError(t, enc.Encode(NewRecord(todo)))
+	}
+	require.NoError(t, enc.Close())
+	return buf.String()
+}
+
+// decodeAll decodes all the records of the file
+func decodeAll(t *testing.T, format Format, file string) []*Record {
+	dec, err := NewDecoder(format, strings.NewReader(file))
+	require.NoError(t, err)
+
+	var records []*Record
+	for {
+		r, err := dec.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, r)
+	}
+}
+
+func TestJSONRoundTripIsLossless(t *testing.T) {
+	due := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	at := due.Add(-24 * time.Hour)
+	sent := at.Add(time.Minute)
+	before := 15
+	todo := &models.Todo{
+		Model:       gorm.Model{ID: 1},
+		Title:       "Pay rent",
+		Description: "Transfer to the landlord\nReference: March",
+		Completed:   true,
+		DueAt:       &due,
+		Timezone:    "Europe/Paris",
+		Recurrence:  "FREQ=MONTHLY;BYMONTHDAY=1",
+		Reminders: []models.Reminder{
+			{Channel: models.ReminderEmail, OffsetMinutes: &before},
+			{Channel: models.ReminderWebhook, Target: "https://example.com/hook", RemindAt: &at, SentAt: &sent},
+		},
+	}
+
+	records := decodeAll(t, FormatJSON, exportTodos(t, FormatJSON, todo))
+	require.Len(t, records, 1)
+	assert.Nil(t, records[0].Validate())
+
+	imported := records[0].Todo(2)
+	assert.Equal(t, uint(2), imported.UserID)
+	assert.Equal(t, todo.Title, imported.Title)
+	assert.Equal(t, todo.Description, imported.Description)
+	assert.True(t, imported.Completed)
+	assert.Equal(t, "Europe/Paris", imported.Timezone)
+	assert.Equal(t, todo.Recurrence, imported.Recurrence)
+	assert.True(t, due.Equal(*imported.DueAt))
+
+	require.Len(t, imported.Reminders, 2)
+	assert.Equal(t, before, *imported.Reminders[0].OffsetMinutes)
+	assert.True(t, due.Add(-15*time.Minute).Equal(*imported.Reminders[0].FireAt))
+	assert.Equal(t, "https://example.com/hook", imported.Reminders[1].Target)
+	assert.True(t, at.Equal(*imported.Reminders[1].RemindAt))
+	assert.True(t, sent.Equal(*imported.Reminders[1].SentAt))
+}
+
+func TestJSONReportsInvalidTypesPerRow(t *testing.T) {
+	records := decodeAll(t, FormatJSON, `[{"title": "Valid"}, {"title": "Oops", "completed": "maybe"}, "nope"]`)
+
+	require.Len(t, records, 3)
+	assert.Nil(t, records[0].Validate())
+	assert.Contains(t, records[1].Validate().Errors, "completed")
+	assert.Contains(t, records[2].Validate().Errors, "todo")
+}
+
+func TestJSONRequiresAnArray(t *testing.T) {
+	dec, _ := NewDecoder(FormatJSON, strings.NewReader(`{"title": "Pay rent"}`))
+
+	_, err := dec.Next()
+	assert.Equal(t, ErrJSONArray, err)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	todo := &models.Todo{Title: "Pay rent, on time", Description: "Line one\nLine two", DueAt: &due, Timezone: "Asia/Manila"}
+
+	file := exportTodos(t, FormatCSV, todo)
+	assert.True(t, strings.HasPrefix(file, "title,description,completed,due_at,timezone,recurrence\n"))
+
+	records := decodeAll(t, FormatCSV, file)
+	require.Len(t, records, 1)
+	assert.Equal(t, 1, records[0].Row)
+	imported := records[0].Todo(1)
+	assert.Equal(t, todo.Title, imported.Title)
+	assert.Equal(t, todo.Description, imported.Description)
+	assert.True(t, due.Equal(*imported.DueAt))
+}
+
+func TestCSVColumnsAreMatchedByName(t *testing.T) {
+	records := decodeAll(t, FormatCSV, "\ufeffDone,Title,Notes\nyes,Pay rent,x\nmaybe,Call mom,\n")
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "Pay rent", records[0].Title)
+	assert.True(t, records[0].Completed)
+	assert.Nil(t, records[0].Validate())
+	assert.Contains(t, records[1].Validate().Errors, "completed")
+}
+
+func TestCSVRequiresATitleColumn(t *testing.T) {
+	dec, _ := NewDecoder(FormatCSV, strings.NewReader("subject,done\nPay rent,no\n"))
+
+	_, err := dec.Next()
+	assert.Equal(t, ErrCSVHeader, err)
+}
+
+func TestTodoTxt(t *testing.T) {
+	records := decodeAll(t, FormatTodoTxt, strings.Join([]string{
+		"(A) 2026-01-01 Call mom +family @phone due:2026-01-05",
+		"",
+		"x 2026-01-03 2026-01-01 Pay rent +home pri:B",
+		"Water the plants due:2026-01-06T18:30",
+	}, "\n"))
+
+	require.Len(t, records, 3)
+
+	assert.Equal(t, 1, records[0].Row)
+	assert.Equal(t, "Call mom +family @phone", records[0].Title)
+	assert.Equal(t, "A", records[0].Priority)
+	assert.Equal(t, "2026-01-05", records[0].DueAt)
+	assert.False(t, records[0].Completed)
+
+	assert.Equal(t, 3, records[1].Row)
+	assert.Equal(t, "Pay rent +home", records[1].Title)
+	assert.Equal(t, "B", records[1].Priority)
+	assert.True(t, records[1].Completed)
+
+	assert.Equal(t, "2026-01-06T18:30", records[2].DueAt)
+	assert.Nil(t, records[2].Validate())
+}
+
+func TestTodoTxtExport(t *testing.T) {
+	midnight := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 1, 6, 18, 30, 0, 0, time.UTC)
+
+	file := exportTodos(t, FormatTodoTxt,
+		&models.Todo{Title: "Call mom +family", DueAt: &midnight},
+		&models.Todo{Title: "Pay\nrent", Completed: true, DueAt: &evening},
+	)
+
+	assert.Equal(t, "Call mom +family due:2026-01-05\nx Pay rent due:2026-01-06T18:30\n", file)
+}
+
+func TestMarkdown(t *testing.T) {
+	records := decodeAll(t, FormatMarkdown, strings.Join([]string{
+		"# Groceries",
+		"",
+		"- [ ] Buy milk due:2026-01-05",
+		"  Two litres",
+		"",
+		"  Semi-skimmed",
+		"* [X] Buy bread",
+		"  - [ ] Check the bakery",
+		"Some notes which are not a task",
+		"  and not a description either",
+	}, "\n"))
+
+	require.Len(t, records, 3)
+
+	assert.Equal(t, 3, records[0].Row)
+	assert.Equal(t, "Buy milk", records[0].Title)
+	assert.Equal(t, "2026-01-05", records[0].DueAt)
+	assert.Equal(t, "Two litres", records[0].Description)
+
+	assert.Equal(t, "Buy bread", records[1].Title)
+	assert.True(t, records[1].Completed)
+	assert.Empty(t, records[1].Description)
+
+	assert.Equal(t, "Check the bakery", records[2].Title)
+	assert.Empty(t, records[2].Description)
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	todo := &models.Todo{Title: "Buy milk", Description: "Two litres\n\nSemi-skimmed", Completed: true}
+
+	file := exportTodos(t, FormatMarkdown, todo)
+	assert.Equal(t, "- [x] Buy milk\n  Two litres\n  \n  Semi-skimmed\n", file)
+
+	records := decodeAll(t, FormatMarkdown, file)
+	require.Len(t, records, 1)
+	assert.Equal(t, todo.Description, records[0].Description)
+	assert.True(t, records[0].Completed)
+}
+
+func TestFormats(t *testing.T) {
+	format, _ := FormatFromFilename("backup/todo.txt")
+	assert.Equal(t, FormatTodoTxt, format)
+
+	format, _ = FormatFromContentType("text/csv; charset=utf-8")
+	assert.Equal(t, FormatCSV, format)
+
+	_, err := ParseFormat("xlsx")
+	assert.Equal(t, ErrUnknownFormat, err)
+}
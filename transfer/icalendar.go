@@ -0,0 +1,391 @@
+package transfer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksungcaya/todo-echo/models"
+)
+
+// Components an iCalendar export is made of. Todos are exported
+// as VTODO, while VEVENT suits calendar apps which don't show
+// tasks, only todos with a due date are exported then.
+const (
+	ComponentTodo  = models.CalendarTodos
+	ComponentEvent = models.CalendarEvents
+)
+
+// icalUTC is the layout of date-times in UTC
+const icalUTC = "20060102T150405Z"
+
+// ErrICalendar is returned when a file is not an iCalendar file
+var ErrICalendar = errors.New("The file is not an iCalendar file")
+
+var (
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	icalDuration  = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+)
+
+// icalendarEncoder writes the records as an RFC 5545 calendar
+type icalendarEncoder struct {
+	w         *bufio.Writer
+	component string
+}
+
+// NewICalendarEncoder creates an Encoder writing the records as
+// components of the kind, either ComponentTodo or ComponentEvent.
+func NewICalendarEncoder(w io.Writer, component string) Encoder {
+	e := &icalendarEncoder{bufio.NewWriter(w), component}
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:-//todo-echo//Todos//EN")
+	e.line("CALSCALE:GREGORIAN")
+	e.line("X-WR-CALNAME:Todos")
+	return e
+}
+
+// Encode writes the record as a component with an alarm for
+// each of its reminders which is yet to fire.
+func (e *icalendarEncoder) Encode(r *Record) error {
+	due, err := time.Parse(time.RFC3339, r.DueAt)
+	hasDue := err == nil
+	if e.component == ComponentEvent && !hasDue {
+		return nil
+	}
+
+	stamp := r.UpdatedAt
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	e.line("BEGIN:" + e.component)
+	e.line("UID:" + icalUID(r))
+	e.line("DTSTAMP:" + stamp.UTC().Format(icalUTC))
+	e.line("SUMMARY:" + icalEscaper.Replace(r.Title))
+	if r.Description != "" {
+		e.line("DESCRIPTION:" + icalEscaper.Replace(r.Description))
+	}
+
+	if e.component == ComponentEvent {
+		e.eventDates(due)
+	} else {
+		if hasDue {
+			e.line("DUE:" + due.UTC().Format(icalUTC))
+		}
+		status := "NEEDS-ACTION"
+		if r.Completed {
+			status = "COMPLETED"
+		}
+		e.line("STATUS:" + status)
+		if len(r.Priority) == 1 && r.Priority >= "A" && r.Priority <= "I" {
+			e.line("PRIORITY:" + strconv.Itoa(int(r.Priority[0]-'A'+1)))
+		}
+	}
+
+	if r.Recurrence != "" {
+		e.line("RRULE:" + r.Recurrence)
+	}
+
+	for _, reminder := range r.Reminders {
+		if reminder.SentAt == nil {
+			e.alarm(r, reminder)
+		}
+	}
+
+	e.line("END:" + e.component)
+	return nil
+}
+
+// Close ends the calendar
+func (e *icalendarEncoder) Close() error {
+	e.line("END:VCALENDAR")
+	return e.w.Flush()
+}
+
+// eventDates writes when the event of a todo happens. A todo
+// due at midnight is shown as an all-day event.
+func (e *icalendarEncoder) eventDates(due time.Time) {
+	if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+		e.line("DTSTART;VALUE=DATE:" + due.Format("20060102"))
+		e.line("DTEND;VALUE=DATE:" + due.AddDate(0, 0, 1).Format("20060102"))
+	} else {
+		e.line("DTSTART:" + due.UTC().Format(icalUTC))
+		e.line("DTEND:" + due.UTC().Format(icalUTC))
+	}
+	e.line("TRANSP:TRANSPARENT")
+}
+
+// alarm writes a reminder as a VALARM. A relative reminder of a
+// VTODO is related to its end, which is when the todo is due.
+func (e *icalendarEncoder) alarm(r *Record, reminder Reminder) {
+	var trigger string
+	switch {
+	case reminder.Before != nil && e.component == ComponentTodo:
+		trigger = fmt.Sprintf("TRIGGER;RELATED=END:-PT%dM", *reminder.Before)
+	case reminder.Before != nil:
+		trigger = fmt.Sprintf("TRIGGER:-PT%dM", *reminder.Before)
+	default:
+		at, err := time.Parse(time.RFC3339, reminder.At)
+		if err != nil {
+			return
+		}
+		trigger = "TRIGGER;VALUE=DATE-TIME:" + at.UTC().Format(icalUTC)
+	}
+
+	e.line("BEGIN:VALARM")
+	e.line("ACTION:DISPLAY")
+	e.line("DESCRIPTION:" + icalEscaper.Replace(r.Title))
+	e.line(trigger)
+	e.line("END:VALARM")
+}
+
+// line writes a content line, folded to lines of 75 octets
+// without breaking a multi-byte character apart.
+func (e *icalendarEncoder) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		e.w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	e.w.WriteString(s + "\r\n")
+}
+
+// icalUID identifies the todo of a record across exports
+func icalUID(r *Record) string {
+	if r.ID != 0 {
+		return fmt.Sprintf("todo-%d@todo-echo", r.ID)
+	}
+	sum := sha256.Sum256([]byte(r.Title + "|" + r.DueAt))
+	return "todo-" + hex.EncodeToString(sum[:8]) + "@todo-echo"
+}
+
+// icalendarDecoder reads the VTODO components of a calendar,
+// other components such as events are skipped.
+type icalendarDecoder struct {
+	s          *bufio.Scanner
+	pending    string
+	hasPending bool
+	calendar   bool
+	row        int
+}
+
+func newICalendarDecoder(r io.Reader) *icalendarDecoder {
+	return &icalendarDecoder{s: newScanner(r)}
+}
+
+// icalAlarm holds the properties of a VALARM being read
+type icalAlarm struct {
+	action  string
+	trigger string
+	params  map[string]string
+}
+
+// Next reads the next VTODO
+func (d *icalendarDecoder) Next() (*Record, error) {
+	var r *Record
+	var alarm *icalAlarm
+
+	for {
+		line, err := d.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+
+		name, params, value := parseContentLine(line)
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			d.calendar = true
+		case !d.calendar:
+			return nil, ErrICalendar
+		case name == "BEGIN" && value == "VTODO":
+			d.row++
+			r = &Record{Row: d.row}
+		case r == nil:
+			continue
+		case name == "BEGIN" && value == "VALARM":
+			alarm = &icalAlarm{}
+		case name == "END" && value == "VALARM" && alarm != nil:
+			addAlarm(r, alarm)
+			alarm = nil
+		case alarm != nil:
+			switch name {
+			case "ACTION":
+				alarm.action = strings.ToUpper(value)
+			case "TRIGGER":
+				alarm.trigger, alarm.params = value, params
+			}
+		case name == "END" && value == "VTODO":
+			return r, nil
+		default:
+			setProperty(r, name, params, value)
+		}
+	}
+}
+
+// nextLine reads the next content line, unfolding the lines
+// which continue on the following ones.
+func (d *icalendarDecoder) nextLine() (string, error) {
+	for d.s.Scan() {
+		text := strings.TrimRight(d.s.Text(), "\r")
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			d.pending += text[1:]
+			continue
+		}
+
+		line, had := d.pending, d.hasPending
+		d.pending, d.hasPending = text, true
+		if had {
+			return line, nil
+		}
+	}
+
+	if err := d.s.Err(); err != nil {
+		return "", err
+	}
+	if d.hasPending {
+		d.hasPending = false
+		return d.pending, nil
+	}
+	return "", io.EOF
+}
+
+// setProperty copies a property of a VTODO to the record
+func setProperty(r *Record, name string, params map[string]string, value string) {
+	switch name {
+	case "SUMMARY":
+		r.Title = strings.TrimSpace(icalUnescaper.Replace(value))
+	case "DESCRIPTION":
+		r.Description = icalUnescaper.Replace(value)
+	case "STATUS":
+		r.Completed = r.Completed || strings.EqualFold(value, "COMPLETED")
+	case "COMPLETED":
+		r.Completed = true
+	case "PERCENT-COMPLETE":
+		r.Completed = r.Completed || value == "100"
+	case "RRULE":
+		r.Recurrence = value
+	case "PRIORITY":
+		if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= 9 {
+			r.Priority = string(rune('A' + n - 1))
+		}
+	case "DUE":
+		due, tz, err := parseICalDate(value, params)
+		if err != nil {
+			r.invalid("due_at", "The due_at field must be a valid date and time")
+			return
+		}
+		r.DueAt = due
+		if r.Timezone == "" {
+			r.Timezone = tz
+		}
+	}
+}
+
+// addAlarm adds a VALARM to the record as a reminder. Alarms
+// triggering after the todo is due can't be represented.
+func addAlarm(r *Record, alarm *icalAlarm) {
+	reminder := Reminder{}
+	reminder.Channel = models.ReminderInApp
+	if alarm.action == "EMAIL" {
+		reminder.Channel = models.ReminderEmail
+	}
+
+	if strings.EqualFold(alarm.params["VALUE"], "DATE-TIME") {
+		at, _, err := parseICalDate(alarm.trigger, alarm.params)
+		if err != nil {
+			r.invalid("reminders", "The reminder must have a valid trigger")
+			return
+		}
+		reminder.At = at
+		r.Reminders = append(r.Reminders, reminder)
+		return
+	}
+
+	m := icalDuration.FindStringSubmatch(alarm.trigger)
+	if m == nil || !strings.ContainsAny(alarm.trigger, "0123456789") {
+		r.invalid("reminders", "The reminder must have a valid trigger")
+		return
+	}
+
+	minutes := 0
+	for i, scale := range []int{7 * 24 * 60, 24 * 60, 60, 1} {
+		n, _ := strconv.Atoi(m[i+2])
+		minutes += n * scale
+	}
+	seconds, _ := strconv.Atoi(m[6])
+	minutes += seconds / 60
+
+	if m[1] != "-" && minutes > 0 {
+		r.invalid("reminders", "The reminder must fire before the todo is due")
+		return
+	}
+	reminder.Before = &minutes
+	r.Reminders = append(r.Reminders, reminder)
+}
+
+// parseICalDate converts a DATE or DATE-TIME value to one of the
+// layouts of the API. A local time keeps its TZID as timezone
+// when it is a known one, otherwise it is read as floating.
+func parseICalDate(value string, params map[string]string) (string, string, error) {
+	if t, err := time.Parse(icalUTC, value); err == nil {
+		return t.Format(time.RFC3339), "", nil
+	}
+	if t, err := time.Parse("20060102T150405", value); err == nil {
+		tz := strings.TrimPrefix(params["TZID"], "/")
+		if _, err := time.LoadLocation(tz); err != nil {
+			tz = ""
+		}
+		return t.Format("2006-01-02T15:04:05"), tz, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Format("2006-01-02"), "", nil
+	}
+	return "", "", fmt.Errorf("invalid date %q", value)
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value" into its parts.
+// Parameter values may be quoted, in which case they can hold the
+// ":" and ";" separators.
+func parseContentLine(line string) (string, map[string]string, string) {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case line[i] == ';':
+			parts = append(parts, line[start:i])
+			start = i + 1
+		case line[i] == ':':
+			parts = append(parts, line[start:i])
+			params := make(map[string]string, len(parts)-1)
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) == 2 {
+					params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+				}
+			}
+			return strings.ToUpper(parts[0]), params, line[i+1:]
+		}
+	}
+	return strings.ToUpper(line), nil, ""
+}
@@ -0,0 +1,170 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestICalendarExportsTodos(t *testing.T) {
+	due := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	sent := due.Add(-time.Hour)
+	before := 30
+	todo := &models.Todo{
+		Model:       gorm.Model{ID: 7, UpdatedAt: due.Add(-48 * time.Hour)},
+		Title:       "Pay rent; landlord, " + strings.Repeat("é", 40),
+		Description: "Line one\nLine two",
+		DueAt:       &due,
+		Timezone:    "Europe/Paris",
+		Recurrence:  "FREQ=MONTHLY;BYMONTHDAY=1",
+		Reminders: []models.Reminder{
+			{Channel: models.ReminderEmail, OffsetMinutes: &before},
+			{Channel: models.ReminderEmail, RemindAt: &sent, SentAt: &sent},
+		},
+	}
+
+	file := exportTodos(t, FormatICalendar, todo)
+
+	assert.True(t, strings.HasPrefix(file, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(file, "END:VTODO\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, file, "UID:todo-7@todo-echo\r\n")
+	assert.Contains(t, file, "DTSTAMP:20260227T083000Z\r\n")
+	assert.Contains(t, file, "DUE:20260301T083000Z\r\n")
+	assert.Contains(t, file, "STATUS:NEEDS-ACTION\r\n")
+	assert.Contains(t, file, "DESCRIPTION:Line one\\nLine two\r\n")
+	assert.Contains(t, file, "RRULE:FREQ=MONTHLY;BYMONTHDAY=1\r\n")
+	assert.Contains(t, file, "TRIGGER;RELATED=END:-PT30M\r\n")
+	assert.Equal(t, 1, strings.Count(file, "BEGIN:VALARM"), "sent reminders are left out")
+
+	for _, line := range strings.Split(file, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, file, `SUMMARY:Pay rent\; landlord\, `)
+}
+
+func TestICalendarExportsEvents(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Manila")
+	allDay := time.Date(2026, 3, 1, 0, 0, 0, 0, loc).UTC()
+	meeting := time.Date(2026, 3, 2, 9, 0, 0, 0, loc).UTC()
+
+	var buf bytes.Buffer
+	enc := NewICalendarEncoder(&buf, ComponentEvent)
+	enc.Encode(NewRecord(&models.Todo{Model: gorm.Model{ID: 1}, Title: "No due date"}))
+	enc.Encode(NewRecord(&models.Todo{Model: gorm.Model{ID: 2}, Title: "Holiday", DueAt: &allDay, Timezone: "Asia/Manila"}))
+	enc.Encode(NewRecord(&models.Todo{Model: gorm.Model{ID: 3}, Title: "Standup", DueAt: &meeting, Timezone: "Asia/Manila"}))
+	require.NoError(t, enc.Close())
+	file := buf.String()
+
+	assert.Equal(t, 2, strings.Count(file, "BEGIN:VEVENT"))
+	assert.NotContains(t, file, "No due date")
+	assert.Contains(t, file, "DTSTART;VALUE=DATE:20260301\r\nDTEND;VALUE=DATE:20260302\r\n")
+	assert.Contains(t, file, "DTSTART:20260302T010000Z\r\n")
+}
+
+func TestICalendarImport(t *testing.T) {
+	file := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Tasks//EN",
+		"BEGIN:VEVENT",
+		"SUMMARY:An event, not a todo",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:1@example.com",
+		"SUMMARY:Submit the quarterly report to the finance team before the end ",
+		" of the month",
+		"DESCRIPTION:Attach the receipts\\, too",
+		`DUE;TZID="Europe/Paris":20260331T170000`,
+		"PRIORITY:1",
+		"RRULE:FREQ=MONTHLY",
+		"BEGIN:VALARM",
+		"ACTION:EMAIL",
+		"TRIGGER;RELATED=END:-P1DT2H",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"TRIGGER;VALUE=DATE-TIME:20260330T080000Z",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Water the plants",
+		"STATUS:COMPLETED",
+		"DUE;VALUE=DATE:20260305",
+		"BEGIN:VALARM",
+		"TRIGGER:PT15M",
+		"END:VALARM",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	records := decodeAll(t, FormatICalendar, file)
+	require.Len(t, records, 2)
+
+	report := records[0]
+	assert.Nil(t, report.Validate())
+	assert.Equal(t, "Submit the quarterly report to the finance team before the end of the month", report.Title)
+	assert.Equal(t, "Attach the receipts, too", report.Description)
+	assert.Equal(t, "A", report.Priority)
+	assert.Equal(t, "Europe/Paris", report.Timezone)
+
+	todo := report.Todo(1)
+	assert.True(t, time.Date(2026, 3, 31, 15, 0, 0, 0, time.UTC).Equal(*todo.DueAt))
+	assert.Equal(t, "FREQ=MONTHLY", todo.Recurrence)
+	require.Len(t, todo.Reminders, 2)
+	assert.Equal(t, models.ReminderEmail, todo.Reminders[0].Channel)
+	assert.Equal(t, 26*60, *todo.Reminders[0].OffsetMinutes)
+	assert.Equal(t, models.ReminderInApp, todo.Reminders[1].Channel)
+	assert.True(t, time.Date(2026, 3, 30, 8, 0, 0, 0, time.UTC).Equal(*todo.Reminders[1].RemindAt))
+
+	plants := records[1]
+	assert.Equal(t, 2, plants.Row)
+	assert.True(t, plants.Completed)
+	assert.Equal(t, "2026-03-05", plants.DueAt)
+	assert.Contains(t, plants.Validate().Errors, "reminders")
+}
+
+func TestICalendarRoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	before := 45
+	todo := &models.Todo{
+		Model:      gorm.Model{ID: 1},
+		Title:      "Pay rent, on time",
+		Completed:  true,
+		DueAt:      &due,
+		Recurrence: "FREQ=MONTHLY",
+		Reminders:  []models.Reminder{{Channel: models.ReminderInApp, OffsetMinutes: &before}},
+	}
+
+	records := decodeAll(t, FormatICalendar, exportTodos(t, FormatICalendar, todo))
+	require.Len(t, records, 1)
+	assert.Nil(t, records[0].Validate())
+
+	imported := records[0].Todo(1)
+	assert.Equal(t, todo.Title, imported.Title)
+	assert.True(t, imported.Completed)
+	assert.True(t, due.Equal(*imported.DueAt))
+	assert.Equal(t, "FREQ=MONTHLY", imported.Recurrence)
+	assert.Equal(t, before, *imported.Reminders[0].OffsetMinutes)
+}
+
+func TestICalendarRejectsOtherFiles(t *testing.T) {
+	dec, _ := NewDecoder(FormatICalendar, strings.NewReader("title,completed\nPay rent,false\n"))
+
+	_, err := dec.Next()
+	assert.Equal(t, ErrICalendar, err)
+}
+
+func TestICalendarValidatesRecurrence(t *testing.T) {
+	file := "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Stretch\nRRULE:FREQ=HOURLY;COUNT=3;UNTIL=20260101\nEND:VTODO\nEND:VCALENDAR\n"
+
+	records := decodeAll(t, FormatICalendar, file)
+	require.Len(t, records, 1)
+	assert.Contains(t, records[0].Validate().Errors, "recurrence")
+}
@@ -0,0 +1,198 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+)
+
+// Statuses of an imported row
+const (
+	RowCreated   = "created"
+	RowValid     = "valid"
+	RowDuplicate = "duplicate"
+	RowInvalid   = "invalid"
+)
+
+// Errors returned when an import exceeds its limits
+var (
+	ErrTooLarge    = errors.New("The file is too large")
+	ErrTooManyRows = errors.New("The file has too many todos")
+)
+
+// DecodeError is returned when a file can't be read in its format,
+// unlike the errors of creating the todos it has
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of reading the file
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Options of an import
+type Options struct {
+	Format Format
+	// DryRun only reports what would be created
+	DryRun bool
+	// AllowDuplicates creates todos which already exist
+	AllowDuplicates bool
+	// Timezone is used for the todos which have none
+	Timezone string
+}
+
+// Report is the outcome of an import. On a dry run, Created is
+// the number of todos which would have been created.
+type Report struct {
+	Format     Format      `json:"format"`
+	DryRun     bool        `json:"dry_run"`
+	Total      int         `json:"total"`
+	Created    int         `json:"created"`
+	Duplicates int         `json:"duplicates"`
+	Invalid    int         `json:"invalid"`
+	Rows       []RowResult `json:"rows"`
+}
+
+// RowResult is the outcome of a single row of an import
+type RowResult struct {
+	Row    int                 `json:"row"`
+	Title  string              `json:"title"`
+	Status string              `json:"status"`
+	TodoID uint                `json:"todo_id,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"`
+}
+
+// Importer creates todos from files
+type Importer struct {
+	tr     repositories.TodoRepository
+	config configs.ImportConfig
+}
+
+// NewImporter creates an Importer which enforces the limits of config
+func NewImporter(tr repositories.TodoRepository, config configs.ImportConfig) *Importer {
+	return &Importer{tr, config}
+}
+
+// Workspace returns an Importer which creates the todos in the
+// workspace, only the todos in there count as duplicates
+func (im *Importer) Workspace(id uint) *Importer {
+	return &Importer{im.tr.Workspace(id), im.config}
+}
+
+// Import reads the todos of the user from r. The whole file is
+// validated before anything is created, so a file exceeding the
+// limits or which can't be parsed, a DecodeError, creates no todos at
+// all. Rows which are invalid or duplicates are reported and skipped.
+// The todos are created in a transaction, all of them or none.
+func (im *Importer) Import(userID uint, r io.Reader, opts Options) (*Report, error) {
+	dec, err := NewDecoder(opts.Format, &limitedReader{r, im.config.MaxBytes})
+	if err != nil {
+		return nil, &DecodeError{err}
+	}
+
+	seen := make(map[string]bool)
+	for _, todo := range im.tr.ByUser(userID) {
+		seen[duplicateKey(&todo)] = true
+	}
+
+	report := &Report{Format: opts.Format, DryRun: opts.DryRun, Rows: []RowResult{}}
+	todos := make(map[int]*models.Todo)
+
+	for {
+		record, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, &DecodeError{err}
+		}
+		if report.Total >= im.config.MaxRows {
+			return nil, fmt.Errorf("%w, at most %d are allowed", ErrTooManyRows, im.config.MaxRows)
+		}
+		report.Total++
+
+		if record.Timezone == "" {
+			record.Timezone = opts.Timezone
+		}
+
+		row := RowResult{Row: record.Row, Title: record.Title, Status: RowValid}
+		if verr := record.Validate(); verr != nil {
+			row.Status = RowInvalid
+			row.Errors = verr.Errors
+			report.Invalid++
+		} else {
+			todo := record.Todo(userID)
+			key := duplicateKey(todo)
+			if seen[key] && !opts.AllowDuplicates {
+				row.Status = RowDuplicate
+				report.Duplicates++
+			} else {
+				seen[key] = true
+				todos[len(report.Rows)] = todo
+				report.Created++
+			}
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	err = im.tr.Transaction(func(tr repositories.TodoRepository) error {
+		for i := range report.Rows {
+			todo, ok := todos[i]
+			if !ok {
+				continue
+			}
+			if err := tr.Create(todo); err != nil {
+				return fmt.Errorf("row %d: %w", report.Rows[i].Row, err)
+			}
+			report.Rows[i].Status = RowCreated
+			report.Rows[i].TodoID = todo.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// limitedReader reads at most n bytes from r, and returns
+// ErrTooLarge rather than io.EOF when there is more.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n > 0 {
+			return 0, ErrTooLarge
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,147 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var limits = configs.ImportConfig{MaxBytes: 1 << 20, MaxRows: 100}
+
+const csvFile = `title,completed,due_at
+Pay rent,false,2026-03-01
+,false,
+Call mom,false,2026-13-01
+pay   RENT,true,2026-03-01
+Water the plants,false,
+Water the plants,false,
+`
+
+// newTodoRepository creates the mock repository of the imports, its
+// transactions run straight on it
+func newTodoRepository() *mocks.TodoRepository {
+	repo := &mocks.TodoRepository{}
+	repo.On("Transaction", mock.Anything).Return(func(fn func(repositories.TodoRepository) error) error {
+		return fn(repo)
+	})
+	return repo
+}
+
+func TestImportCreatesValidRows(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{})
+	repo.On("Create", mock.AnythingOfType("*models.Todo")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Todo).ID = 10
+	})
+
+	report, err := NewImporter(repo, limits).Import(1, strings.NewReader(csvFile), Options{Format: FormatCSV, Timezone: "Asia/Manila"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 2, report.Duplicates)
+	assert.Equal(t, 2, report.Invalid)
+
+	assert.Equal(t, RowCreated, report.Rows[0].Status)
+	assert.Equal(t, uint(10), report.Rows[0].TodoID)
+	assert.Equal(t, RowInvalid, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Errors, "title")
+	assert.Contains(t, report.Rows[2].Errors, "due_at")
+	assert.Equal(t, RowDuplicate, report.Rows[3].Status)
+	assert.Equal(t, RowCreated, report.Rows[4].Status)
+	assert.Equal(t, RowDuplicate, report.Rows[5].Status)
+
+	repo.AssertNumberOfCalls(t, "Create", 2)
+	todo := repo.Calls[2].Arguments.Get(0).(*models.Todo)
+	assert.Equal(t, uint(1), todo.UserID)
+	assert.Equal(t, "Asia/Manila", todo.Timezone)
+	assert.True(t, time.Date(2026, 2, 28, 16, 0, 0, 0, time.UTC).Equal(*todo.DueAt))
+}
+
+func TestImportDryRun(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{})
+
+	report, err := NewImporter(repo, limits).Import(1, strings.NewReader(csvFile), Options{Format: FormatCSV, DryRun: true})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, RowValid, report.Rows[0].Status)
+	assert.Zero(t, report.Rows[0].TodoID)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestImportSkipsExistingTodos(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{
+		{Model: gorm.Model{ID: 1}, UserID: 1, Title: "Water the plants"},
+	})
+
+	file := "- [ ] Water  the plants\n- [ ] Water the plants due:2026-01-05\n"
+	report, err := NewImporter(repo, limits).Import(1, strings.NewReader(file), Options{Format: FormatMarkdown, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, RowDuplicate, report.Rows[0].Status)
+	assert.Equal(t, RowValid, report.Rows[1].Status)
+
+	report, _ = NewImporter(repo, limits).Import(1, strings.NewReader(file), Options{Format: FormatMarkdown, DryRun: true, AllowDuplicates: true})
+	assert.Equal(t, RowValid, report.Rows[0].Status)
+}
+
+func TestImportValidatesReminders(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{})
+
+	file := `[{"title": "Pay rent", "reminders": [{"channel": "email", "before": 30}, {"channel": "pigeon", "at": "2026-03-01"}]}]`
+	report, err := NewImporter(repo, limits).Import(1, strings.NewReader(file), Options{Format: FormatJSON, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, RowInvalid, report.Rows[0].Status)
+	assert.Contains(t, report.Rows[0].Errors, "reminders.1.channel")
+	assert.NotContains(t, report.Rows[0].Errors, "reminders.0.channel")
+}
+
+func TestImportFailingToCreate(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{})
+	repo.On("Create", mock.AnythingOfType("*models.Todo")).Return(nil).Once()
+	repo.On("Create", mock.AnythingOfType("*models.Todo")).Return(errors.New("connection reset"))
+
+	report, err := NewImporter(repo, limits).Import(1, strings.NewReader(csvFile), Options{Format: FormatCSV})
+
+	// the file is fine, so it is not a DecodeError
+	assert.Nil(t, report)
+	assert.EqualError(t, err, "row 5: connection reset")
+	var decodeErr *DecodeError
+	assert.False(t, errors.As(err, &decodeErr))
+	repo.AssertNumberOfCalls(t, "Transaction", 1)
+}
+
+func TestImportLimits(t *testing.T) {
+	repo := newTodoRepository()
+	repo.On("ByUser", uint(1)).Return([]models.Todo{})
+
+	file := strings.Repeat("Water the plants\n", 3)
+
+	_, err := NewImporter(repo, configs.ImportConfig{MaxBytes: 1 << 20, MaxRows: 2}).
+		Import(1, strings.NewReader(file), Options{Format: FormatTodoTxt})
+	assert.True(t, errors.Is(err, ErrTooManyRows))
+
+	_, err = NewImporter(repo, configs.ImportConfig{MaxBytes: 20, MaxRows: 100}).
+		Import(1, bytes.NewBufferString(file), Options{Format: FormatTodoTxt})
+	assert.True(t, errors.Is(err, ErrTooLarge))
+
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
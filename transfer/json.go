@@ -0,0 +1,103 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrJSONArray is returned when a JSON file is not an array
+var ErrJSONArray = errors.New("The JSON file must contain an array of todos")
+
+// jsonEncoder writes the records as an array, one per line,
+// without holding all of them in memory.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+	return &jsonEncoder{w: w}
+}
+
+// Encode writes the record as the next item of the array
+func (e *jsonEncoder) Encode(r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if e.count == 0 {
+		separator = "[\n"
+	}
+	e.count++
+
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Close ends the array
+func (e *jsonEncoder) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// jsonDecoder reads the items of the array one at a time
+type jsonDecoder struct {
+	dec     *json.Decoder
+	started bool
+	row     int
+}
+
+func newJSONDecoder(r io.Reader) *jsonDecoder {
+	return &jsonDecoder{dec: json.NewDecoder(r)}
+}
+
+// Next reads the next item of the array. An item with a value
+// of the wrong type is returned with the error on its field.
+func (d *jsonDecoder) Next() (*Record, error) {
+	if !d.started {
+		token, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != json.Delim('[') {
+			return nil, ErrJSONArray
+		}
+		d.started = true
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	r := &Record{}
+	err := d.dec.Decode(r)
+	d.row++
+	r.Row = d.row
+
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		if typeErr.Field == "" {
+			r.invalid("todo", "The todo must be an object")
+		} else {
+			r.invalid(typeErr.Field, fmt.Sprintf("The %s field must be a %s", typeErr.Field, typeErr.Type))
+		}
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
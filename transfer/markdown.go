@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// taskPattern matches a Markdown task list item, e.g. "- [x] Title"
+var taskPattern = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s*(.*)$`)
+
+// markdownEncoder writes the records as a task list. The due
+// date is written as a due: tag and the description as lines
+// indented below the task.
+type markdownEncoder struct {
+	w *bufio.Writer
+}
+
+func newMarkdownEncoder(w io.Writer) *markdownEncoder {
+	return &markdownEncoder{bufio.NewWriter(w)}
+}
+
+// Encode writes the record as a task
+func (e *markdownEncoder) Encode(r *Record) error {
+	line := "- [ ] "
+	if r.Completed {
+		line = "- [x] "
+	}
+
+	line += singleLine(r.Title)
+	if due := dueToken(r.DueAt); due != "" {
+		line += " " + due
+	}
+	if _, err := e.w.WriteString(line + "\n"); err != nil {
+		return err
+	}
+
+	if r.Description == "" {
+		return nil
+	}
+	for _, description := range strings.Split(r.Description, "\n") {
+		if _, err := e.w.WriteString("  " + strings.TrimRight(description, " \r") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the lines which are still buffered
+func (e *markdownEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// markdownDecoder reads the tasks of a Markdown file, other
+// content such as headings and paragraphs is skipped.
+type markdownDecoder struct {
+	s      *bufio.Scanner
+	line   int
+	task   *Record
+	indent string
+	lines  []string
+}
+
+func newMarkdownDecoder(r io.Reader) *markdownDecoder {
+	return &markdownDecoder{s: newScanner(r)}
+}
+
+// Next reads the next task along with the lines indented below
+// it, which make up its description.
+func (d *markdownDecoder) Next() (*Record, error) {
+	for d.s.Scan() {
+		d.line++
+		text := d.s.Text()
+
+		if m := taskPattern.FindStringSubmatch(text); m != nil {
+			r := d.flush()
+			d.task = &Record{Row: d.line, Completed: m[2] != " "}
+			d.task.Title, d.task.DueAt = splitDue(m[3])
+			d.indent = m[1] + "  "
+			if r != nil {
+				return r, nil
+			}
+			continue
+		}
+
+		if d.task != nil && strings.HasPrefix(text, d.indent) {
+			d.lines = append(d.lines, strings.TrimRight(text[len(d.indent):], " \r"))
+			continue
+		}
+
+		// anything else ends the task
+		if r := d.flush(); r != nil {
+			return r, nil
+		}
+	}
+
+	if err := d.s.Err(); err != nil {
+		return nil, err
+	}
+	if r := d.flush(); r != nil {
+		return r, nil
+	}
+	return nil, io.EOF
+}
+
+// flush returns the task being read with its description
+func (d *markdownDecoder) flush() *Record {
+	r := d.task
+	if r != nil {
+		r.Description = strings.TrimSpace(strings.Join(d.lines, "\n"))
+	}
+
+	d.task = nil
+	d.lines = nil
+	return r
+}
+
+// splitDue takes the due: tag off the text of a task
+func splitDue(text string) (string, string) {
+	due := ""
+	words := strings.Fields(text)
+	title := make([]string, 0, len(words))
+	for _, word := range words {
+		if strings.HasPrefix(word, "due:") && len(word) > len("due:") {
+			due = strings.TrimPrefix(word, "due:")
+			continue
+		}
+		title = append(title, word)
+	}
+	return strings.Join(title, " "), due
+}
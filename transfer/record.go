@@ -0,0 +1,145 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/requests"
+)
+
+// Record is a todo as it is written to or read from a file.
+// Dates are kept as text so they are validated the same way
+// as the ones sent to the API.
+type Record struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueAt       string     `json:"due_at,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Reminders   []Reminder `json:"reminders,omitempty"`
+
+	// ID and UpdatedAt are those of an exported todo, used by
+	// formats which identify their items such as iCalendar.
+	ID        uint      `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	// Row is the position of the record in the file: the line
+	// for todo.txt and Markdown, the item for CSV, JSON and
+	// iCalendar.
+	Row int `json:"-"`
+
+	// errors are the ones found while decoding the record
+	errors map[string][]string
+}
+
+// Reminder is a reminder of a record. SentAt is kept so an
+// imported reminder which already fired won't fire again.
+type Reminder struct {
+	requests.ReminderRequest
+	SentAt *time.Time `json:"sent_at,omitempty"`
+}
+
+// NewRecord creates a Record from the todo, dates are written
+// in the todo's own timezone.
+func NewRecord(t *models.Todo) *Record {
+	r := &Record{
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		Timezone:    t.Timezone,
+		Recurrence:  t.Recurrence,
+		ID:          t.ID,
+		UpdatedAt:   t.UpdatedAt,
+	}
+	if due := t.LocalDueAt(); due != nil {
+		r.DueAt = due.Format(time.RFC3339)
+	}
+
+	for _, reminder := range t.Reminders {
+		rr := Reminder{SentAt: reminder.SentAt}
+		rr.Channel = reminder.Channel
+		rr.Target = reminder.Target
+		rr.Before = reminder.OffsetMinutes
+		if reminder.RemindAt != nil {
+			rr.At = reminder.RemindAt.In(t.Location()).Format(time.RFC3339)
+		}
+		r.Reminders = append(r.Reminders, rr)
+	}
+
+	return r
+}
+
+// invalid adds a decoding error to the field
+func (r *Record) invalid(field string, message string) {
+	if r.errors == nil {
+		r.errors = make(map[string][]string)
+	}
+	r.errors[field] = append(r.errors[field], message)
+}
+
+// request creates the request the record is validated with
+func (r *Record) request() *requests.TodoRequest {
+	return &requests.TodoRequest{
+		Title:       r.Title,
+		Description: r.Description,
+		Completed:   r.Completed,
+		DueAt:       r.DueAt,
+		Timezone:    r.Timezone,
+		Recurrence:  r.Recurrence,
+	}
+}
+
+// Validate validates the record with the same rules as the
+// todo and reminder requests. Errors of the reminders are
+// keyed by their position, e.g. "reminders.0.channel".
+func (r *Record) Validate() *requests.ValidationErrors {
+	errs := make(map[string][]string)
+	for field, messages := range r.errors {
+		errs[field] = append(errs[field], messages...)
+	}
+
+	if err, ok := requests.ValidateRequest(r.request()).(requests.ValidationErrors); ok {
+		for field, messages := range err.Errors {
+			errs[field] = append(errs[field], messages...)
+		}
+	}
+	for i := range r.Reminders {
+		if err, ok := r.Reminders[i].Check().(requests.ValidationErrors); ok {
+			for field, messages := range err.Errors {
+				key := fmt.Sprintf("reminders.%d.%s", i, field)
+				errs[key] = append(errs[key], messages...)
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &requests.ValidationErrors{Errors: errs}
+}
+
+// Todo creates the *models.Todo of the record owned by the user
+func (r *Record) Todo(userID uint) *models.Todo {
+	t := r.request().TodoModel(userID)
+	for i := range r.Reminders {
+		reminder := r.Reminders[i].ReminderModel(t)
+		reminder.SentAt = r.Reminders[i].SentAt
+		t.Reminders = append(t.Reminders, *reminder)
+	}
+	return t
+}
+
+// duplicateKey identifies todos which are considered the same,
+// those with the same title, ignoring case and spacing, which
+// are due at the same time.
+func duplicateKey(t *models.Todo) string {
+	key := strings.ToLower(strings.Join(strings.Fields(t.Title), " "))
+	if t.DueAt != nil {
+		key += "|" + t.DueAt.UTC().Format(time.RFC3339)
+	}
+	return key
+}
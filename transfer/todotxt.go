@@ -0,0 +1,151 @@
+package transfer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxLineSize is the longest line read from the text formats
+const maxLineSize = 64 * 1024
+
+var (
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	priorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+)
+
+// todoTxtEncoder writes the records in the todo.txt format, see
+// https://github.com/todotxt/todo.txt. It only has room for a
+// single line, so descriptions are not exported.
+type todoTxtEncoder struct {
+	w *bufio.Writer
+}
+
+func newTodoTxtEncoder(w io.Writer) *todoTxtEncoder {
+	return &todoTxtEncoder{bufio.NewWriter(w)}
+}
+
+// Encode writes the record as a line
+func (e *todoTxtEncoder) Encode(r *Record) error {
+	var parts []string
+	switch {
+	case r.Completed:
+		parts = append(parts, "x")
+	case r.Priority != "":
+		parts = append(parts, "("+r.Priority+")")
+	}
+
+	parts = append(parts, singleLine(r.Title))
+	if due := dueToken(r.DueAt); due != "" {
+		parts = append(parts, due)
+	}
+	if r.Completed && r.Priority != "" {
+		parts = append(parts, "pri:"+r.Priority)
+	}
+
+	_, err := e.w.WriteString(strings.Join(parts, " ") + "\n")
+	return err
+}
+
+// Close flushes the lines which are still buffered
+func (e *todoTxtEncoder) Close() error {
+	return e.w.Flush()
+}
+
+type todoTxtDecoder struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newTodoTxtDecoder(r io.Reader) *todoTxtDecoder {
+	return &todoTxtDecoder{s: newScanner(r)}
+}
+
+// Next reads the next task, blank lines are skipped
+func (d *todoTxtDecoder) Next() (*Record, error) {
+	for d.s.Scan() {
+		d.line++
+		if strings.TrimSpace(d.s.Text()) == "" {
+			continue
+		}
+
+		r := parseTodoTxt(d.s.Text())
+		r.Row = d.line
+		return r, nil
+	}
+
+	if err := d.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parseTodoTxt parses a todo.txt line. The completion mark,
+// priority and dates are taken off the title, while +project
+// and @context tags are kept in it as they read as words.
+func parseTodoTxt(line string) *Record {
+	r := &Record{}
+	words := strings.Fields(line)
+
+	if len(words) > 0 && words[0] == "x" {
+		r.Completed = true
+		words = words[1:]
+	} else if len(words) > 0 && priorityPattern.MatchString(words[0]) {
+		r.Priority = words[0][1:2]
+		words = words[1:]
+	}
+
+	// completed tasks may have a completion date before the creation date
+	for i := 0; i < 2 && len(words) > 0 && datePattern.MatchString(words[0]); i++ {
+		words = words[1:]
+		if !r.Completed {
+			break
+		}
+	}
+
+	title := make([]string, 0, len(words))
+	for _, word := range words {
+		switch {
+		case strings.HasPrefix(word, "due:") && len(word) > len("due:"):
+			r.DueAt = strings.TrimPrefix(word, "due:")
+		case strings.HasPrefix(word, "pri:") && len(word) == len("pri:A") && r.Priority == "":
+			r.Priority = strings.ToUpper(strings.TrimPrefix(word, "pri:"))
+		default:
+			title = append(title, word)
+		}
+	}
+	r.Title = strings.Join(title, " ")
+
+	return r
+}
+
+// dueToken formats the due date as a due: tag. The time is
+// left out when the todo is due at midnight.
+func dueToken(dueAt string) string {
+	if dueAt == "" {
+		return ""
+	}
+
+	due, err := time.Parse(time.RFC3339, dueAt)
+	if err != nil {
+		return "due:" + dueAt
+	}
+	if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+		return "due:" + due.Format("2006-01-02")
+	}
+	return "due:" + due.Format("2006-01-02T15:04")
+}
+
+// singleLine joins the lines of the text with spaces
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// newScanner creates a line scanner which allows long lines
+func newScanner(r io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), maxLineSize)
+	return s
+}
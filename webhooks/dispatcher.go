@@ -0,0 +1,91 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/events"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+)
+
+// Dispatcher queues a delivery for every webhook subscribed to a
+// published event. It is meant to be combined with the event bus
+// through events.Multi, the Worker then sends the deliveries.
+type Dispatcher struct {
+	wr repositories.WebhookRepository
+}
+
+var _ events.Publisher = &Dispatcher{}
+
+// payload is the body POSTed to the webhooks
+type payload struct {
+	Event      string                 `json:"event"`
+	Resource   string                 `json:"resource"`
+	ResourceID uint                   `json:"resource_id"`
+	Data       interface{}            `json:"data"`
+	Previous   map[string]interface{} `json:"previous,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// NewDispatcher creates Dispatcher instance
+func NewDispatcher(wr repositories.WebhookRepository) *Dispatcher {
+	return &Dispatcher{wr}
+}
+
+// Publish queues the event for the webhooks of its users, the ones of
+// the workspace of its record when it has one
+func (d *Dispatcher) Publish(e events.Event) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+
+	body, err := json.Marshal(payload{
+		Event:      e.Type,
+		Resource:   e.Resource,
+		ResourceID: e.ResourceID,
+		Data:       e.Data,
+		Previous:   e.Previous,
+		CreatedAt:  e.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	wr := d.wr
+	if data, ok := e.Data.(map[string]interface{}); ok {
+		if workspaceID, ok := data["workspace_id"].(uint); ok {
+			wr = wr.Workspace(workspaceID)
+		}
+	}
+	for _, userID := range e.UserIDs {
+		for _, w := range wr.Subscribed(userID, e.Type) {
+			delivery := &models.WebhookDelivery{
+				WebhookID:     w.ID,
+				EventType:     e.Type,
+				Payload:       string(body),
+				Status:        models.DeliveryPending,
+				NextAttemptAt: &e.CreatedAt,
+			}
+			if err := d.wr.CreateDelivery(delivery); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Redeliver queues the payload of a previous delivery again
+func (d *Dispatcher) Redeliver(previous *models.WebhookDelivery, at time.Time) (*models.WebhookDelivery, error) {
+	at = at.UTC()
+	delivery := &models.WebhookDelivery{
+		WebhookID:     previous.WebhookID,
+		EventType:     previous.EventType,
+		Payload:       previous.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &at,
+	}
+
+	return delivery, d.wr.CreateDelivery(delivery)
+}
@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent along with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrInvalidSignature is returned when a signature doesn't match
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// NewSecret generates the secret a webhook signs its payloads with
+func NewSecret() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Sign creates the signature header of the payload. The timestamp is
+// signed along with the body so receivers can reject replayed requests:
+//
+//	t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">
+func Sign(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, digest(secret, ts, body))
+}
+
+// Verify checks the signature header against the payload, rejecting
+// signatures older than the tolerance. It's what receivers should do.
+func Verify(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(digest(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// digest is the hex HMAC-SHA256 of the signed content
+func digest(secret string, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,35 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"todo.created"}`)
+
+	header := Sign("whsec_test", now, body)
+
+	assert.True(t, strings.HasPrefix(header, "t=1767344400,v1="))
+	assert.NoError(t, Verify("whsec_test", header, body, time.Minute, now.Add(30*time.Second)))
+}
+
+func TestVerifyRejectsInvalidSignatures(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"todo.created"}`)
+	header := Sign("whsec_test", now, body)
+
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_other", header, body, time.Minute, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_test", header, []byte(`{}`), time.Minute, now))
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_test", header, body, time.Minute, now.Add(2*time.Minute)))
+	assert.Equal(t, ErrInvalidSignature, Verify("whsec_test", "garbage", body, time.Minute, now))
+}
+
+func TestNewSecret(t *testing.T) {
+	assert.True(t, strings.HasPrefix(NewSecret(), "whsec_"))
+	assert.NotEqual(t, NewSecret(), NewSecret())
+}
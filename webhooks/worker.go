@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/ksungcaya/todo-echo/repositories"
+	"github.com/ksungcaya/todo-echo/scheduler"
+	"github.com/ksungcaya/todo-echo/text"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// maxResponseBody is how much of the endpoint's response is kept
+const maxResponseBody = 1024
+
+// Worker sends the queued deliveries, retrying failed ones with an
+// exponential backoff and disabling webhooks which keep failing.
+type Worker struct {
+	config configs.WebhookConfig
+	clock  scheduler.Clock
+	owner  string
+	client *http.Client
+	wr     repositories.WebhookRepository
+}
+
+// NewWorker creates Worker instance
+func NewWorker(config configs.WebhookConfig, clock scheduler.Clock, wr repositories.WebhookRepository) *Worker {
+	host, _ := os.Hostname()
+
+	return &Worker{
+		config: config,
+		clock:  clock,
+		owner:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+		client: &http.Client{Timeout: config.Timeout},
+		wr:     wr,
+	}
+}
+
+// Start sends deliveries every configured interval until ctx is done.
+// It is meant to be run in its own goroutine.
+func (w *Worker) Start(ctx context.Context) {
+	scheduler.Every(ctx, w.clock, w.config.Interval, func(ctx context.Context, now time.Time) {
+		w.Tick(ctx)
+	})
+}
+
+// Tick claims the deliveries due at the clock's current time and
+// attempts them. It returns the number of successful deliveries.
+func (w *Worker) Tick(ctx context.Context) int {
+	now := w.clock.Now().UTC()
+
+	deliveries, err := w.wr.ClaimDeliveries(w.owner, now, w.config.Lease, w.config.BatchSize)
+	if err != nil {
+		log.Errorf("webhooks: claiming deliveries: %v", err)
+	}
+
+	succeeded := 0
+	for i := range deliveries {
+		if w.attempt(ctx, &deliveries[i], now) {
+			succeeded++
+		}
+	}
+	return succeeded
+}
+
+// attempt sends the delivery and records its outcome
+func (w *Worker) attempt(ctx context.Context, d *models.WebhookDelivery, now time.Time) bool {
+	webhook := w.wr.ByID(d.WebhookID)
+	if webhook == nil || !webhook.Active {
+		d.Status = models.DeliveryFailed
+		d.LastError = "webhook is disabled"
+		w.save(d)
+		return false
+	}
+
+	d.Attempts++
+	d.LastAttemptAt = &now
+	err := w.send(ctx, webhook, d, now)
+
+	if err == nil {
+		d.Status = models.DeliverySucceeded
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		d.LastError = ""
+	} else {
+		d.LastError = text.Truncate(err.Error(), 255)
+		if d.Attempts >= w.config.MaxAttempts {
+			d.Status = models.DeliveryFailed
+			d.NextAttemptAt = nil
+		} else {
+			next := now.Add(w.config.RetryBackoff * time.Duration(1<<uint(d.Attempts-1)))
+			d.NextAttemptAt = &next
+		}
+	}
+	w.save(d)
+
+	disabled, terr := w.wr.TrackFailure(webhook.ID, err != nil, w.config.DisableAfter, now)
+	if terr != nil {
+		log.Errorf("webhooks: tracking failures of webhook %d: %v", webhook.ID, terr)
+	}
+	if disabled {
+		log.Warnf("webhooks: disabled webhook %d after %d failed attempts", webhook.ID, w.config.DisableAfter)
+	}
+
+	return err == nil
+}
+
+// send POSTs the signed payload, any non 2xx response is a failure
+func (w *Worker) send(ctx context.Context, webhook *models.Webhook, d *models.WebhookDelivery, now time.Time) error {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("User-Agent", "todo-echo-webhooks")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, now, body))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		d.ResponseStatus = 0
+		d.ResponseBody = ""
+		return err
+	}
+	defer res.Body.Close()
+
+	data, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	d.ResponseStatus = res.StatusCode
+	d.ResponseBody = string(data)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.New(res.Status)
+	}
+	return nil
+}
+
+// save stores the delivery, logging when it couldn't
+func (w *Worker) save(d *models.WebhookDelivery) {
+	if err := w.wr.SaveDelivery(d); err != nil {
+		log.Errorf("webhooks: saving delivery %d: %v", d.ID, err)
+	}
+}
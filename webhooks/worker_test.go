@@ -0,0 +1,227 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksungcaya/todo-echo/configs"
+	"github.com/ksungcaya/todo-echo/events"
+	mocks "github.com/ksungcaya/todo-echo/mocks/repositories"
+	"github.com/ksungcaya/todo-echo/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// fakeClock always tells the same time
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type WorkerTestSuite struct {
+	suite.Suite
+	clock    *fakeClock
+	repo     *mocks.WebhookRepository
+	worker   *Worker
+	endpoint *httptest.Server
+	status   int
+	received []*http.Request
+	bodies   []string
+	webhook  *models.Webhook
+	delivery models.WebhookDelivery
+}
+
+var config = configs.WebhookConfig{
+	Lease:        time.Minute,
+	BatchSize:    10,
+	Timeout:      time.Second,
+	RetryBackoff: 30 * time.Second,
+	MaxAttempts:  3,
+	DisableAfter: 5,
+}
+
+func (suite *WorkerTestSuite) SetupTest() {
+	suite.status = http.StatusOK
+	suite.received = nil
+	suite.bodies = nil
+	suite.endpoint = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		suite.received = append(suite.received, r)
+		suite.bodies = append(suite.bodies, string(body))
+		w.WriteHeader(suite.status)
+		w.Write([]byte("thanks"))
+	}))
+
+	suite.clock = &fakeClock{time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)}
+	suite.repo = &mocks.WebhookRepository{}
+	suite.worker = NewWorker(config, suite.clock, suite.repo)
+
+	suite.webhook = &models.Webhook{Model: gorm.Model{ID: 1}, URL: suite.endpoint.URL, Secret: "whsec_test", Active: true}
+	suite.delivery = models.WebhookDelivery{
+		Model:     gorm.Model{ID: 9},
+		WebhookID: 1,
+		EventType: events.TodoCreated,
+		Payload:   `{"event":"todo.created"}`,
+		Status:    models.DeliveryPending,
+	}
+
+	suite.repo.On("ByID", uint(1)).Return(suite.webhook)
+	suite.repo.On("SaveDelivery", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+}
+
+func (suite *WorkerTestSuite) TearDownTest() {
+	suite.endpoint.Close()
+}
+
+func (suite *WorkerTestSuite) claims(deliveries ...models.WebhookDelivery) {
+	suite.repo.On("ClaimDeliveries", suite.worker.owner, suite.clock.now, config.Lease, config.BatchSize).Return(deliveries, nil)
+}
+
+func (suite *WorkerTestSuite) saved() *models.WebhookDelivery {
+	for _, call := range suite.repo.Calls {
+		if call.Method == "SaveDelivery" {
+			return call.Arguments.Get(0).(*models.WebhookDelivery)
+		}
+	}
+	return nil
+}
+
+func (suite *WorkerTestSuite) TestTickSendsSignedPayloads() {
+	assert := assert.New(suite.T())
+
+	suite.claims(suite.delivery)
+	suite.repo.On("TrackFailure", uint(1), false, config.DisableAfter, suite.clock.now).Return(false, nil)
+
+	assert.Equal(1, suite.worker.Tick(context.Background()))
+
+	if assert.Len(suite.received, 1) {
+		r := suite.received[0]
+		assert.Equal(events.TodoCreated, r.Header.Get(HeaderEvent))
+		assert.Equal("9", r.Header.Get(HeaderDelivery))
+		assert.Equal(`{"event":"todo.created"}`, suite.bodies[0])
+		assert.NoError(Verify("whsec_test", r.Header.Get(HeaderSignature), []byte(suite.bodies[0]), time.Minute, suite.clock.now))
+	}
+
+	d := suite.saved()
+	assert.Equal(models.DeliverySucceeded, d.Status)
+	assert.Equal(1, d.Attempts)
+	assert.Equal(http.StatusOK, d.ResponseStatus)
+	assert.Equal("thanks", d.ResponseBody)
+	assert.Nil(d.NextAttemptAt)
+}
+
+func (suite *WorkerTestSuite) TestTickRetriesWithExponentialBackoff() {
+	assert := assert.New(suite.T())
+
+	suite.status = http.StatusBadGateway
+	suite.delivery.Attempts = 1
+	suite.claims(suite.delivery)
+	suite.repo.On("TrackFailure", uint(1), true, config.DisableAfter, suite.clock.now).Return(false, nil)
+
+	assert.Equal(0, suite.worker.Tick(context.Background()))
+
+	d := suite.saved()
+	assert.Equal(models.DeliveryPending, d.Status)
+	assert.Equal(2, d.Attempts)
+	assert.Equal(http.StatusBadGateway, d.ResponseStatus)
+	assert.Equal("502 Bad Gateway", d.LastError)
+	// second attempt waits twice the backoff
+	assert.Equal(suite.clock.now.Add(time.Minute), *d.NextAttemptAt)
+}
+
+func (suite *WorkerTestSuite) TestTickGivesUpAfterMaxAttempts() {
+	assert := assert.New(suite.T())
+
+	suite.status = http.StatusInternalServerError
+	suite.delivery.Attempts = config.MaxAttempts - 1
+	suite.claims(suite.delivery)
+	suite.repo.On("TrackFailure", uint(1), true, config.DisableAfter, suite.clock.now).Return(true, nil)
+
+	suite.worker.Tick(context.Background())
+
+	d := suite.saved()
+	assert.Equal(models.DeliveryFailed, d.Status)
+	assert.Nil(d.NextAttemptAt)
+	suite.repo.AssertCalled(suite.T(), "TrackFailure", uint(1), true, config.DisableAfter, suite.clock.now)
+}
+
+func (suite *WorkerTestSuite) TestTickSkipsDisabledWebhooks() {
+	assert := assert.New(suite.T())
+
+	suite.webhook.Active = false
+	suite.claims(suite.delivery)
+
+	suite.worker.Tick(context.Background())
+
+	assert.Empty(suite.received)
+	assert.Equal(models.DeliveryFailed, suite.saved().Status)
+	suite.repo.AssertNotCalled(suite.T(), "TrackFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *WorkerTestSuite) TestDispatcherQueuesSubscribedWebhooks() {
+	assert := assert.New(suite.T())
+
+	repo := &mocks.WebhookRepository{}
+	repo.On("Subscribed", uint(3), events.TodoUpdated).Return([]models.Webhook{*suite.webhook})
+	repo.On("CreateDelivery", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+
+	err := NewDispatcher(repo).Publish(events.Event{
+		Type:       events.TodoUpdated,
+		Resource:   "todo",
+		ResourceID: 4,
+		UserIDs:    []uint{3},
+		Data:       map[string]interface{}{"title": "Ship release"},
+		CreatedAt:  suite.clock.now,
+	})
+
+	assert.NoError(err)
+	d := repo.Calls[1].Arguments.Get(0).(*models.WebhookDelivery)
+	assert.Equal(uint(1), d.WebhookID)
+	assert.Equal(models.DeliveryPending, d.Status)
+	assert.Equal(suite.clock.now, *d.NextAttemptAt)
+	assert.JSONEq(`{
+		"event": "todo.updated",
+		"resource": "todo",
+		"resource_id": 4,
+		"data": {"title": "Ship release"},
+		"created_at": "2026-01-02T09:00:00Z"
+	}`, d.Payload)
+}
+
+func (suite *WorkerTestSuite) TestDispatcherMatchesTheWorkspaceOfTheEvent() {
+	assert := assert.New(suite.T())
+
+	scoped := &mocks.WebhookRepository{}
+	scoped.On("Subscribed", uint(3), events.TodoCreated).Return([]models.Webhook{*suite.webhook})
+	repo := &mocks.WebhookRepository{}
+	repo.On("Workspace", uint(5)).Return(scoped)
+	repo.On("CreateDelivery", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+
+	err := NewDispatcher(repo).Publish(events.Event{
+		Type:       events.TodoCreated,
+		Resource:   "todo",
+		ResourceID: 4,
+		UserIDs:    []uint{3},
+		Data:       map[string]interface{}{"workspace_id": uint(5), "title": "Ship release"},
+		CreatedAt:  suite.clock.now,
+	})
+
+	assert.NoError(err)
+	repo.AssertCalled(suite.T(), "CreateDelivery", mock.AnythingOfType("*models.WebhookDelivery"))
+	repo.AssertNotCalled(suite.T(), "Subscribed", mock.Anything, mock.Anything)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWorkerTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkerTestSuite))
+}